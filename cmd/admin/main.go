@@ -0,0 +1,11 @@
+package main // ประกาศ package main ของคำสั่งสำหรับผู้ดูแลระบบ
+
+import (
+	"os" // นำเข้า os สำหรับอ่าน argument
+
+	"go-music-api/internal/app" // นำเข้า app
+)
+
+func main() {
+	app.Admin(os.Args[1:])
+}
@@ -0,0 +1,314 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"go-music-api/internal/domain"
+	"go-music-api/internal/infrastructure/database"
+	"go-music-api/internal/repository/postgres"
+	"go-music-api/internal/service"
+
+	"github.com/joho/godotenv"
+	"gorm.io/gorm"
+)
+
+// adminUsage ข้อความแสดงคำสั่งที่มี
+const adminUsage = `usage: admin <command> [flags]
+
+commands:
+  migrate           apply, roll back, list or create database schema migrations (up|down|status|create)
+  storage-migrate   copy track media from one storage backend to another and rewrite the URLs
+  media-gc          report files no row references and references whose file is missing, optionally delete the orphans
+`
+
+// Admin รันคำสั่งสำหรับผู้ดูแลระบบ เช่น go run ./cmd/admin storage-migrate -from local -to s3
+func Admin(args []string) {
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using system environment variables")
+	}
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, adminUsage)
+		os.Exit(2)
+	}
+
+	// Ctrl+C หยุดหลังงานที่ทำอยู่เสร็จ
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var err error
+	switch args[0] {
+	case "migrate":
+		err = migrate(ctx, args[1:])
+	case "storage-migrate":
+		err = migrateStorage(ctx, args[1:])
+	case "media-gc":
+		err = mediaGC(ctx, args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", args[0], adminUsage)
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatalf("%s: %v", args[0], err)
+	}
+}
+
+// migrateUsage ข้อความแสดงคำสั่งย่อยของ migrate
+const migrateUsage = `usage: admin migrate <command> [flags]
+
+commands:
+  up [-steps n]            apply pending migrations (all by default)
+  down [-steps n]          roll back the latest applied migrations (1 by default)
+  status                   list migrations and when they were applied
+  create [-dir d] <name>   add empty up/down files with the next version
+`
+
+// migrate จัดการ schema ของฐานข้อมูลด้วย migration ที่ฝังไว้ใน binary
+func migrate(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, migrateUsage)
+		os.Exit(2)
+	}
+	fs := flag.NewFlagSet("migrate "+args[0], flag.ExitOnError)
+	switch args[0] {
+	case "up", "down":
+		defaultSteps := 0
+		if args[0] == "down" {
+			defaultSteps = 1
+		}
+		steps := fs.Int("steps", defaultSteps, "number of migrations (0 = all, up only)")
+		fs.Parse(args[1:])
+		if *steps < 0 || (args[0] == "down" && *steps == 0) {
+			return errors.New("-steps must be at least 1 for down and not negative for up")
+		}
+		db, err := database.NewPostgresDB()
+		if err != nil {
+			return err
+		}
+		if args[0] == "up" {
+			// ขั้นตอน Go ของ migration ตัดคำด้วยพจนานุกรมเดียวกับ API
+			if err := loadThaiDictionary(); err != nil {
+				return err
+			}
+			return migrateUp(ctx, db, *steps)
+		}
+		migrator, err := newMigrator(db)
+		if err != nil {
+			return err
+		}
+		reverted, err := migrator.Down(ctx, *steps)
+		for _, m := range reverted {
+			log.Printf("Reverted migration %d_%s", m.Version, m.Name)
+		}
+		return err
+	case "status":
+		fs.Parse(args[1:])
+		db, err := database.NewPostgresDB()
+		if err != nil {
+			return err
+		}
+		migrator, err := newMigrator(db)
+		if err != nil {
+			return err
+		}
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			state := "pending"
+			if s.AppliedAt != nil {
+				state = "applied " + s.AppliedAt.Local().Format(time.RFC3339)
+			}
+			if s.Missing {
+				state += " (not in this binary)"
+			}
+			fmt.Printf("%04d  %-40s %s\n", s.Version, s.Name, state)
+		}
+		return nil
+	case "create":
+		dir := fs.String("dir", database.MigrationsDir, "directory of the migration files")
+		fs.Parse(args[1:])
+		if fs.NArg() != 1 {
+			return errors.New("usage: admin migrate create [-dir d] <name>")
+		}
+		up, down, err := database.CreateMigration(*dir, fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		fmt.Println(up)
+		fmt.Println(down)
+		return nil
+	}
+	fmt.Fprintf(os.Stderr, "unknown migrate command %q\n\n%s", args[0], migrateUsage)
+	os.Exit(2)
+	return nil
+}
+
+// dataMigrations ขั้นตอนที่เขียนด้วย Go ของ migration แต่ละเวอร์ชัน (ย้ายข้อมูลเดิมให้เข้ากับ schema ใหม่)
+var dataMigrations = []database.DataMigration{
+	{Version: 3, Up: postgres.BackfillMusicSearchVectors}, // สร้าง search_vector ให้เพลงเดิม (ตัดคำภาษาไทยใน Go)
+	{Version: 4, Up: postgres.LinkMusicArtists},           // เชื่อมเพลงเดิมที่มีแต่ชื่อศิลปินแบบข้อความเข้ากับ Artist
+}
+
+// newMigrator สร้าง Migrator ที่รวมขั้นตอน Go ของ migration ไว้ด้วย
+func newMigrator(db *gorm.DB) (*database.Migrator, error) {
+	return database.NewMigrator(db, dataMigrations...)
+}
+
+// migrateUp รัน migration ที่ยังไม่ได้รัน สูงสุด steps เวอร์ชัน (0 = ทั้งหมด) และ log เวอร์ชันที่รัน
+// เมื่อรันครบทุกเวอร์ชันจะตั้ง admin ตาม ADMIN_EMAILS (คั่นด้วย ,) ภายใต้ lock เดียวกัน
+func migrateUp(ctx context.Context, db *gorm.DB, steps int) error {
+	migrator, err := newMigrator(db)
+	if err != nil {
+		return err
+	}
+	applied, err := migrator.Up(ctx, steps)
+	for _, m := range applied {
+		log.Printf("Applied migration %d_%s", m.Version, m.Name)
+	}
+	if err != nil || steps > 0 {
+		return err
+	}
+	return migrator.Seed(ctx, func(ctx context.Context, tx *gorm.DB) error {
+		return postgres.PromoteAdmins(ctx, tx, listEnv("ADMIN_EMAILS"))
+	})
+}
+
+// migrateStorage คัดลอกไฟล์ของเพลงจาก storage ต้นทางไปปลายทาง ตรวจ checksum และแก้ URL ในฐานข้อมูล
+func migrateStorage(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("storage-migrate", flag.ExitOnError)
+	from := fs.String("from", "local", "source storage (local or s3)")
+	to := fs.String("to", "s3", "destination storage (local or s3)")
+	dryRun := fs.Bool("dry-run", false, "only report what would be copied")
+	afterID := fs.Uint("after", 0, "skip tracks with an ID up to this value (resume from a previous run's last_id)")
+	batchSize := fs.Int("batch", 100, "tracks read per batch")
+	reportFile := fs.String("report", "", "also write the report as JSON to this file")
+	fs.Parse(args)
+
+	if *from == *to {
+		return errors.New("-from and -to must be different storage types")
+	}
+	source, err := newBlobStorage(*from)
+	if err != nil {
+		return fmt.Errorf("source: %w", err)
+	}
+	dest, err := newBlobStorage(*to)
+	if err != nil {
+		return fmt.Errorf("destination: %w", err)
+	}
+	db, err := database.NewPostgresDB()
+	if err != nil {
+		return err
+	}
+
+	migration := service.NewStorageMigrationService(postgres.NewMusicRepository(db), postgres.NewMediaObjectRepository(db), source, dest, 30*time.Second)
+	log.Printf("Migrating media from %s to %s (dry run: %t)", *from, *to, *dryRun)
+	report, runErr := migration.Run(ctx, domain.StorageMigrationOptions{DryRun: *dryRun, AfterID: uint(*afterID), BatchSize: *batchSize})
+
+	printMigrationReport(report)
+	if *reportFile != "" {
+		if err := writeJSONReport(*reportFile, report); err != nil {
+			return err
+		}
+	}
+	if runErr != nil {
+		return fmt.Errorf("stopped after track %d (run again to continue): %w", report.LastID, runErr)
+	}
+	if len(report.Failed) > 0 {
+		return fmt.Errorf("%d files failed, run again to retry", len(report.Failed))
+	}
+	return nil
+}
+
+// printMigrationReport แสดงสรุปผลการย้ายไฟล์
+func printMigrationReport(report *domain.StorageMigrationReport) {
+	fmt.Printf("tracks checked:    %d (last id %d)\n", report.Tracks, report.LastID)
+	fmt.Printf("files copied:      %d (%d bytes)\n", report.Copied, report.Bytes)
+	fmt.Printf("already migrated:  %d\n", report.Migrated)
+	fmt.Printf("external URLs:     %d\n", report.External)
+	fmt.Printf("missing in source: %d\n", len(report.Missing))
+	for _, m := range report.Missing {
+		fmt.Printf("  music %d %s %s\n", m.MusicID, m.Field, m.URL)
+	}
+	fmt.Printf("failed:            %d\n", len(report.Failed))
+	for _, f := range report.Failed {
+		fmt.Printf("  music %d %s %s: %s\n", f.MusicID, f.Field, f.URL, f.Error)
+	}
+}
+
+// mediaGC เทียบไฟล์ใน storage กับ URL ในฐานข้อมูล และลบไฟล์กำพร้าเมื่อระบุ -delete
+func mediaGC(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("media-gc", flag.ExitOnError)
+	kind := fs.String("storage", stringEnv("STORAGE_TYPE", "local"), "storage to check (local or s3)")
+	deleteOrphans := fs.Bool("delete", false, "delete orphaned files older than -grace")
+	grace := fs.Duration("grace", 24*time.Hour, "ignore unreferenced files modified within this period")
+	reportFile := fs.String("report", "", "also write the report as JSON to this file")
+	fs.Parse(args)
+
+	blob, err := newBlobStorage(*kind)
+	if err != nil {
+		return err
+	}
+	db, err := database.NewPostgresDB()
+	if err != nil {
+		return err
+	}
+
+	gc := service.NewMediaGCService(postgres.NewMediaObjectRepository(db), blob, 30*time.Second)
+	log.Printf("Checking %s storage (delete orphans: %t, grace period: %s)", *kind, *deleteOrphans, *grace)
+	report, runErr := gc.Run(ctx, domain.MediaGCOptions{DeleteOrphans: *deleteOrphans, GracePeriod: *grace})
+
+	printMediaGCReport(report)
+	if *reportFile != "" {
+		if err := writeJSONReport(*reportFile, report); err != nil {
+			return err
+		}
+	}
+	if runErr != nil {
+		return runErr
+	}
+	if failed := len(report.Orphans) - report.Deleted; *deleteOrphans && failed > 0 {
+		return fmt.Errorf("%d orphaned files could not be deleted", failed)
+	}
+	return nil
+}
+
+// printMediaGCReport แสดงสรุปผลการตรวจไฟล์
+func printMediaGCReport(report *domain.MediaGCReport) {
+	fmt.Printf("files in storage:    %d (%d bytes)\n", report.Files, report.Bytes)
+	fmt.Printf("references:          %d (%d external)\n", report.References, report.External)
+	fmt.Printf("within grace period: %d\n", report.Recent)
+	fmt.Printf("orphaned files:      %d (%d bytes, %d deleted)\n", len(report.Orphans), report.OrphanBytes, report.Deleted)
+	for _, o := range report.Orphans {
+		switch {
+		case o.Deleted:
+			fmt.Printf("  deleted %s\n", o.URL)
+		case o.Error != "":
+			fmt.Printf("  %s: %s\n", o.URL, o.Error)
+		default:
+			fmt.Printf("  %s (%d bytes, modified %s)\n", o.URL, o.Size, o.ModTime.Format(time.RFC3339))
+		}
+	}
+	fmt.Printf("missing files:       %d\n", len(report.Missing))
+	for _, m := range report.Missing {
+		fmt.Printf("  %s %s %s %s\n", m.Table, m.ID, m.Column, m.URL)
+	}
+}
+
+// writeJSONReport เขียนรายงานเป็นไฟล์ JSON
+func writeJSONReport(path string, report any) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
@@ -1,8 +1,14 @@
 package app
 
 import (
+	"context"
+	"crypto/rand"
+	"fmt"
 	"log"
+	"net/http"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"go-music-api/docs"
@@ -10,9 +16,14 @@ import (
 	"go-music-api/internal/delivery/http/middleware"
 	"go-music-api/internal/domain"
 	"go-music-api/internal/infrastructure/database"
+	"go-music-api/internal/infrastructure/mailer"
+	"go-music-api/internal/infrastructure/oidc"
+	"go-music-api/internal/infrastructure/ratelimit"
 	"go-music-api/internal/infrastructure/storage"
 	"go-music-api/internal/repository/postgres"
 	"go-music-api/internal/service"
+	"go-music-api/pkg/textseg"
+	"go-music-api/pkg/utils"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -29,6 +40,21 @@ func Run() {
 		log.Println("No .env file found, using system environment variables")
 	}
 
+	// JWT keys
+	// โหลด key สำหรับเซ็นและตรวจสอบ access token
+	jwtKeys, err := loadJWTKeys()
+	if err != nil {
+		log.Fatalf("Failed to load JWT keys: %v", err)
+	}
+	utils.SetKeyManager(jwtKeys)
+	log.Printf("Signing JWTs with %s key %s", jwtKeys.Active().Algorithm, jwtKeys.Active().KID)
+
+	// Thai dictionary
+	// โหลดพจนานุกรมคำภาษาไทยเพิ่มเติมสำหรับการตัดคำในการค้นหา (ก่อน migrate เพราะ migration สร้าง search_vector ของเพลงเดิม)
+	if err := loadThaiDictionary(); err != nil {
+		log.Fatalf("Failed to load Thai dictionary: %v", err)
+	}
+
 	// Init Database
 	// เริ่มต้นการเชื่อมต่อฐานข้อมูล Postgres
 	db, err := database.NewPostgresDB()
@@ -37,76 +63,228 @@ func Run() {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 
+	// Migrations
+	// รัน migration ที่ยังไม่ได้รัน (instance ที่เริ่มพร้อมกันจะรอกันด้วย advisory lock)
+	// SKIP_MIGRATIONS=true ไม่ migrate ตอนเริ่ม (รัน admin migrate up เองก่อน deploy)
+	if skip, _ := strconv.ParseBool(os.Getenv("SKIP_MIGRATIONS")); skip {
+		log.Println("SKIP_MIGRATIONS is set, not running database migrations")
+	} else if err := migrateUp(context.Background(), db, 0); err != nil {
+		log.Fatalf("Failed to migrate database: %v", err)
+	}
+
 	// Init Storage
 	// ตรวจสอบประเภท Storage ที่ต้องการใช้ (local หรือ s3)
 	storageType := os.Getenv("STORAGE_TYPE")
-	var storageService domain.StorageService
+	// storage ที่เก็บไฟล์ตาม SHA-256 ของเนื้อหา (ครอบด้วยการนับการอ้างอิงหลังเชื่อมต่อฐานข้อมูล)
+	var blobStorage domain.BlobStorage
+	// storage ที่ให้ client อัปโหลดไฟล์ได้โดยตรงผ่าน URL ที่ลงลายเซ็น
+	var directUploader domain.DirectUploadStorage
 
-	// ตัวแปรสำหรับเก็บ path upload (ใช้เฉพาะ Local Storage)
+	// ตัวแปรสำหรับเก็บ path upload และ storage ในเครื่อง (ใช้เฉพาะ Local Storage)
 	var uploadDir string
+	var localStorage *storage.LocalStorage
 
 	if storageType == "s3" {
-		// ถ้าเลือกใช้ S3
-		bucketName := os.Getenv("AWS_BUCKET_NAME")
-		region := os.Getenv("AWS_REGION")
-		if bucketName == "" || region == "" {
-			log.Fatal("AWS_BUCKET_NAME and AWS_REGION are required for s3 storage")
-		}
-		// เริ่มต้น S3 Storage
-		var err error
-		storageService, err = storage.NewS3Storage(bucketName, region)
+		// ถ้าเลือกใช้ S3 (กำหนด S3_ENDPOINT เพื่อใช้ S3-compatible storage เช่น MinIO)
+		s3Storage, err := newS3Storage()
 		if err != nil {
 			log.Fatalf("Failed to initialize S3 storage: %v", err)
 		}
+		blobStorage = s3Storage
+		directUploader = s3Storage
 		log.Println("Using S3 Storage")
 	} else {
 		// Default ใช้ Local Storage
-		// อ่านค่า UPLOAD_DIR จาก environment variable
-		uploadDir = os.Getenv("UPLOAD_DIR")
-		if uploadDir == "" {
-			// ถ้าไม่ได้ตั้งค่าไว้ ให้ใช้ค่า default เป็น "./uploads"
-			uploadDir = "./uploads"
-		}
-		// อ่านค่า BASE_URL จาก environment variable
-		baseURL := os.Getenv("BASE_URL")
-		if baseURL == "" {
-			// ถ้าไม่ได้ตั้งค่าไว้ ให้ใช้ค่า default
-			baseURL = "http://localhost:8080/uploads"
-		}
-
-		// เริ่มต้น service สำหรับจัดการไฟล์ (Local Storage)
-		var err error
-		storageService, err = storage.NewLocalStorage(uploadDir, baseURL)
+		localStorage, uploadDir, err = newLocalStorage()
 		if err != nil {
 			// ถ้าเริ่มต้นไม่ได้ ให้จบการทำงานและแสดง error
 			log.Fatalf("Failed to initialize storage: %v", err)
 		}
+		blobStorage = localStorage
+		directUploader = localStorage
 		log.Println("Using Local Storage")
 	}
 
+	// Init Mailer
+	// เลือกวิธีส่งอีเมล: smtp หรือ log (default, เขียนอีเมลลง log หรือไฟล์ MAIL_LOG_FILE สำหรับพัฒนาในเครื่อง)
+	mailFrom := os.Getenv("MAIL_FROM")
+	if mailFrom == "" {
+		mailFrom = "Go Music API <no-reply@localhost>"
+	}
+	var mailService domain.Mailer
+	if os.Getenv("MAIL_DRIVER") == "smtp" {
+		smtpPort := 587
+		if v := os.Getenv("SMTP_PORT"); v != "" {
+			if smtpPort, err = strconv.Atoi(v); err != nil {
+				log.Fatalf("Invalid SMTP_PORT: %q", v)
+			}
+		}
+		smtpMailer, err := mailer.NewSMTPMailer(os.Getenv("SMTP_HOST"), smtpPort, os.Getenv("SMTP_USERNAME"), os.Getenv("SMTP_PASSWORD"), mailFrom)
+		if err != nil {
+			log.Fatalf("Failed to initialize SMTP mailer: %v", err)
+		}
+		mailService = smtpMailer
+		log.Println("Using SMTP mailer")
+	} else {
+		mailService = mailer.NewLogMailer(mailFrom, os.Getenv("MAIL_LOG_FILE"))
+		log.Println("Using log mailer (emails are not sent)")
+	}
+
 	// Init Repositories
 	// สร้าง repository สำหรับจัดการข้อมูล Music โดยใช้ db connection ที่สร้างไว้
 	musicRepo := postgres.NewMusicRepository(db)
 	// สร้าง repository สำหรับจัดการข้อมูล User
 	userRepo := postgres.NewUserRepository(db)
+	// สร้าง repository สำหรับจัดการข้อมูล Artist และ Album
+	artistRepo := postgres.NewArtistRepository(db)
+	albumRepo := postgres.NewAlbumRepository(db)
+	// สร้าง repository สำหรับจัดการ Playlist
+	playlistRepo := postgres.NewPlaylistRepository(db)
+	// สร้าง repository สำหรับจัดการการอัปโหลดตรง
+	uploadRepo := postgres.NewUploadRepository(db)
+	resumableUploadRepo := postgres.NewResumableUploadRepository(db)
+	// สร้าง repository สำหรับจัดการ session และ refresh token
+	sessionRepo := postgres.NewSessionRepository(db)
+	// สร้าง repository สำหรับ token รีเซ็ตรหัสผ่านและยืนยันอีเมล
+	accountTokenRepo := postgres.NewAccountTokenRepository(db)
+	// สร้าง repository สำหรับ 2FA
+	twoFactorRepo := postgres.NewTwoFactorRepository(db)
+	// สร้าง repository สำหรับ API key
+	apiKeyRepo := postgres.NewAPIKeyRepository(db)
+	// สร้าง repository สำหรับบัญชีภายนอก (OpenID Connect)
+	identityRepo := postgres.NewIdentityRepository(db)
+	// สร้าง repository สำหรับคิวลบไฟล์
+	mediaDeletionRepo := postgres.NewMediaDeletionRepository(db)
+	// สร้าง repository สำหรับนับการอ้างอิงไฟล์ใน storage
+	mediaObjectRepo := postgres.NewMediaObjectRepository(db)
+	// ไฟล์เนื้อหาเดียวกันเก็บครั้งเดียว และถูกลบเมื่อไม่มีเพลงอ้างถึงแล้ว
+	storageService := service.NewMediaStorage(blobStorage, mediaObjectRepo)
 
 	// Init Services
 	// กำหนด timeout สำหรับ context เป็น 5 วินาที
 	timeout := 5 * time.Second
 	// สร้าง service สำหรับ Music โดยส่ง repository, storage service และ timeout เข้าไป
-	musicService := service.NewMusicService(musicRepo, storageService, timeout)
-	// สร้าง service สำหรับ User
-	userService := service.NewUserService(userRepo, timeout)
+	// transaction ที่ครอบหลาย repository (unit of work)
+	txManager := postgres.NewTxManager(db)
+	musicService := service.NewMusicService(musicRepo, artistRepo, albumRepo, txManager, storageService, timeout)
+	// สร้าง service สำหรับ session (อายุ refresh token นับจากการใช้งานล่าสุด) และ User
+	sessionService := service.NewSessionService(sessionRepo, userRepo, durationEnv("REFRESH_TOKEN_TTL", 7*24*time.Hour), timeout)
+	// REQUIRE_EMAIL_VERIFICATION=true ไม่ให้บัญชีที่ยังไม่ยืนยันอีเมลเข้าสู่ระบบ
+	requireVerified, _ := strconv.ParseBool(os.Getenv("REQUIRE_EMAIL_VERIFICATION"))
+	// สร้าง service สำหรับ 2FA (ชื่อที่แสดงในแอปยืนยันตัวตนกำหนดได้จาก TOTP_ISSUER)
+	twoFactorService := service.NewTwoFactorService(twoFactorRepo, userRepo, sessionService,
+		stringEnv("TOTP_ISSUER", "Go Music API"), durationEnv("TWO_FACTOR_CHALLENGE_TTL", 5*time.Minute), timeout)
+	go runPeriodically(context.Background(), "2fa challenge cleanup", durationEnv("SESSION_CLEANUP_INTERVAL", time.Hour), twoFactorService.CleanupExpired)
+	// ล็อกอีเมลที่เข้าสู่ระบบผิดครบ LOGIN_LOCKOUT_THRESHOLD ครั้ง นาน LOGIN_LOCKOUT_BASE และเพิ่มเท่าตัวเมื่อผิดต่อ (ไม่เกิน LOGIN_LOCKOUT_MAX)
+	loginLockout := ratelimit.NewMemoryLockout(int(sizeEnv("LOGIN_LOCKOUT_THRESHOLD", 5)),
+		durationEnv("LOGIN_LOCKOUT_BASE", time.Minute), durationEnv("LOGIN_LOCKOUT_MAX", time.Hour))
+	go runPeriodically(context.Background(), "login lockout cleanup", time.Hour, loginLockout.Prune)
+	userService := service.NewUserService(userRepo, musicRepo, playlistRepo, sessionService, twoFactorService, loginLockout, requireVerified, timeout)
+	// สร้าง service สำหรับรีเซ็ตรหัสผ่านและยืนยันอีเมล (ลิงก์ในอีเมลกำหนดได้จาก environment variable)
+	accountService := service.NewAccountService(userRepo, accountTokenRepo, sessionService, mailService,
+		stringEnv("PASSWORD_RESET_URL", "http://localhost:8080/reset-password"),
+		stringEnv("EMAIL_VERIFY_URL", "http://localhost:8080/api/v1/auth/verify-email"),
+		durationEnv("PASSWORD_RESET_TTL", time.Hour), durationEnv("EMAIL_VERIFY_TTL", 48*time.Hour), timeout)
+	go runPeriodically(context.Background(), "account token cleanup", durationEnv("ACCOUNT_TOKEN_CLEANUP_INTERVAL", time.Hour), accountService.CleanupExpired)
+	// ลบ session ที่หมดอายุหรือถูกยกเลิกเป็นระยะ
+	go runPeriodically(context.Background(), "session cleanup", durationEnv("SESSION_CLEANUP_INTERVAL", time.Hour), sessionService.CleanupExpired)
+	// สร้าง service สำหรับเข้าสู่ระบบผ่านผู้ให้บริการ OpenID Connect
+	oidcProviders, err := loadOIDCProviders()
+	if err != nil {
+		log.Fatalf("Failed to configure OIDC providers: %v", err)
+	}
+	oidcService := service.NewOIDCService(oidcProviders, identityRepo, userRepo, twoFactorService, sessionService, timeout)
+	go runPeriodically(context.Background(), "oidc state cleanup", durationEnv("SESSION_CLEANUP_INTERVAL", time.Hour), oidcService.CleanupExpired)
+	// สร้าง service สำหรับ API key ของสคริปต์และบริการอื่น
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo, userRepo, timeout)
+	// สร้าง service สำหรับ Artist และ Album
+	artistService := service.NewArtistService(artistRepo, timeout)
+	albumService := service.NewAlbumService(albumRepo, artistRepo, timeout)
+	// สร้าง service สำหรับ Playlist
+	playlistService := service.NewPlaylistService(playlistRepo, musicRepo, userRepo, timeout)
+	// สร้าง service สำหรับ URL ไฟล์แบบมีลายเซ็น โดยกำหนดอายุแยกตามประเภทไฟล์
+	mediaURLService := service.NewMediaURLService(storageService, map[domain.MediaKind]time.Duration{
+		domain.MediaAudio: durationEnv("MEDIA_URL_TTL_AUDIO", time.Hour),
+		domain.MediaVideo: durationEnv("MEDIA_URL_TTL_VIDEO", 2*time.Hour),
+		domain.MediaImage: durationEnv("MEDIA_URL_TTL_IMAGE", 24*time.Hour),
+	})
+	// สร้าง service สำหรับอัปโหลดไฟล์ใหญ่ตรงไปยัง storage (ขนาดสูงสุดและอายุ URL กำหนดได้จาก environment variable)
+	uploadService := service.NewUploadService(uploadRepo, musicRepo, storageService, directUploader,
+		sizeEnv("DIRECT_UPLOAD_MAX_SIZE", 2<<30), durationEnv("UPLOAD_URL_TTL", time.Hour), timeout)
+
+	// Resumable uploads (tus)
+	// พัก chunk ของการอัปโหลดแบบต่อได้ไว้ในเครื่อง แล้วส่งเข้า storage เมื่อได้รับครบ
+	tusDir := os.Getenv("TUS_UPLOAD_DIR")
+	if tusDir == "" {
+		tusDir = "./tus_uploads"
+	}
+	chunkStore, err := storage.NewLocalChunkStore(tusDir)
+	if err != nil {
+		log.Fatalf("Failed to initialize tus upload directory: %v", err)
+	}
+	resumableUploadService := service.NewResumableUploadService(resumableUploadRepo, musicRepo, storageService, chunkStore,
+		sizeEnv("TUS_MAX_SIZE", 2<<30), durationEnv("TUS_UPLOAD_TTL", 24*time.Hour), timeout)
+	// ลบ chunk ของการอัปโหลดที่ถูกทิ้งไว้จนหมดอายุเป็นระยะ
+	go runPeriodically(context.Background(), "tus cleanup", durationEnv("TUS_CLEANUP_INTERVAL", time.Hour), resumableUploadService.CleanupExpired)
+
+	// ลบไฟล์ที่อยู่ในคิว (เช่น ไฟล์เพลงของบัญชีที่ถูกลบ) เป็นระยะ ไฟล์ที่ลบไม่สำเร็จจะลองใหม่ในรอบถัดไป
+	mediaDeletionInterval := durationEnv("MEDIA_DELETION_INTERVAL", 5*time.Minute)
+	mediaDeletionService := service.NewMediaDeletionService(mediaDeletionRepo, txManager, blobStorage, mediaDeletionInterval, timeout)
+	go runPeriodically(context.Background(), "media deletion", mediaDeletionInterval, mediaDeletionService.ProcessDue)
+
+	// ตรวจไฟล์กำพร้าและไฟล์ที่หายไปเป็นระยะ เมื่อกำหนด MEDIA_GC_INTERVAL (ค่าเริ่มต้นรายงานลง log อย่างเดียว)
+	if os.Getenv("MEDIA_GC_INTERVAL") != "" {
+		mediaGCDelete, _ := strconv.ParseBool(os.Getenv("MEDIA_GC_DELETE"))
+		mediaGCOptions := domain.MediaGCOptions{DeleteOrphans: mediaGCDelete, GracePeriod: durationEnv("MEDIA_GC_GRACE", 24*time.Hour)}
+		mediaGCService := service.NewMediaGCService(mediaObjectRepo, blobStorage, 30*time.Second)
+		go runPeriodically(context.Background(), "media gc", durationEnv("MEDIA_GC_INTERVAL", 24*time.Hour), func(ctx context.Context) (int, error) {
+			report, err := mediaGCService.Run(ctx, mediaGCOptions)
+			if err != nil {
+				return 0, err
+			}
+			if len(report.Orphans) > report.Deleted || len(report.Missing) > 0 {
+				log.Printf("media gc: %d orphaned file(s) kept, %d reference(s) to missing files (run `admin media-gc` for details)",
+					len(report.Orphans)-report.Deleted, len(report.Missing))
+			}
+			return report.Deleted, nil
+		})
+	}
 
 	// Init Handlers
 	// สร้าง handler สำหรับ Music โดยส่ง service เข้าไป
-	musicHandler := handler.NewMusicHandler(musicService)
+	musicHandler := handler.NewMusicHandler(musicService, mediaURLService)
 	// สร้าง handler สำหรับ User
-	userHandler := handler.NewUserHandler(userService)
+	userHandler := handler.NewUserHandler(userService, sessionService, accountService)
+	twoFactorHandler := handler.NewTwoFactorHandler(twoFactorService, userService)
+	// สร้าง handler สำหรับเข้าสู่ระบบผ่าน OpenID Connect
+	oidcHandler := handler.NewOIDCHandler(oidcService)
+	// สร้าง handler สำหรับ API key
+	apiKeyHandler := handler.NewAPIKeyHandler(apiKeyService)
+	// สร้าง handler สำหรับ Artist และ Album
+	artistHandler := handler.NewArtistHandler(artistService)
+	albumHandler := handler.NewAlbumHandler(albumService, mediaURLService)
+	// สร้าง handler สำหรับ Playlist
+	playlistHandler := handler.NewPlaylistHandler(playlistService, mediaURLService)
+	// สร้าง handler สำหรับการอัปโหลดตรง (รับไฟล์เองเฉพาะ Local Storage)
+	var uploadReceiver handler.LocalUploadReceiver
+	if localStorage != nil {
+		uploadReceiver = localStorage
+	}
+	uploadHandler := handler.NewUploadHandler(uploadService, mediaURLService, uploadReceiver)
+	// สร้าง handler สำหรับตรวจว่ามีไฟล์อยู่แล้วก่อนอัปโหลด
+	mediaObjectHandler := handler.NewMediaObjectHandler(service.NewMediaObjectService(mediaObjectRepo, timeout))
+	// สร้าง handler สำหรับการอัปโหลดแบบต่อได้ (tus)
+	tusHandler := handler.NewTusHandler(resumableUploadService)
+	// สร้าง handler สำหรับ public key ของ JWT
+	jwksHandler := handler.NewJWKSHandler(jwtKeys)
 
 	// Init Router
 	// สร้าง router ของ Gin (Default จะมี Logger และ Recovery middleware มาให้)
 	r := gin.Default()
+	// เชื่อ X-Forwarded-For เฉพาะจาก proxy ใน TRUSTED_PROXIES (คั่นด้วย ,) ไม่อย่างนั้น client ปลอม IP เพื่อหลบ rate limit ได้
+	if err := r.SetTrustedProxies(listEnv("TRUSTED_PROXIES")); err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
 	// Allow multipart parsing for requests with large files.
 	// Note: This is not a security limit; actual per-file limit is enforced in handler.
 	r.MaxMultipartMemory = 25 << 20 // 25MB
@@ -116,42 +294,170 @@ func Run() {
 	r.Use(middleware.CORSMiddleware())
 
 	// Static files for uploads
-	// กำหนด path /uploads ให้เข้าถึงไฟล์ในโฟลเดอร์ uploadDir ได้โดยตรง (เฉพาะ Local Storage)
-	if storageType != "s3" && uploadDir != "" {
-		r.Static("/uploads", uploadDir)
+	// กำหนด path /uploads ให้เข้าถึงไฟล์ในโฟลเดอร์ uploadDir ได้เฉพาะ URL ที่ลงลายเซ็น (เฉพาะ Local Storage)
+	if localStorage != nil {
+		r.Group("/uploads", middleware.SignedURLMiddleware(localStorage)).Static("/", uploadDir)
 	}
 
 	// Swagger docs (Gin Swagger)
 	docs.SwaggerInfo.Host = "localhost:" + os.Getenv("PORT")
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	// public key สำหรับให้ service อื่นตรวจสอบ access token
+	r.GET("/.well-known/jwks.json", jwksHandler.Get)
+
+	// Rate limits
+	// จำกัด request ต่อกลุ่ม route: auth นับต่อ IP ส่วนกลุ่มอื่นนับต่อ API key หรือผู้ใช้ (ค่า "off" ปิดการจำกัด)
+	rateStore := ratelimit.NewMemoryStore()
+	go runPeriodically(context.Background(), "rate limit cleanup", 10*time.Minute, rateStore.Prune)
+	authLimit := middleware.RateLimit(rateStore, "auth", rateEnv("RATE_LIMIT_AUTH", "20/1m"), middleware.ByIP)
+	apiLimit := middleware.RateLimit(rateStore, "api", rateEnv("RATE_LIMIT_API", "300/1m"), middleware.ByAPIKey)
+	uploadLimit := middleware.RateLimit(rateStore, "uploads", rateEnv("RATE_LIMIT_UPLOADS", "600/1m"), middleware.ByAPIKey)
+
+	// Authentication
+	// route ที่ต้องเข้าสู่ระบบรับทั้ง access token และ API key ยกเว้นการจัดการบัญชีซึ่งต้องเข้าสู่ระบบด้วยตัวเอง
+	authenticate := middleware.AuthMiddleware(apiKeyService)
+	userOnly := middleware.RejectAPIKeys()
+
 	// Routes
 	api := r.Group("/api/v1")
 	{
 		auth := api.Group("/auth")
+		auth.Use(authLimit)
 		{
 			auth.POST("/register", userHandler.Register)
 			auth.POST("/login", userHandler.Login)
+			auth.POST("/2fa/verify", twoFactorHandler.Verify)
+			auth.GET("/oidc/providers", oidcHandler.Providers)
+			auth.GET("/oidc/:provider/login", oidcHandler.Login)
+			auth.GET("/oidc/:provider/callback", oidcHandler.Callback)
 			auth.POST("/refresh-token", userHandler.RefreshToken)
+			auth.POST("/logout", userHandler.Logout)
+			auth.POST("/logout-all", authenticate, userOnly, userHandler.LogoutAll)
+			auth.POST("/forgot-password", userHandler.ForgotPassword)
+			auth.POST("/reset-password", userHandler.ResetPassword)
+			auth.GET("/verify-email", userHandler.VerifyEmail)
+			auth.POST("/verify-email", userHandler.VerifyEmail)
+			auth.POST("/resend-verification", userHandler.ResendVerification)
 		}
 
+		// สิทธิ์ตามบทบาท: listener อ่านอย่างเดียว, editor สร้าง/แก้ไขเพลงของตัวเองและข้อมูลศิลปิน/อัลบั้ม, admin จัดการทุกอย่าง
+		// (การตรวจว่าเป็นเจ้าของเพลงทำใน service)
+		canWriteMusic := middleware.RequirePermission(domain.PermMusicWrite)
+		canWriteCatalog := middleware.RequirePermission(domain.PermCatalogWrite)
+		canDeleteCatalog := middleware.RequirePermission(domain.PermCatalogDelete)
+		canWritePlaylists := middleware.RequirePermission(domain.PermPlaylistWrite)
+		// การลบข้อมูลศิลปิน/อัลบั้มและงานของ admin ต้องใช้ token ที่เข้าสู่ระบบด้วย 2FA
+		requireMFA := middleware.RequireTwoFactor()
+
 		music := api.Group("/music")
-		music.Use(middleware.AuthMiddleware())
+		music.Use(authenticate, apiLimit, middleware.RequirePermission(domain.PermMusicRead))
 		{
-			music.POST("", musicHandler.Create)
-			music.POST("/", musicHandler.Create)
+			music.POST("", canWriteMusic, musicHandler.Create)
+			music.POST("/", canWriteMusic, musicHandler.Create)
 			music.GET("", musicHandler.GetAll)
 			music.GET("/", musicHandler.GetAll)
+			music.GET("/search", musicHandler.Search)
 			music.GET("/:id", musicHandler.GetByID)
-			music.PUT("/:id", musicHandler.Update)
-			music.DELETE("/:id", musicHandler.Delete)
+			music.GET("/:id/stream", musicHandler.Stream)
+			music.HEAD("/:id/stream", musicHandler.Stream)
+			music.PUT("/:id", canWriteMusic, musicHandler.Update)
+			music.DELETE("/:id", canWriteMusic, musicHandler.Delete)
+		}
+
+		artists := api.Group("/artists")
+		artists.Use(authenticate, apiLimit)
+		{
+			artists.POST("", canWriteCatalog, artistHandler.Create)
+			artists.GET("", artistHandler.GetAll)
+			artists.GET("/:id", artistHandler.GetByID)
+			artists.PUT("/:id", canWriteCatalog, artistHandler.Update)
+			artists.DELETE("/:id", canDeleteCatalog, requireMFA, artistHandler.Delete)
+		}
+
+		albums := api.Group("/albums")
+		albums.Use(authenticate, apiLimit)
+		{
+			albums.POST("", canWriteCatalog, albumHandler.Create)
+			albums.GET("", albumHandler.GetAll)
+			albums.GET("/:id", albumHandler.GetByID)
+			albums.PUT("/:id", canWriteCatalog, albumHandler.Update)
+			albums.DELETE("/:id", canDeleteCatalog, requireMFA, albumHandler.Delete)
+		}
+
+		// ลิงก์แชร์ playlist เปิดได้โดยไม่ต้องเข้าสู่ระบบ
+		api.GET("/playlists/shared/:token", apiLimit, playlistHandler.GetShared)
+
+		playlists := api.Group("/playlists")
+		playlists.Use(authenticate, apiLimit)
+		{
+			playlists.POST("", canWritePlaylists, playlistHandler.Create)
+			playlists.GET("", playlistHandler.ListMine)
+			playlists.GET("/public", playlistHandler.ListPublic)
+			playlists.GET("/:id", playlistHandler.GetByID)
+			playlists.PUT("/:id", canWritePlaylists, playlistHandler.Update)
+			playlists.DELETE("/:id", canWritePlaylists, playlistHandler.Delete)
+			playlists.POST("/:id/share-token", canWritePlaylists, playlistHandler.RegenerateShareToken)
+			playlists.POST("/:id/entries", canWritePlaylists, playlistHandler.AddEntry)
+			playlists.PUT("/:id/entries/:entryId", canWritePlaylists, playlistHandler.MoveEntry)
+			playlists.DELETE("/:id/entries/:entryId", canWritePlaylists, playlistHandler.RemoveEntry)
+			playlists.POST("/:id/collaborators", canWritePlaylists, playlistHandler.AddCollaborator)
+			playlists.DELETE("/:id/collaborators/:userId", canWritePlaylists, playlistHandler.RemoveCollaborator)
+		}
+
+		// URL อัปโหลดของ Local Storage ตรวจสิทธิ์ด้วยลายเซ็นใน URL แทน token
+		if localStorage != nil {
+			api.PUT("/uploads/local/:name", uploadLimit, uploadHandler.ReceiveLocal)
+		}
+
+		// tus discovery ไม่ต้องเข้าสู่ระบบ
+		api.OPTIONS("/uploads/tus", tusHandler.Options)
+		api.OPTIONS("/uploads/tus/:id", tusHandler.Options)
+
+		tus := api.Group("/uploads/tus")
+		tus.Use(authenticate, uploadLimit, canWriteMusic, tusHandler.Protocol)
+		{
+			tus.POST("", tusHandler.Create)
+			tus.HEAD("/:id", tusHandler.Head)
+			tus.PATCH("/:id", tusHandler.Patch)
+			tus.DELETE("/:id", tusHandler.Delete)
+		}
+
+		uploads := api.Group("/uploads")
+		uploads.Use(authenticate, uploadLimit, canWriteMusic)
+		{
+			uploads.POST("", uploadHandler.Create)
+			uploads.POST("/:id/finalize", uploadHandler.Finalize)
+			uploads.GET("/objects/:sha256", mediaObjectHandler.Get)
+			uploads.HEAD("/objects/:sha256", mediaObjectHandler.Get)
 		}
 
 		user := api.Group("/user")
-		user.Use(middleware.AuthMiddleware())
+		user.Use(authenticate, userOnly, apiLimit)
 		{
 			user.GET("", userHandler.GetMe)
 			user.PUT("", userHandler.UpdateMe)
+			user.DELETE("", userHandler.DeleteMe)
+			user.PUT("/password", userHandler.ChangePassword)
+			user.GET("/export", userHandler.Export)
+			user.GET("/sessions", userHandler.ListSessions)
+			user.DELETE("/sessions/:id", userHandler.RevokeSession)
+			user.GET("/2fa", twoFactorHandler.Status)
+			user.POST("/2fa/setup", twoFactorHandler.Setup)
+			user.POST("/2fa/confirm", twoFactorHandler.Confirm)
+			user.POST("/2fa/recovery-codes", twoFactorHandler.RegenerateRecoveryCodes)
+			user.DELETE("/2fa", twoFactorHandler.Disable)
+			user.GET("/identities", oidcHandler.ListIdentities)
+			user.DELETE("/identities/:id", oidcHandler.Unlink)
+			user.GET("/api-keys", apiKeyHandler.List)
+			user.POST("/api-keys", apiKeyHandler.Create)
+			user.DELETE("/api-keys/:id", apiKeyHandler.Revoke)
+		}
+
+		admin := api.Group("/admin")
+		admin.Use(authenticate, userOnly, apiLimit, middleware.RequirePermission(domain.PermUserManage), requireMFA)
+		{
+			admin.PUT("/users/:id/role", userHandler.AssignRole)
 		}
 	}
 
@@ -170,3 +476,183 @@ func Run() {
 		log.Fatalf("Failed to start server: %v", err)
 	}
 }
+
+// listEnv อ่านรายการที่คั่นด้วย , จาก environment variable (ไม่ได้ตั้งค่า = รายการว่าง)
+func listEnv(key string) []string {
+	var items []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// rateEnv อ่านค่าจำกัด request รูปแบบ "20/1m" หรือ "off" จาก environment variable ถ้าไม่ได้ตั้งค่าจะใช้ค่า default
+func rateEnv(key, def string) domain.RateLimit {
+	limit, err := domain.ParseRateLimit(stringEnv(key, def))
+	if err != nil {
+		log.Fatalf("Invalid %s: %v", key, err)
+	}
+	return limit
+}
+
+// stringEnv อ่านข้อความจาก environment variable ถ้าไม่ได้ตั้งค่าจะใช้ค่า default
+func stringEnv(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+// durationEnv อ่านระยะเวลาจาก environment variable (เช่น 30m, 2h) ถ้าไม่ได้ตั้งค่าจะใช้ค่า default
+func durationEnv(key string, def time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		log.Fatalf("Invalid %s: %q", key, v)
+	}
+	return d
+}
+
+// sizeEnv อ่านจำนวนไบต์จาก environment variable ถ้าไม่ได้ตั้งค่าจะใช้ค่า default
+func sizeEnv(key string, def int64) int64 {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n <= 0 {
+		log.Fatalf("Invalid %s: %q", key, v)
+	}
+	return n
+}
+
+// wellKnownIssuers issuer ของผู้ให้บริการที่รู้จัก (ไม่ต้องตั้ง OIDC_<NAME>_ISSUER)
+var wellKnownIssuers = map[string]string{
+	"google": "https://accounts.google.com",
+	"line":   "https://access.line.me",
+}
+
+// loadThaiDictionary โหลดพจนานุกรมคำภาษาไทยเพิ่มเติมจากไฟล์ THAI_DICT_PATH (หนึ่งคำต่อบรรทัด ไม่กำหนด = ใช้พจนานุกรมในตัว)
+func loadThaiDictionary() error {
+	dictPath := os.Getenv("THAI_DICT_PATH")
+	if dictPath == "" {
+		return nil
+	}
+	f, err := os.Open(dictPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return textseg.LoadDictionary(f)
+}
+
+// loadOIDCProviders สร้างผู้ให้บริการ OpenID Connect ตาม OIDC_PROVIDERS (คั่นด้วย , เช่น google,line,keycloak)
+// แต่ละรายตั้งค่าด้วย OIDC_<NAME>_ISSUER, _CLIENT_ID, _CLIENT_SECRET, _SCOPES, _REDIRECT_URL และ _TRUST_EMAIL
+func loadOIDCProviders() ([]domain.IdentityProvider, error) {
+	redirectBase := strings.TrimSuffix(stringEnv("OIDC_REDIRECT_BASE_URL", "http://localhost:8080/api/v1/auth/oidc"), "/")
+	client := &http.Client{Timeout: 10 * time.Second}
+
+	var providers []domain.IdentityProvider
+	for _, name := range listEnv("OIDC_PROVIDERS") {
+		name = strings.ToLower(name)
+		prefix := "OIDC_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+		trustEmail, _ := strconv.ParseBool(os.Getenv(prefix + "TRUST_EMAIL"))
+		p, err := oidc.NewProvider(oidc.Config{
+			Name:         name,
+			Issuer:       stringEnv(prefix+"ISSUER", wellKnownIssuers[name]),
+			ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			RedirectURL:  stringEnv(prefix+"REDIRECT_URL", redirectBase+"/"+name+"/callback"),
+			Scopes:       strings.Fields(stringEnv(prefix+"SCOPES", "openid email profile")),
+			TrustEmail:   trustEmail,
+		}, client)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		providers = append(providers, p)
+	}
+	return providers, nil
+}
+
+// loadJWTKeys สร้างชุด key ของ JWT จาก environment variable
+// key ที่ใช้เซ็นคือ JWT_PRIVATE_KEY / JWT_PRIVATE_KEY_FILE (RSA หรือ Ed25519) ถ้าไม่มีจะใช้ JWT_SECRET (HS256)
+// key เดิมใส่ใน JWT_RETIRED_KEY_FILES และ JWT_RETIRED_SECRETS เพื่อให้ token ที่ออกไปแล้วยังใช้ได้ระหว่างหมุน key
+// kid คำนวณจากตัว key จึงตรงกันเสมอไม่ว่า key จะเป็นตัวที่ใช้เซ็นหรือเลิกใช้แล้ว
+func loadJWTKeys() (*utils.KeyManager, error) {
+	secret := os.Getenv("JWT_SECRET")
+
+	var active *utils.SigningKey
+	var retired []*utils.SigningKey
+	var err error
+	switch {
+	case os.Getenv("JWT_PRIVATE_KEY") != "":
+		active, err = utils.ParsePEMKey([]byte(os.Getenv("JWT_PRIVATE_KEY")), "")
+	case os.Getenv("JWT_PRIVATE_KEY_FILE") != "":
+		active, err = utils.LoadPEMKeyFile(os.Getenv("JWT_PRIVATE_KEY_FILE"), "")
+	case secret != "":
+		active, err = utils.NewHMACKey([]byte(secret), "")
+		secret = ""
+	default:
+		// ไม่ได้กำหนด key จะสุ่มใหม่ทุกครั้งที่เริ่มระบบ (access token เดิมจะใช้ไม่ได้)
+		random := make([]byte, 32)
+		if _, err := rand.Read(random); err != nil {
+			return nil, err
+		}
+		log.Println("JWT_SECRET is not set, using a random key (access tokens will not survive restarts)")
+		active, err = utils.NewHMACKey(random, "")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// JWT_SECRET ที่ไม่ได้ใช้เซ็นแล้ว (เปลี่ยนไปใช้ key แบบ asymmetric) ยังรับตรวจสอบ token เดิม
+	retiredSecrets := strings.Split(os.Getenv("JWT_RETIRED_SECRETS"), ",")
+	if secret != "" {
+		retiredSecrets = append(retiredSecrets, secret)
+	}
+	for _, s := range retiredSecrets {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		k, err := utils.NewHMACKey([]byte(s), "")
+		if err != nil {
+			return nil, err
+		}
+		retired = append(retired, k)
+	}
+	for _, path := range strings.Split(os.Getenv("JWT_RETIRED_KEY_FILES"), ",") {
+		if path = strings.TrimSpace(path); path == "" {
+			continue
+		}
+		k, err := utils.LoadPEMKeyFile(path, "")
+		if err != nil {
+			return nil, err
+		}
+		retired = append(retired, k)
+	}
+	return utils.NewKeyManager(active, retired...)
+}
+
+// runPeriodically เรียก job ทุก interval จนกว่า ctx จะถูกยกเลิก และ log จำนวนรายการที่ job จัดการ
+func runPeriodically(ctx context.Context, name string, interval time.Duration, job func(context.Context) (int, error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := job(ctx)
+			if err != nil {
+				log.Printf("%s failed: %v", name, err)
+			} else if n > 0 {
+				log.Printf("%s: removed %d item(s)", name, n)
+			}
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package app
+
+import (
+	"crypto/rand"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"go-music-api/internal/domain"
+	"go-music-api/internal/infrastructure/storage"
+)
+
+// newLocalStorage สร้าง Local Storage จาก UPLOAD_DIR, BASE_URL และ MEDIA_SIGNING_KEY คืนโฟลเดอร์ที่เก็บไฟล์ด้วย
+func newLocalStorage() (*storage.LocalStorage, string, error) {
+	// อ่านค่า UPLOAD_DIR จาก environment variable ถ้าไม่ได้ตั้งค่าไว้ ให้ใช้ค่า default เป็น "./uploads"
+	uploadDir := stringEnv("UPLOAD_DIR", "./uploads")
+	// อ่านค่า BASE_URL จาก environment variable
+	baseURL := stringEnv("BASE_URL", "http://localhost:8080/uploads")
+
+	// key สำหรับลงลายเซ็น URL ของไฟล์ ถ้าไม่ได้กำหนดจะสุ่มใหม่ทุกครั้งที่เริ่มระบบ (URL เดิมจะใช้ไม่ได้)
+	signingKey := []byte(os.Getenv("MEDIA_SIGNING_KEY"))
+	if len(signingKey) == 0 {
+		signingKey = make([]byte, 32)
+		if _, err := rand.Read(signingKey); err != nil {
+			return nil, "", fmt.Errorf("generate media signing key: %w", err)
+		}
+		log.Println("MEDIA_SIGNING_KEY is not set, using a random key (signed URLs will not survive restarts)")
+	}
+
+	localStorage, err := storage.NewLocalStorage(uploadDir, baseURL, signingKey)
+	if err != nil {
+		return nil, "", err
+	}
+	return localStorage, uploadDir, nil
+}
+
+// newS3Storage สร้าง S3 Storage จาก AWS_BUCKET_NAME, AWS_REGION และ S3_*
+func newS3Storage() (*storage.S3Storage, error) {
+	bucketName := os.Getenv("AWS_BUCKET_NAME")
+	region := os.Getenv("AWS_REGION")
+	if bucketName == "" || region == "" {
+		return nil, fmt.Errorf("AWS_BUCKET_NAME and AWS_REGION are required for s3 storage")
+	}
+	pathStyle, _ := strconv.ParseBool(os.Getenv("S3_FORCE_PATH_STYLE"))
+	return storage.NewS3Storage(storage.S3Config{
+		Bucket:        bucketName,
+		Region:        region,
+		Endpoint:      os.Getenv("S3_ENDPOINT"),
+		UsePathStyle:  pathStyle,
+		KeyPrefix:     os.Getenv("S3_KEY_PREFIX"),
+		PublicBaseURL: os.Getenv("S3_PUBLIC_BASE_URL"),
+	})
+}
+
+// newBlobStorage สร้าง storage ตามชนิด (local หรือ s3)
+func newBlobStorage(kind string) (domain.BlobStorage, error) {
+	switch kind {
+	case "local":
+		localStorage, _, err := newLocalStorage()
+		if err != nil {
+			return nil, err
+		}
+		return localStorage, nil
+	case "s3":
+		return newS3Storage()
+	}
+	return nil, fmt.Errorf("unknown storage type %q (use local or s3)", kind)
+}
@@ -0,0 +1,187 @@
+package handler // ประกาศ package handler
+
+import (
+	"net/http" // นำเข้า net/http
+	"strconv"  // นำเข้า strconv
+	"time"     // นำเข้า time
+
+	"go-music-api/internal/domain" // นำเข้า domain entities
+
+	"github.com/gin-gonic/gin" // นำเข้า gin
+)
+
+// AlbumHandler struct สำหรับจัดการ HTTP request ที่เกี่ยวกับ Album
+type AlbumHandler struct {
+	albumService domain.AlbumService    // ใช้ service ในการทำงาน
+	mediaURLs    domain.MediaURLService // ใช้สร้าง URL ไฟล์แบบมีลายเซ็น
+}
+
+// NewAlbumHandler สร้าง instance ของ AlbumHandler
+func NewAlbumHandler(albumService domain.AlbumService, mediaURLs domain.MediaURLService) *AlbumHandler {
+	return &AlbumHandler{albumService: albumService, mediaURLs: mediaURLs}
+}
+
+type albumRequest struct {
+	Title       string `json:"title" binding:"required"`
+	ArtistID    *uint  `json:"artist_id"`
+	ReleaseDate string `json:"release_date"` // YYYY-MM-DD
+	ImageURL    string `json:"image_url"`
+}
+
+// toAlbum แปลง request เป็น domain.Album
+func (r albumRequest) toAlbum() (*domain.Album, bool) {
+	album := &domain.Album{
+		Title:    r.Title,
+		ArtistID: r.ArtistID,
+		ImageURL: r.ImageURL,
+	}
+	if r.ReleaseDate != "" {
+		t, err := time.Parse("2006-01-02", r.ReleaseDate)
+		if err != nil {
+			return nil, false
+		}
+		album.ReleaseDate = &t
+	}
+	return album, true
+}
+
+// albumError แปลง error จาก service เป็น HTTP response
+func albumError(c *gin.Context, err error) {
+	switch err {
+	case domain.ErrNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Album not found"})
+	case domain.ErrInvalidInput:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "title is required and artist_id must exist"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// Create สร้างอัลบั้มใหม่
+func (h *AlbumHandler) Create(c *gin.Context) {
+	var req albumRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	album, ok := req.toAlbum()
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "release_date must be YYYY-MM-DD"})
+		return
+	}
+
+	email := c.GetString("email")
+	if email == "" {
+		email = "system"
+	}
+	album.CreatedBy = email
+	album.UpdatedBy = email
+
+	if err := h.albumService.Create(c.Request.Context(), album); err != nil {
+		albumError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": album})
+}
+
+// GetAll ดึงรายการอัลบั้มแบบแบ่งหน้า (กรองด้วย title และ artist_id ได้)
+func (h *AlbumHandler) GetAll(c *gin.Context) {
+	page, pageSize, err := parsePageParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	query := domain.AlbumQuery{Title: c.Query("title"), Page: page, PageSize: pageSize}
+	if v := c.Query("artist_id"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "artist_id must be a positive integer"})
+			return
+		}
+		query.ArtistID = uint(id)
+	}
+
+	albums, total, err := h.albumService.GetAll(c.Request.Context(), query)
+	if err != nil {
+		albumError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":       albums,
+		"pagination": offsetPagination(c, page, pageSize, total),
+	})
+}
+
+// GetByID ดึงข้อมูลอัลบั้มพร้อมรายการเพลง
+func (h *AlbumHandler) GetByID(c *gin.Context) {
+	id64, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	album, err := h.albumService.GetByID(c.Request.Context(), uint(id64))
+	if err != nil {
+		albumError(c, err)
+		return
+	}
+
+	hydrateMusicListMediaURLs(c, h.mediaURLs, album.Tracks)
+	album.ImageURL = toPublicURL(h.mediaURLs.SignURL(c.Request.Context(), album.ImageURL, domain.MediaImage))
+	c.JSON(http.StatusOK, gin.H{"data": album})
+}
+
+// Update แก้ไขข้อมูลอัลบั้ม
+func (h *AlbumHandler) Update(c *gin.Context) {
+	id64, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req albumRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	album, ok := req.toAlbum()
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "release_date must be YYYY-MM-DD"})
+		return
+	}
+
+	email := c.GetString("email")
+	if email == "" {
+		email = "system"
+	}
+	album.ID = uint(id64)
+	album.UpdatedBy = email
+
+	if err := h.albumService.Update(c.Request.Context(), album); err != nil {
+		albumError(c, err)
+		return
+	}
+
+	hydrateMusicListMediaURLs(c, h.mediaURLs, album.Tracks)
+	album.ImageURL = toPublicURL(h.mediaURLs.SignURL(c.Request.Context(), album.ImageURL, domain.MediaImage))
+	c.JSON(http.StatusOK, gin.H{"data": album})
+}
+
+// Delete ลบอัลบั้ม (เพลงในอัลบั้มยังคงอยู่)
+func (h *AlbumHandler) Delete(c *gin.Context) {
+	id64, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	if err := h.albumService.Delete(c.Request.Context(), uint(id64)); err != nil {
+		albumError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Album deleted successfully"})
+}
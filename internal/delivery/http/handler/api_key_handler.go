@@ -0,0 +1,103 @@
+package handler // ประกาศ package handler
+
+import (
+	"net/http" // นำเข้า net/http
+	"time"     // นำเข้า time
+
+	"go-music-api/internal/domain" // นำเข้า domain entities
+
+	"github.com/gin-gonic/gin" // นำเข้า gin
+)
+
+// APIKeyHandler struct สำหรับจัดการ HTTP request ที่เกี่ยวกับ API key
+type APIKeyHandler struct {
+	apiKeyService domain.APIKeyService // ใช้ service ในการทำงาน
+}
+
+// NewAPIKeyHandler สร้าง instance ของ APIKeyHandler
+func NewAPIKeyHandler(apiKeyService domain.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{apiKeyService: apiKeyService}
+}
+
+type createAPIKeyRequest struct {
+	Name      string              `json:"name" binding:"required,max=100"`
+	Scopes    []domain.Permission `json:"scopes" binding:"required,min=1"`
+	ExpiresAt *time.Time          `json:"expires_at"`
+}
+
+// List คืนรายการ API key ของผู้ใช้ (ไม่มีตัว key)
+func (h *APIKeyHandler) List(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	keys, err := h.apiKeyService.List(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": keys})
+}
+
+// Create สร้าง API key ตัว key จะแสดงในคำตอบนี้ครั้งเดียว
+func (h *APIKeyHandler) Create(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req createAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	key, secret, err := h.apiKeyService.Create(c.Request.Context(), actor, domain.APIKeyRequest{
+		Name:      req.Name,
+		Scopes:    req.Scopes,
+		ExpiresAt: req.ExpiresAt,
+	})
+	if err != nil {
+		switch err {
+		case domain.ErrForbidden:
+			c.JSON(http.StatusForbidden, gin.H{"error": "Your role cannot create API keys with these scopes"})
+		case domain.ErrInvalidInput:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid name, scopes or expires_at"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusCreated, gin.H{"data": gin.H{"key": secret, "api_key": key}})
+}
+
+// Revoke ยกเลิก API key
+func (h *APIKeyHandler) Revoke(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	id, ok := paramID(c, "id")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	if err := h.apiKeyService.Revoke(c.Request.Context(), userID, id); err != nil {
+		if err == domain.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,157 @@
+package handler // ประกาศ package handler
+
+import (
+	"net/http" // นำเข้า net/http
+	"strconv"  // นำเข้า strconv
+
+	"go-music-api/internal/domain" // นำเข้า domain entities
+
+	"github.com/gin-gonic/gin" // นำเข้า gin
+)
+
+// ArtistHandler struct สำหรับจัดการ HTTP request ที่เกี่ยวกับ Artist
+type ArtistHandler struct {
+	artistService domain.ArtistService // ใช้ service ในการทำงาน
+}
+
+// NewArtistHandler สร้าง instance ของ ArtistHandler
+func NewArtistHandler(artistService domain.ArtistService) *ArtistHandler {
+	return &ArtistHandler{artistService: artistService}
+}
+
+type artistRequest struct {
+	Name     string `json:"name" binding:"required"`
+	Bio      string `json:"bio"`
+	ImageURL string `json:"image_url"`
+}
+
+// artistError แปลง error จาก service เป็น HTTP response
+func artistError(c *gin.Context, err error) {
+	switch err {
+	case domain.ErrNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Artist not found"})
+	case domain.ErrConflict:
+		c.JSON(http.StatusConflict, gin.H{"error": "Artist already exists or is still referenced by tracks"})
+	case domain.ErrInvalidInput:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name is required"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// Create สร้างศิลปินใหม่
+func (h *ArtistHandler) Create(c *gin.Context) {
+	var req artistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	email := c.GetString("email")
+	if email == "" {
+		email = "system"
+	}
+	artist := &domain.Artist{
+		Name:     req.Name,
+		Bio:      req.Bio,
+		ImageURL: req.ImageURL,
+		BaseModel: domain.BaseModel{
+			CreatedBy: email,
+			UpdatedBy: email,
+		},
+	}
+
+	if err := h.artistService.Create(c.Request.Context(), artist); err != nil {
+		artistError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": artist})
+}
+
+// GetAll ดึงรายการศิลปินแบบแบ่งหน้า (กรองด้วย name ได้)
+func (h *ArtistHandler) GetAll(c *gin.Context) {
+	page, pageSize, err := parsePageParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	query := domain.ArtistQuery{Name: c.Query("name"), Page: page, PageSize: pageSize}
+	artists, total, err := h.artistService.GetAll(c.Request.Context(), query)
+	if err != nil {
+		artistError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":       artists,
+		"pagination": offsetPagination(c, page, pageSize, total),
+	})
+}
+
+// GetByID ดึงข้อมูลศิลปินตาม ID
+func (h *ArtistHandler) GetByID(c *gin.Context) {
+	id64, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	artist, err := h.artistService.GetByID(c.Request.Context(), uint(id64))
+	if err != nil {
+		artistError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": artist})
+}
+
+// Update แก้ไขข้อมูลศิลปิน
+func (h *ArtistHandler) Update(c *gin.Context) {
+	id64, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req artistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	email := c.GetString("email")
+	if email == "" {
+		email = "system"
+	}
+	artist := &domain.Artist{
+		Name:      req.Name,
+		Bio:       req.Bio,
+		ImageURL:  req.ImageURL,
+		BaseModel: domain.BaseModel{ID: uint(id64), UpdatedBy: email},
+	}
+
+	if err := h.artistService.Update(c.Request.Context(), artist); err != nil {
+		artistError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": artist})
+}
+
+// Delete ลบศิลปิน
+func (h *ArtistHandler) Delete(c *gin.Context) {
+	id64, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	if err := h.artistService.Delete(c.Request.Context(), uint(id64)); err != nil {
+		artistError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Artist deleted successfully"})
+}
@@ -0,0 +1,25 @@
+package handler // ประกาศ package handler
+
+import (
+	"net/http" // นำเข้า net/http
+
+	"go-music-api/pkg/utils" // นำเข้า utils สำหรับชุด key ของ JWT
+
+	"github.com/gin-gonic/gin" // นำเข้า gin
+)
+
+// JWKSHandler struct สำหรับเปิดเผย public key ที่ใช้ตรวจสอบ access token
+type JWKSHandler struct {
+	keys *utils.KeyManager // ชุด key ของ JWT
+}
+
+// NewJWKSHandler สร้าง instance ของ JWKSHandler
+func NewJWKSHandler(keys *utils.KeyManager) *JWKSHandler {
+	return &JWKSHandler{keys: keys}
+}
+
+// Get คืน JSON Web Key Set ของ key ที่ใช้อยู่และที่เลิกใช้แล้ว (ไม่รวม key แบบ HMAC)
+func (h *JWKSHandler) Get(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.keys.JWKS())
+}
@@ -0,0 +1,37 @@
+package handler // ประกาศ package handler
+
+import (
+	"net/http" // นำเข้า net/http
+
+	"go-music-api/internal/domain" // นำเข้า domain entities
+
+	"github.com/gin-gonic/gin" // นำเข้า gin
+)
+
+// MediaObjectHandler struct สำหรับตรวจว่ามีไฟล์อยู่ใน storage แล้วหรือไม่
+type MediaObjectHandler struct {
+	objectService domain.MediaObjectService // ใช้ service ในการทำงาน
+}
+
+// NewMediaObjectHandler สร้าง instance ของ MediaObjectHandler
+func NewMediaObjectHandler(objectService domain.MediaObjectService) *MediaObjectHandler {
+	return &MediaObjectHandler{objectService: objectService}
+}
+
+// Get คืนข้อมูลไฟล์ตาม SHA-256 (hex) ให้ client ตรวจก่อนอัปโหลดไฟล์ซ้ำ (รองรับ HEAD)
+func (h *MediaObjectHandler) Get(c *gin.Context) {
+	object, err := h.objectService.GetBySHA256(c.Request.Context(), c.Param("sha256"))
+	if err != nil {
+		switch err {
+		case domain.ErrInvalidInput:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "sha256 must be 64 hex characters"})
+		case domain.ErrNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Object not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": object})
+}
@@ -1,6 +1,9 @@
 package handler // ประกาศ package handler
 
 import (
+	"encoding/json"  // นำเข้า json สำหรับอ่านรายชื่อศิลปินจาก form
+	"errors"         // นำเข้า errors
+	"fmt"            // นำเข้า fmt
 	"mime/multipart" // นำเข้า multipart สำหรับจัดการไฟล์
 	"net/http"       // นำเข้า net/http
 	"strconv"        // นำเข้า strconv
@@ -9,6 +12,7 @@ import (
 
 	"os"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin" // นำเข้า gin
 )
@@ -34,39 +38,150 @@ func toPublicURL(path string) string {
 	return publicBaseURL() + path
 }
 
-func hydrateMusicMediaURLs(m *domain.Music) {
+// hydrateMusicMediaURLs แทนที่ URL ของไฟล์ด้วย URL ที่ลงลายเซ็นและเติม host สำหรับ path ในเครื่อง
+func hydrateMusicMediaURLs(c *gin.Context, media domain.MediaURLService, m *domain.Music) {
 	if m == nil {
 		return
 	}
+	media.SignMusic(c.Request.Context(), m)
 	m.MP3URL = toPublicURL(m.MP3URL)
 	m.MP4URL = toPublicURL(m.MP4URL)
 	m.ImageURL = toPublicURL(m.ImageURL)
+	if m.Album != nil {
+		m.Album.ImageURL = toPublicURL(m.Album.ImageURL)
+	}
 }
 
-func hydrateMusicListMediaURLs(items []domain.Music) {
+func hydrateMusicListMediaURLs(c *gin.Context, media domain.MediaURLService, items []domain.Music) {
 	for i := range items {
-		hydrateMusicMediaURLs(&items[i])
+		hydrateMusicMediaURLs(c, media, &items[i])
 	}
 }
 
 // MusicHandler struct สำหรับจัดการ HTTP request ที่เกี่ยวกับ Music
 type MusicHandler struct {
-	musicService domain.MusicService // ใช้ service ในการทำงาน
+	musicService domain.MusicService    // ใช้ service ในการทำงาน
+	mediaURLs    domain.MediaURLService // ใช้สร้าง URL ไฟล์แบบมีลายเซ็น
 }
 
 // NewMusicHandler สร้าง instance ของ MusicHandler
-func NewMusicHandler(musicService domain.MusicService) *MusicHandler {
-	return &MusicHandler{musicService: musicService}
+func NewMusicHandler(musicService domain.MusicService, mediaURLs domain.MediaURLService) *MusicHandler {
+	return &MusicHandler{musicService: musicService, mediaURLs: mediaURLs}
+}
+
+type artistCreditRequest struct {
+	ArtistID uint              `json:"artist_id" binding:"required"`
+	Role     domain.ArtistRole `json:"role"`
 }
 
 type updateMusicRequest struct {
-	Title  *string `json:"title"`
-	Artist *string `json:"artist"`
-	Lyrics *string `json:"lyrics"`
+	Title       *string                `json:"title"`
+	Artist      *string                `json:"artist"`
+	Lyrics      *string                `json:"lyrics"`
+	AlbumID     *uint                  `json:"album_id"` // 0 = เอาออกจากอัลบั้ม
+	TrackNumber *int                   `json:"track_number"`
+	DiscNumber  *int                   `json:"disc_number"`
+	Artists     *[]artistCreditRequest `json:"artists"`
+}
+
+// toMusicArtists แปลง credits จาก request เป็น domain
+func toMusicArtists(reqs []artistCreditRequest) []domain.MusicArtist {
+	credits := make([]domain.MusicArtist, 0, len(reqs))
+	for _, r := range reqs {
+		credits = append(credits, domain.MusicArtist{ArtistID: r.ArtistID, Role: r.Role})
+	}
+	return credits
+}
+
+// musicRelationForm ข้อมูลอัลบั้มและศิลปินที่อ่านได้จาก multipart form
+type musicRelationForm struct {
+	AlbumID     *uint
+	TrackNumber *int
+	DiscNumber  *int
+	Artists     []domain.MusicArtist // nil = ไม่ได้ส่งมา
+}
+
+// parseMusicRelationForm อ่าน album_id, track_number, disc_number และ artists (JSON) จาก form
+func parseMusicRelationForm(c *gin.Context) (musicRelationForm, error) {
+	var f musicRelationForm
+	if v, ok := c.GetPostForm("album_id"); ok && v != "" {
+		id, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			return f, errors.New("album_id must be a positive integer")
+		}
+		albumID := uint(id)
+		f.AlbumID = &albumID
+	}
+	if v, ok := c.GetPostForm("track_number"); ok && v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return f, errors.New("track_number must be a non-negative integer")
+		}
+		f.TrackNumber = &n
+	}
+	if v, ok := c.GetPostForm("disc_number"); ok && v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return f, errors.New("disc_number must be a positive integer")
+		}
+		f.DiscNumber = &n
+	}
+	if v, ok := c.GetPostForm("artists"); ok && v != "" {
+		var reqs []artistCreditRequest
+		if err := json.Unmarshal([]byte(v), &reqs); err != nil {
+			return f, errors.New(`artists must be a JSON array like [{"artist_id":1,"role":"primary"}]`)
+		}
+		for _, r := range reqs {
+			if r.ArtistID == 0 {
+				return f, errors.New("artists[].artist_id is required")
+			}
+		}
+		f.Artists = toMusicArtists(reqs)
+	}
+	return f, nil
+}
+
+// applyTo ใส่ข้อมูลจาก form ลงใน music (ค่าที่ไม่ได้ส่งมาจะไม่ถูกแตะ)
+func (f musicRelationForm) applyTo(m *domain.Music) {
+	if f.AlbumID != nil {
+		m.AlbumID = f.AlbumID
+		if *f.AlbumID == 0 {
+			m.AlbumID = nil
+		}
+	}
+	if f.TrackNumber != nil {
+		m.TrackNumber = *f.TrackNumber
+	}
+	if f.DiscNumber != nil {
+		m.DiscNumber = *f.DiscNumber
+	}
+	if f.Artists != nil {
+		m.Artists = f.Artists
+	}
+}
+
+// musicWriteError แปลง error จาก service เป็น HTTP response สำหรับการสร้าง/แก้ไขเพลง
+func musicWriteError(c *gin.Context, err error) {
+	switch err {
+	case domain.ErrNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Music not found"})
+	case domain.ErrForbidden:
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only modify your own tracks"})
+	case domain.ErrInvalidInput:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid title, artist, album or artists"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
 }
 
 // Create จัดการ request สำหรับสร้างเพลงใหม่
 func (h *MusicHandler) Create(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
 	email, ok := c.Get("email")
 	createdEmail, ok2 := email.(string)
 	if !ok || !ok2 || createdEmail == "" {
@@ -91,8 +206,9 @@ func (h *MusicHandler) Create(c *gin.Context) {
 	title := c.PostForm("title")
 	artist := c.PostForm("artist")
 	lyrics := c.PostForm("lyrics")
-	if title == "" || artist == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "title and artist are required"})
+	relations, err := parseMusicRelationForm(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
@@ -134,22 +250,30 @@ func (h *MusicHandler) Create(c *gin.Context) {
 		return
 	}
 
+	// ถ้ามีไฟล์ MP3 ชื่อเพลงและศิลปินจะอ่านจาก ID3 tag ได้
+	if mp3File == nil && (title == "" || (artist == "" && len(relations.Artists) == 0)) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "title and artist (or artists) are required"})
+		return
+	}
+
 	music := &domain.Music{
-		Title:  title,
-		Artist: artist,
-		Lyrics: lyrics,
+		OwnerID: userID,
+		Title:   title,
+		Artist:  artist,
+		Lyrics:  lyrics,
 		BaseModel: domain.BaseModel{
 			CreatedBy: createdEmail,
 			UpdatedBy: createdEmail,
 		},
 	}
+	relations.applyTo(music)
 
 	if err := h.musicService.Create(c.Request.Context(), music, mp3File, mp4File, imageFile); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		musicWriteError(c, err)
 		return
 	}
 
-	hydrateMusicMediaURLs(music)
+	hydrateMusicMediaURLs(c, h.mediaURLs, music)
 	c.JSON(http.StatusCreated, gin.H{"music": music})
 }
 
@@ -171,20 +295,236 @@ func (h *MusicHandler) GetByID(c *gin.Context) {
 		return
 	}
 
-	hydrateMusicMediaURLs(music)
+	hydrateMusicMediaURLs(c, h.mediaURLs, music)
 	c.JSON(http.StatusOK, gin.H{"data": music})
 }
 
-// GetAll ดึงข้อมูลเพลงทั้งหมด
+// paginationMeta ข้อมูลการแบ่งหน้าที่ส่งกลับไปพร้อมรายการ
+type paginationMeta struct {
+	Page       int    `json:"page,omitempty"`
+	PageSize   int    `json:"page_size"`
+	Total      int64  `json:"total"`
+	TotalPages int64  `json:"total_pages"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+	Next       string `json:"next,omitempty"`
+	Prev       string `json:"prev,omitempty"`
+}
+
+// parseTimeParam รองรับทั้งรูปแบบ RFC3339 และวันที่ล้วน (YYYY-MM-DD)
+func parseTimeParam(v string) (*time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse("2006-01-02", v)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// parseMusicQuery อ่าน query string ของ GET /music เป็น MusicQuery
+func parseMusicQuery(c *gin.Context) (domain.MusicQuery, error) {
+	q := domain.MusicQuery{
+		Cursor:    c.Query("cursor"),
+		Artist:    c.Query("artist"),
+		Title:     c.Query("title"),
+		CreatedBy: c.Query("created_by"),
+		SortBy:    c.Query("sort"),
+	}
+
+	if v := c.Query("page"); v != "" {
+		page, err := strconv.Atoi(v)
+		if err != nil || page < 1 {
+			return q, errors.New("page must be a positive integer")
+		}
+		q.Page = page
+	}
+	if v := c.Query("page_size"); v != "" {
+		size, err := strconv.Atoi(v)
+		if err != nil || size < 1 || size > domain.MaxPageSize {
+			return q, fmt.Errorf("page_size must be between 1 and %d", domain.MaxPageSize)
+		}
+		q.PageSize = size
+	}
+	if v := c.Query("artist_id"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			return q, errors.New("artist_id must be a positive integer")
+		}
+		q.ArtistID = uint(id)
+	}
+	if v := c.Query("album_id"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			return q, errors.New("album_id must be a positive integer")
+		}
+		q.AlbumID = uint(id)
+	}
+	if q.SortBy != "" && !domain.MusicSortFields[q.SortBy] {
+		return q, errors.New("sort must be one of id, title, artist, created_at, updated_at")
+	}
+	switch strings.ToLower(c.Query("order")) {
+	case "", "asc":
+	case "desc":
+		q.SortDesc = true
+	default:
+		return q, errors.New("order must be asc or desc")
+	}
+	if v := c.Query("created_from"); v != "" {
+		t, err := parseTimeParam(v)
+		if err != nil {
+			return q, errors.New("created_from must be RFC3339 or YYYY-MM-DD")
+		}
+		q.CreatedFrom = t
+	}
+	if v := c.Query("created_to"); v != "" {
+		t, err := parseTimeParam(v)
+		if err != nil {
+			return q, errors.New("created_to must be RFC3339 or YYYY-MM-DD")
+		}
+		q.CreatedTo = t
+	}
+
+	q.Normalize()
+	return q, nil
+}
+
+// pageLink สร้างลิงก์ของหน้าอื่นโดยคง query string เดิมไว้และแทนค่าที่ระบุ
+func pageLink(c *gin.Context, set map[string]string, drop ...string) string {
+	values := c.Request.URL.Query()
+	for _, k := range drop {
+		values.Del(k)
+	}
+	for k, v := range set {
+		values.Set(k, v)
+	}
+	return publicBaseURL() + c.Request.URL.Path + "?" + values.Encode()
+}
+
+// buildPagination สร้าง metadata การแบ่งหน้าพร้อมลิงก์ next/prev
+func buildPagination(c *gin.Context, page *domain.MusicPage) paginationMeta {
+	meta := paginationMeta{
+		Page:       page.Page,
+		PageSize:   page.PageSize,
+		Total:      page.Total,
+		TotalPages: (page.Total + int64(page.PageSize) - 1) / int64(page.PageSize),
+		NextCursor: page.NextCursor,
+		PrevCursor: page.PrevCursor,
+	}
+
+	if page.Page > 0 {
+		// โหมดเลขหน้า
+		if page.NextCursor != "" {
+			meta.Next = pageLink(c, map[string]string{"page": strconv.Itoa(page.Page + 1)}, "cursor")
+		}
+		if page.Page > 1 {
+			meta.Prev = pageLink(c, map[string]string{"page": strconv.Itoa(page.Page - 1)}, "cursor")
+		}
+		return meta
+	}
+
+	// โหมด cursor
+	if page.NextCursor != "" {
+		meta.Next = pageLink(c, map[string]string{"cursor": page.NextCursor}, "page")
+	}
+	if page.PrevCursor != "" {
+		meta.Prev = pageLink(c, map[string]string{"cursor": page.PrevCursor}, "page")
+	}
+	return meta
+}
+
+// offsetPagination สร้าง metadata การแบ่งหน้าแบบเลขหน้าพร้อมลิงก์ next/prev
+func offsetPagination(c *gin.Context, page, pageSize int, total int64) paginationMeta {
+	meta := paginationMeta{
+		Page:       page,
+		PageSize:   pageSize,
+		Total:      total,
+		TotalPages: (total + int64(pageSize) - 1) / int64(pageSize),
+	}
+	if int64(page*pageSize) < total {
+		meta.Next = pageLink(c, map[string]string{"page": strconv.Itoa(page + 1)})
+	}
+	if page > 1 {
+		meta.Prev = pageLink(c, map[string]string{"page": strconv.Itoa(page - 1)})
+	}
+	return meta
+}
+
+// parsePageParams อ่าน page และ page_size จาก query string
+func parsePageParams(c *gin.Context) (int, int, error) {
+	page, pageSize := 1, domain.DefaultPageSize
+	if v := c.Query("page"); v != "" {
+		p, err := strconv.Atoi(v)
+		if err != nil || p < 1 {
+			return 0, 0, errors.New("page must be a positive integer")
+		}
+		page = p
+	}
+	if v := c.Query("page_size"); v != "" {
+		size, err := strconv.Atoi(v)
+		if err != nil || size < 1 || size > domain.MaxPageSize {
+			return 0, 0, fmt.Errorf("page_size must be between 1 and %d", domain.MaxPageSize)
+		}
+		pageSize = size
+	}
+	return page, pageSize, nil
+}
+
+// GetAll ดึงข้อมูลเพลงแบบแบ่งหน้า กรอง และเรียงลำดับ
 func (h *MusicHandler) GetAll(c *gin.Context) {
-	musics, err := h.musicService.GetAll(c.Request.Context())
+	query, err := parseMusicQuery(c)
 	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := h.musicService.GetAll(c.Request.Context(), query)
+	if err != nil {
+		if err == domain.ErrInvalidInput {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid cursor"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	hydrateMusicListMediaURLs(musics)
-	c.JSON(http.StatusOK, gin.H{"data": musics})
+	hydrateMusicListMediaURLs(c, h.mediaURLs, page.Items)
+	c.JSON(http.StatusOK, gin.H{
+		"data":       page.Items,
+		"pagination": buildPagination(c, page),
+	})
+}
+
+// Search ค้นหาเพลงแบบ full-text จากชื่อเพลง ศิลปิน และเนื้อเพลง
+func (h *MusicHandler) Search(c *gin.Context) {
+	q := strings.TrimSpace(c.Query("q"))
+	if q == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "q is required"})
+		return
+	}
+
+	page, pageSize, err := parsePageParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	query := domain.MusicSearchQuery{Q: q, Page: page, PageSize: pageSize}
+
+	result, err := h.musicService.Search(c.Request.Context(), query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	for i := range result.Items {
+		hydrateMusicMediaURLs(c, h.mediaURLs, &result.Items[i].Music)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":       result.Items,
+		"pagination": offsetPagination(c, result.Page, result.PageSize, result.Total),
+	})
 }
 
 // Update แก้ไขข้อมูลเพลง
@@ -194,6 +534,11 @@ func (h *MusicHandler) Update(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
 		return
 	}
+	actor, ok := currentActor(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
 
 	email, ok := c.Get("email")
 	updatedEmail, ok2 := email.(string)
@@ -231,6 +576,11 @@ func (h *MusicHandler) Update(c *gin.Context) {
 		title := c.PostForm("title")
 		artist := c.PostForm("artist")
 		lyrics := c.PostForm("lyrics")
+		relations, err := parseMusicRelationForm(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 
 		var mp3File *multipart.FileHeader
 		if fh, err := c.FormFile("mp3_file"); err == nil {
@@ -276,16 +626,23 @@ func (h *MusicHandler) Update(c *gin.Context) {
 				CreatedBy: existing.CreatedBy,
 				UpdatedBy: updatedEmail,
 			},
-			Title:    existing.Title,
-			Artist:   existing.Artist,
-			Lyrics:   existing.Lyrics,
-			MP3URL:   existing.MP3URL,
-			MP4URL:   existing.MP4URL,
-			ImageURL: existing.ImageURL,
+			Title:       existing.Title,
+			Artist:      existing.Artist,
+			Lyrics:      existing.Lyrics,
+			MP3URL:      existing.MP3URL,
+			MP4URL:      existing.MP4URL,
+			ImageURL:    existing.ImageURL,
+			AlbumID:     existing.AlbumID,
+			TrackNumber: existing.TrackNumber,
+			DiscNumber:  existing.DiscNumber,
 		}
+		relations.applyTo(merged)
 		if title != "" {
 			merged.Title = title
 		}
+		if len(relations.Artists) > 0 {
+			merged.Artist = "" // ให้ service สร้างข้อความ credit ใหม่จากศิลปินที่ระบุมา
+		}
 		if artist != "" {
 			merged.Artist = artist
 		}
@@ -293,22 +650,14 @@ func (h *MusicHandler) Update(c *gin.Context) {
 			merged.Lyrics = lyrics
 		}
 
-		if err := h.musicService.Update(c.Request.Context(), merged, mp3File, mp4File, imageFile); err != nil {
-			if err == domain.ErrNotFound {
-				c.JSON(http.StatusNotFound, gin.H{"error": "Music not found"})
-				return
-			}
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		if err := h.musicService.Update(c.Request.Context(), actor, merged, mp3File, mp4File, imageFile); err != nil {
+			musicWriteError(c, err)
 			return
 		}
 
-		updated, err := h.musicService.GetByID(c.Request.Context(), uint(id64))
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
-		hydrateMusicMediaURLs(updated)
-		c.JSON(http.StatusOK, gin.H{"data": updated})
+		// service โหลดเพลงกลับมาหลังบันทึกแล้ว (พร้อมอัลบั้มและศิลปิน)
+		hydrateMusicMediaURLs(c, h.mediaURLs, merged)
+		c.JSON(http.StatusOK, gin.H{"data": merged})
 		return
 	}
 
@@ -324,16 +673,27 @@ func (h *MusicHandler) Update(c *gin.Context) {
 			CreatedBy: existing.CreatedBy,
 			UpdatedBy: updatedEmail,
 		},
-		Title:    existing.Title,
-		Artist:   existing.Artist,
-		Lyrics:   existing.Lyrics,
-		MP3URL:   existing.MP3URL,
-		MP4URL:   existing.MP4URL,
-		ImageURL: existing.ImageURL,
-	}
+		Title:       existing.Title,
+		Artist:      existing.Artist,
+		Lyrics:      existing.Lyrics,
+		MP3URL:      existing.MP3URL,
+		MP4URL:      existing.MP4URL,
+		ImageURL:    existing.ImageURL,
+		AlbumID:     existing.AlbumID,
+		TrackNumber: existing.TrackNumber,
+		DiscNumber:  existing.DiscNumber,
+	}
+	relations := musicRelationForm{AlbumID: req.AlbumID, TrackNumber: req.TrackNumber, DiscNumber: req.DiscNumber}
+	if req.Artists != nil {
+		relations.Artists = toMusicArtists(*req.Artists)
+	}
+	relations.applyTo(merged)
 	if req.Title != nil {
 		merged.Title = *req.Title
 	}
+	if len(relations.Artists) > 0 {
+		merged.Artist = "" // ให้ service สร้างข้อความ credit ใหม่จากศิลปินที่ระบุมา
+	}
 	if req.Artist != nil {
 		merged.Artist = *req.Artist
 	}
@@ -341,22 +701,14 @@ func (h *MusicHandler) Update(c *gin.Context) {
 		merged.Lyrics = *req.Lyrics
 	}
 
-	if err := h.musicService.Update(c.Request.Context(), merged, nil, nil, nil); err != nil {
-		if err == domain.ErrNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Music not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.musicService.Update(c.Request.Context(), actor, merged, nil, nil, nil); err != nil {
+		musicWriteError(c, err)
 		return
 	}
 
-	updated, err := h.musicService.GetByID(c.Request.Context(), uint(id64))
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-	hydrateMusicMediaURLs(updated)
-	c.JSON(http.StatusOK, gin.H{"data": updated})
+	// service โหลดเพลงกลับมาหลังบันทึกแล้ว (พร้อมอัลบั้มและศิลปิน)
+	hydrateMusicMediaURLs(c, h.mediaURLs, merged)
+	c.JSON(http.StatusOK, gin.H{"data": merged})
 }
 
 // Delete ลบเพลง
@@ -366,12 +718,25 @@ func (h *MusicHandler) Delete(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
 		return
 	}
+	actor, ok := currentActor(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
 
-	if err := h.musicService.Delete(c.Request.Context(), uint(id64)); err != nil {
+	if err := h.musicService.Delete(c.Request.Context(), actor, uint(id64)); err != nil {
 		if err == domain.ErrNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Music not found"})
 			return
 		}
+		if err == domain.ErrForbidden {
+			c.JSON(http.StatusForbidden, gin.H{"error": "You can only modify your own tracks"})
+			return
+		}
+		if err == domain.ErrMFARequired {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Two-factor authentication required: enable 2FA and log in again"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
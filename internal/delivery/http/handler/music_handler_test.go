@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"context"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-music-api/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// updateMusicService MusicService จำลองที่มีเพลงเดียวและจำเพลงที่ถูกส่งไปบันทึก
+type updateMusicService struct {
+	domain.MusicService
+	existing *domain.Music
+	updated  *domain.Music
+}
+
+func (s *updateMusicService) GetByID(ctx context.Context, id uint) (*domain.Music, error) {
+	m := *s.existing
+	return &m, nil
+}
+
+func (s *updateMusicService) Update(ctx context.Context, actor domain.Actor, music *domain.Music, mp3File, mp4File, imageFile *multipart.FileHeader) error {
+	m := *music
+	s.updated = &m
+	return nil
+}
+
+// noopMediaURLs MediaURLService ที่ไม่แก้ URL
+type noopMediaURLs struct {
+	domain.MediaURLService
+}
+
+func (noopMediaURLs) SignMusic(ctx context.Context, music *domain.Music) {}
+
+func TestUpdateMusicArtistText(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		body       string
+		wantArtist string // ข้อความที่ส่งให้ service ("" = ให้ service สร้างจาก credits)
+	}{
+		{name: "other fields keep the artist text", body: `{"title":"New"}`, wantArtist: "Old Artist"},
+		{name: "new credits rebuild the artist text", body: `{"artists":[{"artist_id":1},{"artist_id":2,"role":"featured"}]}`},
+		{
+			name:       "explicit artist text wins over credits",
+			body:       `{"artist":"Custom","artists":[{"artist_id":1}]}`,
+			wantArtist: "Custom",
+		},
+		{name: "empty credits keep the artist text", body: `{"artists":[]}`, wantArtist: "Old Artist"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			existing := &domain.Music{Title: "Old", Artist: "Old Artist"}
+			existing.ID = 1
+			svc := &updateMusicService{existing: existing}
+			router := gin.New()
+			router.PUT("/music/:id", func(c *gin.Context) {
+				c.Set("user_id", uint(1))
+			}, NewMusicHandler(svc, noopMediaURLs{}).Update)
+
+			req := httptest.NewRequest(http.MethodPut, "/music/1", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d (%s)", w.Code, http.StatusOK, w.Body.String())
+			}
+			if svc.updated.Artist != tt.wantArtist {
+				t.Fatalf("artist sent to Update = %q, want %q", svc.updated.Artist, tt.wantArtist)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package handler // ประกาศ package handler
+
+import (
+	"net/http" // นำเข้า net/http
+
+	"go-music-api/internal/domain" // นำเข้า domain entities
+
+	"github.com/gin-gonic/gin" // นำเข้า gin
+)
+
+// OIDCHandler struct สำหรับจัดการ HTTP request ของการเข้าสู่ระบบผ่าน OpenID Connect
+type OIDCHandler struct {
+	oidcService domain.OIDCService // ใช้ service ในการทำงาน
+}
+
+// NewOIDCHandler สร้าง instance ของ OIDCHandler
+func NewOIDCHandler(oidcService domain.OIDCService) *OIDCHandler {
+	return &OIDCHandler{oidcService: oidcService}
+}
+
+// Providers คืนรายชื่อผู้ให้บริการที่เปิดใช้
+func (h *OIDCHandler) Providers(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"data": h.oidcService.Providers()})
+}
+
+// Login ส่งผู้ใช้ไปเข้าสู่ระบบกับผู้ให้บริการ
+func (h *OIDCHandler) Login(c *gin.Context) {
+	authURL, err := h.oidcService.Begin(c.Request.Context(), c.Param("provider"))
+	if err != nil {
+		if err == domain.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Unknown identity provider"})
+			return
+		}
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Identity provider is unavailable"})
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// Callback รับผู้ใช้กลับจากผู้ให้บริการ แล้วคืน token pair หรือ challenge ของ 2FA แบบเดียวกับ /auth/login
+func (h *OIDCHandler) Callback(c *gin.Context) {
+	if e := c.Query("error"); e != "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Identity provider returned " + e})
+		return
+	}
+	state, code := c.Query("state"), c.Query("code")
+	if state == "" || code == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "state and code are required"})
+		return
+	}
+
+	result, err := h.oidcService.Complete(c.Request.Context(), c.Param("provider"), state, code, clientInfo(c))
+	if err != nil {
+		switch err {
+		case domain.ErrNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Unknown identity provider"})
+		case domain.ErrUnauthorized:
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Login with the identity provider failed or expired, start again"})
+		case domain.ErrUnverified:
+			c.JSON(http.StatusForbidden, gin.H{"error": "The identity provider did not return a verified email address"})
+		case domain.ErrConflict:
+			c.JSON(http.StatusConflict, gin.H{"error": "An account with this email exists but its email is not verified; verify it or reset the password first"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	loginResponse(c, result)
+}
+
+// ListIdentities คืนบัญชีภายนอกที่ผูกกับผู้ใช้
+func (h *OIDCHandler) ListIdentities(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	identities, err := h.oidcService.ListIdentities(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": identities})
+}
+
+// Unlink ยกเลิกการผูกบัญชีภายนอก
+func (h *OIDCHandler) Unlink(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	id, ok := paramID(c, "id")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	if err := h.oidcService.Unlink(c.Request.Context(), userID, id); err != nil {
+		if err == domain.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Linked identity not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
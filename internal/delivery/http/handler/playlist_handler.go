@@ -0,0 +1,377 @@
+package handler // ประกาศ package handler
+
+import (
+	"net/http" // นำเข้า net/http
+	"strconv"  // นำเข้า strconv
+
+	"go-music-api/internal/domain" // นำเข้า domain entities
+
+	"github.com/gin-gonic/gin" // นำเข้า gin
+)
+
+// PlaylistHandler struct สำหรับจัดการ HTTP request ที่เกี่ยวกับ Playlist
+type PlaylistHandler struct {
+	playlistService domain.PlaylistService // ใช้ service ในการทำงาน
+	mediaURLs       domain.MediaURLService // ใช้สร้าง URL ไฟล์แบบมีลายเซ็น
+}
+
+// NewPlaylistHandler สร้าง instance ของ PlaylistHandler
+func NewPlaylistHandler(playlistService domain.PlaylistService, mediaURLs domain.MediaURLService) *PlaylistHandler {
+	return &PlaylistHandler{playlistService: playlistService, mediaURLs: mediaURLs}
+}
+
+type playlistRequest struct {
+	Name        string                    `json:"name" binding:"required"`
+	Description string                    `json:"description"`
+	Visibility  domain.PlaylistVisibility `json:"visibility"`
+}
+
+type addEntryRequest struct {
+	MusicID  uint `json:"music_id" binding:"required"`
+	Position *int `json:"position"` // ลำดับที่ต้องการแทรก (เริ่มที่ 0) ไม่ระบุ = ต่อท้าย
+}
+
+type moveEntryRequest struct {
+	Position *int `json:"position" binding:"required"`
+}
+
+type addCollaboratorRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// currentUserID อ่าน user_id ที่ AuthMiddleware ใส่ไว้ใน context
+func currentUserID(c *gin.Context) (uint, bool) {
+	userIDAny, ok := c.Get("user_id")
+	if !ok {
+		return 0, false
+	}
+	userID, ok := userIDAny.(uint)
+	return userID, ok
+}
+
+// currentActor อ่านผู้ใช้และบทบาทที่ AuthMiddleware ใส่ไว้ใน context สำหรับตรวจสิทธิ์ใน service
+func currentActor(c *gin.Context) (domain.Actor, bool) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return domain.Actor{}, false
+	}
+	actor := domain.Actor{UserID: userID, Email: c.GetString("email"), Role: domain.Role(c.GetString("role")), MFA: c.GetBool("mfa")}
+	// เรียกด้วย API key สิทธิ์ถูกจำกัดตาม scope ของ key
+	if scopes, ok := c.Get("scopes"); ok {
+		actor.Scopes, _ = scopes.([]domain.Permission)
+	}
+	return actor, true
+}
+
+// paramID อ่าน path parameter ที่เป็น ID
+func paramID(c *gin.Context, name string) (uint, bool) {
+	id64, err := strconv.ParseUint(c.Param(name), 10, 32)
+	if err != nil {
+		return 0, false
+	}
+	return uint(id64), true
+}
+
+// playlistError แปลง error จาก service เป็น HTTP response
+func playlistError(c *gin.Context, err error) {
+	switch err {
+	case domain.ErrNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Playlist or entry not found"})
+	case domain.ErrForbidden:
+		c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to modify this playlist"})
+	case domain.ErrInvalidInput:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid playlist data, music or user"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// hydratePlaylist แปลง URL ของไฟล์เพลงใน playlist เป็น URL ที่ลงลายเซ็น
+func (h *PlaylistHandler) hydratePlaylist(c *gin.Context, p *domain.Playlist) {
+	for i := range p.Entries {
+		hydrateMusicMediaURLs(c, h.mediaURLs, p.Entries[i].Music)
+	}
+}
+
+// Create สร้าง playlist ใหม่ของผู้ใช้ปัจจุบัน
+func (h *PlaylistHandler) Create(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req playlistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	email := c.GetString("email")
+	playlist := &domain.Playlist{
+		OwnerID:     userID,
+		Name:        req.Name,
+		Description: req.Description,
+		Visibility:  req.Visibility,
+		BaseModel:   domain.BaseModel{CreatedBy: email, UpdatedBy: email},
+	}
+	if err := h.playlistService.Create(c.Request.Context(), playlist); err != nil {
+		playlistError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": playlist})
+}
+
+// ListMine ดึง playlist ที่ผู้ใช้เป็นเจ้าของหรือร่วมแก้ไข
+func (h *PlaylistHandler) ListMine(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	page, pageSize, err := parsePageParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	playlists, total, err := h.playlistService.ListMine(c.Request.Context(), userID, page, pageSize)
+	if err != nil {
+		playlistError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": playlists, "pagination": offsetPagination(c, page, pageSize, total)})
+}
+
+// ListPublic ดึง playlist สาธารณะ
+func (h *PlaylistHandler) ListPublic(c *gin.Context) {
+	page, pageSize, err := parsePageParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	playlists, total, err := h.playlistService.ListPublic(c.Request.Context(), page, pageSize)
+	if err != nil {
+		playlistError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": playlists, "pagination": offsetPagination(c, page, pageSize, total)})
+}
+
+// GetByID ดึง playlist พร้อมรายการเพลง
+func (h *PlaylistHandler) GetByID(c *gin.Context) {
+	userID, _ := currentUserID(c)
+	id, ok := paramID(c, "id")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	playlist, err := h.playlistService.Get(c.Request.Context(), id, userID)
+	if err != nil {
+		playlistError(c, err)
+		return
+	}
+
+	h.hydratePlaylist(c, playlist)
+	c.JSON(http.StatusOK, gin.H{"data": playlist})
+}
+
+// GetShared ดึง playlist ผ่านลิงก์แชร์ (ไม่ต้องเข้าสู่ระบบ)
+func (h *PlaylistHandler) GetShared(c *gin.Context) {
+	playlist, err := h.playlistService.GetShared(c.Request.Context(), c.Param("token"))
+	if err != nil {
+		playlistError(c, err)
+		return
+	}
+
+	h.hydratePlaylist(c, playlist)
+	c.JSON(http.StatusOK, gin.H{"data": playlist})
+}
+
+// Update แก้ไขชื่อ คำอธิบาย และ visibility ของ playlist
+func (h *PlaylistHandler) Update(c *gin.Context) {
+	userID, _ := currentUserID(c)
+	id, ok := paramID(c, "id")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req playlistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Visibility == "" {
+		req.Visibility = domain.PlaylistPrivate
+	}
+
+	playlist := &domain.Playlist{
+		Name:        req.Name,
+		Description: req.Description,
+		Visibility:  req.Visibility,
+		BaseModel:   domain.BaseModel{ID: id, UpdatedBy: c.GetString("email")},
+	}
+	if err := h.playlistService.Update(c.Request.Context(), playlist, userID); err != nil {
+		playlistError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": playlist})
+}
+
+// Delete ลบ playlist
+func (h *PlaylistHandler) Delete(c *gin.Context) {
+	userID, _ := currentUserID(c)
+	id, ok := paramID(c, "id")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	if err := h.playlistService.Delete(c.Request.Context(), id, userID); err != nil {
+		playlistError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Playlist deleted successfully"})
+}
+
+// RegenerateShareToken ออก token แชร์ใหม่ ลิงก์เดิมจะใช้ไม่ได้
+func (h *PlaylistHandler) RegenerateShareToken(c *gin.Context) {
+	userID, _ := currentUserID(c)
+	id, ok := paramID(c, "id")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	token, err := h.playlistService.RegenerateShareToken(c.Request.Context(), id, userID)
+	if err != nil {
+		playlistError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"share_token": token})
+}
+
+// AddEntry เพิ่มเพลงลง playlist
+func (h *PlaylistHandler) AddEntry(c *gin.Context) {
+	userID, _ := currentUserID(c)
+	id, ok := paramID(c, "id")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req addEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Position != nil && *req.Position < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "position must be >= 0"})
+		return
+	}
+
+	entry, err := h.playlistService.AddEntry(c.Request.Context(), id, req.MusicID, userID, req.Position)
+	if err != nil {
+		playlistError(c, err)
+		return
+	}
+
+	hydrateMusicMediaURLs(c, h.mediaURLs, entry.Music)
+	c.JSON(http.StatusCreated, gin.H{"data": entry})
+}
+
+// MoveEntry ย้ายเพลงไปลำดับใหม่
+func (h *PlaylistHandler) MoveEntry(c *gin.Context) {
+	userID, _ := currentUserID(c)
+	id, ok := paramID(c, "id")
+	entryID, ok2 := paramID(c, "entryId")
+	if !ok || !ok2 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req moveEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if *req.Position < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "position must be >= 0"})
+		return
+	}
+
+	if err := h.playlistService.MoveEntry(c.Request.Context(), id, entryID, userID, *req.Position); err != nil {
+		playlistError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Entry moved successfully"})
+}
+
+// RemoveEntry ลบเพลงออกจาก playlist
+func (h *PlaylistHandler) RemoveEntry(c *gin.Context) {
+	userID, _ := currentUserID(c)
+	id, ok := paramID(c, "id")
+	entryID, ok2 := paramID(c, "entryId")
+	if !ok || !ok2 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	if err := h.playlistService.RemoveEntry(c.Request.Context(), id, entryID, userID); err != nil {
+		playlistError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Entry removed successfully"})
+}
+
+// AddCollaborator เพิ่มผู้ร่วมแก้ไขด้วยอีเมล
+func (h *PlaylistHandler) AddCollaborator(c *gin.Context) {
+	userID, _ := currentUserID(c)
+	id, ok := paramID(c, "id")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req addCollaboratorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.playlistService.AddCollaborator(c.Request.Context(), id, userID, req.Email); err != nil {
+		playlistError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Collaborator added successfully"})
+}
+
+// RemoveCollaborator ลบผู้ร่วมแก้ไข
+func (h *PlaylistHandler) RemoveCollaborator(c *gin.Context) {
+	userID, _ := currentUserID(c)
+	id, ok := paramID(c, "id")
+	collaboratorID, ok2 := paramID(c, "userId")
+	if !ok || !ok2 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	if err := h.playlistService.RemoveCollaborator(c.Request.Context(), id, userID, collaboratorID); err != nil {
+		playlistError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Collaborator removed successfully"})
+}
@@ -0,0 +1,181 @@
+package handler // ประกาศ package handler
+
+import (
+	"errors"   // นำเข้า errors
+	"fmt"      // นำเข้า fmt สำหรับสร้าง header Content-Range
+	"io"       // นำเข้า io สำหรับคัดลอกข้อมูลไปยัง response
+	"net/http" // นำเข้า net/http
+	"strconv"  // นำเข้า strconv
+	"strings"  // นำเข้า strings
+	"time"     // นำเข้า time
+
+	"go-music-api/internal/domain" // นำเข้า domain entities
+
+	"github.com/gin-gonic/gin" // นำเข้า gin
+)
+
+// errUnsatisfiableRange ช่วงที่ขอไม่อยู่ในไฟล์
+var errUnsatisfiableRange = errors.New("range not satisfiable")
+
+// byteRange ช่วงข้อมูลที่ขอผ่าน header Range
+type byteRange struct {
+	start  int64
+	length int64
+}
+
+// parseRange อ่าน header Range รูปแบบ bytes=a-b, bytes=a- หรือ bytes=-n (คั่นหลายช่วงด้วย ,)
+func parseRange(header string, size int64) ([]byteRange, error) {
+	const prefix = "bytes="
+	if !strings.HasPrefix(header, prefix) {
+		return nil, errors.New("invalid range")
+	}
+
+	var ranges []byteRange
+	for _, spec := range strings.Split(header[len(prefix):], ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		first, last, ok := strings.Cut(spec, "-")
+		if !ok {
+			return nil, errors.New("invalid range")
+		}
+		first, last = strings.TrimSpace(first), strings.TrimSpace(last)
+
+		var r byteRange
+		if first == "" {
+			// bytes=-n คือ n ไบต์สุดท้าย
+			n, err := strconv.ParseInt(last, 10, 64)
+			if err != nil || n < 0 {
+				return nil, errors.New("invalid range")
+			}
+			if n == 0 {
+				continue
+			}
+			if n > size {
+				n = size
+			}
+			r = byteRange{start: size - n, length: n}
+		} else {
+			start, err := strconv.ParseInt(first, 10, 64)
+			if err != nil || start < 0 {
+				return nil, errors.New("invalid range")
+			}
+			if start >= size {
+				continue
+			}
+			end := size - 1
+			if last != "" {
+				end, err = strconv.ParseInt(last, 10, 64)
+				if err != nil || end < start {
+					return nil, errors.New("invalid range")
+				}
+				if end >= size {
+					end = size - 1
+				}
+			}
+			r = byteRange{start: start, length: end - start + 1}
+		}
+		ranges = append(ranges, r)
+	}
+	if len(ranges) == 0 {
+		return nil, errUnsatisfiableRange
+	}
+	return ranges, nil
+}
+
+// ifRangeMatches ตรวจ header If-Range ว่ายังตรงกับไฟล์ปัจจุบัน (ถ้าไม่ตรงต้องส่งทั้งไฟล์)
+func ifRangeMatches(c *gin.Context, info domain.FileInfo) bool {
+	v := strings.TrimSpace(c.GetHeader("If-Range"))
+	if v == "" {
+		return true
+	}
+	if strings.HasPrefix(v, `"`) || strings.HasPrefix(v, "W/") {
+		// ต้องเทียบ ETag แบบ strong เท่านั้น
+		return info.ETag != "" && !strings.HasPrefix(info.ETag, "W/") && v == info.ETag
+	}
+	t, err := http.ParseTime(v)
+	if err != nil || info.ModTime.IsZero() {
+		return false
+	}
+	return info.ModTime.Truncate(time.Second).Equal(t)
+}
+
+// Stream ส่งไฟล์เสียง/วิดีโอของเพลง รองรับ Range และ If-Range เพื่อให้ player เลื่อนตำแหน่งได้
+func (h *MusicHandler) Stream(c *gin.Context) {
+	id64, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	format := domain.MediaFormat(c.DefaultQuery("format", string(domain.MediaMP3)))
+	if format != domain.MediaMP3 && format != domain.MediaMP4 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be mp3 or mp4"})
+		return
+	}
+
+	media, err := h.musicService.StatMedia(c.Request.Context(), uint(id64), format)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Media not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	contentType := media.ContentType
+	if contentType == "" || contentType == "application/octet-stream" {
+		contentType = format.ContentType()
+	}
+	header := c.Writer.Header()
+	header.Set("Accept-Ranges", "bytes")
+	header.Set("Content-Type", contentType)
+	if media.ETag != "" {
+		header.Set("ETag", media.ETag)
+	}
+	if !media.ModTime.IsZero() {
+		header.Set("Last-Modified", media.ModTime.UTC().Format(http.TimeFormat))
+	}
+
+	status := http.StatusOK
+	start, length := int64(0), media.Size
+	if rh := c.GetHeader("Range"); rh != "" && ifRangeMatches(c, media.FileInfo) {
+		ranges, err := parseRange(rh, media.Size)
+		if err == errUnsatisfiableRange {
+			header.Set("Content-Range", fmt.Sprintf("bytes */%d", media.Size))
+			c.AbortWithStatusJSON(http.StatusRequestedRangeNotSatisfiable, gin.H{"error": err.Error()})
+			return
+		}
+		// Range ที่ผิดรูปแบบจะถูกละเว้น และรองรับเฉพาะช่วงเดียว ถ้าขอหลายช่วงจะส่งทั้งไฟล์ (อนุญาตตาม RFC 9110)
+		if err == nil && len(ranges) == 1 {
+			status = http.StatusPartialContent
+			start, length = ranges[0].start, ranges[0].length
+			header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, start+length-1, media.Size))
+		}
+	}
+	header.Set("Content-Length", strconv.FormatInt(length, 10))
+
+	if c.Request.Method == http.MethodHead {
+		c.Status(status)
+		return
+	}
+
+	body, err := h.musicService.OpenMedia(c.Request.Context(), media, start, length)
+	if err != nil {
+		header.Del("Content-Length")
+		header.Del("Content-Range")
+		if err == domain.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Media not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer body.Close()
+
+	c.Status(status)
+	// ผู้ใช้อาจปิดการเล่นกลางทาง จึงไม่ต้องรายงาน error ของการคัดลอก
+	_, _ = io.Copy(c.Writer, body)
+}
@@ -0,0 +1,202 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"go-music-api/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestParseRange(t *testing.T) {
+	const size = 1000
+
+	tests := []struct {
+		name    string
+		header  string
+		want    []byteRange
+		wantErr error // errUnsatisfiableRange เมื่อไม่มีช่วงใดอยู่ในไฟล์ (ตอบ 416)
+		invalid bool  // header ผิดรูปแบบ (ถูกละเว้น)
+	}{
+		{name: "closed range", header: "bytes=0-499", want: []byteRange{{0, 500}}},
+		{name: "open range", header: "bytes=500-", want: []byteRange{{500, 500}}},
+		{name: "single byte", header: "bytes=999-999", want: []byteRange{{999, 1}}},
+		{name: "suffix range", header: "bytes=-100", want: []byteRange{{900, 100}}},
+		{name: "suffix longer than the file", header: "bytes=-5000", want: []byteRange{{0, 1000}}},
+		{name: "end past EOF is clamped", header: "bytes=900-5000", want: []byteRange{{900, 100}}},
+		{name: "spaces around values", header: "bytes= 10 - 19 ", want: []byteRange{{10, 10}}},
+		{name: "multiple ranges", header: "bytes=0-9, 20-29,-5", want: []byteRange{{0, 10}, {20, 10}, {995, 5}}},
+		{name: "unsatisfiable range is dropped from a set", header: "bytes=0-9,2000-2100", want: []byteRange{{0, 10}}},
+		{name: "start past EOF", header: "bytes=1000-", wantErr: errUnsatisfiableRange},
+		{name: "every range past EOF", header: "bytes=1000-1100,5000-", wantErr: errUnsatisfiableRange},
+		{name: "zero-length suffix", header: "bytes=-0", wantErr: errUnsatisfiableRange},
+		{name: "empty set", header: "bytes=", wantErr: errUnsatisfiableRange},
+		{name: "other unit", header: "items=0-1", invalid: true},
+		{name: "missing dash", header: "bytes=100", invalid: true},
+		{name: "end before start", header: "bytes=500-100", invalid: true},
+		{name: "not a number", header: "bytes=a-b", invalid: true},
+		{name: "negative start", header: "bytes=--5", invalid: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseRange(tt.header, size)
+			switch {
+			case tt.invalid:
+				if err == nil || err == errUnsatisfiableRange {
+					t.Fatalf("parseRange(%q) error = %v, want invalid range", tt.header, err)
+				}
+			case tt.wantErr != nil:
+				if err != tt.wantErr {
+					t.Fatalf("parseRange(%q) error = %v, want %v", tt.header, err, tt.wantErr)
+				}
+			default:
+				if err != nil {
+					t.Fatalf("parseRange(%q) error = %v", tt.header, err)
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Fatalf("parseRange(%q) = %v, want %v", tt.header, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+// streamMusicService MusicService จำลองที่มีไฟล์สื่อเดียว (เมธอดอื่นไม่ถูกเรียกในการทดสอบนี้)
+type streamMusicService struct {
+	domain.MusicService
+	media *domain.MediaFile
+	data  []byte
+}
+
+func (s *streamMusicService) StatMedia(ctx context.Context, id uint, format domain.MediaFormat) (*domain.MediaFile, error) {
+	if id != 1 {
+		return nil, domain.ErrNotFound
+	}
+	return s.media, nil
+}
+
+func (s *streamMusicService) OpenMedia(ctx context.Context, media *domain.MediaFile, offset, length int64) (io.ReadCloser, error) {
+	return io.NopCloser(bytes.NewReader(s.data[offset : offset+length])), nil
+}
+
+func TestStreamRange(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	data := make([]byte, 1000)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	modTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	svc := &streamMusicService{
+		media: &domain.MediaFile{Format: domain.MediaMP3, FileInfo: domain.FileInfo{
+			Size: int64(len(data)), ContentType: "audio/mpeg", ModTime: modTime, ETag: `"v1"`,
+		}},
+		data: data,
+	}
+	router := gin.New()
+	router.GET("/musics/:id/stream", NewMusicHandler(svc, nil).Stream)
+
+	tests := []struct {
+		name             string
+		headers          map[string]string
+		wantStatus       int
+		wantContentRange string
+		wantBody         []byte
+	}{
+		{name: "no range", wantStatus: http.StatusOK, wantBody: data},
+		{
+			name:             "closed range",
+			headers:          map[string]string{"Range": "bytes=10-19"},
+			wantStatus:       http.StatusPartialContent,
+			wantContentRange: "bytes 10-19/1000",
+			wantBody:         data[10:20],
+		},
+		{
+			name:             "suffix range",
+			headers:          map[string]string{"Range": "bytes=-10"},
+			wantStatus:       http.StatusPartialContent,
+			wantContentRange: "bytes 990-999/1000",
+			wantBody:         data[990:],
+		},
+		{
+			name:             "range past EOF is clamped",
+			headers:          map[string]string{"Range": "bytes=995-2000"},
+			wantStatus:       http.StatusPartialContent,
+			wantContentRange: "bytes 995-999/1000",
+			wantBody:         data[995:],
+		},
+		{
+			name:             "unsatisfiable range",
+			headers:          map[string]string{"Range": "bytes=1000-"},
+			wantStatus:       http.StatusRequestedRangeNotSatisfiable,
+			wantContentRange: "bytes */1000",
+		},
+		{
+			name:       "multiple ranges send the whole file",
+			headers:    map[string]string{"Range": "bytes=0-9,20-29"},
+			wantStatus: http.StatusOK,
+			wantBody:   data,
+		},
+		{
+			name:       "malformed range is ignored",
+			headers:    map[string]string{"Range": "bytes=abc"},
+			wantStatus: http.StatusOK,
+			wantBody:   data,
+		},
+		{
+			name:             "If-Range with the current ETag",
+			headers:          map[string]string{"Range": "bytes=0-9", "If-Range": `"v1"`},
+			wantStatus:       http.StatusPartialContent,
+			wantContentRange: "bytes 0-9/1000",
+			wantBody:         data[:10],
+		},
+		{
+			name:             "If-Range with the current Last-Modified",
+			headers:          map[string]string{"Range": "bytes=0-9", "If-Range": modTime.Format(http.TimeFormat)},
+			wantStatus:       http.StatusPartialContent,
+			wantContentRange: "bytes 0-9/1000",
+			wantBody:         data[:10],
+		},
+		{
+			name:       "If-Range with an old ETag sends the whole file",
+			headers:    map[string]string{"Range": "bytes=0-9", "If-Range": `"v0"`},
+			wantStatus: http.StatusOK,
+			wantBody:   data,
+		},
+		{
+			name:       "If-Range with a weak ETag sends the whole file",
+			headers:    map[string]string{"Range": "bytes=0-9", "If-Range": `W/"v1"`},
+			wantStatus: http.StatusOK,
+			wantBody:   data,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/musics/1/stream", nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (%s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if got := w.Header().Get("Content-Range"); got != tt.wantContentRange {
+				t.Fatalf("Content-Range = %q, want %q", got, tt.wantContentRange)
+			}
+			if tt.wantBody != nil && !bytes.Equal(w.Body.Bytes(), tt.wantBody) {
+				t.Fatalf("body = %d bytes, want %d bytes", w.Body.Len(), len(tt.wantBody))
+			}
+		})
+	}
+}
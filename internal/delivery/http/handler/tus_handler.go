@@ -0,0 +1,264 @@
+package handler // ประกาศ package handler
+
+import (
+	"crypto/md5"      // นำเข้า md5 สำหรับ Upload-Checksum
+	"crypto/sha1"     // นำเข้า sha1 สำหรับ Upload-Checksum (อัลกอริทึมที่ tus กำหนดให้ต้องรองรับ)
+	"crypto/sha256"   // นำเข้า sha256 สำหรับ Upload-Checksum
+	"encoding/base64" // นำเข้า base64 สำหรับอ่าน Upload-Metadata และ Upload-Checksum
+	"hash"            // นำเข้า hash
+	"net/http"        // นำเข้า net/http
+	"strconv"         // นำเข้า strconv
+	"strings"         // นำเข้า strings
+
+	"go-music-api/internal/domain" // นำเข้า domain entities
+
+	"github.com/gin-gonic/gin" // นำเข้า gin
+)
+
+// ค่าคงที่ของโปรโตคอล tus ที่รองรับ
+const (
+	tusVersion           = "1.0.0"
+	tusExtensions        = "creation,termination,checksum,expiration"
+	tusChecksumAlgorithm = "sha1,sha256,md5"
+	tusContentType       = "application/offset+octet-stream"
+	tusBasePath          = "/api/v1/uploads/tus/"
+
+	// statusChecksumMismatch status code ที่ tus ใช้เมื่อ checksum ของ chunk ไม่ตรง
+	statusChecksumMismatch = 460
+)
+
+// TusHandler struct สำหรับจัดการ HTTP request ของการอัปโหลดแบบต่อได้ตามโปรโตคอล tus 1.0
+type TusHandler struct {
+	uploadService domain.ResumableUploadService // ใช้ service ในการทำงาน
+}
+
+// NewTusHandler สร้าง instance ของ TusHandler
+func NewTusHandler(uploadService domain.ResumableUploadService) *TusHandler {
+	return &TusHandler{uploadService: uploadService}
+}
+
+// tusError แปลง error จาก service เป็น HTTP response
+func tusError(c *gin.Context, err error) {
+	switch err {
+	case domain.ErrNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Upload or music not found"})
+	case domain.ErrForbidden:
+		c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to use this upload or modify this track"})
+	case domain.ErrConflict:
+		c.JSON(http.StatusConflict, gin.H{"error": "Upload-Offset does not match or the upload is busy"})
+	case domain.ErrTooLarge:
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Upload-Length exceeds Tus-Max-Size"})
+	case domain.ErrChecksum:
+		c.JSON(statusChecksumMismatch, gin.H{"error": "Checksum Mismatch"})
+	case domain.ErrInvalidInput:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid upload length, metadata, file type or chunk"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// Protocol middleware ตรวจ header Tus-Resumable และใส่ header ของ tus ใน response
+func (h *TusHandler) Protocol(c *gin.Context) {
+	c.Header("Tus-Resumable", tusVersion)
+	if c.GetHeader("Tus-Resumable") != tusVersion {
+		c.Header("Tus-Version", tusVersion)
+		c.AbortWithStatusJSON(http.StatusPreconditionFailed, gin.H{"error": "Unsupported Tus-Resumable version"})
+		return
+	}
+	c.Next()
+}
+
+// Options คืนความสามารถของ server ตามโปรโตคอล tus
+func (h *TusHandler) Options(c *gin.Context) {
+	c.Header("Tus-Resumable", tusVersion)
+	c.Header("Tus-Version", tusVersion)
+	c.Header("Tus-Extension", tusExtensions)
+	c.Header("Tus-Max-Size", strconv.FormatInt(h.uploadService.MaxSize(), 10))
+	c.Header("Tus-Checksum-Algorithm", tusChecksumAlgorithm)
+	c.Status(http.StatusNoContent)
+}
+
+// parseTusMetadata อ่าน Upload-Metadata รูปแบบ "key base64,key2 base64"
+func parseTusMetadata(header string) (map[string]string, bool) {
+	meta := make(map[string]string)
+	if strings.TrimSpace(header) == "" {
+		return meta, true
+	}
+	for _, pair := range strings.Split(header, ",") {
+		fields := strings.Fields(pair)
+		if len(fields) == 0 || len(fields) > 2 {
+			return nil, false
+		}
+		var value []byte
+		if len(fields) == 2 {
+			var err error
+			if value, err = base64.StdEncoding.DecodeString(fields[1]); err != nil {
+				return nil, false
+			}
+		}
+		meta[fields[0]] = string(value)
+	}
+	return meta, true
+}
+
+// firstOf คืนค่าแรกที่ไม่ว่างจาก metadata ตามลำดับ key (client แต่ละตัวใช้ชื่อ key ต่างกัน)
+func firstOf(meta map[string]string, keys ...string) string {
+	for _, k := range keys {
+		if v := meta[k]; v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// parseTusChecksum อ่าน Upload-Checksum รูปแบบ "<algorithm> <base64>"
+func parseTusChecksum(header string) (*domain.ChunkChecksum, bool) {
+	alg, encoded, ok := strings.Cut(strings.TrimSpace(header), " ")
+	if !ok {
+		return nil, false
+	}
+	sum, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, false
+	}
+	var h hash.Hash
+	switch alg {
+	case "sha1":
+		h = sha1.New()
+	case "sha256":
+		h = sha256.New()
+	case "md5":
+		h = md5.New()
+	default:
+		return nil, false
+	}
+	return &domain.ChunkChecksum{Hash: h, Sum: sum}, true
+}
+
+// setUploadHeaders ใส่ offset และข้อมูลของการอัปโหลดใน response
+func setUploadHeaders(c *gin.Context, u *domain.ResumableUpload) {
+	c.Header("Upload-Offset", strconv.FormatInt(u.Offset, 10))
+	c.Header("Upload-Length", strconv.FormatInt(u.Length, 10))
+	if u.Status != domain.UploadCompleted {
+		c.Header("Upload-Expires", u.ExpiresAt.UTC().Format(http.TimeFormat))
+	}
+}
+
+// Create สร้างการอัปโหลดใหม่ (tus creation)
+// Upload-Metadata ต้องมี music_id, kind (mp3, mp4, image), filename และ filetype
+func (h *TusHandler) Create(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	if c.GetHeader("Upload-Defer-Length") != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Upload-Defer-Length is not supported"})
+		return
+	}
+	length, err := strconv.ParseInt(c.GetHeader("Upload-Length"), 10, 64)
+	if err != nil || length < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Upload-Length"})
+		return
+	}
+	rawMeta := c.GetHeader("Upload-Metadata")
+	meta, ok := parseTusMetadata(rawMeta)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Upload-Metadata"})
+		return
+	}
+	musicID, err := strconv.ParseUint(meta["music_id"], 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Upload-Metadata must include music_id"})
+		return
+	}
+
+	upload, err := h.uploadService.Create(c.Request.Context(), domain.ResumableUploadRequest{
+		MusicID:     uint(musicID),
+		Kind:        domain.UploadKind(meta["kind"]),
+		Filename:    firstOf(meta, "filename", "name"),
+		ContentType: firstOf(meta, "filetype", "type", "content_type"),
+		Length:      length,
+		Metadata:    rawMeta,
+	}, actor)
+	if err != nil {
+		tusError(c, err)
+		return
+	}
+
+	c.Header("Location", toPublicURL(tusBasePath+upload.ID))
+	setUploadHeaders(c, upload)
+	c.Status(http.StatusCreated)
+}
+
+// Head คืน offset ปัจจุบันเพื่อให้ client ส่งต่อจากจุดที่ค้างไว้
+func (h *TusHandler) Head(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.Status(http.StatusUnauthorized)
+		return
+	}
+
+	upload, err := h.uploadService.Get(c.Request.Context(), c.Param("id"), userID)
+	if err != nil {
+		tusError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	if upload.Metadata != "" {
+		c.Header("Upload-Metadata", upload.Metadata)
+	}
+	setUploadHeaders(c, upload)
+	c.Status(http.StatusOK)
+}
+
+// Patch เขียน chunk ต่อจาก Upload-Offset เมื่อได้รับครบไฟล์จะถูกผูกกับเพลง
+func (h *TusHandler) Patch(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	if c.ContentType() != tusContentType {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "Content-Type must be " + tusContentType})
+		return
+	}
+	offset, err := strconv.ParseInt(c.GetHeader("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Upload-Offset"})
+		return
+	}
+	var checksum *domain.ChunkChecksum
+	if v := c.GetHeader("Upload-Checksum"); v != "" {
+		if checksum, ok = parseTusChecksum(v); !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or unsupported Upload-Checksum"})
+			return
+		}
+	}
+
+	upload, err := h.uploadService.Append(c.Request.Context(), c.Param("id"), userID, offset, c.Request.Body, checksum)
+	if err != nil {
+		tusError(c, err)
+		return
+	}
+
+	setUploadHeaders(c, upload)
+	c.Status(http.StatusNoContent)
+}
+
+// Delete ยกเลิกการอัปโหลด (tus termination)
+func (h *TusHandler) Delete(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.uploadService.Terminate(c.Request.Context(), c.Param("id"), userID); err != nil {
+		tusError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
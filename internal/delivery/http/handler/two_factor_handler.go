@@ -0,0 +1,181 @@
+package handler // ประกาศ package handler
+
+import (
+	"net/http" // นำเข้า net/http
+
+	"go-music-api/internal/domain" // นำเข้า domain entities
+
+	"github.com/gin-gonic/gin" // นำเข้า gin
+)
+
+// TwoFactorHandler struct สำหรับจัดการ HTTP request ที่เกี่ยวกับ 2FA
+type TwoFactorHandler struct {
+	twoFactorService domain.TwoFactorService // ใช้ service ในการทำงาน
+	userService      domain.UserService      // ใช้ดึงอีเมลของผู้ใช้สำหรับแสดงในแอปยืนยันตัวตน
+}
+
+// NewTwoFactorHandler สร้าง instance ของ TwoFactorHandler
+func NewTwoFactorHandler(twoFactorService domain.TwoFactorService, userService domain.UserService) *TwoFactorHandler {
+	return &TwoFactorHandler{twoFactorService: twoFactorService, userService: userService}
+}
+
+// twoFactorError แปลง error จาก service เป็น HTTP response
+func twoFactorError(c *gin.Context, err error) {
+	switch err {
+	case domain.ErrInvalidCreds:
+		c.JSON(http.StatusForbidden, gin.H{"error": "Invalid password or verification code"})
+	case domain.ErrReauthRequired:
+		c.JSON(http.StatusForbidden, gin.H{"error": "Log in again to confirm this action"})
+	case domain.ErrNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Two-factor authentication is not set up"})
+	case domain.ErrConflict:
+		c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is already enabled"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+type twoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type disableTwoFactorRequest struct {
+	Password string `json:"password"` // ว่างได้สำหรับบัญชีที่ไม่มีรหัสผ่าน (ยืนยันด้วยการเข้าสู่ระบบล่าสุด)
+	Code     string `json:"code" binding:"required"`
+}
+
+type verifyChallengeRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required"`
+}
+
+// Status คืนสถานะ 2FA ของผู้ใช้
+func (h *TwoFactorHandler) Status(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	status, err := h.twoFactorService.Status(c.Request.Context(), userID)
+	if err != nil {
+		twoFactorError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": status})
+}
+
+// Setup เริ่มตั้งค่า 2FA คืน secret และ otpauth URI สำหรับสร้าง QR code
+func (h *TwoFactorHandler) Setup(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	user, err := h.userService.GetByID(c.Request.Context(), userID)
+	if err != nil {
+		twoFactorError(c, err)
+		return
+	}
+	setup, err := h.twoFactorService.Setup(c.Request.Context(), user)
+	if err != nil {
+		twoFactorError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, gin.H{"data": setup})
+}
+
+// Confirm ยืนยันรหัสแรกจากแอปเพื่อเปิดใช้ 2FA คืนรหัสกู้คืนซึ่งแสดงได้ครั้งเดียว
+func (h *TwoFactorHandler) Confirm(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req twoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	codes, err := h.twoFactorService.Confirm(c.Request.Context(), userID, req.Code)
+	if err != nil {
+		twoFactorError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"recovery_codes": codes}})
+}
+
+// RegenerateRecoveryCodes ออกรหัสกู้คืนชุดใหม่ (รหัสชุดเดิมใช้ไม่ได้อีก)
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req twoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	codes, err := h.twoFactorService.RegenerateRecoveryCodes(c.Request.Context(), userID, req.Code)
+	if err != nil {
+		twoFactorError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"recovery_codes": codes}})
+}
+
+// Disable ปิด 2FA (ต้องยืนยันด้วยรหัสผ่านและรหัส 2FA หรือรหัสกู้คืน บัญชีที่ไม่มีรหัสผ่านใช้การเข้าสู่ระบบล่าสุดแทนรหัสผ่าน)
+func (h *TwoFactorHandler) Disable(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req disableTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.twoFactorService.Disable(c.Request.Context(), userID, c.GetString("session_id"), req.Password, req.Code); err != nil {
+		twoFactorError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Verify ขั้นที่สองของการเข้าสู่ระบบ รับ challenge_token กับรหัส TOTP หรือรหัสกู้คืน แล้วคืน token
+func (h *TwoFactorHandler) Verify(c *gin.Context) {
+	var req verifyChallengeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tokens, err := h.twoFactorService.CompleteChallenge(c.Request.Context(), req.ChallengeToken, req.Code, clientInfo(c))
+	if err != nil {
+		if err == domain.ErrInvalidCreds {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired challenge or verification code"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
@@ -0,0 +1,138 @@
+package handler // ประกาศ package handler
+
+import (
+	"context"  // นำเข้า context
+	"io"       // นำเข้า io
+	"net/http" // นำเข้า net/http
+	"net/url"  // นำเข้า url
+
+	"go-music-api/internal/domain" // นำเข้า domain entities
+
+	"github.com/gin-gonic/gin" // นำเข้า gin
+)
+
+// LocalUploadReceiver interface สำหรับ storage ในเครื่องที่รับไฟล์จาก URL อัปโหลดที่ลงลายเซ็น
+type LocalUploadReceiver interface {
+	ReceiveUpload(ctx context.Context, name string, query url.Values, body io.Reader) (string, error)
+}
+
+// UploadHandler struct สำหรับจัดการ HTTP request ที่เกี่ยวกับการอัปโหลดตรง
+type UploadHandler struct {
+	uploadService domain.UploadService   // ใช้ service ในการทำงาน
+	mediaURLs     domain.MediaURLService // ใช้สร้าง URL ไฟล์แบบมีลายเซ็น
+	receiver      LocalUploadReceiver    // รับไฟล์เมื่อใช้ storage ในเครื่อง (nil เมื่อใช้ S3)
+}
+
+// NewUploadHandler สร้าง instance ของ UploadHandler
+func NewUploadHandler(uploadService domain.UploadService, mediaURLs domain.MediaURLService, receiver LocalUploadReceiver) *UploadHandler {
+	return &UploadHandler{uploadService: uploadService, mediaURLs: mediaURLs, receiver: receiver}
+}
+
+type createUploadRequest struct {
+	Kind           domain.UploadKind `json:"kind" binding:"required"`
+	Filename       string            `json:"filename" binding:"required"`
+	ContentType    string            `json:"content_type" binding:"required"`
+	Size           int64             `json:"size" binding:"required,min=1"`
+	ChecksumSHA256 string            `json:"checksum_sha256"`
+}
+
+type finalizeUploadRequest struct {
+	MusicID uint                   `json:"music_id" binding:"required"`
+	Parts   []domain.CompletedPart `json:"parts" binding:"dive"`
+}
+
+// uploadError แปลง error จาก service เป็น HTTP response
+func uploadError(c *gin.Context, err error) {
+	switch err {
+	case domain.ErrNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Upload or music not found"})
+	case domain.ErrUnauthorized:
+		c.JSON(http.StatusForbidden, gin.H{"error": "Invalid or expired signed URL"})
+	case domain.ErrForbidden:
+		c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to use this upload or modify this track"})
+	case domain.ErrConflict:
+		c.JSON(http.StatusConflict, gin.H{"error": "Upload is already finalized or expired"})
+	case domain.ErrInvalidInput:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid file kind, type, size, checksum or parts"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// Create เริ่มการอัปโหลดตรงและคืน URL ที่ลงลายเซ็นสำหรับอัปโหลดไฟล์
+func (h *UploadHandler) Create(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req createUploadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ticket, err := h.uploadService.Create(c.Request.Context(), domain.UploadRequest{
+		Kind:           req.Kind,
+		Filename:       req.Filename,
+		ContentType:    req.ContentType,
+		Size:           req.Size,
+		ChecksumSHA256: req.ChecksumSHA256,
+	}, userID)
+	if err != nil {
+		uploadError(c, err)
+		return
+	}
+
+	// URL ของ storage ในเครื่องเป็น path ต้องเติม host
+	if ticket.Request != nil {
+		ticket.Request.URL = toPublicURL(ticket.Request.URL)
+	}
+	for i := range ticket.Parts {
+		ticket.Parts[i].URL = toPublicURL(ticket.Parts[i].URL)
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": ticket})
+}
+
+// Finalize ตรวจสอบไฟล์ที่อัปโหลดและผูกกับเพลง
+func (h *UploadHandler) Finalize(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req finalizeUploadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	music, err := h.uploadService.Finalize(c.Request.Context(), c.Param("id"), actor, req.MusicID, req.Parts)
+	if err != nil {
+		uploadError(c, err)
+		return
+	}
+
+	hydrateMusicMediaURLs(c, h.mediaURLs, music)
+	c.JSON(http.StatusOK, gin.H{"data": music})
+}
+
+// ReceiveLocal รับไฟล์จาก URL อัปโหลดที่ลงลายเซ็นเมื่อใช้ storage ในเครื่อง
+func (h *UploadHandler) ReceiveLocal(c *gin.Context) {
+	if h.receiver == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+		return
+	}
+
+	etag, err := h.receiver.ReceiveUpload(c.Request.Context(), c.Param("name"), c.Request.URL.Query(), c.Request.Body)
+	if err != nil {
+		uploadError(c, err)
+		return
+	}
+
+	c.Header("ETag", etag)
+	c.Status(http.StatusOK)
+}
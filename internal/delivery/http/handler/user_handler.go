@@ -1,7 +1,14 @@
 package handler // ประกาศ package handler
 
 import (
-	"net/http" // นำเข้า net/http
+	"archive/zip"   // นำเข้า zip สำหรับ export แบบ ZIP
+	"bytes"         // นำเข้า bytes
+	"encoding/json" // นำเข้า json สำหรับไฟล์ใน ZIP
+	"errors"        // นำเข้า errors
+	"fmt"           // นำเข้า fmt
+	"math"          // นำเข้า math สำหรับปัดวินาทีของ Retry-After
+	"net/http"      // นำเข้า net/http
+	"strconv"       // นำเข้า strconv
 
 	"go-music-api/internal/domain" // นำเข้า domain entities
 
@@ -10,12 +17,19 @@ import (
 
 // UserHandler struct สำหรับจัดการ HTTP request ที่เกี่ยวกับ User
 type UserHandler struct {
-	userService domain.UserService // ใช้ service ในการทำงาน
+	userService    domain.UserService    // ใช้ service ในการทำงาน
+	sessionService domain.SessionService // ใช้หมุนและยกเลิก refresh token
+	accountService domain.AccountService // ใช้รีเซ็ตรหัสผ่านและยืนยันอีเมล
 }
 
 // NewUserHandler สร้าง instance ของ UserHandler
-func NewUserHandler(userService domain.UserService) *UserHandler {
-	return &UserHandler{userService: userService}
+func NewUserHandler(userService domain.UserService, sessionService domain.SessionService, accountService domain.AccountService) *UserHandler {
+	return &UserHandler{userService: userService, sessionService: sessionService, accountService: accountService}
+}
+
+// clientInfo ข้อมูลอุปกรณ์ของ request สำหรับบันทึกใน session
+func clientInfo(c *gin.Context) domain.ClientInfo {
+	return domain.ClientInfo{UserAgent: c.Request.UserAgent(), IP: c.ClientIP()}
 }
 
 type registerRequest struct {
@@ -51,7 +65,13 @@ func (h *UserHandler) Register(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{"message": "User registered successfully"})
+	// ผู้ใช้ถูกสร้างแล้ว ถ้าส่งอีเมลไม่สำเร็จให้ขอส่งใหม่ได้ภายหลัง
+	if err := h.accountService.SendVerification(c.Request.Context(), user); err != nil {
+		c.JSON(http.StatusCreated, gin.H{"message": "User registered, but the verification email could not be sent; request a new one from /auth/resend-verification"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "User registered successfully, check your email to verify your address"})
 }
 
 type loginRequest struct {
@@ -67,27 +87,49 @@ func (h *UserHandler) Login(c *gin.Context) {
 		return
 	}
 
-	accessToken, refreshToken, err := h.userService.Login(c.Request.Context(), req.Email, req.Password)
+	result, err := h.userService.Login(c.Request.Context(), req.Email, req.Password, clientInfo(c))
 	if err != nil {
+		var locked *domain.LockedError
+		if errors.As(err, &locked) {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(locked.RetryAfter.Seconds()))))
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many failed login attempts, try again later"})
+			return
+		}
 		if err == domain.ErrInvalidCreds {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
 			return
 		}
+		if err == domain.ErrUnverified {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Email address is not verified"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"access_token":  accessToken,
-		"refresh_token": refreshToken,
-	})
+	loginResponse(c, result)
+}
+
+// loginResponse ตอบผลการเข้าสู่ระบบ: token pair หรือ challenge ของ 2FA
+func loginResponse(c *gin.Context, result *domain.LoginResult) {
+	// ผู้ใช้ที่เปิด 2FA ต้องส่งรหัสพร้อม challenge_token ไปที่ /auth/2fa/verify เพื่อรับ token
+	if result.Tokens == nil {
+		c.JSON(http.StatusOK, gin.H{
+			"two_factor_required": true,
+			"challenge_token":     result.ChallengeToken,
+			"expires_at":          result.ChallengeExpiresAt,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result.Tokens)
 }
 
 type refreshTokenRequest struct {
 	RefreshToken string `json:"refresh_token" binding:"required"`
 }
 
-// RefreshToken ขอ Access Token ใหม่
+// RefreshToken หมุน Refresh Token และขอ Access Token ใหม่ (refresh token เดิมใช้ได้ครั้งเดียว)
 func (h *UserHandler) RefreshToken(c *gin.Context) {
 	var req refreshTokenRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -95,13 +137,175 @@ func (h *UserHandler) RefreshToken(c *gin.Context) {
 		return
 	}
 
-	accessToken, err := h.userService.RefreshToken(c.Request.Context(), req.RefreshToken)
+	tokens, err := h.sessionService.Refresh(c.Request.Context(), req.RefreshToken, clientInfo(c))
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token"})
+		if err == domain.ErrUnauthorized {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// Logout ออกจากระบบ โดยยกเลิก session ของ refresh token
+func (h *UserHandler) Logout(c *gin.Context) {
+	var req refreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.sessionService.Logout(c.Request.Context(), req.RefreshToken); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// LogoutAll ออกจากระบบทุกอุปกรณ์
+func (h *UserHandler) LogoutAll(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.sessionService.LogoutAll(c.Request.Context(), userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListSessions รายการอุปกรณ์ที่เข้าสู่ระบบอยู่ของผู้ใช้
+func (h *UserHandler) ListSessions(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	sessions, err := h.sessionService.List(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	current := c.GetString("session_id")
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == current
+	}
+	c.JSON(http.StatusOK, gin.H{"data": sessions})
+}
+
+// RevokeSession ยกเลิก session หนึ่งของผู้ใช้ (ออกจากระบบอุปกรณ์นั้น)
+func (h *UserHandler) RevokeSession(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.sessionService.Revoke(c.Request.Context(), userID, c.Param("id")); err != nil {
+		if err == domain.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"access_token": accessToken})
+	c.Status(http.StatusNoContent)
+}
+
+type emailRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ForgotPassword ส่งลิงก์รีเซ็ตรหัสผ่านทางอีเมล (ตอบเหมือนกันไม่ว่าอีเมลจะมีในระบบหรือไม่)
+func (h *UserHandler) ForgotPassword(c *gin.Context) {
+	var req emailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.accountService.RequestPasswordReset(c.Request.Context(), req.Email)
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "If the email is registered, a password reset link has been sent"})
+}
+
+type resetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=6"`
+}
+
+// ResetPassword ตั้งรหัสผ่านใหม่ด้วย token จากอีเมล (ทุก session เดิมจะถูกยกเลิก)
+func (h *UserHandler) ResetPassword(c *gin.Context) {
+	var req resetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.accountService.ResetPassword(c.Request.Context(), req.Token, req.Password); err != nil {
+		if err == domain.ErrUnauthorized {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired reset token"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password has been reset, please log in again"})
+}
+
+type verifyEmailRequest struct {
+	Token string `json:"token" form:"token" binding:"required"`
+}
+
+// VerifyEmail ยืนยันอีเมลด้วย token (รับได้ทั้ง GET ?token= จากลิงก์ในอีเมล และ POST JSON)
+func (h *UserHandler) VerifyEmail(c *gin.Context) {
+	var req verifyEmailRequest
+	var err error
+	if c.Request.Method == http.MethodGet {
+		err = c.ShouldBindQuery(&req)
+	} else {
+		err = c.ShouldBindJSON(&req)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.accountService.VerifyEmail(c.Request.Context(), req.Token); err != nil {
+		if err == domain.ErrUnauthorized {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired verification token"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Email verified successfully"})
+}
+
+// ResendVerification ส่งลิงก์ยืนยันอีเมลอีกครั้ง (ตอบเหมือนกันไม่ว่าอีเมลจะมีในระบบหรือไม่)
+func (h *UserHandler) ResendVerification(c *gin.Context) {
+	var req emailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.accountService.ResendVerification(c.Request.Context(), req.Email)
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "If the email is registered and not yet verified, a verification link has been sent"})
 }
 
 func (h *UserHandler) GetMe(c *gin.Context) {
@@ -189,3 +393,185 @@ func (h *UserHandler) UpdateMe(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"data": user})
 }
+
+type changePasswordRequest struct {
+	CurrentPassword string `json:"current_password"` // ว่างได้สำหรับบัญชีที่ยังไม่มีรหัสผ่าน
+	NewPassword     string `json:"new_password" binding:"required,min=6"`
+}
+
+// ChangePassword เปลี่ยนรหัสผ่าน (session อื่นทั้งหมดจะถูกยกเลิก)
+func (h *UserHandler) ChangePassword(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req changePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := h.userService.ChangePassword(c.Request.Context(), userID, c.GetString("session_id"), req.CurrentPassword, req.NewPassword)
+	if err != nil {
+		switch err {
+		case domain.ErrInvalidCreds:
+			c.JSON(http.StatusForbidden, gin.H{"error": "Current password is incorrect"})
+		case domain.ErrReauthRequired:
+			c.JSON(http.StatusForbidden, gin.H{"error": "Log in again to confirm this action"})
+		case domain.ErrNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password changed, other sessions have been signed out"})
+}
+
+// Export ดาวน์โหลดข้อมูลทั้งหมดของผู้ใช้ (?format=json หรือ zip)
+func (h *UserHandler) Export(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "zip" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be json or zip"})
+		return
+	}
+
+	export, err := h.userService.Export(c.Request.Context(), userID)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	filename := fmt.Sprintf("account-export-%d-%s", userID, export.ExportedAt.UTC().Format("20060102"))
+	c.Header("Cache-Control", "no-store")
+	if format == "json" {
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.json"`, filename))
+		c.JSON(http.StatusOK, export)
+		return
+	}
+
+	archive, err := exportZip(export)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.zip"`, filename))
+	c.Data(http.StatusOK, "application/zip", archive)
+}
+
+// exportZip สร้าง ZIP ที่มีไฟล์ JSON แยกตามประเภทข้อมูล
+func exportZip(export *domain.UserExport) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	files := []struct {
+		name string
+		data any
+	}{
+		{"profile.json", export.Profile},
+		{"tracks.json", export.Tracks},
+		{"playlists.json", export.Playlists},
+	}
+	for _, f := range files {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: f.name, Method: zip.Deflate, Modified: export.ExportedAt})
+		if err != nil {
+			return nil, err
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(f.data); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+type deleteAccountRequest struct {
+	Password string `json:"password"` // ว่างได้สำหรับบัญชีที่ไม่มีรหัสผ่าน (ยืนยันด้วยการเข้าสู่ระบบล่าสุด)
+}
+
+// DeleteMe ลบบัญชีของตัวเอง (ต้องยืนยันด้วยรหัสผ่าน หรือการเข้าสู่ระบบล่าสุดถ้าไม่มีรหัสผ่าน)
+func (h *UserHandler) DeleteMe(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req deleteAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.userService.DeleteAccount(c.Request.Context(), userID, c.GetString("session_id"), req.Password); err != nil {
+		switch err {
+		case domain.ErrInvalidCreds:
+			c.JSON(http.StatusForbidden, gin.H{"error": "Password is incorrect"})
+		case domain.ErrReauthRequired:
+			c.JSON(http.StatusForbidden, gin.H{"error": "Log in again to confirm this action"})
+		case domain.ErrNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+type assignRoleRequest struct {
+	Role domain.Role `json:"role" binding:"required"`
+}
+
+// AssignRole กำหนดบทบาทให้ผู้ใช้ (admin เท่านั้น)
+func (h *UserHandler) AssignRole(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	id, ok := paramID(c, "id")
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req assignRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := h.userService.AssignRole(c.Request.Context(), actor, id, req.Role)
+	if err != nil {
+		switch err {
+		case domain.ErrNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		case domain.ErrForbidden:
+			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+		case domain.ErrInvalidInput:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "role must be admin, editor or listener (admins cannot demote themselves)"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": user})
+}
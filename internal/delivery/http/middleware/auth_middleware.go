@@ -4,16 +4,21 @@ import (
 	"net/http" // นำเข้า package net/http
 	"strings"  // นำเข้า strings สำหรับจัดการข้อความ
 
-	"go-music-api/pkg/utils" // นำเข้า utils สำหรับตรวจสอบ JWT
+	"go-music-api/internal/domain" // นำเข้า domain สำหรับตรวจสอบ API key
+	"go-music-api/pkg/utils"       // นำเข้า utils สำหรับตรวจสอบ JWT
 
 	"github.com/gin-gonic/gin" // นำเข้า gin
 )
 
-// AuthMiddleware ตรวจสอบ JWT token ใน request header (สำหรับ Gin)
-func AuthMiddleware() gin.HandlerFunc {
+// AuthMiddleware ตรวจสอบ JWT token ใน header Authorization หรือ API key ใน header X-API-Key (สำหรับ Gin)
+func AuthMiddleware(apiKeys domain.APIKeyService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// อ่าน header Authorization
 		authHeader := c.GetHeader("Authorization")
+		if authHeader == "" && c.GetHeader("X-API-Key") != "" {
+			authenticateAPIKey(c, apiKeys)
+			return
+		}
 		if authHeader == "" {
 			// ถ้าไม่มี header ให้ส่ง error 401
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is required"})
@@ -39,25 +44,67 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		// บันทึก user_id และ email ลงใน context เพื่อให้ handler ถัดไปใช้งานได้
+		// บันทึก user_id, email, role, session_id และ mfa ลงใน context เพื่อให้ handler ถัดไปใช้งานได้
 		c.Set("user_id", claims.UserID)
 		c.Set("email", claims.Email)
+		c.Set("role", claims.Role)
+		c.Set("session_id", claims.SessionID)
+		c.Set("mfa", claims.MFA)
 		// ไปทำงานต่อที่ handler ถัดไป
 		c.Next()
 	}
 }
 
+// authenticateAPIKey ตรวจ API key และใส่ข้อมูลของเจ้าของ key ใน context แบบเดียวกับ JWT
+// พร้อม api_key_id และ scopes ซึ่งจำกัดสิทธิ์ของ request ให้อยู่ใน scope ของ key
+func authenticateAPIKey(c *gin.Context, apiKeys domain.APIKeyService) {
+	key, user, err := apiKeys.Authenticate(c.Request.Context(), c.GetHeader("X-API-Key"))
+	if err != nil {
+		if err == domain.ErrUnauthorized {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired API key"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		c.Abort()
+		return
+	}
+
+	c.Set("user_id", user.ID)
+	c.Set("email", user.Email)
+	c.Set("role", string(user.Role))
+	c.Set("api_key_id", key.ID)
+	c.Set("scopes", key.Scopes)
+	c.Next()
+}
+
+// RejectAPIKeys ใช้กับ route ที่ต้องเข้าสู่ระบบด้วยตัวเอง เช่น การจัดการบัญชีและ API key (ต้องใช้หลัง AuthMiddleware)
+func RejectAPIKeys() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := c.Get("api_key_id"); ok {
+			c.JSON(http.StatusForbidden, gin.H{"error": "This endpoint requires a user login; API keys are not accepted"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
 // CORSMiddleware จัดการ Cross-Origin Resource Sharing (CORS)
 func CORSMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// กำหนด headers เพื่ออนุญาตการเข้าถึงข้ามโดเมน
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*") // อนุญาตทุก origin (ควรระบุเจาะจงใน production)
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, "+
+			"Tus-Resumable, Upload-Length, Upload-Metadata, Upload-Offset, Upload-Checksum, Upload-Defer-Length, X-API-Key")
+		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, HEAD, PUT, PATCH, DELETE")
+		// ให้ client อ่าน ETag ของส่วนที่อัปโหลด header ของ tus และ header ของ rate limit ได้
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "ETag, Content-Range, Content-Length, Location, "+
+			"Tus-Resumable, Tus-Version, Tus-Extension, Tus-Max-Size, Tus-Checksum-Algorithm, Upload-Offset, Upload-Length, Upload-Metadata, Upload-Expires, "+
+			"RateLimit-Policy, RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset, Retry-After")
 
-		// จัดการ Preflight request (OPTIONS)
-		if c.Request.Method == "OPTIONS" {
+		// จัดการ Preflight request (OPTIONS) ส่วน OPTIONS ที่ไม่ใช่ preflight (เช่น tus discovery) ส่งต่อให้ handler
+		if c.Request.Method == "OPTIONS" && c.GetHeader("Access-Control-Request-Method") != "" {
 			// ตอบกลับ status 204 No Content ทันที
 			c.AbortWithStatus(204)
 			return
@@ -0,0 +1,38 @@
+package middleware // ประกาศ package middleware
+
+import (
+	"net/http" // นำเข้า package net/http
+
+	"go-music-api/internal/domain" // นำเข้า domain สำหรับบทบาทและสิทธิ์
+
+	"github.com/gin-gonic/gin" // นำเข้า gin
+)
+
+// RequirePermission อนุญาตเฉพาะผู้ใช้ที่บทบาทมีสิทธิ์ perm และเมื่อเรียกด้วย API key ต้องอยู่ใน scope ของ key ด้วย (ต้องใช้หลัง AuthMiddleware)
+// การตรวจความเป็นเจ้าของข้อมูลทำใน service
+func RequirePermission(perm domain.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		actor := domain.Actor{Role: domain.Role(c.GetString("role"))}
+		if scopes, ok := c.Get("scopes"); ok {
+			actor.Scopes, _ = scopes.([]domain.Permission)
+		}
+		if !actor.Can(perm) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireTwoFactor อนุญาตเฉพาะ access token ที่ได้มาจากการเข้าสู่ระบบด้วย 2FA (ต้องใช้หลัง AuthMiddleware)
+func RequireTwoFactor() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !c.GetBool("mfa") {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Two-factor authentication required: enable 2FA and log in again"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,74 @@
+package middleware // ประกาศ package middleware
+
+import (
+	"fmt"      // นำเข้า fmt
+	"log"      // นำเข้า log
+	"math"     // นำเข้า math สำหรับปัดวินาทีขึ้น
+	"net/http" // นำเข้า net/http
+	"strconv"  // นำเข้า strconv
+	"time"     // นำเข้า time
+
+	"go-music-api/internal/domain" // นำเข้า domain สำหรับ RateLimitStore
+
+	"github.com/gin-gonic/gin" // นำเข้า gin
+)
+
+// RateLimitKey เลือก key ของ bucket จาก request
+type RateLimitKey func(c *gin.Context) string
+
+// ByIP แยก bucket ตาม IP ของ client
+func ByIP(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// ByUser แยก bucket ตามผู้ใช้ที่เข้าสู่ระบบ (ต้องใช้หลัง AuthMiddleware) ถ้าไม่มีจะใช้ IP
+func ByUser(c *gin.Context) string {
+	if userID, ok := c.Get("user_id"); ok {
+		return fmt.Sprintf("user:%v", userID)
+	}
+	return ByIP(c)
+}
+
+// ByAPIKey แยก bucket ตาม API key ที่ AuthMiddleware ยืนยันแล้ว ถ้าไม่ได้ใช้ key จะใช้ผู้ใช้หรือ IP
+// ไม่ใช้ header X-API-Key โดยตรง เพราะ header ที่ไม่ถูกตรวจ (เช่น ส่งมาพร้อม JWT) จะได้ bucket ใหม่ทุกครั้ง
+func ByAPIKey(c *gin.Context) string {
+	if keyID, ok := c.Get("api_key_id"); ok {
+		return fmt.Sprintf("key:%v", keyID)
+	}
+	return ByUser(c)
+}
+
+// seconds ปัดระยะเวลาขึ้นเป็นวินาที
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
+
+// RateLimit จำกัดจำนวน request ต่อ key ด้วย token bucket และใส่ header RateLimit-* ใน response
+// name แยก bucket ของแต่ละกลุ่ม route ออกจากกัน ถ้า store ใช้งานไม่ได้จะปล่อย request ผ่าน
+func RateLimit(store domain.RateLimitStore, name string, limit domain.RateLimit, key RateLimitKey) gin.HandlerFunc {
+	if !limit.Enabled() {
+		return func(c *gin.Context) { c.Next() }
+	}
+	policy := fmt.Sprintf("%d;w=%s", limit.Requests, seconds(limit.Period))
+
+	return func(c *gin.Context) {
+		res, err := store.Take(c.Request.Context(), name+":"+key(c), limit)
+		if err != nil {
+			log.Printf("rate limit %s: %v", name, err)
+			c.Next()
+			return
+		}
+
+		c.Header("RateLimit-Policy", policy)
+		c.Header("RateLimit-Limit", strconv.Itoa(limit.Requests))
+		c.Header("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		c.Header("RateLimit-Reset", seconds(res.Reset))
+		if !res.Allowed {
+			c.Header("Retry-After", seconds(res.RetryAfter))
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many requests, try again later"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
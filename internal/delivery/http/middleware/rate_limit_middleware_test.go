@@ -0,0 +1,149 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-music-api/internal/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fakeRateStore RateLimitStore ที่คืนผลที่กำหนดไว้และจำ key ที่ถูกขอ
+type fakeRateStore struct {
+	res  domain.RateLimitResult
+	err  error
+	keys []string
+}
+
+func (s *fakeRateStore) Take(ctx context.Context, key string, limit domain.RateLimit) (domain.RateLimitResult, error) {
+	s.keys = append(s.keys, key)
+	return s.res, s.err
+}
+
+func TestRateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	limit := domain.RateLimit{Requests: 20, Period: time.Minute}
+
+	tests := []struct {
+		name        string
+		limit       domain.RateLimit
+		res         domain.RateLimitResult
+		err         error
+		wantStatus  int
+		wantHeaders map[string]string // "" = ต้องไม่มี header นี้
+		wantTake    bool
+	}{
+		{
+			name:       "allowed request carries RateLimit headers",
+			limit:      limit,
+			res:        domain.RateLimitResult{Allowed: true, Remaining: 7, Reset: 1500 * time.Millisecond},
+			wantStatus: http.StatusOK,
+			wantHeaders: map[string]string{
+				"RateLimit-Policy":    "20;w=60",
+				"RateLimit-Limit":     "20",
+				"RateLimit-Remaining": "7",
+				"RateLimit-Reset":     "2",
+				"Retry-After":         "",
+			},
+			wantTake: true,
+		},
+		{
+			name:       "limited request is rejected with Retry-After rounded up",
+			limit:      limit,
+			res:        domain.RateLimitResult{RetryAfter: 2100 * time.Millisecond, Reset: time.Minute},
+			wantStatus: http.StatusTooManyRequests,
+			wantHeaders: map[string]string{
+				"RateLimit-Limit":     "20",
+				"RateLimit-Remaining": "0",
+				"RateLimit-Reset":     "60",
+				"Retry-After":         "3",
+			},
+			wantTake: true,
+		},
+		{
+			name:        "store errors let the request through without headers",
+			limit:       limit,
+			err:         errors.New("store down"),
+			wantStatus:  http.StatusOK,
+			wantHeaders: map[string]string{"RateLimit-Limit": "", "Retry-After": ""},
+			wantTake:    true,
+		},
+		{
+			name:        "disabled limit skips the store",
+			wantStatus:  http.StatusOK,
+			wantHeaders: map[string]string{"RateLimit-Limit": ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &fakeRateStore{res: tt.res, err: tt.err}
+			router := gin.New()
+			router.GET("/", RateLimit(store, "api", tt.limit, ByIP), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			for name, want := range tt.wantHeaders {
+				if got := w.Header().Get(name); got != want {
+					t.Fatalf("%s = %q, want %q", name, got, want)
+				}
+			}
+			if took := len(store.keys) > 0; took != tt.wantTake {
+				t.Fatalf("Take called = %v, want %v", took, tt.wantTake)
+			}
+		})
+	}
+}
+
+func TestRateLimitKeys(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name    string
+		key     RateLimitKey
+		context map[string]any // ค่าที่ AuthMiddleware ใส่ไว้
+		want    string
+	}{
+		{name: "ByIP", key: ByIP, context: map[string]any{"user_id": uint(7)}, want: "api:ip:192.0.2.1"},
+		{name: "ByUser with a user", key: ByUser, context: map[string]any{"user_id": uint(7)}, want: "api:user:7"},
+		{name: "ByUser without a user", key: ByUser, want: "api:ip:192.0.2.1"},
+		{
+			name:    "ByAPIKey with a verified key",
+			key:     ByAPIKey,
+			context: map[string]any{"user_id": uint(7), "api_key_id": uint(3)},
+			want:    "api:key:3",
+		},
+		{name: "ByAPIKey with a user token", key: ByAPIKey, context: map[string]any{"user_id": uint(7)}, want: "api:user:7"},
+		{name: "ByAPIKey ignores an unverified X-API-Key header", key: ByAPIKey, want: "api:ip:192.0.2.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &fakeRateStore{res: domain.RateLimitResult{Allowed: true}}
+			router := gin.New()
+			router.GET("/", func(c *gin.Context) {
+				for k, v := range tt.context {
+					c.Set(k, v)
+				}
+			}, RateLimit(store, "api", domain.RateLimit{Requests: 1, Period: time.Second}, tt.key))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = "192.0.2.1:1234"
+			req.Header.Set("X-API-Key", "unverified")
+			router.ServeHTTP(httptest.NewRecorder(), req)
+
+			if len(store.keys) != 1 || store.keys[0] != tt.want {
+				t.Fatalf("bucket keys = %q, want [%q]", store.keys, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package middleware // ประกาศ package middleware
+
+import (
+	"net/http" // นำเข้า package net/http
+	"net/url"  // นำเข้า url
+
+	"github.com/gin-gonic/gin" // นำเข้า gin
+)
+
+// SignedURLVerifier ตรวจลายเซ็นของ URL ไฟล์ (implement โดย LocalStorage)
+type SignedURLVerifier interface {
+	VerifySignedURL(path string, query url.Values) error
+}
+
+// SignedURLMiddleware อนุญาตให้เข้าถึงไฟล์เฉพาะ URL ที่ลงลายเซ็นและยังไม่หมดอายุ
+func SignedURLMiddleware(verifier SignedURLVerifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := verifier.VerifySignedURL(c.Request.URL.Path, c.Request.URL.Query()); err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Invalid or expired signed URL"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
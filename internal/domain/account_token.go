@@ -0,0 +1,42 @@
+package domain // ประกาศ package domain
+
+import (
+	"context" // นำเข้า context
+	"time"    // นำเข้า time
+)
+
+// AccountTokenPurpose จุดประสงค์ของ token ที่ส่งทางอีเมล
+type AccountTokenPurpose string
+
+const (
+	TokenPasswordReset     AccountTokenPurpose = "password_reset"     // รีเซ็ตรหัสผ่าน
+	TokenEmailVerification AccountTokenPurpose = "email_verification" // ยืนยันอีเมล
+)
+
+// AccountToken token ใช้ครั้งเดียวที่ส่งทางอีเมล (เก็บเฉพาะ hash)
+type AccountToken struct {
+	TokenHash string              `gorm:"primaryKey;type:varchar(64)"` // SHA-256 (hex) ของ token
+	UserID    uint                `gorm:"not null;index"`
+	Purpose   AccountTokenPurpose `gorm:"type:varchar(32);not null"`
+	ExpiresAt time.Time           `gorm:"not null;index"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// AccountTokenRepository interface สำหรับจัดการ token ที่ส่งทางอีเมลในฐานข้อมูล
+type AccountTokenRepository interface {
+	Create(ctx context.Context, token *AccountToken) error                                                            // บันทึก token ใหม่
+	Consume(ctx context.Context, tokenHash string, purpose AccountTokenPurpose, now time.Time) (*AccountToken, error) // ใช้ token (คืน ErrNotFound ถ้าไม่มี ใช้แล้ว หรือหมดอายุ)
+	DeleteForUser(ctx context.Context, userID uint, purpose AccountTokenPurpose) error                                // ลบ token ที่ยังไม่ได้ใช้ของผู้ใช้
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)                                               // ลบ token ที่หมดอายุก่อน before
+}
+
+// AccountService interface กำหนดเมธอดสำหรับการกู้คืนบัญชีและยืนยันอีเมล
+type AccountService interface {
+	RequestPasswordReset(ctx context.Context, email string)             // ส่งลิงก์รีเซ็ตรหัสผ่านเบื้องหลัง (อีเมลที่ไม่มีในระบบจะไม่ทำอะไร error ถูกบันทึกใน log)
+	ResetPassword(ctx context.Context, token, newPassword string) error // ตั้งรหัสผ่านใหม่และออกจากระบบทุกอุปกรณ์
+	SendVerification(ctx context.Context, user *User) error             // ส่งลิงก์ยืนยันอีเมล
+	ResendVerification(ctx context.Context, email string)               // ส่งลิงก์ยืนยันอีเมลอีกครั้งเบื้องหลัง (อีเมลที่ไม่มีหรือยืนยันแล้วจะไม่ทำอะไร)
+	VerifyEmail(ctx context.Context, token string) error                // ยืนยันอีเมล
+	CleanupExpired(ctx context.Context) (int, error)                    // ลบ token ที่หมดอายุ
+}
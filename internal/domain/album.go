@@ -0,0 +1,43 @@
+package domain // ประกาศ package domain
+
+import (
+	"context" // นำเข้า context
+	"time"    // นำเข้า time
+)
+
+// Album struct เก็บข้อมูลอัลบั้ม
+type Album struct {
+	BaseModel
+	Title       string     `json:"title" gorm:"not null;index"`                          // ชื่ออัลบั้ม
+	ArtistID    *uint      `json:"artist_id" gorm:"index"`                               // ศิลปินเจ้าของอัลบั้ม
+	Artist      *Artist    `json:"artist,omitempty" gorm:"constraint:OnDelete:SET NULL"` // ข้อมูลศิลปินเจ้าของอัลบั้ม
+	ReleaseDate *time.Time `json:"release_date"`                                         // วันที่วางจำหน่าย
+	ImageURL    string     `json:"image_url"`                                            // URL รูปหน้าปกอัลบั้ม
+	Tracks      []Music    `json:"tracks,omitempty" gorm:"constraint:OnDelete:SET NULL"` // เพลงในอัลบั้ม (เรียงตามแผ่นและลำดับเพลง)
+}
+
+// AlbumQuery เงื่อนไขการดึงรายการอัลบั้ม
+type AlbumQuery struct {
+	Title    string // กรองตามชื่ออัลบั้มแบบ substring
+	ArtistID uint   // กรองตามศิลปินเจ้าของอัลบั้ม
+	Page     int    // หน้าที่ต้องการ (เริ่มที่ 1)
+	PageSize int    // จำนวนรายการต่อหน้า
+}
+
+// AlbumRepository interface กำหนดเมธอดสำหรับจัดการข้อมูล Album ในฐานข้อมูล
+type AlbumRepository interface {
+	Create(ctx context.Context, album *Album) error                       // สร้างอัลบั้มใหม่
+	GetByID(ctx context.Context, id uint) (*Album, error)                 // ดึงข้อมูลอัลบั้มพร้อมรายการเพลง
+	GetAll(ctx context.Context, query AlbumQuery) ([]Album, int64, error) // ดึงรายการอัลบั้มแบบแบ่งหน้า
+	Update(ctx context.Context, album *Album) error                       // อัปเดตข้อมูลอัลบั้ม
+	Delete(ctx context.Context, id uint) error                            // ลบอัลบั้ม
+}
+
+// AlbumService interface กำหนดเมธอดสำหรับ business logic ของ Album
+type AlbumService interface {
+	Create(ctx context.Context, album *Album) error                       // สร้างอัลบั้มใหม่
+	GetByID(ctx context.Context, id uint) (*Album, error)                 // ดึงข้อมูลอัลบั้มพร้อมรายการเพลง
+	GetAll(ctx context.Context, query AlbumQuery) ([]Album, int64, error) // ดึงรายการอัลบั้มแบบแบ่งหน้า
+	Update(ctx context.Context, album *Album) error                       // อัปเดตข้อมูลอัลบั้ม
+	Delete(ctx context.Context, id uint) error                            // ลบอัลบั้ม
+}
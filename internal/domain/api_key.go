@@ -0,0 +1,49 @@
+package domain // ประกาศ package domain
+
+import (
+	"context" // นำเข้า context
+	"time"    // นำเข้า time
+)
+
+// APIKeyPrefix ขึ้นต้นของ API key ทุกตัว ช่วยให้เครื่องมือสแกน secret จำได้
+const APIKeyPrefix = "gma_"
+
+// APIKeyScopes scope ที่กำหนดให้ API key ได้ (ต้องเป็นสิทธิ์ที่บทบาทของเจ้าของมีด้วย)
+var APIKeyScopes = []Permission{PermMusicRead, PermMusicWrite, PermMusicManage, PermCatalogWrite, PermPlaylistWrite}
+
+// APIKey key สำหรับสคริปต์และบริการอื่นเรียก API แทนผู้ใช้ (เก็บเฉพาะ hash)
+type APIKey struct {
+	ID         uint         `json:"id" gorm:"primaryKey"`
+	UserID     uint         `json:"-" gorm:"not null;index"`
+	Name       string       `json:"name" gorm:"not null"`
+	Prefix     string       `json:"prefix" gorm:"type:varchar(16);not null"`          // ส่วนต้นของ key สำหรับแยกว่าเป็น key ไหน
+	KeyHash    string       `json:"-" gorm:"type:varchar(64);uniqueIndex;not null"`   // SHA-256 (hex) ของ key
+	Scopes     []Permission `json:"scopes" gorm:"serializer:json;type:text;not null"` // สิทธิ์ที่ key นี้ใช้ได้
+	ExpiresAt  *time.Time   `json:"expires_at"`                                       // nil = ไม่หมดอายุ
+	LastUsedAt *time.Time   `json:"last_used_at"`
+	CreatedAt  time.Time    `json:"created_at"`
+}
+
+// APIKeyRequest ข้อมูลสำหรับสร้าง API key
+type APIKeyRequest struct {
+	Name      string
+	Scopes    []Permission
+	ExpiresAt *time.Time
+}
+
+// APIKeyRepository interface สำหรับจัดการ API key ในฐานข้อมูล
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *APIKey) error                                // บันทึก key ใหม่
+	GetByHash(ctx context.Context, keyHash string) (*APIKey, error)               // ดึง key ตาม hash
+	ListByUser(ctx context.Context, userID uint) ([]APIKey, error)                // key ทั้งหมดของผู้ใช้
+	Delete(ctx context.Context, userID, id uint) error                            // ลบ key ของผู้ใช้ (ErrNotFound ถ้าไม่มี)
+	Touch(ctx context.Context, id uint, now time.Time, every time.Duration) error // บันทึกเวลาใช้งานล่าสุด (ไม่เกินครั้งละ every)
+}
+
+// APIKeyService interface กำหนดเมธอดสำหรับจัดการและตรวจสอบ API key
+type APIKeyService interface {
+	Create(ctx context.Context, actor Actor, req APIKeyRequest) (*APIKey, string, error) // สร้าง key คืน key จริงซึ่งแสดงได้ครั้งเดียว
+	List(ctx context.Context, userID uint) ([]APIKey, error)                             // รายการ key ของผู้ใช้
+	Revoke(ctx context.Context, userID, id uint) error                                   // ยกเลิก key
+	Authenticate(ctx context.Context, key string) (*APIKey, *User, error)                // ตรวจ key คืน key และเจ้าของ (ErrUnauthorized ถ้าไม่ถูกต้องหรือหมดอายุ)
+}
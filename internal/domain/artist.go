@@ -0,0 +1,106 @@
+package domain // ประกาศ package domain
+
+import (
+	"context" // นำเข้า context
+	"regexp"  // นำเข้า regexp สำหรับแยกศิลปินรับเชิญ
+	"strings" // นำเข้า strings
+)
+
+// ArtistRole บทบาทของศิลปินในเพลง
+type ArtistRole string
+
+// บทบาทที่รองรับ
+const (
+	ArtistRolePrimary  ArtistRole = "primary"  // ศิลปินหลัก
+	ArtistRoleFeatured ArtistRole = "featured" // ศิลปินรับเชิญ
+	ArtistRoleComposer ArtistRole = "composer" // ผู้แต่ง
+)
+
+// Valid ตรวจสอบว่าเป็นบทบาทที่รองรับหรือไม่
+func (r ArtistRole) Valid() bool {
+	switch r {
+	case ArtistRolePrimary, ArtistRoleFeatured, ArtistRoleComposer:
+		return true
+	}
+	return false
+}
+
+// Artist struct เก็บข้อมูลศิลปิน
+type Artist struct {
+	BaseModel
+	Name           string `json:"name" gorm:"not null"`          // ชื่อศิลปินตามที่แสดง
+	NormalizedName string `json:"-" gorm:"not null;uniqueIndex"` // ชื่อที่ normalize แล้ว ใช้กันชื่อซ้ำ (เช่น BNK48 กับ bnk48)
+	Bio            string `json:"bio"`                           // ประวัติศิลปิน
+	ImageURL       string `json:"image_url"`                     // URL รูปศิลปิน
+}
+
+// MusicArtist ความสัมพันธ์ระหว่างเพลงกับศิลปินพร้อมบทบาท
+type MusicArtist struct {
+	MusicID  uint       `json:"-" gorm:"primaryKey"`                                  // ID ของเพลง
+	ArtistID uint       `json:"artist_id" gorm:"primaryKey"`                          // ID ของศิลปิน
+	Role     ArtistRole `json:"role" gorm:"primaryKey;type:varchar(20)"`              // บทบาทของศิลปินในเพลงนี้
+	Position int        `json:"position"`                                             // ลำดับการแสดงผล
+	Artist   *Artist    `json:"artist,omitempty" gorm:"constraint:OnDelete:RESTRICT"` // ข้อมูลศิลปิน
+}
+
+// ArtistCredit ชื่อศิลปินพร้อมบทบาทที่แยกได้จากข้อความ
+type ArtistCredit struct {
+	Name string
+	Role ArtistRole
+}
+
+// NormalizeArtistName ทำให้ชื่อศิลปินอยู่ในรูปแบบมาตรฐาน (ตัดช่องว่างซ้ำ และเป็นตัวพิมพ์เล็ก)
+func NormalizeArtistName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// featPattern ตัวคั่นศิลปินรับเชิญ เช่น "feat.", "ft.", "featuring"
+var featPattern = regexp.MustCompile(`(?i)\s*[\(\[]?\s*\b(?:feat\.?|ft\.?|featuring)\s+`)
+
+// featSeparator ตัวคั่นระหว่างศิลปินรับเชิญหลายคน
+var featSeparator = regexp.MustCompile(`\s*(?:,|&|\band\b)\s*`)
+
+// ParseArtistCredits แยกข้อความชื่อศิลปินเป็นศิลปินหลักและศิลปินรับเชิญ
+// เช่น "Ink Waruntorn feat. Violette Wautier" -> primary: Ink Waruntorn, featured: Violette Wautier
+func ParseArtistCredits(s string) []ArtistCredit {
+	parts := featPattern.Split(s, 2)
+	var credits []ArtistCredit
+	if name := strings.TrimSpace(parts[0]); name != "" {
+		credits = append(credits, ArtistCredit{Name: name, Role: ArtistRolePrimary})
+	}
+	if len(parts) == 2 {
+		featured := strings.TrimRight(strings.TrimSpace(parts[1]), ")]")
+		for _, name := range featSeparator.Split(featured, -1) {
+			if name = strings.TrimSpace(name); name != "" {
+				credits = append(credits, ArtistCredit{Name: name, Role: ArtistRoleFeatured})
+			}
+		}
+	}
+	return credits
+}
+
+// ArtistQuery เงื่อนไขการดึงรายการศิลปิน
+type ArtistQuery struct {
+	Name     string // กรองตามชื่อแบบ substring
+	Page     int    // หน้าที่ต้องการ (เริ่มที่ 1)
+	PageSize int    // จำนวนรายการต่อหน้า
+}
+
+// ArtistRepository interface กำหนดเมธอดสำหรับจัดการข้อมูล Artist ในฐานข้อมูล
+type ArtistRepository interface {
+	Create(ctx context.Context, artist *Artist) error                       // สร้างศิลปินใหม่
+	FirstOrCreate(ctx context.Context, artist *Artist) error                // ค้นหาด้วยชื่อ ถ้าไม่มีให้สร้างใหม่
+	GetByID(ctx context.Context, id uint) (*Artist, error)                  // ดึงข้อมูลศิลปินตาม ID
+	GetAll(ctx context.Context, query ArtistQuery) ([]Artist, int64, error) // ดึงรายการศิลปินแบบแบ่งหน้า
+	Update(ctx context.Context, artist *Artist) error                       // อัปเดตข้อมูลศิลปิน
+	Delete(ctx context.Context, id uint) error                              // ลบศิลปิน
+}
+
+// ArtistService interface กำหนดเมธอดสำหรับ business logic ของ Artist
+type ArtistService interface {
+	Create(ctx context.Context, artist *Artist) error                       // สร้างศิลปินใหม่
+	GetByID(ctx context.Context, id uint) (*Artist, error)                  // ดึงข้อมูลศิลปินตาม ID
+	GetAll(ctx context.Context, query ArtistQuery) ([]Artist, int64, error) // ดึงรายการศิลปินแบบแบ่งหน้า
+	Update(ctx context.Context, artist *Artist) error                       // อัปเดตข้อมูลศิลปิน
+	Delete(ctx context.Context, id uint) error                              // ลบศิลปิน
+}
@@ -4,9 +4,17 @@ import "errors" // นำเข้า errors standard library
 
 // กำหนดตัวแปร error มาตรฐานที่ใช้ในโปรเจค
 var (
-	ErrNotFound     = errors.New("record not found")      // ไม่พบข้อมูล
-	ErrConflict     = errors.New("record already exists") // ข้อมูลซ้ำ
-	ErrInternal     = errors.New("internal server error") // ข้อผิดพลาดภายในเซิร์ฟเวอร์
-	ErrInvalidCreds = errors.New("invalid credentials")   // รหัสผ่านหรือข้อมูลยืนยันตัวตนไม่ถูกต้อง
-	ErrUnauthorized = errors.New("unauthorized")          // ไม่มีสิทธิ์เข้าถึง
+	ErrNotFound       = errors.New("record not found")                   // ไม่พบข้อมูล
+	ErrConflict       = errors.New("record already exists")              // ข้อมูลซ้ำ
+	ErrInternal       = errors.New("internal server error")              // ข้อผิดพลาดภายในเซิร์ฟเวอร์
+	ErrInvalidCreds   = errors.New("invalid credentials")                // รหัสผ่านหรือข้อมูลยืนยันตัวตนไม่ถูกต้อง
+	ErrUnauthorized   = errors.New("unauthorized")                       // ไม่มีสิทธิ์เข้าถึง
+	ErrInvalidInput   = errors.New("invalid input")                      // ข้อมูลที่ส่งมาไม่ถูกต้อง
+	ErrForbidden      = errors.New("forbidden")                          // ยืนยันตัวตนแล้วแต่ไม่มีสิทธิ์ทำรายการนี้
+	ErrTooLarge       = errors.New("payload too large")                  // ข้อมูลมีขนาดเกินที่กำหนด
+	ErrChecksum       = errors.New("checksum mismatch")                  // checksum ของข้อมูลไม่ตรงกับที่แจ้งไว้
+	ErrUnverified     = errors.New("email not verified")                 // ยังไม่ได้ยืนยันอีเมล
+	ErrLocked         = errors.New("temporarily locked")                 // ถูกล็อกชั่วคราวจากการทำรายการผิดซ้ำ
+	ErrMFARequired    = errors.New("two-factor authentication required") // ต้องเข้าสู่ระบบด้วย 2FA ก่อนทำรายการนี้
+	ErrReauthRequired = errors.New("recent login required")              // บัญชีที่ไม่มีรหัสผ่านต้องเข้าสู่ระบบใหม่ก่อนทำรายการนี้
 )
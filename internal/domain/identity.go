@@ -0,0 +1,67 @@
+package domain // ประกาศ package domain
+
+import (
+	"context" // นำเข้า context
+	"time"    // นำเข้า time
+)
+
+// UserIdentity บัญชีของผู้ให้บริการ OpenID Connect ที่ผูกกับผู้ใช้
+type UserIdentity struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	UserID      uint       `json:"-" gorm:"not null;index"`
+	Provider    string     `json:"provider" gorm:"type:varchar(50);not null;uniqueIndex:idx_identity_provider_subject"`
+	Subject     string     `json:"-" gorm:"not null;uniqueIndex:idx_identity_provider_subject"` // claim "sub" ของผู้ให้บริการ
+	Email       string     `json:"email"`                                                       // อีเมลที่ผู้ให้บริการแจ้งตอนผูก
+	CreatedAt   time.Time  `json:"created_at"`
+	LastLoginAt *time.Time `json:"last_login_at"`
+}
+
+// OAuthState สถานะของการเข้าสู่ระบบผ่านผู้ให้บริการที่ยังไม่เสร็จ (เก็บเฉพาะ hash ของ state)
+type OAuthState struct {
+	StateHash    string    `gorm:"primaryKey;type:varchar(64)"`
+	Provider     string    `gorm:"type:varchar(50);not null"`
+	Nonce        string    `gorm:"not null"` // ต้องตรงกับ claim "nonce" ของ ID token
+	CodeVerifier string    `gorm:"not null"` // PKCE code verifier
+	ExpiresAt    time.Time `gorm:"not null;index"`
+	CreatedAt    time.Time
+}
+
+// ExternalIdentity ข้อมูลผู้ใช้ที่ได้จาก ID token ที่ตรวจสอบแล้ว
+type ExternalIdentity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	FirstName     string
+	LastName      string
+	Picture       string
+}
+
+// IdentityProvider ผู้ให้บริการ OpenID Connect หนึ่งราย (authorization code + PKCE)
+type IdentityProvider interface {
+	Name() string                                                                                  // ชื่อที่ใช้ใน URL เช่น google
+	AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error)           // URL สำหรับส่งผู้ใช้ไปเข้าสู่ระบบ
+	Authenticate(ctx context.Context, code, codeVerifier, nonce string) (*ExternalIdentity, error) // แลก code เป็น token และตรวจ ID token
+}
+
+// IdentityRepository interface สำหรับจัดการบัญชีภายนอกและ state ในฐานข้อมูล
+type IdentityRepository interface {
+	Get(ctx context.Context, provider, subject string) (*UserIdentity, error) // ดึงบัญชีที่ผูกไว้
+	Create(ctx context.Context, identity *UserIdentity) error                 // ผูกบัญชี (ErrConflict ถ้าผูกไว้แล้ว)
+	ListByUser(ctx context.Context, userID uint) ([]UserIdentity, error)      // บัญชีที่ผูกกับผู้ใช้
+	Delete(ctx context.Context, userID, id uint) error                        // ยกเลิกการผูก (ErrNotFound ถ้าไม่มี)
+	TouchLogin(ctx context.Context, id uint, now time.Time) error             // บันทึกเวลาเข้าสู่ระบบล่าสุด
+	CreateState(ctx context.Context, state *OAuthState) error                 // บันทึก state ใหม่
+	ConsumeState(ctx context.Context, stateHash string) (*OAuthState, error)  // ใช้ state (ลบทิ้ง คืน ErrNotFound ถ้าไม่มี)
+	DeleteExpiredStates(ctx context.Context, before time.Time) (int64, error) // ลบ state ที่หมดอายุ
+}
+
+// OIDCService interface กำหนดเมธอดสำหรับเข้าสู่ระบบผ่าน OpenID Connect
+type OIDCService interface {
+	Providers() []string                                                                                 // ชื่อผู้ให้บริการที่เปิดใช้
+	Begin(ctx context.Context, provider string) (string, error)                                          // เริ่มเข้าสู่ระบบ คืน URL ของผู้ให้บริการ
+	Complete(ctx context.Context, provider, state, code string, client ClientInfo) (*LoginResult, error) // รับ callback ผูกหรือสร้างผู้ใช้ แล้วเริ่ม session
+	ListIdentities(ctx context.Context, userID uint) ([]UserIdentity, error)                             // บัญชีภายนอกที่ผูกไว้
+	Unlink(ctx context.Context, userID, id uint) error                                                   // ยกเลิกการผูกบัญชีภายนอก
+	CleanupExpired(ctx context.Context) (int, error)                                                     // ลบ state ที่หมดอายุ
+}
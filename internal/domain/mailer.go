@@ -0,0 +1,15 @@
+package domain // ประกาศ package domain
+
+import "context" // นำเข้า context
+
+// Mail อีเมลหนึ่งฉบับ (เนื้อหาเป็นข้อความธรรมดา)
+type Mail struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer interface สำหรับส่งอีเมล (SMTP หรือเขียนลง log สำหรับพัฒนาในเครื่อง)
+type Mailer interface {
+	Send(ctx context.Context, mail Mail) error
+}
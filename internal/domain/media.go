@@ -0,0 +1,19 @@
+package domain // ประกาศ package domain
+
+import "context" // นำเข้า context
+
+// MediaKind ประเภทของไฟล์สื่อ ใช้กำหนดอายุของ URL ที่ลงลายเซ็น
+type MediaKind string
+
+// ประเภทของไฟล์สื่อ
+const (
+	MediaAudio MediaKind = "audio"
+	MediaVideo MediaKind = "video"
+	MediaImage MediaKind = "image"
+)
+
+// MediaURLService interface สร้าง URL แบบมีลายเซ็นและหมดอายุสำหรับไฟล์สื่อที่ส่งกลับใน response
+type MediaURLService interface {
+	SignURL(ctx context.Context, fileURL string, kind MediaKind) string // คืน URL ที่ลงลายเซ็นแล้ว (ค่าว่างถ้าสร้างไม่ได้)
+	SignMusic(ctx context.Context, music *Music)                        // แทนที่ URL ของไฟล์ MP3/MP4/รูปหน้าปก (รวมรูปอัลบั้ม) ด้วย URL ที่ลงลายเซ็น
+}
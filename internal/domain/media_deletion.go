@@ -0,0 +1,29 @@
+package domain // ประกาศ package domain
+
+import (
+	"context" // นำเข้า context
+	"time"    // นำเข้า time
+)
+
+// MediaDeletion ไฟล์ใน storage ที่รอลบ (เช่น ไฟล์เพลงของบัญชีที่ถูกลบ)
+type MediaDeletion struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	FileURL   string    `json:"file_url" gorm:"not null"`
+	NotBefore time.Time `json:"not_before" gorm:"not null;index"` // ลบได้ตั้งแต่เวลานี้ (เลื่อนออกไปเมื่อลบไม่สำเร็จ)
+	Attempts  int       `json:"attempts"`                         // จำนวนครั้งที่ลองลบแล้ว
+	LastError string    `json:"last_error"`                       // error ล่าสุด
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// MediaDeletionRepository interface สำหรับจัดการคิวลบไฟล์
+type MediaDeletionRepository interface {
+	ListDue(ctx context.Context, now time.Time, limit int) ([]MediaDeletion, error) // รายการที่ถึงเวลาลบ
+	Claim(ctx context.Context, id uint) (*MediaDeletion, error)                     // ล็อกรายการไว้จนจบ transaction (ErrNotFound ถ้าถูกนำออกแล้วหรือตัวลบอื่นจับไว้)
+	Delete(ctx context.Context, id uint) error                                      // นำออกจากคิวเมื่อลบไฟล์สำเร็จ
+	Reschedule(ctx context.Context, deletion *MediaDeletion) error                  // บันทึกความล้มเหลวและเวลาที่จะลองใหม่
+}
+
+// MediaDeletionService interface สำหรับลบไฟล์ที่อยู่ในคิว
+type MediaDeletionService interface {
+	ProcessDue(ctx context.Context) (int, error) // ลบไฟล์ที่ถึงเวลา คืนจำนวนไฟล์ที่ลบสำเร็จ
+}
@@ -0,0 +1,48 @@
+package domain // ประกาศ package domain
+
+import (
+	"context" // นำเข้า context
+	"time"    // นำเข้า time
+)
+
+// MediaReference การอ้างถึงไฟล์จากแถวหนึ่งในฐานข้อมูล
+type MediaReference struct {
+	Table  string `json:"table"`  // ตารางที่อ้างถึงไฟล์ (musics, users, albums, artists, uploads, media_objects)
+	ID     string `json:"id"`     // primary key ของแถว
+	Column string `json:"column"` // คอลัมน์ที่เก็บ URL
+	URL    string `json:"url"`
+}
+
+// MediaOrphan ไฟล์ใน storage ที่ไม่มีแถวใดอ้างถึง
+type MediaOrphan struct {
+	Key     string    `json:"key"`
+	URL     string    `json:"url"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+	Deleted bool      `json:"deleted"`         // ลบไปแล้วในรอบนี้
+	Error   string    `json:"error,omitempty"` // เหตุผลที่ลบไม่สำเร็จ
+}
+
+// MediaGCOptions ตัวเลือกของการตรวจความสอดคล้องระหว่าง storage กับฐานข้อมูล
+type MediaGCOptions struct {
+	DeleteOrphans bool          // ลบไฟล์กำพร้า (false = รายงานอย่างเดียว)
+	GracePeriod   time.Duration // ไม่นับไฟล์ที่แก้ไขล่าสุดภายในช่วงนี้เป็นไฟล์กำพร้า (อาจกำลังอัปโหลดหรือยังไม่ได้บันทึกลงฐานข้อมูล)
+}
+
+// MediaGCReport สรุปผลการตรวจทั้งสองทาง
+type MediaGCReport struct {
+	Files       int              `json:"files"`        // จำนวนไฟล์ใน storage
+	Bytes       int64            `json:"bytes"`        // ขนาดรวมของไฟล์ใน storage
+	References  int              `json:"references"`   // จำนวนการอ้างอิงไฟล์ใน storage นี้
+	External    int              `json:"external"`     // จำนวนการอ้างอิง URL ที่ไม่ได้อยู่ใน storage นี้ (ไม่ตรวจ)
+	Recent      int              `json:"recent"`       // ไฟล์ที่ไม่มีการอ้างอิงแต่ยังอยู่ใน grace period (ข้าม)
+	Orphans     []MediaOrphan    `json:"orphans"`      // ไฟล์ที่ไม่มีการอ้างอิง
+	OrphanBytes int64            `json:"orphan_bytes"` // ขนาดรวมของไฟล์กำพร้า
+	Deleted     int              `json:"deleted"`      // จำนวนไฟล์กำพร้าที่ลบแล้ว
+	Missing     []MediaReference `json:"missing"`      // การอ้างอิงที่ไม่พบไฟล์ใน storage
+}
+
+// MediaGCService interface สำหรับตรวจไฟล์กำพร้าและไฟล์ที่หายไป
+type MediaGCService interface {
+	Run(ctx context.Context, opts MediaGCOptions) (*MediaGCReport, error) // เทียบรายการไฟล์ใน storage กับ URL ทั้งหมดในฐานข้อมูล
+}
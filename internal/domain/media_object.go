@@ -0,0 +1,34 @@
+package domain // ประกาศ package domain
+
+import (
+	"context" // นำเข้า context
+	"time"    // นำเข้า time
+)
+
+// MediaObject ไฟล์ใน storage ที่เก็บตาม SHA-256 ของเนื้อหาพร้อมจำนวนการอ้างอิง
+// ไฟล์เดียวกันที่อัปโหลดหลายครั้งจะเก็บไว้ครั้งเดียว และถูกลบเมื่อไม่มีใครอ้างถึงแล้ว
+type MediaObject struct {
+	SHA256      string    `json:"sha256" gorm:"primaryKey;type:char(64)"` // SHA-256 ของเนื้อหา (hex)
+	FileURL     string    `json:"-" gorm:"not null;uniqueIndex"`          // URL ของไฟล์ใน storage
+	Size        int64     `json:"size"`                                   // ขนาดไฟล์ (ไบต์)
+	ContentType string    `json:"content_type"`                           // ชนิดไฟล์ตอนอัปโหลดครั้งแรก
+	RefCount    int       `json:"-" gorm:"not null;default:0"`            // จำนวนการอ้างอิง (ฟิลด์ไฟล์ของเพลง)
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// MediaObjectRepository interface สำหรับจัดการจำนวนการอ้างอิงของไฟล์
+type MediaObjectRepository interface {
+	Acquire(ctx context.Context, object *MediaObject) error                                  // เพิ่มการอ้างอิง (สร้างใหม่ถ้ายังไม่มี) และเติมข้อมูลที่บันทึกไว้กลับมา
+	Lock(ctx context.Context, fileURL string) error                                          // ล็อกไฟล์ตาม URL จนจบ transaction (ล็อกเดียวกับ Acquire)
+	Release(ctx context.Context, fileURL string) (bool, error)                               // ลดการอ้างอิง เมื่อเหลือ 0 จะเข้าคิวลบไฟล์ (false = URL นี้ไม่ได้เก็บแบบนับการอ้างอิง)
+	GetBySHA256(ctx context.Context, sum string) (*MediaObject, error)                       // ดึงไฟล์ตาม SHA-256
+	Referenced(ctx context.Context, fileURL string) (bool, error)                            // ตรวจว่ายังมีการอ้างอิงไฟล์นี้อยู่หรือไม่
+	Relocate(ctx context.Context, oldURL string, object *MediaObject) (string, int64, error) // ย้ายการอ้างอิงทั้งหมดของ oldURL (ในตาราง musics) ไปยังไฟล์ object คืน URL ที่เขียนและจำนวนการอ้างอิง
+	ListReferences(ctx context.Context) ([]MediaReference, error)                            // ดึง URL ไฟล์ทั้งหมดที่ฐานข้อมูลอ้างถึง
+}
+
+// MediaObjectService interface สำหรับตรวจว่ามีไฟล์นี้อยู่แล้วก่อนอัปโหลด
+type MediaObjectService interface {
+	GetBySHA256(ctx context.Context, sum string) (*MediaObject, error) // ดึงข้อมูลไฟล์ตาม SHA-256 (hex)
+}
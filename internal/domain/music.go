@@ -2,34 +2,160 @@ package domain // ประกาศ package domain
 
 import (
 	"context"        // นำเข้า context
+	"io"             // นำเข้า io สำหรับอ่านไฟล์สื่อแบบ stream
 	"mime/multipart" // นำเข้า multipart สำหรับจัดการไฟล์อัปโหลด
+	"time"           // นำเข้า time สำหรับช่วงเวลาในการกรองข้อมูล
 )
 
 // Music struct เก็บข้อมูลเพลง
 type Music struct {
 	BaseModel
-	Title    string `json:"title" gorm:"not null"`  // ชื่อเพลง
-	Artist   string `json:"artist" gorm:"not null"` // ชื่อศิลปิน
-	Lyrics   string `json:"lyrics"`                 // เนื้อเพลง
-	MP3URL   string `json:"mp3_url"`                // URL ไฟล์ MP3
-	MP4URL   string `json:"mp4_url"`                // URL ไฟล์ MP4
-	ImageURL string `json:"image_url"`              // URL รูปหน้าปก
+	OwnerID  uint   `json:"owner_id" gorm:"not null;default:0;index"` // ผู้ใช้ที่สร้างเพลง (0 = ไม่ทราบ แก้ไขได้เฉพาะ admin)
+	Title    string `json:"title" gorm:"not null;index"`              // ชื่อเพลง
+	Artist   string `json:"artist" gorm:"not null;index"`             // ชื่อศิลปินตามที่แสดง (credit)
+	Lyrics   string `json:"lyrics"`                                   // เนื้อเพลง
+	MP3URL   string `json:"mp3_url"`                                  // URL ไฟล์ MP3
+	MP4URL   string `json:"mp4_url"`                                  // URL ไฟล์ MP4
+	ImageURL string `json:"image_url"`                                // URL รูปหน้าปก
+
+	AlbumID     *uint         `json:"album_id" gorm:"index"`                                // อัลบั้มที่เพลงนี้อยู่
+	Album       *Album        `json:"album,omitempty" gorm:"constraint:OnDelete:SET NULL"`  // ข้อมูลอัลบั้ม
+	TrackNumber int           `json:"track_number"`                                         // ลำดับเพลงในแผ่น
+	DiscNumber  int           `json:"disc_number" gorm:"default:1"`                         // หมายเลขแผ่น
+	Artists     []MusicArtist `json:"artists,omitempty" gorm:"constraint:OnDelete:CASCADE"` // ศิลปินที่เกี่ยวข้องพร้อมบทบาท
+
+	// ข้อมูลที่อ่านได้จากไฟล์ MP3 ตอนอัปโหลด
+	Duration   float64 `json:"duration"`    // ความยาวเพลง (วินาที)
+	Bitrate    int     `json:"bitrate"`     // kbps
+	SampleRate int     `json:"sample_rate"` // Hz
+	Channels   int     `json:"channels"`    // จำนวนช่องเสียง
+	Year       int     `json:"year"`        // ปีที่ออก
+	Genre      string  `json:"genre"`       // แนวเพลง
+}
+
+// ค่าเริ่มต้นและค่าสูงสุดของจำนวนรายการต่อหน้า
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// MusicSortFields คอลัมน์ที่อนุญาตให้ใช้เรียงลำดับ (ทุกคอลัมน์มี index)
+var MusicSortFields = map[string]bool{
+	"id":         true,
+	"title":      true,
+	"artist":     true,
+	"created_at": true,
+	"updated_at": true,
+}
+
+// MusicQuery เงื่อนไขการกรอง เรียงลำดับ และแบ่งหน้าของรายการเพลง
+type MusicQuery struct {
+	Page        int        // หน้าที่ต้องการ (เริ่มที่ 1) ใช้เมื่อไม่ได้ระบุ Cursor
+	PageSize    int        // จำนวนรายการต่อหน้า
+	Cursor      string     // cursor แบบ opaque จากผลลัพธ์ก่อนหน้า
+	Artist      string     // กรองตามชื่อศิลปิน (ตรงตัว ไม่สนตัวพิมพ์เล็กใหญ่)
+	Title       string     // กรองตามชื่อเพลงแบบ substring
+	CreatedBy   string     // กรองตามผู้สร้าง
+	ArtistID    uint       // กรองตามศิลปินที่เกี่ยวข้อง (ทุกบทบาท)
+	AlbumID     uint       // กรองตามอัลบั้ม
+	CreatedFrom *time.Time // สร้างตั้งแต่เวลานี้
+	CreatedTo   *time.Time // สร้างก่อนเวลานี้
+	SortBy      string     // คอลัมน์ที่ใช้เรียง (ดู MusicSortFields)
+	SortDesc    bool       // เรียงจากมากไปน้อย
+}
+
+// Normalize เติมค่าเริ่มต้นและจำกัดค่าที่เกินขอบเขต
+func (q *MusicQuery) Normalize() {
+	if q.Page < 1 {
+		q.Page = 1
+	}
+	if q.PageSize < 1 {
+		q.PageSize = DefaultPageSize
+	}
+	if q.PageSize > MaxPageSize {
+		q.PageSize = MaxPageSize
+	}
+	if q.SortBy == "" {
+		q.SortBy = "id"
+	}
+}
+
+// MusicPage ผลลัพธ์ของการดึงรายการเพลงแบบแบ่งหน้า
+type MusicPage struct {
+	Items      []Music // รายการเพลงในหน้านี้
+	Total      int64   // จำนวนเพลงทั้งหมดที่ตรงเงื่อนไข
+	Page       int     // หน้าปัจจุบัน (0 เมื่อใช้ cursor)
+	PageSize   int     // จำนวนรายการต่อหน้า
+	NextCursor string  // cursor สำหรับหน้าถัดไป (ว่างถ้าไม่มี)
+	PrevCursor string  // cursor สำหรับหน้าก่อนหน้า (ว่างถ้าไม่มี)
+}
+
+// MusicSearchQuery เงื่อนไขการค้นหาเพลงแบบ full-text
+type MusicSearchQuery struct {
+	Q        string // ข้อความที่ต้องการค้นหา
+	Page     int    // หน้าที่ต้องการ (เริ่มที่ 1)
+	PageSize int    // จำนวนรายการต่อหน้า
+}
+
+// MusicSearchResult ผลการค้นหาหนึ่งรายการพร้อมคะแนนความเกี่ยวข้อง
+type MusicSearchResult struct {
+	Music
+	Rank     float64  `json:"rank"`                        // คะแนนความเกี่ยวข้อง
+	Snippets []string `json:"snippets,omitempty" gorm:"-"` // บรรทัดเนื้อเพลงที่ตรงกับคำค้น (ครอบด้วย <mark>)
+}
+
+// MusicSearchPage ผลการค้นหาแบบแบ่งหน้า
+type MusicSearchPage struct {
+	Items    []MusicSearchResult // ผลการค้นหาในหน้านี้
+	Total    int64               // จำนวนผลลัพธ์ทั้งหมด
+	Page     int                 // หน้าปัจจุบัน
+	PageSize int                 // จำนวนรายการต่อหน้า
 }
 
 // MusicRepository interface กำหนดเมธอดสำหรับจัดการข้อมูล Music ในฐานข้อมูล
 type MusicRepository interface {
-	Create(ctx context.Context, music *Music) error       // สร้างเพลงใหม่
-	GetByID(ctx context.Context, id uint) (*Music, error) // ดึงข้อมูลเพลงตาม ID
-	GetAll(ctx context.Context) ([]Music, error)          // ดึงข้อมูลเพลงทั้งหมด
-	Update(ctx context.Context, music *Music) error       // อัปเดตข้อมูลเพลง
-	Delete(ctx context.Context, id uint) error            // ลบเพลง
+	Create(ctx context.Context, music *Music) error                               // สร้างเพลงใหม่
+	GetByID(ctx context.Context, id uint) (*Music, error)                         // ดึงข้อมูลเพลงตาม ID
+	GetAll(ctx context.Context, query MusicQuery) (*MusicPage, error)             // ดึงข้อมูลเพลงแบบแบ่งหน้า
+	Update(ctx context.Context, music *Music) error                               // อัปเดตข้อมูลเพลง
+	Delete(ctx context.Context, id uint) error                                    // ลบเพลง
+	Search(ctx context.Context, query MusicSearchQuery) (*MusicSearchPage, error) // ค้นหาเพลงแบบ full-text
+	ListByOwner(ctx context.Context, ownerID uint) ([]Music, error)               // เพลงทั้งหมดของผู้ใช้
+	ListMedia(ctx context.Context, afterID uint, limit int) ([]Music, error)      // ID และ URL ไฟล์ของเพลงที่ ID มากกว่า afterID เรียงตาม ID
+}
+
+// MediaFormat ชนิดไฟล์สื่อของเพลงที่ stream ได้
+type MediaFormat string
+
+// ชนิดไฟล์สื่อที่รองรับ
+const (
+	MediaMP3 MediaFormat = "mp3"
+	MediaMP4 MediaFormat = "mp4"
+)
+
+// ContentType คืน MIME type มาตรฐานของชนิดไฟล์
+func (f MediaFormat) ContentType() string {
+	if f == MediaMP4 {
+		return "video/mp4"
+	}
+	return "audio/mpeg"
+}
+
+// MediaFile ไฟล์สื่อของเพลงที่พร้อม stream
+type MediaFile struct {
+	URL    string      // URL ของไฟล์ใน storage
+	Format MediaFormat // ชนิดไฟล์
+	FileInfo
 }
 
 // MusicService interface กำหนดเมธอดสำหรับ business logic ของ Music
 type MusicService interface {
-	Create(ctx context.Context, music *Music, mp3File, mp4File, imageFile *multipart.FileHeader) error // สร้างเพลงพร้อมอัปโหลดไฟล์
-	GetByID(ctx context.Context, id uint) (*Music, error)                                              // ดึงข้อมูลเพลงตาม ID
-	GetAll(ctx context.Context) ([]Music, error)                                                       // ดึงข้อมูลเพลงทั้งหมด
-	Update(ctx context.Context, music *Music, mp3File, mp4File, imageFile *multipart.FileHeader) error // อัปเดตข้อมูลเพลง
-	Delete(ctx context.Context, id uint) error                                                         // ลบเพลง
+	Create(ctx context.Context, music *Music, mp3File, mp4File, imageFile *multipart.FileHeader) error              // สร้างเพลงพร้อมอัปโหลดไฟล์
+	GetByID(ctx context.Context, id uint) (*Music, error)                                                           // ดึงข้อมูลเพลงตาม ID
+	GetAll(ctx context.Context, query MusicQuery) (*MusicPage, error)                                               // ดึงข้อมูลเพลงแบบแบ่งหน้า
+	Update(ctx context.Context, actor Actor, music *Music, mp3File, mp4File, imageFile *multipart.FileHeader) error // อัปเดตข้อมูลเพลง (เจ้าของหรือ admin) แล้วแทน music ด้วยข้อมูลที่บันทึก
+	Delete(ctx context.Context, actor Actor, id uint) error                                                         // ลบเพลง (เจ้าของ หรือ admin ที่เข้าสู่ระบบด้วย 2FA)
+	Search(ctx context.Context, query MusicSearchQuery) (*MusicSearchPage, error)                                   // ค้นหาเพลงแบบ full-text
+	StatMedia(ctx context.Context, id uint, format MediaFormat) (*MediaFile, error)                                 // ดึงข้อมูลไฟล์สื่อของเพลง
+	OpenMedia(ctx context.Context, media *MediaFile, offset, length int64) (io.ReadCloser, error)                   // เปิดอ่านไฟล์สื่อบางช่วงสำหรับ stream
 }
@@ -0,0 +1,93 @@
+package domain // ประกาศ package domain
+
+import (
+	"context" // นำเข้า context
+	"time"    // นำเข้า time
+)
+
+// PlaylistVisibility ระดับการมองเห็นของ playlist
+type PlaylistVisibility string
+
+// ระดับการมองเห็นที่รองรับ
+const (
+	PlaylistPrivate  PlaylistVisibility = "private"  // เห็นเฉพาะเจ้าของและผู้ร่วมแก้ไข
+	PlaylistUnlisted PlaylistVisibility = "unlisted" // เห็นได้ผ่านลิงก์แชร์ แต่ไม่แสดงในรายการสาธารณะ
+	PlaylistPublic   PlaylistVisibility = "public"   // ทุกคนเห็นได้และแสดงในรายการสาธารณะ
+)
+
+// Valid ตรวจสอบว่าเป็นระดับการมองเห็นที่รองรับหรือไม่
+func (v PlaylistVisibility) Valid() bool {
+	switch v {
+	case PlaylistPrivate, PlaylistUnlisted, PlaylistPublic:
+		return true
+	}
+	return false
+}
+
+// Playlist struct เก็บข้อมูล playlist ของผู้ใช้
+type Playlist struct {
+	BaseModel
+	OwnerID       uint                   `json:"owner_id" gorm:"not null;index"`                             // เจ้าของ playlist
+	Owner         *User                  `json:"owner,omitempty" gorm:"constraint:OnDelete:CASCADE"`         // ข้อมูลเจ้าของ
+	Name          string                 `json:"name" gorm:"not null"`                                       // ชื่อ playlist
+	Description   string                 `json:"description"`                                                // คำอธิบาย
+	Visibility    PlaylistVisibility     `json:"visibility" gorm:"type:varchar(20);not null;index"`          // ระดับการมองเห็น
+	ShareToken    string                 `json:"share_token,omitempty" gorm:"uniqueIndex;not null"`          // token สำหรับลิงก์แชร์ (เห็นเฉพาะคนที่แก้ไขได้)
+	Entries       []PlaylistEntry        `json:"entries,omitempty" gorm:"constraint:OnDelete:CASCADE"`       // รายการเพลงเรียงตาม Position
+	Collaborators []PlaylistCollaborator `json:"collaborators,omitempty" gorm:"constraint:OnDelete:CASCADE"` // ผู้ร่วมแก้ไข
+}
+
+// PlaylistEntry เพลงหนึ่งรายการใน playlist
+// Position เว้นช่วงห่างไว้ เพื่อให้แทรกหรือย้ายได้โดยแก้เพียงแถวเดียว
+type PlaylistEntry struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`                                                       // ID ของรายการ
+	PlaylistID uint      `json:"playlist_id" gorm:"not null;index:idx_playlist_entries_position,priority:1"` // playlist ที่อยู่
+	Position   int64     `json:"-" gorm:"not null;index:idx_playlist_entries_position,priority:2"`           // ลำดับ (ค่ามากอยู่ท้าย)
+	MusicID    uint      `json:"music_id" gorm:"not null;index"`                                             // เพลงที่อ้างถึง
+	Music      *Music    `json:"music,omitempty" gorm:"constraint:OnDelete:CASCADE"`                         // ข้อมูลเพลง
+	AddedBy    uint      `json:"added_by"`                                                                   // ผู้ใช้ที่เพิ่มเพลงนี้
+	CreatedAt  time.Time `json:"created_at"`                                                                 // เวลาที่เพิ่ม
+}
+
+// PlaylistCollaborator ผู้ใช้ที่มีสิทธิ์แก้ไขรายการเพลงใน playlist
+type PlaylistCollaborator struct {
+	PlaylistID uint      `json:"playlist_id" gorm:"primaryKey"`                     // playlist
+	UserID     uint      `json:"user_id" gorm:"primaryKey;index"`                   // ผู้ร่วมแก้ไข
+	User       *User     `json:"user,omitempty" gorm:"constraint:OnDelete:CASCADE"` // ข้อมูลผู้ใช้
+	CreatedAt  time.Time `json:"created_at"`                                        // เวลาที่เพิ่ม
+}
+
+// PlaylistRepository interface กำหนดเมธอดสำหรับจัดการข้อมูล Playlist ในฐานข้อมูล
+type PlaylistRepository interface {
+	Create(ctx context.Context, playlist *Playlist) error                                       // สร้าง playlist
+	GetByID(ctx context.Context, id uint) (*Playlist, error)                                    // ดึง playlist พร้อมผู้ร่วมแก้ไข
+	GetByShareToken(ctx context.Context, token string) (*Playlist, error)                       // ดึง playlist จาก token แชร์
+	ListByUser(ctx context.Context, userID uint, page, pageSize int) ([]Playlist, int64, error) // playlist ที่ผู้ใช้เป็นเจ้าของหรือร่วมแก้ไข
+	ListPublic(ctx context.Context, page, pageSize int) ([]Playlist, int64, error)              // playlist สาธารณะ
+	Update(ctx context.Context, playlist *Playlist) error                                       // อัปเดตข้อมูล playlist
+	Delete(ctx context.Context, id uint) error                                                  // ลบ playlist
+	GetEntries(ctx context.Context, playlistID uint) ([]PlaylistEntry, error)                   // รายการเพลงเรียงตามลำดับ
+	InsertEntry(ctx context.Context, entry *PlaylistEntry, index *int) error                    // แทรกเพลงที่ลำดับ index (nil = ต่อท้าย)
+	MoveEntry(ctx context.Context, playlistID, entryID uint, index int) error                   // ย้ายเพลงไปลำดับ index
+	RemoveEntry(ctx context.Context, playlistID, entryID uint) error                            // ลบเพลงออกจาก playlist
+	AddCollaborator(ctx context.Context, playlistID, userID uint) error                         // เพิ่มผู้ร่วมแก้ไข
+	RemoveCollaborator(ctx context.Context, playlistID, userID uint) error                      // ลบผู้ร่วมแก้ไข
+}
+
+// PlaylistService interface กำหนดเมธอดสำหรับ business logic ของ Playlist
+// viewerID คือผู้ใช้ที่ทำรายการ ใช้ตรวจสอบสิทธิ์ตาม visibility และผู้ร่วมแก้ไข
+type PlaylistService interface {
+	Create(ctx context.Context, playlist *Playlist) error                                                 // สร้าง playlist ของผู้ใช้ (OwnerID)
+	Get(ctx context.Context, id, viewerID uint) (*Playlist, error)                                        // ดึง playlist พร้อมรายการเพลง
+	GetShared(ctx context.Context, token string) (*Playlist, error)                                       // ดึง playlist ที่แชร์ผ่านลิงก์
+	ListMine(ctx context.Context, viewerID uint, page, pageSize int) ([]Playlist, int64, error)           // playlist ของฉันและที่ร่วมแก้ไข
+	ListPublic(ctx context.Context, page, pageSize int) ([]Playlist, int64, error)                        // playlist สาธารณะ
+	Update(ctx context.Context, playlist *Playlist, viewerID uint) error                                  // แก้ชื่อ คำอธิบาย visibility (เจ้าของเท่านั้น)
+	Delete(ctx context.Context, id, viewerID uint) error                                                  // ลบ playlist (เจ้าของเท่านั้น)
+	RegenerateShareToken(ctx context.Context, id, viewerID uint) (string, error)                          // ออก token แชร์ใหม่ ลิงก์เดิมใช้ไม่ได้
+	AddEntry(ctx context.Context, playlistID, musicID, viewerID uint, index *int) (*PlaylistEntry, error) // เพิ่มเพลง
+	MoveEntry(ctx context.Context, playlistID, entryID, viewerID uint, index int) error                   // ย้ายลำดับเพลง
+	RemoveEntry(ctx context.Context, playlistID, entryID, viewerID uint) error                            // ลบเพลงออก
+	AddCollaborator(ctx context.Context, playlistID, viewerID uint, email string) error                   // เพิ่มผู้ร่วมแก้ไขด้วยอีเมล (เจ้าของเท่านั้น)
+	RemoveCollaborator(ctx context.Context, playlistID, viewerID, userID uint) error                      // ลบผู้ร่วมแก้ไข (เจ้าของ หรือออกเอง)
+}
@@ -0,0 +1,85 @@
+package domain // ประกาศ package domain
+
+import (
+	"context" // นำเข้า context
+	"errors"  // นำเข้า errors
+	"fmt"     // นำเข้า fmt
+	"strconv" // นำเข้า strconv
+	"strings" // นำเข้า strings
+	"time"    // นำเข้า time
+)
+
+// RateLimit จำกัดจำนวน request ต่อช่วงเวลาแบบ token bucket (ส่งติดกันได้สูงสุด Requests ครั้ง แล้วเติมคืนเรื่อย ๆ ตลอด Period)
+// ค่าศูนย์หมายถึงไม่จำกัด
+type RateLimit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// Enabled บอกว่ามีการจำกัดหรือไม่
+func (l RateLimit) Enabled() bool {
+	return l.Requests > 0 && l.Period > 0
+}
+
+// String คืนค่าในรูปแบบเดียวกับที่ ParseRateLimit อ่าน เช่น "20/1m0s"
+func (l RateLimit) String() string {
+	if !l.Enabled() {
+		return "off"
+	}
+	return fmt.Sprintf("%d/%s", l.Requests, l.Period)
+}
+
+// ParseRateLimit อ่านค่าจำกัดรูปแบบ "<จำนวน>/<ช่วงเวลา>" เช่น "20/1m", "1000/1h" หรือ "off" เพื่อปิด
+func ParseRateLimit(s string) (RateLimit, error) {
+	s = strings.TrimSpace(s)
+	if s == "off" || s == "0" {
+		return RateLimit{}, nil
+	}
+	count, period, ok := strings.Cut(s, "/")
+	if !ok {
+		return RateLimit{}, errors.New(`rate limit must look like "20/1m"`)
+	}
+	n, err := strconv.Atoi(count)
+	if err != nil || n <= 0 {
+		return RateLimit{}, fmt.Errorf("invalid request count %q", count)
+	}
+	d, err := time.ParseDuration(period)
+	if err != nil || d <= 0 {
+		return RateLimit{}, fmt.Errorf("invalid period %q", period)
+	}
+	return RateLimit{Requests: n, Period: d}, nil
+}
+
+// RateLimitResult ผลการขอใช้ request หนึ่งครั้ง
+type RateLimitResult struct {
+	Allowed    bool
+	Remaining  int           // จำนวน request ที่ส่งได้ทันที
+	Reset      time.Duration // เวลาจนกว่า bucket จะเต็มอีกครั้ง
+	RetryAfter time.Duration // เวลาที่ต้องรอก่อนส่งได้อีกครั้ง (เมื่อ Allowed เป็น false)
+}
+
+// RateLimitStore ที่เก็บ token bucket (ในหน่วยความจำ หรือ store กลางเมื่อรันหลาย instance)
+type RateLimitStore interface {
+	Take(ctx context.Context, key string, limit RateLimit) (RateLimitResult, error) // ใช้ token หนึ่งตัวจาก bucket ของ key
+}
+
+// LoginLockout นับการเข้าสู่ระบบผิดต่ออีเมล และล็อกนานขึ้นเรื่อย ๆ เมื่อผิดซ้ำ
+type LoginLockout interface {
+	Locked(ctx context.Context, key string) (time.Duration, error) // เวลาที่ยังถูกล็อกอยู่ (0 = ไม่ถูกล็อก)
+	Fail(ctx context.Context, key string) error                    // บันทึกการเข้าสู่ระบบผิดหนึ่งครั้ง
+	Reset(ctx context.Context, key string) error                   // ล้างตัวนับหลังเข้าสู่ระบบสำเร็จ
+}
+
+// LockedError บัญชีถูกล็อกชั่วคราวจากการเข้าสู่ระบบผิดหลายครั้ง (errors.Is(err, ErrLocked) เป็นจริง)
+type LockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("too many failed login attempts, retry in %s", e.RetryAfter.Round(time.Second))
+}
+
+// Is ทำให้ errors.Is(err, ErrLocked) เป็นจริง
+func (e *LockedError) Is(target error) bool {
+	return target == ErrLocked
+}
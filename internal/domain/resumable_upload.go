@@ -0,0 +1,77 @@
+package domain // ประกาศ package domain
+
+import (
+	"context" // นำเข้า context
+	"hash"    // นำเข้า hash สำหรับตรวจ checksum ของแต่ละ chunk
+	"io"      // นำเข้า io
+	"time"    // นำเข้า time
+)
+
+// ResumableUpload การอัปโหลดแบบต่อได้ (tus) ที่พักข้อมูลไว้ในเครื่องจนกว่าจะได้รับครบ
+type ResumableUpload struct {
+	ID          string       `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	OwnerID     uint         `json:"owner_id" gorm:"not null;index"`
+	MusicID     uint         `json:"music_id" gorm:"not null;index"` // เพลงที่จะผูกไฟล์เมื่ออัปโหลดครบ
+	Kind        UploadKind   `json:"kind" gorm:"type:varchar(10);not null"`
+	Filename    string       `json:"filename"`
+	ContentType string       `json:"content_type"`
+	Length      int64        `json:"length"`   // ขนาดไฟล์ทั้งหมด (Upload-Length)
+	Offset      int64        `json:"offset"`   // จำนวนไบต์ที่ได้รับแล้ว (Upload-Offset)
+	Metadata    string       `json:"metadata"` // Upload-Metadata ตามที่ client ส่งมา
+	FileURL     string       `json:"-"`        // URL ของไฟล์ใน storage เมื่ออัปโหลดครบ
+	Status      UploadStatus `json:"status" gorm:"type:varchar(20);not null;index"`
+	ExpiresAt   time.Time    `json:"expires_at" gorm:"index"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+// ResumableUploadRequest ข้อมูลสำหรับสร้างการอัปโหลดแบบต่อได้
+type ResumableUploadRequest struct {
+	MusicID     uint
+	Kind        UploadKind
+	Filename    string
+	ContentType string
+	Length      int64
+	Metadata    string
+}
+
+// ChunkChecksum checksum ของ chunk ที่ client แจ้งมา (Upload-Checksum)
+type ChunkChecksum struct {
+	Hash hash.Hash // ฟังก์ชัน hash ตามอัลกอริทึมที่ client เลือก
+	Sum  []byte    // ค่าที่คาดหวัง
+}
+
+// StagedFile ไฟล์ที่พักไว้ อ่านได้ทั้งแบบต่อเนื่องและแบบสุ่มตำแหน่ง
+type StagedFile interface {
+	io.Reader
+	io.ReaderAt
+	io.Closer
+}
+
+// ChunkStore interface สำหรับพักข้อมูลของการอัปโหลดแบบต่อได้
+type ChunkStore interface {
+	Create(ctx context.Context, id string) error                                                  // สร้างไฟล์พักข้อมูลเปล่า
+	Append(ctx context.Context, id string, offset int64, r io.Reader, limit int64) (int64, error) // เขียนข้อมูลต่อจาก offset (ไม่เกิน limit ไบต์)
+	Truncate(ctx context.Context, id string, size int64) error                                    // ตัดข้อมูลที่เกิน size ทิ้ง
+	Open(ctx context.Context, id string) (StagedFile, error)                                      // เปิดไฟล์ที่พักไว้เพื่ออ่าน
+	Remove(ctx context.Context, id string) error                                                  // ลบไฟล์ที่พักไว้
+}
+
+// ResumableUploadRepository interface สำหรับจัดการข้อมูลการอัปโหลดแบบต่อได้ในฐานข้อมูล
+type ResumableUploadRepository interface {
+	Create(ctx context.Context, upload *ResumableUpload) error
+	GetByID(ctx context.Context, id string) (*ResumableUpload, error)
+	Update(ctx context.Context, upload *ResumableUpload) error
+	Delete(ctx context.Context, id string) error
+	ListExpired(ctx context.Context, before time.Time, limit int) ([]ResumableUpload, error) // การอัปโหลดที่ยังไม่ครบและหมดอายุก่อน before
+}
+
+// ResumableUploadService interface กำหนดเมธอดสำหรับ business logic ของการอัปโหลดแบบต่อได้
+type ResumableUploadService interface {
+	MaxSize() int64                                                                                                                    // ขนาดไฟล์สูงสุดที่รับได้
+	Create(ctx context.Context, req ResumableUploadRequest, actor Actor) (*ResumableUpload, error)                                     // เริ่มการอัปโหลด (ผู้ใช้ต้องแก้ไขเพลงนั้นได้)
+	Get(ctx context.Context, id string, ownerID uint) (*ResumableUpload, error)                                                        // ดูสถานะและ offset ปัจจุบัน
+	Append(ctx context.Context, id string, ownerID uint, offset int64, r io.Reader, checksum *ChunkChecksum) (*ResumableUpload, error) // เขียน chunk ต่อ และส่งไฟล์เข้า storage เมื่อได้รับครบ
+	Terminate(ctx context.Context, id string, ownerID uint) error                                                                      // ยกเลิกและลบข้อมูลที่พักไว้
+	CleanupExpired(ctx context.Context) (int, error)                                                                                   // ลบการอัปโหลดที่หมดอายุ
+}
@@ -0,0 +1,72 @@
+package domain // ประกาศ package domain
+
+import "slices" // นำเข้า slices
+
+// Role บทบาทของผู้ใช้ ใช้กำหนดสิทธิ์การเข้าถึง
+type Role string
+
+// บทบาทที่รองรับ
+const (
+	RoleAdmin    Role = "admin"    // จัดการได้ทุกอย่าง รวมถึงกำหนดบทบาทผู้ใช้
+	RoleEditor   Role = "editor"   // สร้างเพลง และแก้ไข/ลบเพลงของตัวเอง
+	RoleListener Role = "listener" // อ่านอย่างเดียว (ค่าเริ่มต้นของผู้ใช้ใหม่)
+)
+
+// Valid ตรวจสอบว่าเป็นบทบาทที่รองรับ
+func (r Role) Valid() bool {
+	switch r {
+	case RoleAdmin, RoleEditor, RoleListener:
+		return true
+	}
+	return false
+}
+
+// Permission สิทธิ์ในการทำรายการ
+type Permission string
+
+// สิทธิ์ที่ใช้ตรวจใน middleware และ service
+const (
+	PermMusicRead     Permission = "music:read"      // ดูและ stream เพลง
+	PermMusicWrite    Permission = "music:write"     // สร้างเพลง และแก้ไข/ลบเพลงของตัวเอง
+	PermMusicManage   Permission = "music:manage"    // แก้ไข/ลบเพลงของทุกคน
+	PermCatalogWrite  Permission = "catalog:write"   // สร้าง/แก้ไขศิลปินและอัลบั้ม
+	PermCatalogDelete Permission = "catalog:delete"  // ลบศิลปินและอัลบั้ม
+	PermUserManage    Permission = "users:manage"    // กำหนดบทบาทผู้ใช้
+	PermPlaylistWrite Permission = "playlists:write" // สร้างและแก้ไข playlist ของตัวเอง
+	PermAPIKeyManage  Permission = "api-keys:manage" // สร้าง API key ให้สคริปต์และบริการอื่น
+)
+
+// rolePermissions สิทธิ์ของแต่ละบทบาท
+var rolePermissions = map[Role][]Permission{
+	RoleAdmin:    {PermMusicRead, PermMusicWrite, PermMusicManage, PermCatalogWrite, PermCatalogDelete, PermUserManage, PermPlaylistWrite, PermAPIKeyManage},
+	RoleEditor:   {PermMusicRead, PermMusicWrite, PermCatalogWrite, PermPlaylistWrite, PermAPIKeyManage},
+	RoleListener: {PermMusicRead, PermPlaylistWrite},
+}
+
+// Can ตรวจว่าบทบาทนี้มีสิทธิ์ p หรือไม่ (บทบาทที่ไม่รู้จักถือเป็น listener)
+func (r Role) Can(p Permission) bool {
+	perms, ok := rolePermissions[r]
+	if !ok {
+		perms = rolePermissions[RoleListener]
+	}
+	for _, perm := range perms {
+		if perm == p {
+			return true
+		}
+	}
+	return false
+}
+
+// Actor ผู้ใช้ที่เรียกใช้งาน ใช้ตรวจสิทธิ์และความเป็นเจ้าของใน service
+type Actor struct {
+	UserID uint
+	Email  string
+	Role   Role
+	Scopes []Permission // สิทธิ์ของ API key ที่ใช้เรียก (nil = เข้าสู่ระบบด้วยตัวเอง ใช้สิทธิ์ของบทบาททั้งหมด)
+	MFA    bool         // access token ได้มาจากการเข้าสู่ระบบด้วย 2FA
+}
+
+// Can ตรวจว่าผู้ใช้มีสิทธิ์ p หรือไม่ (เรียกด้วย API key ต้องมีทั้งในบทบาทและใน scope ของ key)
+func (a Actor) Can(p Permission) bool {
+	return a.Role.Can(p) && (a.Scopes == nil || slices.Contains(a.Scopes, p))
+}
@@ -0,0 +1,67 @@
+package domain // ประกาศ package domain
+
+import (
+	"context" // นำเข้า context
+	"time"    // นำเข้า time
+)
+
+// Session การเข้าสู่ระบบหนึ่งครั้งของผู้ใช้ (หนึ่งอุปกรณ์) ใช้เป็น family ของ refresh token ที่หมุนต่อกัน
+type Session struct {
+	ID         string     `json:"id" gorm:"primaryKey;type:varchar(36)"` // family ID
+	UserID     uint       `json:"-" gorm:"not null;index"`
+	UserAgent  string     `json:"user_agent"`
+	IP         string     `json:"ip"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt time.Time  `json:"last_used_at"`
+	ExpiresAt  time.Time  `json:"expires_at" gorm:"index"`
+	RevokedAt  *time.Time `json:"-" gorm:"index"`
+	MFA        bool       `json:"mfa"`              // เข้าสู่ระบบด้วย 2FA (ส่งต่อไปยัง access token ทุกตัวของ session)
+	Current    bool       `json:"current" gorm:"-"` // เป็น session ของ token ที่ใช้เรียก API อยู่
+}
+
+// SessionToken refresh token หนึ่งตัวใน family (เก็บเฉพาะ hash)
+type SessionToken struct {
+	TokenHash string     `gorm:"primaryKey;type:varchar(64)"` // SHA-256 (hex) ของ refresh token
+	SessionID string     `gorm:"type:varchar(36);not null;index"`
+	Session   *Session   `gorm:"constraint:OnDelete:CASCADE"`
+	UsedAt    *time.Time // เวลาที่ถูกหมุนเป็น token ใหม่ (ใช้ซ้ำ = ถูกขโมย)
+	CreatedAt time.Time
+}
+
+// ClientInfo ข้อมูลอุปกรณ์ที่ใช้เข้าสู่ระบบ
+type ClientInfo struct {
+	UserAgent string
+	IP        string
+}
+
+// TokenPair access token และ refresh token ที่ออกให้ผู้ใช้
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// SessionRepository interface สำหรับจัดการ session และ refresh token ในฐานข้อมูล
+type SessionRepository interface {
+	Create(ctx context.Context, session *Session, tokenHash string) error          // สร้าง session พร้อม refresh token ตัวแรก
+	GetByID(ctx context.Context, id string) (*Session, error)                      // ดึง session ตาม ID
+	GetToken(ctx context.Context, tokenHash string) (*SessionToken, error)         // ดึง refresh token ตาม hash
+	Rotate(ctx context.Context, session *Session, oldHash, newHash string) error   // ใช้ token เดิม (คืน ErrConflict ถ้าถูกใช้ไปแล้ว) และบันทึก token ใหม่
+	ListActive(ctx context.Context, userID uint, now time.Time) ([]Session, error) // session ที่ยังไม่หมดอายุและไม่ถูกยกเลิก
+	Revoke(ctx context.Context, id string) error                                   // ยกเลิก session (ทั้ง family)
+	RevokeAll(ctx context.Context, userID uint) error                              // ยกเลิกทุก session ของผู้ใช้
+	RevokeOthers(ctx context.Context, userID uint, keepID string) error            // ยกเลิกทุก session ของผู้ใช้ยกเว้น keepID
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)            // ลบ session ที่หมดอายุหรือถูกยกเลิกก่อน before
+}
+
+// SessionService interface กำหนดเมธอดสำหรับออก หมุน และยกเลิก token
+type SessionService interface {
+	Issue(ctx context.Context, user *User, client ClientInfo, mfa bool) (*TokenPair, error)     // เริ่ม session ใหม่หลังยืนยันตัวตนสำเร็จ (mfa = ผ่าน 2FA แล้ว)
+	Refresh(ctx context.Context, refreshToken string, client ClientInfo) (*TokenPair, error)    // หมุน refresh token (ใช้ซ้ำ = ยกเลิกทั้ง family)
+	Logout(ctx context.Context, refreshToken string) error                                      // ยกเลิก session ของ refresh token
+	LogoutAll(ctx context.Context, userID uint) error                                           // ยกเลิกทุก session ของผู้ใช้
+	LogoutOthers(ctx context.Context, userID uint, keepSessionID string) error                  // ยกเลิกทุก session ยกเว้น session ปัจจุบัน
+	List(ctx context.Context, userID uint) ([]Session, error)                                   // รายการอุปกรณ์ที่เข้าสู่ระบบอยู่
+	Revoke(ctx context.Context, userID uint, sessionID string) error                            // ยกเลิก session หนึ่งของผู้ใช้
+	CleanupExpired(ctx context.Context) (int, error)                                            // ลบ session ที่หมดอายุ
+	RecentLogin(ctx context.Context, userID uint, sessionID string, within time.Duration) error // ตรวจว่า session เริ่มจากการเข้าสู่ระบบภายใน within (ไม่ใช่ = ErrReauthRequired)
+}
@@ -2,11 +2,52 @@ package domain // ประกาศ package domain
 
 import (
 	"context"        // นำเข้า context
+	"io"             // นำเข้า io สำหรับอ่านข้อมูลแบบ stream
 	"mime/multipart" // นำเข้า multipart สำหรับจัดการไฟล์
+	"time"           // นำเข้า time
 )
 
+// FileInfo ข้อมูลของไฟล์ที่เก็บอยู่ใน storage
+type FileInfo struct {
+	Size           int64     // ขนาดไฟล์ (ไบต์)
+	ContentType    string    // ชนิดไฟล์ (อาจว่างถ้า storage ไม่ทราบ)
+	ModTime        time.Time // เวลาแก้ไขล่าสุด
+	ETag           string    // ตัวระบุเวอร์ชันของไฟล์ (รวมเครื่องหมายคำพูด)
+	ChecksumSHA256 string    // base64 ของ SHA-256 ทั้งไฟล์ ถ้า storage มีข้อมูลอยู่แล้ว (ว่าง = ต้องคำนวณเอง)
+}
+
+// StoredFile ไฟล์หนึ่งไฟล์จากการไล่รายการไฟล์ใน storage
+type StoredFile struct {
+	Key     string    // ชื่อไฟล์หรือ object key ใน storage
+	URL     string    // URL ของไฟล์ในรูปแบบที่ storage สร้างให้
+	Size    int64     // ขนาดไฟล์ (ไบต์)
+	ModTime time.Time // เวลาแก้ไขล่าสุด
+}
+
 // StorageService interface กำหนดเมธอดสำหรับจัดการไฟล์
 type StorageService interface {
-	UploadFile(ctx context.Context, file *multipart.FileHeader) (string, error) // อัปโหลดไฟล์และคืนค่า URL
-	DeleteFile(ctx context.Context, fileURL string) error                       // ลบไฟล์ตาม URL
+	UploadFile(ctx context.Context, file *multipart.FileHeader) (string, error)                              // อัปโหลดไฟล์และคืนค่า URL
+	UploadReader(ctx context.Context, r io.Reader, size int64, filename, contentType string) (string, error) // อัปโหลดข้อมูลจาก reader (size < 0 ถ้าไม่ทราบขนาด) และคืนค่า URL
+	StatFile(ctx context.Context, fileURL string) (*FileInfo, error)                                         // ดึงข้อมูลไฟล์ (ErrNotFound ถ้าไม่มี)
+	OpenFile(ctx context.Context, fileURL string, offset, length int64) (io.ReadCloser, error)               // เปิดอ่านไฟล์ตั้งแต่ offset จำนวน length ไบต์ (length < 0 = จนจบไฟล์)
+	SignedURL(ctx context.Context, fileURL string, ttl time.Duration) (string, error)                        // สร้าง URL แบบมีลายเซ็นที่หมดอายุใน ttl (URL ที่ไม่ได้อยู่ใน storage คืนค่าเดิม)
+	DeleteFile(ctx context.Context, fileURL string) error                                                    // ลบไฟล์ตาม URL
+	ListFiles(ctx context.Context, fn func(StoredFile) error) error                                          // ไล่รายการไฟล์ทั้งหมดใน storage (หยุดเมื่อ fn คืน error)
+}
+
+// StoredObject ไฟล์ที่เก็บใน storage ตาม SHA-256 ของเนื้อหา
+type StoredObject struct {
+	URL         string // URL ของไฟล์
+	SHA256      string // SHA-256 ของเนื้อหา (hex ตัวพิมพ์เล็ก) ใช้เป็นชื่อไฟล์ (key) ใน storage
+	Size        int64  // ขนาดไฟล์ (ไบต์)
+	ContentType string // ชนิดไฟล์
+}
+
+// BlobStorage storage ที่เก็บไฟล์แบบ content-addressed (เนื้อหาเดียวกันได้ key เดียวกัน)
+// UploadFile/UploadReader ของ storage แบบนี้คืน URL ของ PutObject
+type BlobStorage interface {
+	StorageService
+	PutObject(ctx context.Context, r io.Reader, size int64, filename, contentType string) (*StoredObject, error) // คำนวณ SHA-256 ระหว่างอ่านและเก็บไฟล์ที่ key ตาม hash (ไม่อัปโหลดซ้ำถ้ามีอยู่แล้ว)
+	Contains(fileURL string) bool                                                                                // ตรวจว่า URL ชี้ไปยังไฟล์ใน storage นี้
+	ObjectKey(fileURL string) (string, bool)                                                                     // ดึงชื่อไฟล์ (key) จาก URL ทุกรูปแบบที่ชี้ไปยัง storage นี้ (false = ไม่ได้อยู่ใน storage นี้)
 }
@@ -0,0 +1,35 @@
+package domain // ประกาศ package domain
+
+import "context" // นำเข้า context
+
+// StorageMigrationOptions ตัวเลือกของการย้ายไฟล์ระหว่าง storage
+type StorageMigrationOptions struct {
+	DryRun    bool // ตรวจอย่างเดียว ไม่คัดลอกไฟล์และไม่แก้ฐานข้อมูล
+	AfterID   uint // เริ่มจากเพลงที่ ID มากกว่านี้ (ใช้ทำต่อจากรอบก่อน)
+	BatchSize int  // จำนวนเพลงที่อ่านต่อรอบ
+}
+
+// StorageMigrationIssue ไฟล์ที่ย้ายไม่ได้
+type StorageMigrationIssue struct {
+	MusicID uint   `json:"music_id"`
+	Field   string `json:"field"` // คอลัมน์ที่อ้างถึงไฟล์ (mp3_url, mp4_url, image_url)
+	URL     string `json:"url"`
+	Error   string `json:"error,omitempty"`
+}
+
+// StorageMigrationReport สรุปผลการย้ายไฟล์
+type StorageMigrationReport struct {
+	Tracks   int                     `json:"tracks"`   // จำนวนเพลงที่ตรวจ
+	Copied   int                     `json:"copied"`   // จำนวนไฟล์ที่คัดลอก (หรือจะคัดลอกเมื่อ dry run)
+	Bytes    int64                   `json:"bytes"`    // ขนาดรวมของไฟล์ที่คัดลอก
+	Migrated int                     `json:"migrated"` // จำนวนไฟล์ที่อยู่ใน storage ปลายทางแล้ว (ข้าม)
+	External int                     `json:"external"` // จำนวน URL ที่ไม่ได้อยู่ใน storage ต้นทาง (ข้าม)
+	LastID   uint                    `json:"last_id"`  // ID ของเพลงสุดท้ายที่ตรวจ
+	Missing  []StorageMigrationIssue `json:"missing"`  // ไฟล์ที่ไม่พบใน storage ต้นทาง
+	Failed   []StorageMigrationIssue `json:"failed"`   // ไฟล์ที่คัดลอกหรือตรวจ checksum ไม่ผ่าน
+}
+
+// StorageMigrationService interface สำหรับย้ายไฟล์ของเพลงจาก storage หนึ่งไปอีก storage หนึ่ง
+type StorageMigrationService interface {
+	Run(ctx context.Context, opts StorageMigrationOptions) (*StorageMigrationReport, error) // ย้ายไฟล์และแก้ URL ในฐานข้อมูล (ทำซ้ำได้ ไฟล์ที่ย้ายแล้วจะถูกข้าม)
+}
@@ -0,0 +1,14 @@
+package domain // ประกาศ package domain
+
+import "context" // นำเข้า context
+
+// TxManager รันงานหลายขั้นตอนใน transaction เดียวกัน (unit of work) โดยที่ service ไม่ต้องรู้จัก ORM
+// transaction ถูกส่งต่อผ่าน context ที่ fn ได้รับ repository ที่ขอจาก context นั้นจะทำงานใน transaction เดียวกัน
+type TxManager interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error // commit เมื่อ fn คืน nil, rollback เมื่อคืน error หรือ panic (เรียกซ้อนได้ ชั้นในใช้ savepoint)
+	Music(ctx context.Context) MusicRepository                              // MusicRepository ที่ใช้ transaction ใน ctx (ไม่มี = ใช้ connection ปกติ)
+	Artist(ctx context.Context) ArtistRepository                            // ArtistRepository ที่ใช้ transaction ใน ctx (ไม่มี = ใช้ connection ปกติ)
+	User(ctx context.Context) UserRepository                                // UserRepository ที่ใช้ transaction ใน ctx (ไม่มี = ใช้ connection ปกติ)
+	MediaObject(ctx context.Context) MediaObjectRepository                  // MediaObjectRepository ที่ใช้ transaction ใน ctx (ไม่มี = ใช้ connection ปกติ)
+	MediaDeletion(ctx context.Context) MediaDeletionRepository              // MediaDeletionRepository ที่ใช้ transaction ใน ctx (ไม่มี = ใช้ connection ปกติ)
+}
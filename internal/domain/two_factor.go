@@ -0,0 +1,84 @@
+package domain // ประกาศ package domain
+
+import (
+	"context" // นำเข้า context
+	"time"    // นำเข้า time
+)
+
+// TwoFactor การตั้งค่า TOTP ของผู้ใช้
+type TwoFactor struct {
+	UserID      uint       `gorm:"primaryKey"`
+	Secret      string     `gorm:"not null"` // secret แบบ base32
+	ConfirmedAt *time.Time // nil = เริ่มตั้งค่าแล้วแต่ยังไม่ยืนยันรหัสแรก (ยังไม่เปิดใช้)
+	LastStep    int64      // ช่วงเวลาของรหัสล่าสุดที่ใช้แล้ว (กันการใช้รหัสซ้ำ)
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// RecoveryCode รหัสกู้คืนสำหรับใช้แทน TOTP ได้ครั้งเดียว (เก็บเฉพาะ hash)
+type RecoveryCode struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"not null;index"`
+	CodeHash  string `gorm:"type:varchar(64);not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// LoginChallenge ขั้นที่สองของการเข้าสู่ระบบหลังรหัสผ่านถูกต้อง (เก็บเฉพาะ hash ของ token)
+type LoginChallenge struct {
+	TokenHash string    `gorm:"primaryKey;type:varchar(64)"`
+	UserID    uint      `gorm:"not null;index"`
+	Attempts  int       // จำนวนครั้งที่ส่งรหัสผิด
+	ExpiresAt time.Time `gorm:"not null;index"`
+	CreatedAt time.Time
+}
+
+// TwoFactorSetup ข้อมูลสำหรับเพิ่มบัญชีในแอปยืนยันตัวตน
+type TwoFactorSetup struct {
+	Secret          string `json:"secret"`      // สำหรับพิมพ์เองในแอป
+	ProvisioningURI string `json:"otpauth_uri"` // ข้อมูลของ QR code
+}
+
+// TwoFactorStatus สถานะ 2FA ของผู้ใช้
+type TwoFactorStatus struct {
+	Enabled           bool       `json:"enabled"`
+	ConfirmedAt       *time.Time `json:"confirmed_at,omitempty"`
+	RecoveryCodesLeft int        `json:"recovery_codes_left"`
+}
+
+// LoginResult ผลการเข้าสู่ระบบด้วยรหัสผ่าน
+// ถ้าผู้ใช้เปิด 2FA จะได้ ChallengeToken แทน Tokens และต้องยืนยันรหัสก่อน
+type LoginResult struct {
+	Tokens             *TokenPair
+	ChallengeToken     string
+	ChallengeExpiresAt time.Time
+}
+
+// TwoFactorRepository interface สำหรับจัดการข้อมูล 2FA ในฐานข้อมูล
+type TwoFactorRepository interface {
+	Get(ctx context.Context, userID uint) (*TwoFactor, error)                     // ดึงการตั้งค่า TOTP
+	Save(ctx context.Context, tf *TwoFactor) error                                // สร้างหรือแทนที่การตั้งค่า
+	Delete(ctx context.Context, userID uint) error                                // ปิด 2FA และลบรหัสกู้คืน
+	UseStep(ctx context.Context, userID uint, step int64) error                   // บันทึกช่วงเวลาที่ใช้ (ErrConflict ถ้าใช้ไปแล้ว)
+	ReplaceRecoveryCodes(ctx context.Context, userID uint, hashes []string) error // แทนรหัสกู้คืนทั้งหมด
+	UseRecoveryCode(ctx context.Context, userID uint, hash string) error          // ใช้รหัสกู้คืน (ErrNotFound ถ้าไม่มีหรือใช้แล้ว)
+	CountRecoveryCodes(ctx context.Context, userID uint) (int, error)             // จำนวนรหัสกู้คืนที่เหลือ
+	CreateChallenge(ctx context.Context, challenge *LoginChallenge) error         // บันทึก challenge ใหม่
+	GetChallenge(ctx context.Context, tokenHash string) (*LoginChallenge, error)  // ดึง challenge
+	AddChallengeAttempt(ctx context.Context, tokenHash string) error              // เพิ่มจำนวนครั้งที่ส่งรหัสผิด
+	DeleteChallenge(ctx context.Context, tokenHash string) error                  // ลบ challenge
+	DeleteExpiredChallenges(ctx context.Context, before time.Time) (int64, error) // ลบ challenge ที่หมดอายุ
+}
+
+// TwoFactorService interface กำหนดเมธอดสำหรับ TOTP 2FA
+type TwoFactorService interface {
+	Status(ctx context.Context, userID uint) (*TwoFactorStatus, error)                                // สถานะ 2FA
+	Setup(ctx context.Context, user *User) (*TwoFactorSetup, error)                                   // เริ่มตั้งค่า (ErrConflict ถ้าเปิดใช้อยู่แล้ว)
+	Confirm(ctx context.Context, userID uint, code string) ([]string, error)                          // ยืนยันรหัสแรกเพื่อเปิดใช้ คืนรหัสกู้คืน
+	RegenerateRecoveryCodes(ctx context.Context, userID uint, code string) ([]string, error)          // ออกรหัสกู้คืนชุดใหม่
+	Disable(ctx context.Context, userID uint, sessionID, password, code string) error                 // ปิด 2FA (ต้องใช้ทั้งรหัสผ่านและรหัส 2FA บัญชีที่ไม่มีรหัสผ่านใช้การเข้าสู่ระบบล่าสุดแทน)
+	Enabled(ctx context.Context, userID uint) (bool, error)                                           // เปิดใช้ 2FA อยู่หรือไม่
+	BeginChallenge(ctx context.Context, userID uint) (string, time.Time, error)                       // ออก challenge token หลังรหัสผ่านถูกต้อง
+	CompleteChallenge(ctx context.Context, token, code string, client ClientInfo) (*TokenPair, error) // ยืนยัน OTP หรือรหัสกู้คืนแล้วออก token
+	CleanupExpired(ctx context.Context) (int, error)                                                  // ลบ challenge ที่หมดอายุ
+}
@@ -0,0 +1,117 @@
+package domain // ประกาศ package domain
+
+import (
+	"context" // นำเข้า context
+	"time"    // นำเข้า time
+)
+
+// UploadKind ชนิดของไฟล์ที่อัปโหลด ใช้กำหนดว่าจะผูกกับฟิลด์ไหนของเพลง
+type UploadKind string
+
+// ชนิดของไฟล์ที่อัปโหลดได้
+const (
+	UploadMP3   UploadKind = "mp3"
+	UploadMP4   UploadKind = "mp4"
+	UploadImage UploadKind = "image"
+)
+
+// Valid ตรวจสอบว่าเป็นชนิดไฟล์ที่รองรับ
+func (k UploadKind) Valid() bool {
+	switch k {
+	case UploadMP3, UploadMP4, UploadImage:
+		return true
+	}
+	return false
+}
+
+// UploadStatus สถานะของการอัปโหลด
+type UploadStatus string
+
+// สถานะของการอัปโหลด
+const (
+	UploadPending   UploadStatus = "pending"
+	UploadCompleted UploadStatus = "completed"
+	UploadFailed    UploadStatus = "failed"
+)
+
+// Upload การอัปโหลดไฟล์ตรงไปยัง storage ที่รอการยืนยัน (finalize)
+type Upload struct {
+	ID             string       `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	OwnerID        uint         `json:"owner_id" gorm:"not null;index"`
+	Kind           UploadKind   `json:"kind" gorm:"type:varchar(10);not null"`
+	Filename       string       `json:"filename"`
+	ContentType    string       `json:"content_type"`
+	Size           int64        `json:"size"`
+	ChecksumSHA256 string       `json:"checksum_sha256,omitempty"` // base64 ของ SHA-256 (ว่าง = ไม่ตรวจ)
+	FileURL        string       `json:"-"`                         // URL ปลายทางที่จองไว้ (ชื่อสุ่ม) เมื่อ finalize แล้วเป็น URL ของไฟล์ที่เก็บตาม SHA-256
+	MultipartID    string       `json:"-"`                         // id ของ multipart upload (ว่าง = อัปโหลดครั้งเดียว)
+	PartSize       int64        `json:"part_size,omitempty"`
+	Status         UploadStatus `json:"status" gorm:"type:varchar(20);not null;index"`
+	MusicID        *uint        `json:"music_id,omitempty"`
+	ExpiresAt      time.Time    `json:"expires_at" gorm:"index"`
+	CreatedAt      time.Time    `json:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at"`
+}
+
+// UploadTarget ปลายทางของไฟล์ใน storage ที่จองไว้สำหรับอัปโหลดตรง
+type UploadTarget struct {
+	FileURL     string // URL ของไฟล์เมื่ออัปโหลดเสร็จ
+	MultipartID string // id ของ multipart upload (ว่าง = อัปโหลดครั้งเดียว)
+}
+
+// PresignedRequest request ที่ลงลายเซ็นแล้วให้ client ส่งตรงไปยัง storage
+type PresignedRequest struct {
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"` // header ที่ต้องส่งให้ตรงกับลายเซ็น
+}
+
+// PresignedPart URL สำหรับอัปโหลดแต่ละส่วนของ multipart upload
+type PresignedPart struct {
+	PartNumber int `json:"part_number"`
+	PresignedRequest
+}
+
+// CompletedPart ส่วนที่อัปโหลดเสร็จแล้ว (ETag จาก response ของการอัปโหลดส่วนนั้น)
+type CompletedPart struct {
+	PartNumber int    `json:"part_number" binding:"required,min=1"`
+	ETag       string `json:"etag" binding:"required"`
+}
+
+// DirectUploadStorage interface สำหรับ storage ที่ให้ client อัปโหลดไฟล์ได้โดยตรงผ่าน URL ที่ลงลายเซ็น
+type DirectUploadStorage interface {
+	CreateUpload(ctx context.Context, filename, contentType string, multipart bool) (*UploadTarget, error)                                        // จองปลายทาง (และเริ่ม multipart upload ถ้าต้องการ)
+	PresignPut(ctx context.Context, target *UploadTarget, contentType, checksum string, size int64, ttl time.Duration) (*PresignedRequest, error) // URL สำหรับอัปโหลดครั้งเดียว
+	PresignPart(ctx context.Context, target *UploadTarget, partNumber int, ttl time.Duration) (*PresignedRequest, error)                          // URL สำหรับอัปโหลดส่วนที่ partNumber
+	CompleteMultipart(ctx context.Context, target *UploadTarget, parts []CompletedPart) error                                                     // รวมส่วนที่อัปโหลดเป็นไฟล์เดียว
+	AbortUpload(ctx context.Context, target *UploadTarget) error                                                                                  // ยกเลิกและลบข้อมูลที่อัปโหลดค้างไว้
+}
+
+// UploadRequest ข้อมูลสำหรับเริ่มการอัปโหลดตรง
+type UploadRequest struct {
+	Kind           UploadKind
+	Filename       string
+	ContentType    string
+	Size           int64
+	ChecksumSHA256 string // base64 ของ SHA-256 (ไม่บังคับ)
+}
+
+// UploadTicket ข้อมูลที่ client ใช้อัปโหลดไฟล์
+type UploadTicket struct {
+	Upload  *Upload           `json:"upload"`
+	Request *PresignedRequest `json:"request,omitempty"` // อัปโหลดครั้งเดียว
+	Parts   []PresignedPart   `json:"parts,omitempty"`   // multipart upload (ขนาดแต่ละส่วนตาม upload.part_size)
+}
+
+// UploadRepository interface สำหรับจัดการข้อมูลการอัปโหลดในฐานข้อมูล
+type UploadRepository interface {
+	Create(ctx context.Context, upload *Upload) error
+	GetByID(ctx context.Context, id string) (*Upload, error)
+	Update(ctx context.Context, upload *Upload) error
+}
+
+// UploadService interface กำหนดเมธอดสำหรับ business logic ของการอัปโหลดตรง
+type UploadService interface {
+	Create(ctx context.Context, req UploadRequest, ownerID uint) (*UploadTicket, error)                        // เริ่มการอัปโหลดและคืน URL ที่ลงลายเซ็น
+	Finalize(ctx context.Context, id string, actor Actor, musicID uint, parts []CompletedPart) (*Music, error) // ตรวจสอบไฟล์และผูกกับเพลงที่ผู้ใช้แก้ไขได้
+}
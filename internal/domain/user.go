@@ -2,6 +2,7 @@ package domain // ประกาศ package domain สำหรับเก็
 
 import (
 	"context" // นำเข้า context สำหรับจัดการ timeout และ cancelation
+	"time"    // นำเข้า time
 )
 
 // User struct เก็บข้อมูลผู้ใช้งาน
@@ -12,6 +13,22 @@ type User struct {
 	FirstName    string `json:"first_name"`
 	LastName     string `json:"last_name"`
 	ImageProfile string `json:"image_profile"`
+	Role         Role   `json:"role" gorm:"type:varchar(20);not null;default:'listener'"` // บทบาทของผู้ใช้
+	// EmailVerifiedAt เวลาที่ยืนยันอีเมล (nil = ยังไม่ยืนยัน)
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	// DeletedAt เวลาที่ลบบัญชี (soft delete ข้อมูลส่วนตัวถูกลบออกแล้ว)
+	DeletedAt *time.Time `json:"-" gorm:"index"`
+}
+
+// DeletedUserLabel ค่าที่ใช้แทนอีเมลใน CreatedBy/UpdatedBy เมื่อบัญชีถูกลบ
+const DeletedUserLabel = "deleted-user"
+
+// UserExport ข้อมูลทั้งหมดของผู้ใช้สำหรับดาวน์โหลด
+type UserExport struct {
+	ExportedAt time.Time  `json:"exported_at"`
+	Profile    *User      `json:"profile"`
+	Tracks     []Music    `json:"tracks"`    // เพลงที่ผู้ใช้อัปโหลด (เฉพาะข้อมูล ไม่รวมไฟล์)
+	Playlists  []Playlist `json:"playlists"` // playlist ที่เป็นเจ้าของหรือร่วมแก้ไข พร้อมรายการเพลง
 }
 
 // UserRepository interface กำหนดเมธอดสำหรับจัดการข้อมูล User ในฐานข้อมูล
@@ -20,13 +37,17 @@ type UserRepository interface {
 	GetByEmail(ctx context.Context, email string) (*User, error) // ค้นหาผู้ใช้ด้วยอีเมล
 	GetByID(ctx context.Context, id uint) (*User, error)         // ค้นหาผู้ใช้ด้วย ID
 	UpdateProfile(ctx context.Context, id uint, updates map[string]any) error
+	SoftDelete(ctx context.Context, id uint, now time.Time) error // ลบบัญชี: ปิดบังข้อมูลส่วนตัว ลบเพลงและ playlist ของผู้ใช้ และเข้าคิวลบไฟล์
 }
 
 // UserService interface กำหนดเมธอดสำหรับ business logic ของ User
 type UserService interface {
-	Register(ctx context.Context, user *User) error                            // ลงทะเบียนผู้ใช้
-	Login(ctx context.Context, email, password string) (string, string, error) // เข้าสู่ระบบ (คืนค่า access token และ refresh token)
-	RefreshToken(ctx context.Context, refreshToken string) (string, error)     // ขอ Access Token ใหม่ด้วย Refresh Token
+	Register(ctx context.Context, user *User) error                                             // ลงทะเบียนผู้ใช้
+	Login(ctx context.Context, email, password string, client ClientInfo) (*LoginResult, error) // เข้าสู่ระบบ (คืนค่า token หรือ challenge ของ 2FA)
 	GetByID(ctx context.Context, id uint) (*User, error)
 	UpdateProfile(ctx context.Context, id uint, updates map[string]any) (*User, error)
+	AssignRole(ctx context.Context, actor Actor, id uint, role Role) (*User, error)         // กำหนดบทบาทผู้ใช้ (admin เท่านั้น)
+	ChangePassword(ctx context.Context, id uint, sessionID, current, password string) error // เปลี่ยนรหัสผ่านและยกเลิก session อื่น
+	Export(ctx context.Context, id uint) (*UserExport, error)                               // ข้อมูลทั้งหมดของผู้ใช้
+	DeleteAccount(ctx context.Context, id uint, sessionID, password string) error           // ลบบัญชีของตัวเอง (บัญชีที่ไม่มีรหัสผ่านใช้การเข้าสู่ระบบล่าสุดแทน)
 }
@@ -0,0 +1,394 @@
+package database // ประกาศ package database
+
+import (
+	"context"       // นำเข้า context
+	"database/sql"  // นำเข้า sql สำหรับใช้ connection เดียวตลอดการ migrate (advisory lock ผูกกับ session)
+	"embed"         // นำเข้า embed สำหรับฝังไฟล์ migration ไว้ใน binary
+	"errors"        // นำเข้า errors
+	"fmt"           // นำเข้า fmt สำหรับจัดรูปแบบข้อความ
+	"io/fs"         // นำเข้า fs สำหรับอ่านไฟล์ migration
+	"os"            // นำเข้า os สำหรับสร้างไฟล์ migration ใหม่
+	"path"          // นำเข้า path สำหรับ path ภายใน fs.FS
+	"path/filepath" // นำเข้า filepath
+	"regexp"        // นำเข้า regexp สำหรับแยกเลขเวอร์ชันจากชื่อไฟล์
+	"sort"          // นำเข้า sort สำหรับเรียงตามเวอร์ชัน
+	"strconv"       // นำเข้า strconv
+	"strings"       // นำเข้า strings
+	"time"          // นำเข้า time
+
+	"gorm.io/gorm" // นำเข้า gorm ORM
+)
+
+// migrationFiles ไฟล์ migration ที่ฝังไว้ใน binary
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// MigrationsDir โฟลเดอร์ของไฟล์ migration ในซอร์สโค้ด (ใช้สร้างไฟล์ใหม่ด้วยคำสั่ง migrate create)
+const MigrationsDir = "internal/infrastructure/database/migrations"
+
+// migrationLockID key ของ advisory lock ที่กันไม่ให้หลาย instance migrate พร้อมกัน
+const migrationLockID = 7_243_105_118
+
+// noTransactionDirective ใส่ไว้บรรทัดแรกของไฟล์เพื่อรันนอก transaction (เช่น CREATE INDEX CONCURRENTLY)
+const noTransactionDirective = "-- migrate:no-transaction"
+
+// migrationName รูปแบบชื่อไฟล์: <เวอร์ชัน>_<ชื่อ>.up.sql หรือ .down.sql
+var migrationName = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// nameSeparators ตัวอักษรที่ถูกแทนด้วย _ ในชื่อ migration ใหม่
+var nameSeparators = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Migration การเปลี่ยน schema หนึ่งเวอร์ชัน
+type Migration struct {
+	Version int64                                        // เวอร์ชัน (เรียงจากน้อยไปมาก)
+	Name    string                                       // ชื่อที่อ่านเข้าใจได้
+	Up      string                                       // SQL สำหรับ migrate ขึ้น
+	Down    string                                       // SQL สำหรับย้อนกลับ (ว่าง = ย้อนกลับไม่ได้)
+	Data    func(ctx context.Context, tx *gorm.DB) error // ขั้นตอนที่เขียนด้วย Go รันต่อจาก Up (nil = ไม่มี)
+}
+
+// DataMigration ขั้นตอนที่เขียนด้วย Go ของ migration เวอร์ชันหนึ่ง สำหรับงานที่ SQL ทำไม่ได้ (เช่น ตัดคำภาษาไทย)
+// รันใน transaction เดียวกับ SQL ของเวอร์ชันนั้นและภายใต้ advisory lock จึงรันครั้งเดียวต่อฐานข้อมูล
+type DataMigration struct {
+	Version int64
+	Up      func(ctx context.Context, tx *gorm.DB) error
+}
+
+// MigrationStatus สถานะของ migration แต่ละเวอร์ชัน
+type MigrationStatus struct {
+	Version   int64
+	Name      string
+	AppliedAt *time.Time // nil = ยังไม่ได้รัน
+	Missing   bool       // รันแล้วแต่ไม่มีไฟล์ใน binary นี้ (เช่น ถูกรันโดยเวอร์ชันที่ใหม่กว่า)
+}
+
+// Migrator รัน migration ที่ฝังไว้และบันทึกเวอร์ชันที่รันแล้วในตาราง schema_migrations
+type Migrator struct {
+	gorm       *gorm.DB
+	db         *sql.DB
+	migrations []Migration
+}
+
+// NewMigrator สร้าง Migrator จาก connection ของ gorm และไฟล์ migration ที่ฝังไว้
+// data ผูกขั้นตอนที่เขียนด้วย Go เข้ากับเวอร์ชันที่มีไฟล์อยู่แล้ว
+func NewMigrator(db *gorm.DB, data ...DataMigration) (*Migrator, error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	migrations, err := loadMigrations(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	m := &Migrator{gorm: db, db: sqlDB, migrations: migrations}
+	for _, d := range data {
+		i := m.index(d.Version)
+		if i < 0 {
+			return nil, fmt.Errorf("data migration for version %d has no migration file", d.Version)
+		}
+		if m.migrations[i].Data != nil {
+			return nil, fmt.Errorf("migration %d has more than one data migration", d.Version)
+		}
+		m.migrations[i].Data = d.Up
+	}
+	return m, nil
+}
+
+// Up รัน migration ที่ยังไม่ได้รันตามลำดับเวอร์ชัน สูงสุด steps เวอร์ชัน (0 = ทั้งหมด) คืนรายการที่รัน
+func (m *Migrator) Up(ctx context.Context, steps int) ([]Migration, error) {
+	var done []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, mig := range m.migrations {
+			if _, ok := applied[mig.Version]; ok {
+				continue
+			}
+			if steps > 0 && len(done) == steps {
+				break
+			}
+			if err := m.runMigration(ctx, conn, mig.Up, mig.Data,
+				"INSERT INTO schema_migrations (version, name) VALUES ($1, $2)", mig.Version, mig.Name); err != nil {
+				return fmt.Errorf("migration %d_%s: %w", mig.Version, mig.Name, err)
+			}
+			done = append(done, mig)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Down ย้อนกลับ migration ล่าสุดที่รันแล้วทีละเวอร์ชัน จำนวน steps เวอร์ชัน คืนรายการที่ย้อนกลับ
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var done []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		versions := make([]int64, 0, len(applied))
+		for v := range applied {
+			versions = append(versions, v)
+		}
+		sort.Slice(versions, func(i, j int) bool { return versions[i] > versions[j] })
+
+		for _, v := range versions {
+			if len(done) == steps {
+				break
+			}
+			i := m.index(v)
+			if i < 0 {
+				return fmt.Errorf("migration %d is applied but not embedded in this binary", v)
+			}
+			mig := m.migrations[i]
+			if strings.TrimSpace(mig.Down) == "" {
+				return fmt.Errorf("migration %d_%s has no down migration", mig.Version, mig.Name)
+			}
+			if err := m.runMigration(ctx, conn, mig.Down, nil,
+				"DELETE FROM schema_migrations WHERE version = $1", mig.Version); err != nil {
+				return fmt.Errorf("migration %d_%s (down): %w", mig.Version, mig.Name, err)
+			}
+			done = append(done, mig)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Seed รัน fn ใน transaction ภายใต้ advisory lock เดียวกับ migration
+// ใช้กับข้อมูลที่มาจากการตั้งค่าและต้องปรับทุกครั้งที่ migrate (เช่น ผู้ดูแลระบบตาม ADMIN_EMAILS)
+func (m *Migrator) Seed(ctx context.Context, fn func(ctx context.Context, tx *gorm.DB) error) error {
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback() // ไม่มีผลหลัง commit
+
+		if err := fn(ctx, m.session(ctx, tx)); err != nil {
+			return err
+		}
+		return tx.Commit()
+	})
+}
+
+// Status คืนสถานะของทุกเวอร์ชัน ทั้งที่ฝังไว้และที่บันทึกว่ารันแล้ว
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	var statuses []MigrationStatus
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, mig := range m.migrations {
+			s := MigrationStatus{Version: mig.Version, Name: mig.Name}
+			if a, ok := applied[mig.Version]; ok {
+				s.AppliedAt = &a.at
+				delete(applied, mig.Version)
+			}
+			statuses = append(statuses, s)
+		}
+		for v, a := range applied {
+			statuses = append(statuses, MigrationStatus{Version: v, Name: a.name, AppliedAt: &a.at, Missing: true})
+		}
+		sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+		return nil
+	})
+	return statuses, err
+}
+
+// index หาตำแหน่งของ migration ตามเวอร์ชัน (-1 = ไม่มี)
+func (m *Migrator) index(version int64) int {
+	for i, mig := range m.migrations {
+		if mig.Version == version {
+			return i
+		}
+	}
+	return -1
+}
+
+// withLock ถือ advisory lock บน connection เดียวตลอดการทำงาน instance อื่นที่เริ่มพร้อมกันจะรอจนเสร็จ
+// และสร้างตาราง schema_migrations ถ้ายังไม่มี
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationLockID); err != nil {
+		return fmt.Errorf("acquire migration lock: %w", err)
+	}
+	// ปลดล็อกด้วย context ใหม่ เผื่อ ctx ถูกยกเลิกไปแล้ว (ถ้าปลดไม่ได้ lock จะหายเมื่อ connection ปิด)
+	defer conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", migrationLockID)
+
+	if _, err := conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version bigint PRIMARY KEY,
+		name text NOT NULL,
+		applied_at timestamptz NOT NULL DEFAULT now()
+	)`); err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+// appliedMigration เวอร์ชันที่บันทึกว่ารันแล้ว
+type appliedMigration struct {
+	name string
+	at   time.Time
+}
+
+// appliedVersions อ่านเวอร์ชันที่รันแล้วจากตาราง schema_migrations
+func appliedVersions(ctx context.Context, conn *sql.Conn) (map[int64]appliedMigration, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT version, name, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := map[int64]appliedMigration{}
+	for rows.Next() {
+		var v int64
+		var a appliedMigration
+		if err := rows.Scan(&v, &a.name, &a.at); err != nil {
+			return nil, err
+		}
+		applied[v] = a
+	}
+	return applied, rows.Err()
+}
+
+// runMigration รัน SQL ของ migration ตามด้วยขั้นตอน Go (ถ้ามี) และบันทึกผลลง schema_migrations ใน transaction เดียวกัน
+// ไฟล์ที่ขึ้นต้นด้วย -- migrate:no-transaction จะรันนอก transaction แล้วบันทึกผลหลังรันสำเร็จ
+func (m *Migrator) runMigration(ctx context.Context, conn *sql.Conn, script string, data func(context.Context, *gorm.DB) error, record string, args ...any) error {
+	if strings.HasPrefix(strings.TrimSpace(script), noTransactionDirective) {
+		if _, err := conn.ExecContext(ctx, script); err != nil {
+			return err
+		}
+		if data != nil {
+			if err := data(ctx, m.session(ctx, conn)); err != nil {
+				return err
+			}
+		}
+		_, err := conn.ExecContext(ctx, record, args...)
+		return err
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() // ไม่มีผลหลัง commit
+
+	// Exec ที่ไม่มี argument ใช้ simple protocol จึงรันหลายคำสั่งในไฟล์เดียวได้
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return err
+	}
+	if data != nil {
+		if err := data(ctx, m.session(ctx, tx)); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, record, args...); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// session คืน gorm ที่ส่งคำสั่งผ่าน pool ที่ให้มา (connection ที่ถือ lock หรือ transaction ของ migration)
+// transaction ที่เปิดซ้อนข้างในจะใช้ savepoint
+func (m *Migrator) session(ctx context.Context, pool gorm.ConnPool) *gorm.DB {
+	db := m.gorm.Session(&gorm.Session{NewDB: true, Context: ctx})
+	db.Statement.ConnPool = pool
+	return db
+}
+
+// loadMigrations อ่านไฟล์ migration ทั้งหมดในโฟลเดอร์และเรียงตามเวอร์ชัน ทุกเวอร์ชันต้องมีไฟล์ up
+func loadMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		match := migrationName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			if strings.HasSuffix(entry.Name(), ".sql") {
+				return nil, fmt.Errorf("invalid migration file name %q (want <version>_<name>.up.sql or .down.sql)", entry.Name())
+			}
+			continue
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %q: %w", entry.Name(), err)
+		}
+		data, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		mig := byVersion[version]
+		if mig == nil {
+			mig = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = mig
+		} else if mig.Name != match[2] {
+			return nil, fmt.Errorf("migration version %d is used by both %q and %q", version, mig.Name, match[2])
+		}
+		if match[3] == "up" {
+			mig.Up = string(data)
+		} else {
+			mig.Down = string(data)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if mig.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", mig.Version, mig.Name)
+		}
+		migrations = append(migrations, *mig)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// CreateMigration สร้างไฟล์ up/down ว่างในโฟลเดอร์ dir ด้วยเวอร์ชันถัดจากเวอร์ชันล่าสุด คืน path ของทั้งสองไฟล์
+func CreateMigration(dir, name string) (string, string, error) {
+	name = strings.Trim(nameSeparators.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		return "", "", errors.New("migration name is required")
+	}
+	existing, err := loadMigrations(os.DirFS(dir), ".")
+	if err != nil {
+		return "", "", err
+	}
+	var version int64 = 1
+	if n := len(existing); n > 0 {
+		version = existing[n-1].Version + 1
+	}
+
+	base := filepath.Join(dir, fmt.Sprintf("%04d_%s", version, name))
+	up, down := base+".up.sql", base+".down.sql"
+	for file, content := range map[string]string{
+		up:   "-- " + name + "\n",
+		down: "-- revert " + name + "\n",
+	} {
+		// O_EXCL ไม่เขียนทับไฟล์ที่มีอยู่แล้ว
+		f, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err != nil {
+			return "", "", err
+		}
+		_, err = f.WriteString(content)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return "", "", err
+		}
+	}
+	return up, down, nil
+}
@@ -0,0 +1,360 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		want    []Migration
+		wantErr bool
+	}{
+		{
+			name: "sorts by numeric version and pairs up and down files",
+			files: map[string]string{
+				"migrations/0010_ten.up.sql":   "up 10",
+				"migrations/0002_two.down.sql": "down 2",
+				"migrations/0002_two.up.sql":   "up 2",
+				"migrations/0001_one.up.sql":   "up 1",
+				"migrations/README.md":         "ignored",
+			},
+			want: []Migration{
+				{Version: 1, Name: "one", Up: "up 1"},
+				{Version: 2, Name: "two", Up: "up 2", Down: "down 2"},
+				{Version: 10, Name: "ten", Up: "up 10"},
+			},
+		},
+		{
+			name:    "rejects a version without an up file",
+			files:   map[string]string{"migrations/0001_one.down.sql": "down 1"},
+			wantErr: true,
+		},
+		{
+			name: "rejects two names for one version",
+			files: map[string]string{
+				"migrations/0001_one.up.sql":   "up 1",
+				"migrations/0001_other.up.sql": "up 1",
+			},
+			wantErr: true,
+		},
+		{
+			name:    "rejects a malformed sql file name",
+			files:   map[string]string{"migrations/one.up.sql": "up 1"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := fstest.MapFS{}
+			for name, data := range tt.files {
+				fsys[name] = &fstest.MapFile{Data: []byte(data)}
+			}
+
+			got, err := loadMigrations(fsys, "migrations")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadMigrations() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("loadMigrations() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	migrations, err := loadMigrations(migrationFiles, "migrations")
+	if err != nil {
+		t.Fatalf("loadMigrations() error = %v", err)
+	}
+	for i, mig := range migrations {
+		if mig.Version != int64(i+1) {
+			t.Fatalf("migration %d_%s at position %d, want version %d", mig.Version, mig.Name, i, i+1)
+		}
+		if strings.TrimSpace(mig.Down) == "" {
+			t.Fatalf("migration %d_%s has no down file", mig.Version, mig.Name)
+		}
+	}
+}
+
+// migrationState สิ่งที่ฐานข้อมูลจำลองเก็บ: เวอร์ชันที่บันทึกว่ารันแล้วและคำสั่งจาก migration ตามลำดับที่รัน
+type migrationState struct {
+	applied map[int64]string
+	log     []string
+}
+
+func (s migrationState) clone() migrationState {
+	return migrationState{applied: maps.Clone(s.applied), log: slices.Clone(s.log)}
+}
+
+// migrationStore ฐานข้อมูลจำลองที่ connection ทุกตัวใช้ร่วมกัน
+type migrationStore struct {
+	mu    sync.Mutex
+	state migrationState
+}
+
+func (s *migrationStore) committed() migrationState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state.clone()
+}
+
+func (s *migrationStore) commit(state migrationState) {
+	s.mu.Lock()
+	s.state = state
+	s.mu.Unlock()
+}
+
+// migrationConnector สร้าง connection ที่เข้าใจเฉพาะคำสั่งที่ Migrator ใช้
+type migrationConnector struct {
+	store *migrationStore
+}
+
+func (c *migrationConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return &migrationConn{store: c.store}, nil
+}
+
+func (c *migrationConnector) Driver() driver.Driver { return migrationDriver{} }
+
+type migrationDriver struct{}
+
+func (migrationDriver) Open(string) (driver.Conn, error) {
+	return nil, errors.New("use migrationConnector")
+}
+
+// migrationConn จำลอง advisory lock, ตาราง schema_migrations และ transaction
+// คำสั่งอื่นถือเป็นสคริปต์ของ migration และถูกบันทึกลง log (ยกเว้น "fail" ที่คืน error)
+type migrationConn struct {
+	store  *migrationStore
+	locked bool
+	tx     *migrationState // สถานะที่ transaction เห็น (nil = ไม่อยู่ใน transaction)
+}
+
+func (c *migrationConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, fmt.Errorf("prepare is not supported: %q", query)
+}
+
+func (c *migrationConn) Close() error { return nil }
+
+func (c *migrationConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *migrationConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if c.tx != nil {
+		return nil, errors.New("transaction already started")
+	}
+	state := c.store.committed()
+	c.tx = &state
+	return &migrationTx{conn: c}, nil
+}
+
+// update แก้สถานะใน transaction ถ้ามี ไม่เช่นนั้นเขียนลง store ทันที
+func (c *migrationConn) update(fn func(s *migrationState)) {
+	if c.tx != nil {
+		fn(c.tx)
+		return
+	}
+	state := c.store.committed()
+	fn(&state)
+	c.store.commit(state)
+}
+
+func (c *migrationConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	switch {
+	case query == "SELECT pg_advisory_lock($1)":
+		c.locked = true
+	case query == "SELECT pg_advisory_unlock($1)":
+		c.locked = false
+	case !c.locked:
+		return nil, fmt.Errorf("%q runs without the migration lock", query)
+	case strings.HasPrefix(query, "CREATE TABLE IF NOT EXISTS schema_migrations"):
+	case strings.HasPrefix(query, "INSERT INTO schema_migrations"):
+		c.update(func(s *migrationState) { s.applied[args[0].Value.(int64)] = args[1].Value.(string) })
+	case strings.HasPrefix(query, "DELETE FROM schema_migrations"):
+		c.update(func(s *migrationState) { delete(s.applied, args[0].Value.(int64)) })
+	case query == "fail":
+		return nil, errors.New("script failed")
+	default:
+		c.update(func(s *migrationState) { s.log = append(s.log, query) })
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (c *migrationConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if query != "SELECT version, name, applied_at FROM schema_migrations" {
+		return nil, fmt.Errorf("unexpected query %q", query)
+	}
+	state := c.store.committed()
+	if c.tx != nil {
+		state = *c.tx
+	}
+	rows := &appliedRows{}
+	for _, v := range slices.Sorted(maps.Keys(state.applied)) {
+		rows.values = append(rows.values, []driver.Value{v, state.applied[v], time.Unix(0, 0)})
+	}
+	return rows, nil
+}
+
+type migrationTx struct {
+	conn *migrationConn
+}
+
+func (t *migrationTx) Commit() error {
+	t.conn.store.commit(*t.conn.tx)
+	t.conn.tx = nil
+	return nil
+}
+
+func (t *migrationTx) Rollback() error {
+	t.conn.tx = nil
+	return nil
+}
+
+// appliedRows ผลลัพธ์ของการอ่านตาราง schema_migrations
+type appliedRows struct {
+	values [][]driver.Value
+}
+
+func (r *appliedRows) Columns() []string { return []string{"version", "name", "applied_at"} }
+
+func (r *appliedRows) Close() error { return nil }
+
+func (r *appliedRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+// newTestMigrator สร้าง Migrator ที่มี migration 1-3 บนฐานข้อมูลจำลองที่รัน applied ไปแล้ว
+// เวอร์ชัน 2 มีขั้นตอน Go ที่รันคำสั่ง data (เช่น "data 2" หรือ "fail")
+func newTestMigrator(t *testing.T, applied []int64, data string) (*Migrator, *migrationStore) {
+	t.Helper()
+	store := &migrationStore{state: migrationState{applied: map[int64]string{}}}
+	for _, v := range applied {
+		store.state.applied[v] = fmt.Sprint(v)
+	}
+	sqlDB := sql.OpenDB(&migrationConnector{store: store})
+	t.Cleanup(func() { sqlDB.Close() })
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("gorm.Open() error = %v", err)
+	}
+	return &Migrator{gorm: db, db: sqlDB, migrations: []Migration{
+		{Version: 1, Name: "one", Up: "up 1", Down: "down 1"},
+		{Version: 2, Name: "two", Up: "up 2", Down: "down 2", Data: func(ctx context.Context, tx *gorm.DB) error {
+			return tx.Exec(data).Error
+		}},
+		{Version: 3, Name: "three", Up: "up 3", Down: "down 3"},
+	}}, store
+}
+
+func TestMigratorUpDown(t *testing.T) {
+	tests := []struct {
+		name        string
+		applied     []int64
+		data        string
+		down        bool
+		steps       int
+		wantDone    []int64
+		wantLog     []string
+		wantApplied []int64
+		wantErr     bool
+	}{
+		{
+			name:        "up runs pending migrations in version order with the data step after its script",
+			data:        "data 2",
+			wantDone:    []int64{1, 2, 3},
+			wantLog:     []string{"up 1", "up 2", "data 2", "up 3"},
+			wantApplied: []int64{1, 2, 3},
+		},
+		{
+			name:        "up skips applied versions and stops after steps",
+			applied:     []int64{1},
+			data:        "data 2",
+			steps:       1,
+			wantDone:    []int64{2},
+			wantLog:     []string{"up 2", "data 2"},
+			wantApplied: []int64{1, 2},
+		},
+		{
+			name:        "up fills a gap below the latest applied version",
+			applied:     []int64{1, 3},
+			data:        "data 2",
+			wantDone:    []int64{2},
+			wantLog:     []string{"up 2", "data 2"},
+			wantApplied: []int64{1, 2, 3},
+		},
+		{
+			name:        "failed data step rolls back its script and record",
+			applied:     []int64{1},
+			data:        "fail",
+			wantLog:     nil,
+			wantApplied: []int64{1},
+			wantErr:     true,
+		},
+		{
+			name:        "down reverts the latest versions first without the data step",
+			applied:     []int64{1, 2, 3},
+			data:        "data 2",
+			down:        true,
+			steps:       2,
+			wantDone:    []int64{3, 2},
+			wantLog:     []string{"down 3", "down 2"},
+			wantApplied: []int64{1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, store := newTestMigrator(t, tt.applied, tt.data)
+
+			run := m.Up
+			if tt.down {
+				run = m.Down
+			}
+			done, err := run(context.Background(), tt.steps)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			var gotDone []int64
+			for _, mig := range done {
+				gotDone = append(gotDone, mig.Version)
+			}
+			if !reflect.DeepEqual(gotDone, tt.wantDone) {
+				t.Fatalf("done = %v, want %v", gotDone, tt.wantDone)
+			}
+			state := store.committed()
+			if !reflect.DeepEqual(state.log, tt.wantLog) {
+				t.Fatalf("executed = %q, want %q", state.log, tt.wantLog)
+			}
+			if got := slices.Sorted(maps.Keys(state.applied)); !reflect.DeepEqual(got, tt.wantApplied) {
+				t.Fatalf("applied = %v, want %v", got, tt.wantApplied)
+			}
+		})
+	}
+}
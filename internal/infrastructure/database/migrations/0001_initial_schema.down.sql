@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS musics;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,33 @@
+-- Baseline: the users and musics tables as created by GORM AutoMigrate before versioned migrations.
+-- IF NOT EXISTS lets databases created by AutoMigrate record this version without changes;
+-- every later column, index and table is added by its own migration.
+
+CREATE TABLE IF NOT EXISTS users (
+    id bigserial,
+    created_by text DEFAULT 'system',
+    updated_by text DEFAULT 'system',
+    created_at timestamptz,
+    updated_at timestamptz,
+    email text NOT NULL,
+    password text NOT NULL,
+    first_name text,
+    last_name text,
+    image_profile text,
+    PRIMARY KEY (id),
+    CONSTRAINT uni_users_email UNIQUE (email)
+);
+
+CREATE TABLE IF NOT EXISTS musics (
+    id bigserial,
+    created_by text DEFAULT 'system',
+    updated_by text DEFAULT 'system',
+    created_at timestamptz,
+    updated_at timestamptz,
+    title text NOT NULL,
+    artist text NOT NULL,
+    lyrics text,
+    mp3_url text,
+    mp4_url text,
+    image_url text,
+    PRIMARY KEY (id)
+);
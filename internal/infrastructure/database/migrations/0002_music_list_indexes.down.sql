@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_musics_artist;
+DROP INDEX IF EXISTS idx_musics_title;
+DROP INDEX IF EXISTS idx_musics_updated_at;
+DROP INDEX IF EXISTS idx_musics_created_at;
+DROP INDEX IF EXISTS idx_users_updated_at;
+DROP INDEX IF EXISTS idx_users_created_at;
@@ -0,0 +1,7 @@
+-- Indexes for paginating, filtering and sorting GET /music.
+CREATE INDEX IF NOT EXISTS idx_users_created_at ON users (created_at);
+CREATE INDEX IF NOT EXISTS idx_users_updated_at ON users (updated_at);
+CREATE INDEX IF NOT EXISTS idx_musics_created_at ON musics (created_at);
+CREATE INDEX IF NOT EXISTS idx_musics_updated_at ON musics (updated_at);
+CREATE INDEX IF NOT EXISTS idx_musics_title ON musics (title);
+CREATE INDEX IF NOT EXISTS idx_musics_artist ON musics (artist);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_musics_search_vector;
+ALTER TABLE musics DROP COLUMN IF EXISTS search_vector;
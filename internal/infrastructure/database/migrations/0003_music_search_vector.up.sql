@@ -0,0 +1,5 @@
+-- Full-text search column used by GET /music/search. It is filled by the application
+-- (Thai word segmentation happens in Go), so existing rows are backfilled by the Go data step
+-- registered for this version (dataMigrations in internal/app/admin.go).
+ALTER TABLE musics ADD COLUMN IF NOT EXISTS search_vector tsvector;
+CREATE INDEX IF NOT EXISTS idx_musics_search_vector ON musics USING GIN (search_vector);
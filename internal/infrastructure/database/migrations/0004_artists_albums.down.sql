@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS music_artists;
+DROP INDEX IF EXISTS idx_musics_album_id;
+ALTER TABLE musics
+    DROP COLUMN IF EXISTS disc_number,
+    DROP COLUMN IF EXISTS track_number,
+    DROP COLUMN IF EXISTS album_id;
+DROP TABLE IF EXISTS albums;
+DROP TABLE IF EXISTS artists;
@@ -0,0 +1,50 @@
+-- Artist and album records, track numbering and artist credits with roles.
+CREATE TABLE IF NOT EXISTS artists (
+    id bigserial,
+    created_by text DEFAULT 'system',
+    updated_by text DEFAULT 'system',
+    created_at timestamptz,
+    updated_at timestamptz,
+    name text NOT NULL,
+    normalized_name text NOT NULL,
+    bio text,
+    image_url text,
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_artists_normalized_name ON artists (normalized_name);
+CREATE INDEX IF NOT EXISTS idx_artists_updated_at ON artists (updated_at);
+CREATE INDEX IF NOT EXISTS idx_artists_created_at ON artists (created_at);
+
+CREATE TABLE IF NOT EXISTS albums (
+    id bigserial,
+    created_by text DEFAULT 'system',
+    updated_by text DEFAULT 'system',
+    created_at timestamptz,
+    updated_at timestamptz,
+    title text NOT NULL,
+    artist_id bigint,
+    release_date timestamptz,
+    image_url text,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_albums_artist FOREIGN KEY (artist_id) REFERENCES artists(id) ON DELETE SET NULL
+);
+CREATE INDEX IF NOT EXISTS idx_albums_artist_id ON albums (artist_id);
+CREATE INDEX IF NOT EXISTS idx_albums_title ON albums (title);
+CREATE INDEX IF NOT EXISTS idx_albums_updated_at ON albums (updated_at);
+CREATE INDEX IF NOT EXISTS idx_albums_created_at ON albums (created_at);
+
+ALTER TABLE musics
+    ADD COLUMN IF NOT EXISTS album_id bigint CONSTRAINT fk_albums_tracks REFERENCES albums(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS track_number bigint,
+    ADD COLUMN IF NOT EXISTS disc_number bigint DEFAULT 1;
+CREATE INDEX IF NOT EXISTS idx_musics_album_id ON musics (album_id);
+
+CREATE TABLE IF NOT EXISTS music_artists (
+    music_id bigint,
+    artist_id bigint,
+    role varchar(20),
+    position bigint,
+    PRIMARY KEY (music_id,artist_id,role),
+    CONSTRAINT fk_music_artists_artist FOREIGN KEY (artist_id) REFERENCES artists(id) ON DELETE RESTRICT,
+    CONSTRAINT fk_musics_artists FOREIGN KEY (music_id) REFERENCES musics(id) ON DELETE CASCADE
+);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS playlist_collaborators;
+DROP TABLE IF EXISTS playlist_entries;
+DROP TABLE IF EXISTS playlists;
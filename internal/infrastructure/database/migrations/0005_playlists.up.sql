@@ -0,0 +1,44 @@
+-- User playlists with ordered entries, collaborators and share links.
+CREATE TABLE IF NOT EXISTS playlists (
+    id bigserial,
+    created_by text DEFAULT 'system',
+    updated_by text DEFAULT 'system',
+    created_at timestamptz,
+    updated_at timestamptz,
+    owner_id bigint NOT NULL,
+    name text NOT NULL,
+    description text,
+    visibility varchar(20) NOT NULL,
+    share_token text NOT NULL,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_playlists_owner FOREIGN KEY (owner_id) REFERENCES users(id) ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_playlists_share_token ON playlists (share_token);
+CREATE INDEX IF NOT EXISTS idx_playlists_visibility ON playlists (visibility);
+CREATE INDEX IF NOT EXISTS idx_playlists_owner_id ON playlists (owner_id);
+CREATE INDEX IF NOT EXISTS idx_playlists_updated_at ON playlists (updated_at);
+CREATE INDEX IF NOT EXISTS idx_playlists_created_at ON playlists (created_at);
+
+CREATE TABLE IF NOT EXISTS playlist_entries (
+    id bigserial,
+    playlist_id bigint NOT NULL,
+    position bigint NOT NULL,
+    music_id bigint NOT NULL,
+    added_by bigint,
+    created_at timestamptz,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_playlists_entries FOREIGN KEY (playlist_id) REFERENCES playlists(id) ON DELETE CASCADE,
+    CONSTRAINT fk_playlist_entries_music FOREIGN KEY (music_id) REFERENCES musics(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_playlist_entries_music_id ON playlist_entries (music_id);
+CREATE INDEX IF NOT EXISTS idx_playlist_entries_position ON playlist_entries (playlist_id,position);
+
+CREATE TABLE IF NOT EXISTS playlist_collaborators (
+    playlist_id bigint,
+    user_id bigint,
+    created_at timestamptz,
+    PRIMARY KEY (playlist_id,user_id),
+    CONSTRAINT fk_playlist_collaborators_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_playlists_collaborators FOREIGN KEY (playlist_id) REFERENCES playlists(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_playlist_collaborators_user_id ON playlist_collaborators (user_id);
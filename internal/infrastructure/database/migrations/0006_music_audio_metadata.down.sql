@@ -0,0 +1,7 @@
+ALTER TABLE musics
+    DROP COLUMN IF EXISTS genre,
+    DROP COLUMN IF EXISTS year,
+    DROP COLUMN IF EXISTS channels,
+    DROP COLUMN IF EXISTS sample_rate,
+    DROP COLUMN IF EXISTS bitrate,
+    DROP COLUMN IF EXISTS duration;
@@ -0,0 +1,8 @@
+-- Duration, audio format and ID3 tags read from uploaded MP3s.
+ALTER TABLE musics
+    ADD COLUMN IF NOT EXISTS duration decimal,
+    ADD COLUMN IF NOT EXISTS bitrate bigint,
+    ADD COLUMN IF NOT EXISTS sample_rate bigint,
+    ADD COLUMN IF NOT EXISTS channels bigint,
+    ADD COLUMN IF NOT EXISTS year bigint,
+    ADD COLUMN IF NOT EXISTS genre text;
@@ -0,0 +1,22 @@
+-- Presigned direct uploads waiting to be finalized.
+CREATE TABLE IF NOT EXISTS uploads (
+    id varchar(36),
+    owner_id bigint NOT NULL,
+    kind varchar(10) NOT NULL,
+    filename text,
+    content_type text,
+    size bigint,
+    checksum_sha256 text,
+    file_url text,
+    multipart_id text,
+    part_size bigint,
+    status varchar(20) NOT NULL,
+    music_id bigint,
+    expires_at timestamptz,
+    created_at timestamptz,
+    updated_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_uploads_expires_at ON uploads (expires_at);
+CREATE INDEX IF NOT EXISTS idx_uploads_status ON uploads (status);
+CREATE INDEX IF NOT EXISTS idx_uploads_owner_id ON uploads (owner_id);
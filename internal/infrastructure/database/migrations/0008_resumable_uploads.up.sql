@@ -0,0 +1,22 @@
+-- tus resumable uploads.
+CREATE TABLE IF NOT EXISTS resumable_uploads (
+    id varchar(36),
+    owner_id bigint NOT NULL,
+    music_id bigint NOT NULL,
+    kind varchar(10) NOT NULL,
+    filename text,
+    content_type text,
+    length bigint,
+    "offset" bigint,
+    metadata text,
+    file_url text,
+    status varchar(20) NOT NULL,
+    expires_at timestamptz,
+    created_at timestamptz,
+    updated_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_resumable_uploads_expires_at ON resumable_uploads (expires_at);
+CREATE INDEX IF NOT EXISTS idx_resumable_uploads_status ON resumable_uploads (status);
+CREATE INDEX IF NOT EXISTS idx_resumable_uploads_music_id ON resumable_uploads (music_id);
+CREATE INDEX IF NOT EXISTS idx_resumable_uploads_owner_id ON resumable_uploads (owner_id);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_musics_owner_id;
+ALTER TABLE musics DROP COLUMN IF EXISTS owner_id;
+ALTER TABLE users DROP COLUMN IF EXISTS role;
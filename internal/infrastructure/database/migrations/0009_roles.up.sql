@@ -0,0 +1,9 @@
+-- User roles and track owners.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role varchar(20) NOT NULL DEFAULT 'listener';
+ALTER TABLE musics ADD COLUMN IF NOT EXISTS owner_id bigint NOT NULL DEFAULT 0;
+CREATE INDEX IF NOT EXISTS idx_musics_owner_id ON musics (owner_id);
+
+-- Existing tracks are owned by the user whose email created them.
+UPDATE musics SET owner_id = users.id
+FROM users
+WHERE musics.owner_id = 0 AND musics.created_by = users.email;
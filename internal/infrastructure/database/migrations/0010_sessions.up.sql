@@ -0,0 +1,25 @@
+-- Login sessions and their rotated refresh tokens.
+CREATE TABLE IF NOT EXISTS sessions (
+    id varchar(36),
+    user_id bigint NOT NULL,
+    user_agent text,
+    ip text,
+    created_at timestamptz,
+    last_used_at timestamptz,
+    expires_at timestamptz,
+    revoked_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_sessions_revoked_at ON sessions (revoked_at);
+CREATE INDEX IF NOT EXISTS idx_sessions_expires_at ON sessions (expires_at);
+CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions (user_id);
+
+CREATE TABLE IF NOT EXISTS session_tokens (
+    token_hash varchar(64),
+    session_id varchar(36) NOT NULL,
+    used_at timestamptz,
+    created_at timestamptz,
+    PRIMARY KEY (token_hash),
+    CONSTRAINT fk_session_tokens_session FOREIGN KEY (session_id) REFERENCES sessions(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_session_tokens_session_id ON session_tokens (session_id);
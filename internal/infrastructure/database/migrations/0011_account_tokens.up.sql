@@ -0,0 +1,14 @@
+-- Password reset and email verification tokens.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified_at timestamptz;
+
+CREATE TABLE IF NOT EXISTS account_tokens (
+    token_hash varchar(64),
+    user_id bigint NOT NULL,
+    purpose varchar(32) NOT NULL,
+    expires_at timestamptz NOT NULL,
+    used_at timestamptz,
+    created_at timestamptz,
+    PRIMARY KEY (token_hash)
+);
+CREATE INDEX IF NOT EXISTS idx_account_tokens_expires_at ON account_tokens (expires_at);
+CREATE INDEX IF NOT EXISTS idx_account_tokens_user_id ON account_tokens (user_id);
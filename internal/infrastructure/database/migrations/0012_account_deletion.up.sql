@@ -0,0 +1,14 @@
+-- Soft-deleted accounts and the queue of files to delete after them.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deleted_at timestamptz;
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
+
+CREATE TABLE IF NOT EXISTS media_deletions (
+    id bigserial,
+    file_url text NOT NULL,
+    not_before timestamptz NOT NULL,
+    attempts bigint,
+    last_error text,
+    created_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_media_deletions_not_before ON media_deletions (not_before);
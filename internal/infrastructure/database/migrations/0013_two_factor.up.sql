@@ -0,0 +1,33 @@
+-- TOTP two-factor authentication, recovery codes and pending login challenges.
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS mfa boolean;
+
+CREATE TABLE IF NOT EXISTS two_factors (
+    user_id bigint NOT NULL,
+    secret text NOT NULL,
+    confirmed_at timestamptz,
+    last_step bigint,
+    created_at timestamptz,
+    updated_at timestamptz,
+    PRIMARY KEY (user_id)
+);
+
+CREATE TABLE IF NOT EXISTS recovery_codes (
+    id bigserial,
+    user_id bigint NOT NULL,
+    code_hash varchar(64) NOT NULL,
+    used_at timestamptz,
+    created_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_recovery_codes_user_id ON recovery_codes (user_id);
+
+CREATE TABLE IF NOT EXISTS login_challenges (
+    token_hash varchar(64),
+    user_id bigint NOT NULL,
+    attempts bigint,
+    expires_at timestamptz NOT NULL,
+    created_at timestamptz,
+    PRIMARY KEY (token_hash)
+);
+CREATE INDEX IF NOT EXISTS idx_login_challenges_expires_at ON login_challenges (expires_at);
+CREATE INDEX IF NOT EXISTS idx_login_challenges_user_id ON login_challenges (user_id);
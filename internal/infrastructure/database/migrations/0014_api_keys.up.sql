@@ -0,0 +1,15 @@
+-- Scoped API keys.
+CREATE TABLE IF NOT EXISTS api_keys (
+    id bigserial,
+    user_id bigint NOT NULL,
+    name text NOT NULL,
+    prefix varchar(16) NOT NULL,
+    key_hash varchar(64) NOT NULL,
+    scopes text NOT NULL,
+    expires_at timestamptz,
+    last_used_at timestamptz,
+    created_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_api_keys_key_hash ON api_keys (key_hash);
+CREATE INDEX IF NOT EXISTS idx_api_keys_user_id ON api_keys (user_id);
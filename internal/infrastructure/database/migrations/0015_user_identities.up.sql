@@ -0,0 +1,24 @@
+-- OpenID Connect identities linked to users and pending login states.
+CREATE TABLE IF NOT EXISTS user_identities (
+    id bigserial,
+    user_id bigint NOT NULL,
+    provider varchar(50) NOT NULL,
+    subject text NOT NULL,
+    email text,
+    created_at timestamptz,
+    last_login_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_identity_provider_subject ON user_identities (provider,subject);
+CREATE INDEX IF NOT EXISTS idx_user_identities_user_id ON user_identities (user_id);
+
+CREATE TABLE IF NOT EXISTS o_auth_states (
+    state_hash varchar(64),
+    provider varchar(50) NOT NULL,
+    nonce text NOT NULL,
+    code_verifier text NOT NULL,
+    expires_at timestamptz NOT NULL,
+    created_at timestamptz,
+    PRIMARY KEY (state_hash)
+);
+CREATE INDEX IF NOT EXISTS idx_o_auth_states_expires_at ON o_auth_states (expires_at);
@@ -0,0 +1,12 @@
+-- Content-addressed media files with reference counts.
+CREATE TABLE IF NOT EXISTS media_objects (
+    sha256 char(64),
+    file_url text NOT NULL,
+    size bigint,
+    content_type text,
+    ref_count bigint NOT NULL DEFAULT 0,
+    created_at timestamptz,
+    updated_at timestamptz,
+    PRIMARY KEY (sha256)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_media_objects_file_url ON media_objects (file_url);
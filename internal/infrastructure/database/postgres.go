@@ -2,16 +2,13 @@ package database // ประกาศ package database
 
 import (
 	"fmt" // นำเข้า package fmt สำหรับจัดรูปแบบข้อความ
-	"log" // นำเข้า package log สำหรับแสดงข้อความ log
 	"os"  // นำเข้า package os สำหรับอ่าน environment variables
 
-	"go-music-api/internal/domain" // นำเข้า domain entities เพื่อใช้ในการ migrate
-
 	"gorm.io/driver/postgres" // นำเข้า postgres driver สำหรับ gorm
 	"gorm.io/gorm"            // นำเข้า gorm ORM
 )
 
-// NewPostgresDB สร้างการเชื่อมต่อกับฐานข้อมูล Postgres
+// NewPostgresDB สร้างการเชื่อมต่อกับฐานข้อมูล Postgres (schema จัดการด้วย Migrator ไม่ได้ migrate อัตโนมัติ)
 func NewPostgresDB() (*gorm.DB, error) {
 	// ถ้ามี DATABASE_URL (เช่น บน Render) ให้ใช้เป็น DSN โดยตรง
 	dsn := os.Getenv("DATABASE_URL")
@@ -28,21 +25,13 @@ func NewPostgresDB() (*gorm.DB, error) {
 	}
 
 	// เปิดการเชื่อมต่อกับฐานข้อมูลโดยใช้ gorm
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	// TranslateError ให้ gorm แปลง error ของ Postgres เป็น gorm.ErrDuplicatedKey / gorm.ErrForeignKeyViolated
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{TranslateError: true})
 	if err != nil {
 		// ถ้าเชื่อมต่อไม่สำเร็จ ส่งค่า nil และ error กลับไป
 		return nil, err
 	}
 
-	// Auto Migrate
-	// ทำการ migrate schema อัตโนมัติสำหรับ User และ Music
-	err = db.AutoMigrate(&domain.User{}, &domain.Music{})
-	if err != nil {
-		// ถ้า migrate ไม่สำเร็จ ให้ log error และส่ง error กลับไป
-		log.Printf("Failed to auto migrate: %v", err)
-		return nil, err
-	}
-
 	// ส่งคืน connection ของฐานข้อมูล
 	return db, nil
 }
@@ -0,0 +1,49 @@
+package mailer // ประกาศ package mailer
+
+import (
+	"context" // นำเข้า context
+	"fmt"     // นำเข้า fmt
+	"log"     // นำเข้า log
+	"os"      // นำเข้า os สำหรับเขียนไฟล์
+	"sync"    // นำเข้า sync สำหรับกันการเขียนไฟล์พร้อมกัน
+	"time"    // นำเข้า time
+
+	"go-music-api/internal/domain" // นำเข้า domain entities
+)
+
+// LogMailer ไม่ส่งอีเมลจริง แต่เขียนอีเมลลง log หรือต่อท้ายไฟล์ (สำหรับพัฒนาในเครื่อง)
+type LogMailer struct {
+	from string     // ที่อยู่ผู้ส่ง
+	path string     // ไฟล์ที่ใช้เก็บอีเมล (ว่าง = เขียนลง log)
+	mu   sync.Mutex // ป้องกันการเขียนไฟล์พร้อมกัน
+}
+
+// NewLogMailer สร้าง instance ของ LogMailer
+func NewLogMailer(from, path string) *LogMailer {
+	return &LogMailer{from: from, path: path}
+}
+
+// Send เขียนอีเมลลง log หรือไฟล์
+func (m *LogMailer) Send(ctx context.Context, msg domain.Mail) error {
+	// ตรวจ header แบบเดียวกับการส่งจริง แต่เก็บเนื้อหาแบบอ่านง่าย (ไม่เข้ารหัส) เพื่อคัดลอกลิงก์ได้
+	if _, err := buildMessage(m.from, msg); err != nil {
+		return err
+	}
+	text := fmt.Sprintf("From: %s\nTo: %s\nSubject: %s\nDate: %s\n\n%s\n", m.from, msg.To, msg.Subject, time.Now().Format(time.RFC1123Z), msg.Body)
+	if m.path == "" {
+		log.Printf("mail (not sent, MAIL_DRIVER=log)\n%s", text)
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	f, err := os.OpenFile(m.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(text + "\n"); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,42 @@
+package mailer // ประกาศ package mailer
+
+import (
+	"bytes"                // นำเข้า bytes
+	"errors"               // นำเข้า errors
+	"fmt"                  // นำเข้า fmt
+	"mime"                 // นำเข้า mime สำหรับเข้ารหัสหัวเรื่องที่ไม่ใช่ ASCII
+	"mime/quotedprintable" // นำเข้า quotedprintable สำหรับเนื้อหาอีเมล
+	"net/mail"             // นำเข้า net/mail สำหรับตรวจรูปแบบอีเมล
+	"strings"              // นำเข้า strings
+	"time"                 // นำเข้า time
+
+	"go-music-api/internal/domain" // นำเข้า domain entities
+)
+
+// buildMessage สร้างอีเมลรูปแบบ RFC 5322 (text/plain, UTF-8)
+func buildMessage(from string, m domain.Mail) ([]byte, error) {
+	if strings.ContainsAny(m.To+m.Subject, "\r\n") {
+		return nil, errors.New("mail headers must not contain line breaks")
+	}
+	if _, err := mail.ParseAddress(m.To); err != nil {
+		return nil, fmt.Errorf("invalid recipient: %w", err)
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", m.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", m.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+
+	w := quotedprintable.NewWriter(&buf)
+	if _, err := w.Write([]byte(strings.ReplaceAll(m.Body, "\n", "\r\n"))); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,103 @@
+package mailer // ประกาศ package mailer
+
+import (
+	"context"    // นำเข้า context
+	"crypto/tls" // นำเข้า tls สำหรับเชื่อมต่อแบบเข้ารหัส
+	"errors"     // นำเข้า errors
+	"net"        // นำเข้า net
+	"net/mail"   // นำเข้า net/mail สำหรับอ่านที่อยู่ผู้ส่ง
+	"net/smtp"   // นำเข้า smtp
+	"strconv"    // นำเข้า strconv
+
+	"go-music-api/internal/domain" // นำเข้า domain entities
+)
+
+// SMTPMailer ส่งอีเมลผ่าน SMTP server
+// port 465 ใช้ TLS ตั้งแต่เริ่มเชื่อมต่อ ส่วน port อื่นจะอัปเกรดด้วย STARTTLS เมื่อ server รองรับ
+type SMTPMailer struct {
+	host     string    // ชื่อ host ของ SMTP server
+	addr     string    // host:port
+	from     string    // ที่อยู่ผู้ส่ง (เช่น "Music <no-reply@example.com>")
+	auth     smtp.Auth // ข้อมูลเข้าสู่ระบบ (nil ถ้าไม่ต้องใช้)
+	implicit bool      // ใช้ TLS ตั้งแต่เริ่มเชื่อมต่อ
+}
+
+// NewSMTPMailer สร้าง instance ของ SMTPMailer
+func NewSMTPMailer(host string, port int, username, password, from string) (*SMTPMailer, error) {
+	if host == "" {
+		return nil, errors.New("SMTP host is required")
+	}
+	if _, err := mail.ParseAddress(from); err != nil {
+		return nil, err
+	}
+	m := &SMTPMailer{
+		host:     host,
+		addr:     net.JoinHostPort(host, strconv.Itoa(port)),
+		from:     from,
+		implicit: port == 465,
+	}
+	if username != "" {
+		// PlainAuth ไม่ยอมส่งรหัสผ่านผ่านการเชื่อมต่อที่ไม่เข้ารหัส (ยกเว้น localhost)
+		m.auth = smtp.PlainAuth("", username, password, host)
+	}
+	return m, nil
+}
+
+// Send ส่งอีเมลหนึ่งฉบับ
+func (m *SMTPMailer) Send(ctx context.Context, msg domain.Mail) error {
+	data, err := buildMessage(m.from, msg)
+	if err != nil {
+		return err
+	}
+	sender, _ := mail.ParseAddress(m.from)
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", m.addr)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	tlsConfig := &tls.Config{ServerName: m.host}
+	if m.implicit {
+		conn = tls.Client(conn, tlsConfig)
+	}
+
+	c, err := smtp.NewClient(conn, m.host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if !m.implicit {
+		if ok, _ := c.Extension("STARTTLS"); ok {
+			if err := c.StartTLS(tlsConfig); err != nil {
+				return err
+			}
+		}
+	}
+	if m.auth != nil {
+		if err := c.Auth(m.auth); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(sender.Address); err != nil {
+		return err
+	}
+	if err := c.Rcpt(msg.To); err != nil {
+		return err
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
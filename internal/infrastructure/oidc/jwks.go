@@ -0,0 +1,81 @@
+package oidc // ประกาศ package oidc
+
+import (
+	"crypto/ecdsa"    // นำเข้า ecdsa สำหรับ ES256/ES384/ES512
+	"crypto/elliptic" // นำเข้า elliptic สำหรับ curve ของ EC key
+	"crypto/rsa"      // นำเข้า rsa สำหรับ RS256
+	"encoding/base64" // นำเข้า base64 สำหรับค่าใน JWK
+	"fmt"             // นำเข้า fmt
+	"math/big"        // นำเข้า big สำหรับแปลงตัวเลขใน JWK
+)
+
+// jwk public key หนึ่งตัวใน JWKS ของผู้ให้บริการ
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// jwkSet รูปแบบของ jwks_uri
+type jwkSet struct {
+	Keys []jwk `json:"keys"`
+}
+
+// decodeInt แปลงค่า base64url ใน JWK เป็นตัวเลข
+func decodeInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, fmt.Errorf("invalid JWK number %q", s)
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// publicKey แปลง JWK เป็น public key (รองรับ RSA และ EC)
+func (k jwk) publicKey() (any, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("EC point is not on curve %s", k.Crv)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
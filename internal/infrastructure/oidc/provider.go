@@ -0,0 +1,322 @@
+package oidc // ประกาศ package oidc
+
+import (
+	"context"       // นำเข้า context
+	"encoding/json" // นำเข้า json สำหรับอ่าน discovery, token และ JWKS
+	"errors"        // นำเข้า errors
+	"fmt"           // นำเข้า fmt
+	"io"            // นำเข้า io
+	"net/http"      // นำเข้า net/http สำหรับเรียกผู้ให้บริการ
+	"net/url"       // นำเข้า url สำหรับสร้าง URL และ form
+	"slices"        // นำเข้า slices
+	"strings"       // นำเข้า strings
+	"sync"          // นำเข้า sync สำหรับ cache
+	"time"          // นำเข้า time
+
+	"go-music-api/internal/domain" // นำเข้า domain entities
+
+	"github.com/golang-jwt/jwt/v5" // นำเข้า jwt สำหรับตรวจ ID token
+)
+
+const (
+	// jwksRefreshInterval ดึง JWKS ใหม่เมื่อเจอ kid ที่ไม่รู้จักได้ไม่เกินครั้งละเท่านี้ (ผู้ให้บริการหมุน key)
+	jwksRefreshInterval = time.Minute
+	// clockLeeway ยอมให้นาฬิกาของผู้ให้บริการคลาดเคลื่อนได้
+	clockLeeway = time.Minute
+	// maxResponseSize ขนาดสูงสุดของคำตอบจากผู้ให้บริการ
+	maxResponseSize = 1 << 20
+)
+
+// Config การตั้งค่าผู้ให้บริการหนึ่งราย
+type Config struct {
+	Name         string   // ชื่อที่ใช้ใน URL เช่น google, line, keycloak
+	Issuer       string   // ต้องตรงกับ issuer ใน discovery และ claim "iss"
+	ClientID     string   // client ID ที่ลงทะเบียนไว้
+	ClientSecret string   // client secret (ไม่มีได้สำหรับ public client ที่ใช้ PKCE อย่างเดียว)
+	RedirectURL  string   // callback URL ที่ลงทะเบียนไว้กับผู้ให้บริการ
+	Scopes       []string // ต้องมี openid
+	// TrustEmail ถือว่าอีเมลยืนยันแล้วแม้ไม่มี claim email_verified (สำหรับผู้ให้บริการที่ไม่ส่ง claim นี้ แต่ยืนยันอีเมลเอง)
+	TrustEmail bool
+}
+
+// discovery ข้อมูลจาก /.well-known/openid-configuration ที่ใช้
+type discovery struct {
+	Issuer            string   `json:"issuer"`
+	AuthorizationURL  string   `json:"authorization_endpoint"`
+	TokenURL          string   `json:"token_endpoint"`
+	JWKSURI           string   `json:"jwks_uri"`
+	TokenAuthMethods  []string `json:"token_endpoint_auth_methods_supported"`
+	SigningAlgorithms []string `json:"id_token_signing_alg_values_supported"`
+}
+
+// Provider ผู้ให้บริการ OpenID Connect (อ่าน discovery ครั้งแรกที่ใช้งาน และ cache JWKS)
+type Provider struct {
+	cfg    Config
+	client *http.Client
+
+	mu          sync.Mutex
+	meta        *discovery
+	keys        map[string]any // public key ตาม kid
+	keysFetched time.Time
+}
+
+// NewProvider สร้าง Provider (ยังไม่เรียกผู้ให้บริการจนกว่าจะมีการเข้าสู่ระบบ)
+func NewProvider(cfg Config, client *http.Client) (*Provider, error) {
+	if cfg.Name == "" || cfg.Issuer == "" || cfg.ClientID == "" || cfg.RedirectURL == "" {
+		return nil, errors.New("name, issuer, client ID and redirect URL are required")
+	}
+	if !slices.Contains(cfg.Scopes, "openid") {
+		cfg.Scopes = append([]string{"openid"}, cfg.Scopes...)
+	}
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Provider{cfg: cfg, client: client}, nil
+}
+
+// Name คืนชื่อของผู้ให้บริการ
+func (p *Provider) Name() string {
+	return p.cfg.Name
+}
+
+// getJSON เรียก GET และอ่านคำตอบแบบ JSON
+func (p *Provider) getJSON(ctx context.Context, endpoint string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	return p.do(req, v)
+}
+
+// do ส่ง request และอ่านคำตอบแบบ JSON (status ที่ไม่ใช่ 2xx ถือเป็น error)
+func (p *Provider) do(req *http.Request, v any) error {
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s %s: %s: %s", req.Method, req.URL.Redacted(), resp.Status, strings.TrimSpace(string(body)))
+	}
+	return json.Unmarshal(body, v)
+}
+
+// discover อ่าน discovery document ครั้งแรกที่ใช้งาน (ถ้าล้มเหลวจะลองใหม่ในครั้งถัดไป)
+func (p *Provider) discover(ctx context.Context) (*discovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.meta != nil {
+		return p.meta, nil
+	}
+	var meta discovery
+	if err := p.getJSON(ctx, strings.TrimSuffix(p.cfg.Issuer, "/")+"/.well-known/openid-configuration", &meta); err != nil {
+		return nil, fmt.Errorf("oidc %s discovery: %w", p.cfg.Name, err)
+	}
+	if meta.Issuer != p.cfg.Issuer {
+		return nil, fmt.Errorf("oidc %s: discovery issuer %q does not match %q", p.cfg.Name, meta.Issuer, p.cfg.Issuer)
+	}
+	if meta.AuthorizationURL == "" || meta.TokenURL == "" || meta.JWKSURI == "" {
+		return nil, fmt.Errorf("oidc %s: discovery document is missing endpoints", p.cfg.Name)
+	}
+	p.meta = &meta
+	return p.meta, nil
+}
+
+// AuthCodeURL สร้าง URL สำหรับส่งผู้ใช้ไปเข้าสู่ระบบ (authorization code + PKCE S256)
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error) {
+	meta, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	u, err := url.Parse(meta.AuthorizationURL)
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	q.Set("response_type", "code")
+	q.Set("client_id", p.cfg.ClientID)
+	q.Set("redirect_uri", p.cfg.RedirectURL)
+	q.Set("scope", strings.Join(p.cfg.Scopes, " "))
+	q.Set("state", state)
+	q.Set("nonce", nonce)
+	q.Set("code_challenge", codeChallenge)
+	q.Set("code_challenge_method", "S256")
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// tokenResponse คำตอบจาก token endpoint
+type tokenResponse struct {
+	IDToken   string `json:"id_token"`
+	TokenType string `json:"token_type"`
+}
+
+// exchange แลก authorization code เป็น token
+func (p *Provider) exchange(ctx context.Context, meta *discovery, code, codeVerifier string) (*tokenResponse, error) {
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.cfg.RedirectURL)
+	form.Set("code_verifier", codeVerifier)
+	form.Set("client_id", p.cfg.ClientID)
+
+	// ส่ง secret ใน body เมื่อผู้ให้บริการรองรับ ไม่อย่างนั้นใช้ HTTP Basic ซึ่งเป็นค่าเริ่มต้นของ OIDC
+	useBasic := p.cfg.ClientSecret != "" && !slices.Contains(meta.TokenAuthMethods, "client_secret_post")
+	if p.cfg.ClientSecret != "" && !useBasic {
+		form.Set("client_secret", p.cfg.ClientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, meta.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if useBasic {
+		req.SetBasicAuth(url.QueryEscape(p.cfg.ClientID), url.QueryEscape(p.cfg.ClientSecret))
+	}
+
+	var tok tokenResponse
+	if err := p.do(req, &tok); err != nil {
+		return nil, fmt.Errorf("oidc %s token exchange: %w", p.cfg.Name, err)
+	}
+	if tok.IDToken == "" {
+		return nil, fmt.Errorf("oidc %s: token response has no id_token", p.cfg.Name)
+	}
+	return &tok, nil
+}
+
+// key คืน public key ตาม kid ดึง JWKS ใหม่ถ้ายังไม่รู้จัก kid นั้น
+func (p *Provider) key(ctx context.Context, meta *discovery, kid string) (any, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if k, ok := p.keys[kid]; ok {
+		return k, nil
+	}
+	if time.Since(p.keysFetched) < jwksRefreshInterval {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	var set jwkSet
+	if err := p.getJSON(ctx, meta.JWKSURI, &set); err != nil {
+		return nil, fmt.Errorf("oidc %s jwks: %w", p.cfg.Name, err)
+	}
+	keys := make(map[string]any)
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		pub, err := k.publicKey()
+		if err != nil {
+			continue // ข้าม key ที่ไม่รองรับ
+		}
+		keys[k.Kid] = pub
+	}
+	p.keys, p.keysFetched = keys, time.Now()
+
+	if k, ok := keys[kid]; ok {
+		return k, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// idTokenClaims claim ของ ID token ที่ใช้
+type idTokenClaims struct {
+	jwt.RegisteredClaims
+	Nonce         string `json:"nonce"`
+	AuthorizedFor string `json:"azp"`
+	Email         string `json:"email"`
+	EmailVerified any    `json:"email_verified"` // บางผู้ให้บริการส่งเป็นข้อความ "true"
+	Name          string `json:"name"`
+	GivenName     string `json:"given_name"`
+	FamilyName    string `json:"family_name"`
+	Picture       string `json:"picture"`
+}
+
+// verified อ่าน email_verified ทั้งแบบ boolean และข้อความ
+func (c *idTokenClaims) verified() bool {
+	switch v := c.EmailVerified.(type) {
+	case bool:
+		return v
+	case string:
+		return v == "true"
+	}
+	return false
+}
+
+// verifyIDToken ตรวจลายเซ็น issuer audience อายุ และ nonce ของ ID token
+func (p *Provider) verifyIDToken(ctx context.Context, meta *discovery, raw, nonce string) (*idTokenClaims, error) {
+	// HS256 ใช้ client secret เป็น key (เช่น LINE Login บนเว็บ) อัลกอริทึมอื่นใช้ key จาก JWKS
+	methods := []string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512", "PS256"}
+	if p.cfg.ClientSecret != "" {
+		methods = append(methods, "HS256")
+	}
+
+	claims := &idTokenClaims{}
+	_, err := jwt.ParseWithClaims(raw, claims, func(t *jwt.Token) (any, error) {
+		if t.Method.Alg() == "HS256" {
+			return []byte(p.cfg.ClientSecret), nil
+		}
+		kid, _ := t.Header["kid"].(string)
+		return p.key(ctx, meta, kid)
+	},
+		jwt.WithValidMethods(methods),
+		jwt.WithIssuer(p.cfg.Issuer),
+		jwt.WithAudience(p.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(clockLeeway),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("oidc %s id_token: %w", p.cfg.Name, err)
+	}
+	if claims.Nonce != nonce {
+		return nil, fmt.Errorf("oidc %s id_token: nonce mismatch", p.cfg.Name)
+	}
+	// token ที่ออกให้หลาย audience ต้องระบุว่าออกให้ client นี้
+	if len(claims.Audience) > 1 && claims.AuthorizedFor != p.cfg.ClientID {
+		return nil, fmt.Errorf("oidc %s id_token: azp %q does not match client", p.cfg.Name, claims.AuthorizedFor)
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("oidc %s id_token: missing sub", p.cfg.Name)
+	}
+	return claims, nil
+}
+
+// Authenticate แลก code เป็น token ตรวจ ID token และคืนข้อมูลผู้ใช้
+func (p *Provider) Authenticate(ctx context.Context, code, codeVerifier, nonce string) (*domain.ExternalIdentity, error) {
+	meta, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tok, err := p.exchange(ctx, meta, code, codeVerifier)
+	if err != nil {
+		return nil, err
+	}
+	claims, err := p.verifyIDToken(ctx, meta, tok.IDToken, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	identity := &domain.ExternalIdentity{
+		Provider:      p.cfg.Name,
+		Subject:       claims.Subject,
+		Email:         strings.TrimSpace(claims.Email),
+		EmailVerified: claims.Email != "" && (claims.verified() || p.cfg.TrustEmail),
+		FirstName:     claims.GivenName,
+		LastName:      claims.FamilyName,
+		Picture:       claims.Picture,
+	}
+	if identity.FirstName == "" && identity.LastName == "" {
+		identity.FirstName = claims.Name
+	}
+	return identity, nil
+}
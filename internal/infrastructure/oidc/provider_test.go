@@ -0,0 +1,353 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testClientID = "music-api"
+	testKid      = "key-1"
+	testCode     = "auth-code"
+	testNonce    = "nonce-123"
+)
+
+// stubIdP ผู้ให้บริการจำลองที่ให้ discovery, JWKS และ token endpoint
+type stubIdP struct {
+	t         *testing.T
+	srv       *httptest.Server
+	key       *rsa.PrivateKey
+	challenge string // code_challenge จาก URL เข้าสู่ระบบ
+	idToken   string // id_token ที่ token endpoint คืน
+}
+
+func newStubIdP(t *testing.T) *stubIdP {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idp := &stubIdP{t: t, key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{
+			"issuer":                 idp.srv.URL,
+			"authorization_endpoint": idp.srv.URL + "/authorize",
+			"token_endpoint":         idp.srv.URL + "/token",
+			"jwks_uri":               idp.srv.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("GET /jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": testKid,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		// ตรวจ PKCE แบบเดียวกับผู้ให้บริการจริง: S256(code_verifier) ต้องตรงกับ code_challenge
+		sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+		if r.PostForm.Get("grant_type") != "authorization_code" ||
+			r.PostForm.Get("code") != testCode ||
+			r.PostForm.Get("client_id") != testClientID ||
+			base64.RawURLEncoding.EncodeToString(sum[:]) != idp.challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"id_token": idp.idToken, "token_type": "Bearer"})
+	})
+	idp.srv = httptest.NewServer(mux)
+	t.Cleanup(idp.srv.Close)
+	return idp
+}
+
+// claims claim ที่ถูกต้องของ ID token สำหรับ client ทดสอบ
+func (idp *stubIdP) claims() jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss":            idp.srv.URL,
+		"sub":            "user-42",
+		"aud":            testClientID,
+		"exp":            now.Add(time.Hour).Unix(),
+		"iat":            now.Unix(),
+		"nonce":          testNonce,
+		"email":          "alice@example.com",
+		"email_verified": true,
+		"given_name":     "Alice",
+		"family_name":    "Liddell",
+	}
+}
+
+// sign ลงนาม ID token ด้วย key และ kid ที่กำหนด
+func (idp *stubIdP) sign(claims jwt.MapClaims, key *rsa.PrivateKey, kid string) string {
+	idp.t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	raw, err := token.SignedString(key)
+	if err != nil {
+		idp.t.Fatal(err)
+	}
+	return raw
+}
+
+// begin สร้าง Provider และเริ่มเข้าสู่ระบบด้วย verifier เพื่อให้ stub จำ code_challenge
+func (idp *stubIdP) begin(verifier string) *Provider {
+	idp.t.Helper()
+	p, err := NewProvider(Config{
+		Name:        "stub",
+		Issuer:      idp.srv.URL,
+		ClientID:    testClientID,
+		RedirectURL: "http://localhost/callback",
+	}, idp.srv.Client())
+	if err != nil {
+		idp.t.Fatal(err)
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	authURL, err := p.AuthCodeURL(context.Background(), "state", testNonce, base64.RawURLEncoding.EncodeToString(sum[:]))
+	if err != nil {
+		idp.t.Fatal(err)
+	}
+	u, err := url.Parse(authURL)
+	if err != nil {
+		idp.t.Fatal(err)
+	}
+	if got := u.Query().Get("code_challenge_method"); got != "S256" {
+		idp.t.Fatalf("code_challenge_method = %q, want S256", got)
+	}
+	idp.challenge = u.Query().Get("code_challenge")
+	return p
+}
+
+func TestAuthenticate(t *testing.T) {
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		verifier string // verifier ที่ส่งตอนแลก code (ต่างจากตอนเริ่ม = PKCE ไม่ผ่าน)
+		nonce    string
+		token    func(idp *stubIdP) string
+		wantErr  string
+	}{
+		{
+			name:  "valid",
+			token: func(idp *stubIdP) string { return idp.sign(idp.claims(), idp.key, testKid) },
+		},
+		{
+			name:     "wrong PKCE verifier",
+			verifier: "another-verifier",
+			token:    func(idp *stubIdP) string { return idp.sign(idp.claims(), idp.key, testKid) },
+			wantErr:  "invalid_grant",
+		},
+		{
+			name:    "bad signature",
+			token:   func(idp *stubIdP) string { return idp.sign(idp.claims(), otherKey, testKid) },
+			wantErr: "signature is invalid",
+		},
+		{
+			name:    "unknown kid",
+			token:   func(idp *stubIdP) string { return idp.sign(idp.claims(), idp.key, "key-2") },
+			wantErr: "unknown signing key",
+		},
+		{
+			name: "HS256 without client secret",
+			token: func(idp *stubIdP) string {
+				raw, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, idp.claims()).SignedString([]byte(""))
+				return raw
+			},
+			wantErr: "signing method HS256 is invalid",
+		},
+		{
+			name: "wrong issuer",
+			token: func(idp *stubIdP) string {
+				c := idp.claims()
+				c["iss"] = "https://evil.example.com"
+				return idp.sign(c, idp.key, testKid)
+			},
+			wantErr: "invalid issuer",
+		},
+		{
+			name: "wrong audience",
+			token: func(idp *stubIdP) string {
+				c := idp.claims()
+				c["aud"] = "another-client"
+				return idp.sign(c, idp.key, testKid)
+			},
+			wantErr: "invalid audience",
+		},
+		{
+			name: "expired",
+			token: func(idp *stubIdP) string {
+				c := idp.claims()
+				c["exp"] = time.Now().Add(-time.Hour).Unix()
+				return idp.sign(c, idp.key, testKid)
+			},
+			wantErr: "token is expired",
+		},
+		{
+			name:    "wrong nonce",
+			nonce:   "nonce-other",
+			token:   func(idp *stubIdP) string { return idp.sign(idp.claims(), idp.key, testKid) },
+			wantErr: "nonce mismatch",
+		},
+		{
+			name: "missing nonce",
+			token: func(idp *stubIdP) string {
+				c := idp.claims()
+				delete(c, "nonce")
+				return idp.sign(c, idp.key, testKid)
+			},
+			wantErr: "nonce mismatch",
+		},
+		{
+			name: "multiple audiences without azp",
+			token: func(idp *stubIdP) string {
+				c := idp.claims()
+				c["aud"] = []string{testClientID, "another-client"}
+				return idp.sign(c, idp.key, testKid)
+			},
+			wantErr: "azp",
+		},
+		{
+			name: "multiple audiences with azp of another client",
+			token: func(idp *stubIdP) string {
+				c := idp.claims()
+				c["aud"] = []string{testClientID, "another-client"}
+				c["azp"] = "another-client"
+				return idp.sign(c, idp.key, testKid)
+			},
+			wantErr: "azp",
+		},
+		{
+			name: "multiple audiences with azp of this client",
+			token: func(idp *stubIdP) string {
+				c := idp.claims()
+				c["aud"] = []string{testClientID, "another-client"}
+				c["azp"] = testClientID
+				return idp.sign(c, idp.key, testKid)
+			},
+		},
+		{
+			name: "missing sub",
+			token: func(idp *stubIdP) string {
+				c := idp.claims()
+				delete(c, "sub")
+				return idp.sign(c, idp.key, testKid)
+			},
+			wantErr: "missing sub",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idp := newStubIdP(t)
+			p := idp.begin("verifier-abc")
+			idp.idToken = tt.token(idp)
+
+			verifier := "verifier-abc"
+			if tt.verifier != "" {
+				verifier = tt.verifier
+			}
+			nonce := testNonce
+			if tt.nonce != "" {
+				nonce = tt.nonce
+			}
+
+			ext, err := p.Authenticate(context.Background(), testCode, verifier, nonce)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Authenticate() error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Authenticate() error = %v", err)
+			}
+			if ext.Provider != "stub" || ext.Subject != "user-42" || ext.Email != "alice@example.com" || !ext.EmailVerified {
+				t.Fatalf("Authenticate() = %+v", ext)
+			}
+			if ext.FirstName != "Alice" || ext.LastName != "Liddell" {
+				t.Fatalf("Authenticate() name = %q %q", ext.FirstName, ext.LastName)
+			}
+		})
+	}
+}
+
+func TestAuthenticateEmailVerified(t *testing.T) {
+	tests := []struct {
+		name       string
+		verified   any // nil = ไม่มี claim email_verified
+		trustEmail bool
+		want       bool
+	}{
+		{name: "boolean true", verified: true, want: true},
+		{name: "string true", verified: "true", want: true},
+		{name: "false", verified: false, want: false},
+		{name: "missing", verified: nil, want: false},
+		{name: "missing but trusted provider", verified: nil, trustEmail: true, want: true},
+		{name: "false but trusted provider", verified: false, trustEmail: true, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idp := newStubIdP(t)
+			p := idp.begin("verifier-abc")
+			p.cfg.TrustEmail = tt.trustEmail
+
+			c := idp.claims()
+			delete(c, "email_verified")
+			if tt.verified != nil {
+				c["email_verified"] = tt.verified
+			}
+			idp.idToken = idp.sign(c, idp.key, testKid)
+
+			ext, err := p.Authenticate(context.Background(), testCode, "verifier-abc", testNonce)
+			if err != nil {
+				t.Fatalf("Authenticate() error = %v", err)
+			}
+			if ext.EmailVerified != tt.want {
+				t.Fatalf("EmailVerified = %v, want %v", ext.EmailVerified, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiscoveryIssuerMismatch(t *testing.T) {
+	idp := newStubIdP(t)
+	p, err := NewProvider(Config{
+		Name:        "stub",
+		Issuer:      idp.srv.URL + "/",
+		ClientID:    testClientID,
+		RedirectURL: "http://localhost/callback",
+	}, idp.srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.AuthCodeURL(context.Background(), "state", testNonce, "challenge"); err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Fatalf("AuthCodeURL() error = %v, want issuer mismatch", err)
+	}
+}
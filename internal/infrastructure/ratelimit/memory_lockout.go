@@ -0,0 +1,102 @@
+package ratelimit // ประกาศ package ratelimit
+
+import (
+	"context" // นำเข้า context
+	"sync"    // นำเข้า sync สำหรับ mutex
+	"time"    // นำเข้า time
+)
+
+// lockoutForget ระยะเวลาที่ไม่มีการเข้าสู่ระบบผิดก่อนตัวนับถูกล้าง
+const lockoutForget = 24 * time.Hour
+
+// failures ประวัติการเข้าสู่ระบบผิดของ key หนึ่ง
+type failures struct {
+	count       int
+	lastFailure time.Time
+	lockedUntil time.Time
+}
+
+// MemoryLockout ล็อกการเข้าสู่ระบบแบบเพิ่มขึ้นเรื่อย ๆ เก็บในหน่วยความจำของ process
+// ผิดครบ threshold ครั้งจะถูกล็อก base และนานขึ้นเท่าตัวทุกครั้งที่ผิดต่อ (ไม่เกิน maxLock)
+type MemoryLockout struct {
+	mu        sync.Mutex
+	entries   map[string]*failures
+	threshold int
+	base      time.Duration
+	maxLock   time.Duration
+	now       func() time.Time
+}
+
+// NewMemoryLockout สร้าง instance ของ MemoryLockout
+func NewMemoryLockout(threshold int, base, maxLock time.Duration) *MemoryLockout {
+	return &MemoryLockout{
+		entries:   make(map[string]*failures),
+		threshold: threshold,
+		base:      base,
+		maxLock:   maxLock,
+		now:       time.Now,
+	}
+}
+
+// Locked คืนเวลาที่ key ยังถูกล็อกอยู่
+func (l *MemoryLockout) Locked(_ context.Context, key string) (time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	f, ok := l.entries[key]
+	if !ok {
+		return 0, nil
+	}
+	if wait := f.lockedUntil.Sub(l.now()); wait > 0 {
+		return wait, nil
+	}
+	return 0, nil
+}
+
+// Fail บันทึกการเข้าสู่ระบบผิดและล็อกเมื่อผิดครบ threshold
+func (l *MemoryLockout) Fail(_ context.Context, key string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	f, ok := l.entries[key]
+	if !ok || now.Sub(f.lastFailure) > lockoutForget {
+		f = &failures{}
+		l.entries[key] = f
+	}
+	f.count++
+	f.lastFailure = now
+	if over := f.count - l.threshold; over >= 0 {
+		lock := l.base
+		for i := 0; i < over && lock < l.maxLock; i++ {
+			lock *= 2
+		}
+		f.lockedUntil = now.Add(min(lock, l.maxLock))
+	}
+	return nil
+}
+
+// Reset ล้างตัวนับของ key
+func (l *MemoryLockout) Reset(_ context.Context, key string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.entries, key)
+	return nil
+}
+
+// Prune ลบ key ที่ไม่ได้ถูกล็อกและไม่มีการเข้าสู่ระบบผิดมานาน
+func (l *MemoryLockout) Prune(context.Context) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	removed := 0
+	for key, f := range l.entries {
+		if now.Sub(f.lastFailure) > lockoutForget && !now.Before(f.lockedUntil) {
+			delete(l.entries, key)
+			removed++
+		}
+	}
+	return removed, nil
+}
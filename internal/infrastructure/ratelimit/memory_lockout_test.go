@@ -0,0 +1,132 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryLockout(t *testing.T) {
+	// ผิดครบ 3 ครั้งล็อก 1 นาที และเพิ่มเท่าตัวทุกครั้งที่ผิดต่อ ไม่เกิน 3 นาที
+	const threshold, base, maxLock = 3, time.Minute, 3 * time.Minute
+
+	type step struct {
+		advance    time.Duration // เวลาที่ผ่านไปก่อนทำ op
+		op         string        // "fail", "reset" หรือ "" (ตรวจอย่างเดียว)
+		wantLocked time.Duration // ค่าจาก Locked หลังทำ op
+	}
+
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "locks after threshold failures",
+			steps: []step{
+				{op: "fail"},
+				{op: "fail"},
+				{op: "fail", wantLocked: base},
+				{advance: 20 * time.Second, wantLocked: 40 * time.Second},
+			},
+		},
+		{
+			name: "lock expires after its duration",
+			steps: []step{
+				{op: "fail"},
+				{op: "fail"},
+				{op: "fail", wantLocked: base},
+				{advance: base - time.Second, wantLocked: time.Second},
+				{advance: time.Second},
+			},
+		},
+		{
+			name: "each further failure doubles the lock up to the maximum",
+			steps: []step{
+				{op: "fail"},
+				{op: "fail"},
+				{op: "fail", wantLocked: base},
+				{advance: base, op: "fail", wantLocked: 2 * base},
+				{advance: 2 * base, op: "fail", wantLocked: maxLock},
+				{advance: maxLock, op: "fail", wantLocked: maxLock},
+			},
+		},
+		{
+			name: "reset clears the counter",
+			steps: []step{
+				{op: "fail"},
+				{op: "fail"},
+				{op: "reset"},
+				{op: "fail"},
+				{op: "fail"},
+				{op: "fail", wantLocked: base},
+			},
+		},
+		{
+			name: "failures are forgotten after a quiet day",
+			steps: []step{
+				{op: "fail"},
+				{op: "fail"},
+				{advance: lockoutForget + time.Second, op: "fail"},
+				{op: "fail"},
+				{op: "fail", wantLocked: base},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			clock := &fakeClock{t: time.Unix(1_700_000_000, 0)}
+			l := NewMemoryLockout(threshold, base, maxLock)
+			l.now = clock.now
+
+			for i, s := range tt.steps {
+				clock.advance(s.advance)
+				switch s.op {
+				case "fail":
+					if err := l.Fail(ctx, "alice@example.com"); err != nil {
+						t.Fatalf("step %d: Fail() error = %v", i, err)
+					}
+				case "reset":
+					if err := l.Reset(ctx, "alice@example.com"); err != nil {
+						t.Fatalf("step %d: Reset() error = %v", i, err)
+					}
+				}
+				got, err := l.Locked(ctx, "alice@example.com")
+				if err != nil {
+					t.Fatalf("step %d: Locked() error = %v", i, err)
+				}
+				if got != s.wantLocked {
+					t.Fatalf("step %d: Locked() = %v, want %v", i, got, s.wantLocked)
+				}
+			}
+			if got, _ := l.Locked(ctx, "bob@example.com"); got != 0 {
+				t.Fatalf("Locked() for another email = %v, want 0", got)
+			}
+		})
+	}
+}
+
+func TestMemoryLockoutPrune(t *testing.T) {
+	ctx := context.Background()
+	clock := &fakeClock{t: time.Unix(1_700_000_000, 0)}
+	l := NewMemoryLockout(2, 2*lockoutForget, 2*lockoutForget)
+	l.now = clock.now
+
+	_ = l.Fail(ctx, "locked")
+	_ = l.Fail(ctx, "locked")
+	_ = l.Fail(ctx, "stale")
+
+	// หลังหนึ่งวัน key ที่ไม่ได้ถูกล็อกถูกลบ ส่วน key ที่ยังถูกล็อกต้องอยู่จนหมดเวลาล็อก
+	clock.advance(lockoutForget + time.Second)
+	if n, _ := l.Prune(ctx); n != 1 {
+		t.Fatalf("Prune() = %d, want 1", n)
+	}
+	if got, _ := l.Locked(ctx, "locked"); got == 0 {
+		t.Fatal("Prune() removed a locked key")
+	}
+	clock.advance(lockoutForget)
+	if n, _ := l.Prune(ctx); n != 1 {
+		t.Fatalf("Prune() after the lock expired = %d, want 1", n)
+	}
+}
@@ -0,0 +1,80 @@
+package ratelimit // ประกาศ package ratelimit
+
+import (
+	"context" // นำเข้า context
+	"math"    // นำเข้า math สำหรับปัดเศษ token
+	"sync"    // นำเข้า sync สำหรับ mutex
+	"time"    // นำเข้า time
+
+	"go-music-api/internal/domain" // นำเข้า domain entities
+)
+
+// bucket token bucket ของ key หนึ่ง
+type bucket struct {
+	tokens  float64   // token ที่เหลือ ณ เวลา updated
+	updated time.Time // เวลาที่คำนวณ tokens ล่าสุด
+	full    time.Time // เวลาที่ bucket จะเต็ม (หลังจากนั้นลบทิ้งได้โดยไม่เปลี่ยนผล)
+}
+
+// MemoryStore เก็บ token bucket ในหน่วยความจำของ process (ใช้ได้เมื่อรัน instance เดียว)
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	now     func() time.Time
+}
+
+// NewMemoryStore สร้าง instance ของ MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]*bucket), now: time.Now}
+}
+
+// Take ใช้ token หนึ่งตัวจาก bucket ของ key (bucket ใหม่เริ่มเต็ม และเติมคืนด้วยอัตรา Requests ต่อ Period)
+func (s *MemoryStore) Take(_ context.Context, key string, limit domain.RateLimit) (domain.RateLimitResult, error) {
+	if !limit.Enabled() {
+		return domain.RateLimitResult{Allowed: true}, nil
+	}
+	capacity := float64(limit.Requests)
+	perToken := limit.Period / time.Duration(limit.Requests) // เวลาที่ใช้เติม token หนึ่งตัว
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity, updated: now}
+		s.buckets[key] = b
+	}
+	if elapsed := now.Sub(b.updated); elapsed > 0 {
+		b.tokens = math.Min(capacity, b.tokens+float64(elapsed)/float64(perToken))
+		b.updated = now
+	}
+
+	res := domain.RateLimitResult{}
+	if b.tokens >= 1 {
+		b.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = time.Duration((1 - b.tokens) * float64(perToken))
+	}
+	res.Remaining = int(b.tokens)
+	res.Reset = time.Duration((capacity - b.tokens) * float64(perToken))
+	b.full = now.Add(res.Reset)
+	return res, nil
+}
+
+// Prune ลบ bucket ที่เติมเต็มแล้ว (ไม่ได้ใช้มานานพอ) เพื่อไม่ให้หน่วยความจำโตตามจำนวน client
+func (s *MemoryStore) Prune(context.Context) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	removed := 0
+	for key, b := range s.buckets {
+		if !now.Before(b.full) {
+			delete(s.buckets, key)
+			removed++
+		}
+	}
+	return removed, nil
+}
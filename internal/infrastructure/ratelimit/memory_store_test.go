@@ -0,0 +1,139 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-music-api/internal/domain"
+)
+
+// fakeClock นาฬิกาที่เดินเมื่อเรียก advance เท่านั้น
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func TestMemoryStoreTake(t *testing.T) {
+	limit := domain.RateLimit{Requests: 3, Period: 3 * time.Second} // เติม token หนึ่งตัวทุกวินาที
+
+	type take struct {
+		advance  time.Duration // เวลาที่ผ่านไปก่อน Take
+		want     domain.RateLimitResult
+		otherKey bool // ใช้ bucket ของ key อื่น
+	}
+	allowed := func(remaining int, reset time.Duration) domain.RateLimitResult {
+		return domain.RateLimitResult{Allowed: true, Remaining: remaining, Reset: reset}
+	}
+	denied := func(retryAfter, reset time.Duration) domain.RateLimitResult {
+		return domain.RateLimitResult{RetryAfter: retryAfter, Reset: reset}
+	}
+
+	tests := []struct {
+		name  string
+		takes []take
+	}{
+		{
+			name: "a new bucket allows a burst of Requests then denies",
+			takes: []take{
+				{want: allowed(2, time.Second)},
+				{want: allowed(1, 2*time.Second)},
+				{want: allowed(0, 3*time.Second)},
+				{want: denied(time.Second, 3*time.Second)},
+			},
+		},
+		{
+			name: "tokens refill at Requests per Period",
+			takes: []take{
+				{want: allowed(2, time.Second)},
+				{want: allowed(1, 2*time.Second)},
+				{want: allowed(0, 3*time.Second)},
+				{advance: 500 * time.Millisecond, want: denied(500*time.Millisecond, 2500*time.Millisecond)},
+				{advance: 500 * time.Millisecond, want: allowed(0, 3*time.Second)},
+				{advance: 2 * time.Second, want: allowed(1, 2*time.Second)},
+			},
+		},
+		{
+			name: "an idle bucket refills only up to the burst size",
+			takes: []take{
+				{want: allowed(2, time.Second)},
+				{advance: time.Hour, want: allowed(2, time.Second)},
+				{want: allowed(1, 2*time.Second)},
+				{want: allowed(0, 3*time.Second)},
+				{want: denied(time.Second, 3*time.Second)},
+			},
+		},
+		{
+			name: "keys have separate buckets",
+			takes: []take{
+				{want: allowed(2, time.Second)},
+				{want: allowed(1, 2*time.Second)},
+				{want: allowed(0, 3*time.Second)},
+				{otherKey: true, want: allowed(2, time.Second)},
+				{want: denied(time.Second, 3*time.Second)},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := &fakeClock{t: time.Unix(1_700_000_000, 0)}
+			s := NewMemoryStore()
+			s.now = clock.now
+
+			for i, tk := range tt.takes {
+				clock.advance(tk.advance)
+				key := "a"
+				if tk.otherKey {
+					key = "b"
+				}
+				got, err := s.Take(context.Background(), key, limit)
+				if err != nil {
+					t.Fatalf("take %d: Take() error = %v", i, err)
+				}
+				if got != tk.want {
+					t.Fatalf("take %d: Take() = %+v, want %+v", i, got, tk.want)
+				}
+			}
+		})
+	}
+}
+
+func TestMemoryStoreDisabledLimit(t *testing.T) {
+	s := NewMemoryStore()
+	for i := 0; i < 5; i++ {
+		got, err := s.Take(context.Background(), "a", domain.RateLimit{})
+		if err != nil || !got.Allowed {
+			t.Fatalf("Take() = %+v, %v, want allowed", got, err)
+		}
+	}
+}
+
+func TestMemoryStorePrune(t *testing.T) {
+	limit := domain.RateLimit{Requests: 2, Period: 2 * time.Second}
+	clock := &fakeClock{t: time.Unix(1_700_000_000, 0)}
+	s := NewMemoryStore()
+	s.now = clock.now
+
+	for _, key := range []string{"a", "b", "b"} {
+		if _, err := s.Take(context.Background(), key, limit); err != nil {
+			t.Fatalf("Take() error = %v", err)
+		}
+	}
+
+	// a เต็มหลัง 1 วินาที ส่วน b ต้องรอ 2 วินาที
+	clock.advance(time.Second)
+	if n, _ := s.Prune(context.Background()); n != 1 {
+		t.Fatalf("Prune() = %d, want 1", n)
+	}
+	clock.advance(time.Second)
+	if n, _ := s.Prune(context.Background()); n != 1 {
+		t.Fatalf("Prune() = %d, want 1", n)
+	}
+	if len(s.buckets) != 0 {
+		t.Fatalf("buckets left = %d, want 0", len(s.buckets))
+	}
+}
@@ -0,0 +1,96 @@
+package storage // ประกาศ package storage
+
+import (
+	"context"       // นำเข้า context
+	"io"            // นำเข้า io
+	"os"            // นำเข้า os
+	"path/filepath" // นำเข้า filepath
+
+	"go-music-api/internal/domain" // นำเข้า domain entities
+)
+
+// LocalChunkStore พักข้อมูลของการอัปโหลดแบบต่อได้ไว้ในโฟลเดอร์ในเครื่อง (ไฟล์ละหนึ่งการอัปโหลด)
+type LocalChunkStore struct {
+	Dir string // โฟลเดอร์สำหรับพักข้อมูล
+}
+
+// NewLocalChunkStore สร้าง instance ของ LocalChunkStore และสร้างโฟลเดอร์ถ้ายังไม่มี
+func NewLocalChunkStore(dir string) (*LocalChunkStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &LocalChunkStore{Dir: dir}, nil
+}
+
+// Create สร้างไฟล์พักข้อมูลเปล่า
+func (s *LocalChunkStore) Create(ctx context.Context, id string) error {
+	f, err := os.OpenFile(s.path(id), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		if os.IsExist(err) {
+			return domain.ErrConflict
+		}
+		return err
+	}
+	return f.Close()
+}
+
+// Append เขียนข้อมูลต่อจาก offset โดยตัดข้อมูลที่เกิน offset ทิ้งก่อน (เช่น chunk ที่ค้างจากครั้งก่อน)
+// คืนจำนวนไบต์ที่เขียนได้แม้จะเกิด error ระหว่างอ่าน body
+func (s *LocalChunkStore) Append(ctx context.Context, id string, offset int64, r io.Reader, limit int64) (int64, error) {
+	f, err := os.OpenFile(s.path(id), os.O_WRONLY, 0)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, domain.ErrNotFound
+		}
+		return 0, err
+	}
+	defer f.Close()
+
+	if err := f.Truncate(offset); err != nil {
+		return 0, err
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return 0, err
+	}
+	n, err := io.Copy(f, io.LimitReader(r, limit))
+	if serr := f.Sync(); err == nil {
+		err = serr
+	}
+	return n, err
+}
+
+// Truncate ตัดข้อมูลที่เกิน size ทิ้ง
+func (s *LocalChunkStore) Truncate(ctx context.Context, id string, size int64) error {
+	if err := os.Truncate(s.path(id), size); err != nil {
+		if os.IsNotExist(err) {
+			return domain.ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// Open เปิดไฟล์ที่พักไว้เพื่ออ่าน
+func (s *LocalChunkStore) Open(ctx context.Context, id string) (domain.StagedFile, error) {
+	f, err := os.Open(s.path(id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return f, nil
+}
+
+// Remove ลบไฟล์ที่พักไว้ (ไม่ถือเป็น error ถ้าไม่มีไฟล์)
+func (s *LocalChunkStore) Remove(ctx context.Context, id string) error {
+	if err := os.Remove(s.path(id)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// path ตำแหน่งไฟล์พักข้อมูลของการอัปโหลด
+func (s *LocalChunkStore) path(id string) string {
+	return filepath.Join(s.Dir, filepath.Base(id)+".bin")
+}
@@ -0,0 +1,9 @@
+package storage // ประกาศ package storage
+
+import "encoding/hex" // นำเข้า hex
+
+// contentName ชื่อไฟล์ตาม SHA-256 ของเนื้อหาเท่านั้น (ไม่รวมนามสกุล)
+// เนื้อหาเดียวกันที่อัปโหลดด้วยนามสกุลต่างกันจึงได้ไฟล์เดียวกัน ไม่เกิดไฟล์ซ้ำที่ไม่มีใครอ้างถึง
+func contentName(sum []byte) string {
+	return hex.EncodeToString(sum)
+}
@@ -0,0 +1,219 @@
+package storage // ประกาศ package storage
+
+import (
+	"context"       // นำเข้า context
+	"crypto/sha256" // นำเข้า sha256 สำหรับสร้าง ETag ของแต่ละส่วน
+	"encoding/hex"  // นำเข้า hex
+	"errors"        // นำเข้า errors
+	"io"            // นำเข้า io
+	"net/url"       // นำเข้า url สำหรับสร้าง query ที่ลงลายเซ็น
+	"os"            // นำเข้า os
+	"path/filepath" // นำเข้า filepath
+	"strconv"       // นำเข้า strconv
+	"time"          // นำเข้า time
+
+	"go-music-api/internal/domain" // นำเข้า domain entities
+
+	"github.com/google/uuid" // นำเข้า uuid
+)
+
+// LocalUploadPath path ของ endpoint ที่รับไฟล์จาก URL อัปโหลดที่ลงลายเซ็น (ต่อท้ายด้วยชื่อไฟล์)
+const LocalUploadPath = "/api/v1/uploads/local/"
+
+// ขนาดสูงสุดของแต่ละส่วนและจำนวนส่วนสูงสุด (เท่ากับข้อจำกัดของ S3)
+const (
+	maxLocalPartSize = 5 << 30
+	maxLocalParts    = 10000
+)
+
+// CreateUpload จองชื่อไฟล์ และสร้างโฟลเดอร์เก็บส่วนต่างๆ ถ้าเป็น multipart upload
+func (s *LocalStorage) CreateUpload(ctx context.Context, filename, contentType string, multipart bool) (*domain.UploadTarget, error) {
+	target := &domain.UploadTarget{FileURL: localURLPrefix + newLocalName(filename)}
+	if !multipart {
+		return target, nil
+	}
+	target.MultipartID = uuid.New().String()
+	if err := os.MkdirAll(s.partsDir(target.MultipartID), 0755); err != nil {
+		return nil, err
+	}
+	return target, nil
+}
+
+// PresignPut สร้าง URL สำหรับ PUT ไฟล์ทั้งไฟล์ (ขนาดถูกลงลายเซ็นไว้ใน URL)
+func (s *LocalStorage) PresignPut(ctx context.Context, target *domain.UploadTarget, contentType, checksum string, size int64, ttl time.Duration) (*domain.PresignedRequest, error) {
+	q := url.Values{}
+	q.Set("size", strconv.FormatInt(size, 10))
+	return &domain.PresignedRequest{
+		Method:  "PUT",
+		URL:     s.signPath(LocalUploadPath+filepath.Base(target.FileURL), q, ttl),
+		Headers: map[string]string{"Content-Type": contentType},
+	}, nil
+}
+
+// PresignPart สร้าง URL สำหรับ PUT ส่วนที่ partNumber ของ multipart upload
+func (s *LocalStorage) PresignPart(ctx context.Context, target *domain.UploadTarget, partNumber int, ttl time.Duration) (*domain.PresignedRequest, error) {
+	q := url.Values{}
+	q.Set("upload_id", target.MultipartID)
+	q.Set("part", strconv.Itoa(partNumber))
+	return &domain.PresignedRequest{
+		Method: "PUT",
+		URL:    s.signPath(LocalUploadPath+filepath.Base(target.FileURL), q, ttl),
+	}, nil
+}
+
+// ReceiveUpload รับข้อมูลจาก URL อัปโหลดที่ลงลายเซ็น และคืน ETag ของข้อมูลที่ได้รับ
+func (s *LocalStorage) ReceiveUpload(ctx context.Context, name string, query url.Values, body io.Reader) (string, error) {
+	if err := s.VerifySignedURL(LocalUploadPath+name, query); err != nil {
+		return "", err
+	}
+	if name != filepath.Base(name) || name == "" || name[0] == '.' {
+		return "", domain.ErrInvalidInput
+	}
+
+	// อัปโหลดเป็นส่วนของ multipart upload
+	if uploadID := query.Get("upload_id"); uploadID != "" {
+		part, err := strconv.Atoi(query.Get("part"))
+		if err != nil || part < 1 || part > maxLocalParts {
+			return "", domain.ErrInvalidInput
+		}
+		if _, err := uuid.Parse(uploadID); err != nil {
+			return "", domain.ErrInvalidInput
+		}
+		dir := s.partsDir(uploadID)
+		if _, err := os.Stat(dir); err != nil {
+			return "", domain.ErrNotFound
+		}
+		etag, _, err := writeAtomically(filepath.Join(dir, partName(part)), body, maxLocalPartSize)
+		return etag, err
+	}
+
+	// อัปโหลดทั้งไฟล์ ขนาดต้องตรงกับที่ลงลายเซ็นไว้
+	size, err := strconv.ParseInt(query.Get("size"), 10, 64)
+	if err != nil || size < 0 {
+		return "", domain.ErrInvalidInput
+	}
+	dst := filepath.Join(s.UploadDir, name)
+	if _, err := os.Stat(dst); err == nil {
+		return "", domain.ErrConflict
+	}
+	etag, written, err := writeAtomically(dst, body, size)
+	if err != nil {
+		return "", err
+	}
+	if written != size {
+		os.Remove(dst)
+		return "", domain.ErrInvalidInput
+	}
+	return etag, nil
+}
+
+// CompleteMultipart รวมส่วนต่างๆ ตามลำดับเป็นไฟล์เดียว โดยตรวจ ETag ของทุกส่วน
+func (s *LocalStorage) CompleteMultipart(ctx context.Context, target *domain.UploadTarget, parts []domain.CompletedPart) error {
+	if len(parts) == 0 {
+		return domain.ErrInvalidInput
+	}
+	dir := s.partsDir(target.MultipartID)
+	dst := s.localPath(target.FileURL)
+	tmp := dst + ".tmp"
+
+	out, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	err = func() error {
+		defer out.Close()
+		prev := 0
+		for _, p := range parts {
+			if p.PartNumber <= prev {
+				return domain.ErrInvalidInput
+			}
+			prev = p.PartNumber
+			if err := appendPart(out, filepath.Join(dir, partName(p.PartNumber)), p.ETag); err != nil {
+				return err
+			}
+		}
+		return out.Close()
+	}()
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, dst); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.RemoveAll(dir)
+}
+
+// AbortUpload ลบส่วนที่อัปโหลดค้างไว้และไฟล์ปลายทาง
+func (s *LocalStorage) AbortUpload(ctx context.Context, target *domain.UploadTarget) error {
+	if target.MultipartID != "" {
+		if err := os.RemoveAll(s.partsDir(target.MultipartID)); err != nil {
+			return err
+		}
+	}
+	if err := os.Remove(s.localPath(target.FileURL)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// partsDir โฟลเดอร์เก็บส่วนต่างๆ ของ multipart upload
+func (s *LocalStorage) partsDir(uploadID string) string {
+	return filepath.Join(s.UploadDir, ".parts", filepath.Base(uploadID))
+}
+
+// partName ชื่อไฟล์ของส่วนที่ n
+func partName(n int) string {
+	return strconv.Itoa(n) + ".part"
+}
+
+// appendPart ต่อข้อมูลของส่วนหนึ่งลงท้ายไฟล์ หลังตรวจว่า ETag ตรงกัน
+func appendPart(out io.Writer, path, etag string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return domain.ErrInvalidInput
+		}
+		return err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(out, h), f); err != nil {
+		return err
+	}
+	if `"`+hex.EncodeToString(h.Sum(nil))+`"` != etag {
+		return domain.ErrInvalidInput
+	}
+	return nil
+}
+
+// writeAtomically เขียนข้อมูลลงไฟล์ชั่วคราวแล้วเปลี่ยนชื่อ คืน ETag (SHA-256) และจำนวนไบต์ที่เขียน
+// ถ้าข้อมูลเกิน limit จะไม่บันทึกและคืน ErrInvalidInput
+func writeAtomically(dst string, body io.Reader, limit int64) (string, int64, error) {
+	tmp := dst + ".tmp-" + uuid.New().String()
+	f, err := os.Create(tmp)
+	if err != nil {
+		return "", 0, err
+	}
+	h := sha256.New()
+	written, err := io.Copy(io.MultiWriter(f, h), io.LimitReader(body, limit+1))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil && written > limit {
+		err = domain.ErrInvalidInput
+	}
+	if err == nil {
+		err = os.Rename(tmp, dst)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			err = domain.ErrInvalidInput
+		}
+		return "", 0, err
+	}
+	return `"` + hex.EncodeToString(h.Sum(nil)) + `"`, written, nil
+}
@@ -1,32 +1,52 @@
 package storage // ประกาศ package storage
 
 import (
-	"context"        // นำเข้า context
-	"fmt"            // นำเข้า fmt สำหรับจัดรูปแบบข้อความ
-	"io"             // นำเข้า io สำหรับการคัดลอกข้อมูลไฟล์
-	"mime/multipart" // นำเข้า multipart สำหรับจัดการไฟล์อัปโหลด
-	"os"             // นำเข้า os สำหรับจัดการไฟล์และโฟลเดอร์ในระบบ
-	"path/filepath"  // นำเข้า filepath สำหรับจัดการ path ของไฟล์
-	"time"           // นำเข้า time
+	"context"         // นำเข้า context
+	"crypto/hmac"     // นำเข้า hmac สำหรับลงลายเซ็น URL
+	"crypto/sha256"   // นำเข้า sha256 สำหรับลายเซ็นและ hash ของเนื้อหาไฟล์
+	"encoding/base64" // นำเข้า base64 สำหรับเข้ารหัสลายเซ็นใน URL
+	"encoding/hex"    // นำเข้า hex สำหรับชื่อไฟล์ตาม hash
+	"errors"          // นำเข้า errors
+	"fmt"             // นำเข้า fmt สำหรับจัดรูปแบบข้อความ
+	"io"              // นำเข้า io สำหรับการคัดลอกข้อมูลไฟล์
+	"io/fs"           // นำเข้า fs สำหรับตรวจว่าไฟล์มีอยู่แล้วหรือไม่
+	"mime"            // นำเข้า mime สำหรับเดาชนิดไฟล์จากนามสกุล
+	"mime/multipart"  // นำเข้า multipart สำหรับจัดการไฟล์อัปโหลด
+	"net/url"         // นำเข้า url สำหรับสร้าง query string
+	"os"              // นำเข้า os สำหรับจัดการไฟล์และโฟลเดอร์ในระบบ
+	"path/filepath"   // นำเข้า filepath สำหรับจัดการ path ของไฟล์
+	"strconv"         // นำเข้า strconv
+	"strings"         // นำเข้า strings
+	"time"            // นำเข้า time
+
+	"go-music-api/internal/domain" // นำเข้า domain entities
 
 	"github.com/google/uuid" // นำเข้า uuid สำหรับสร้างชื่อไฟล์ที่ไม่ซ้ำกัน
 )
 
+// localURLPrefix path ที่ใช้เข้าถึงไฟล์ที่เก็บในเครื่อง
+const localURLPrefix = "/uploads/"
+
 // LocalStorage struct เก็บค่า configuration สำหรับการเก็บไฟล์ในเครื่อง
 type LocalStorage struct {
-	UploadDir string // โฟลเดอร์ที่จะเก็บไฟล์
-	BaseURL   string // URL พื้นฐานสำหรับเข้าถึงไฟล์
+	UploadDir  string // โฟลเดอร์ที่จะเก็บไฟล์
+	BaseURL    string // URL พื้นฐานสำหรับเข้าถึงไฟล์
+	signingKey []byte // key สำหรับลงลายเซ็น URL (HMAC-SHA256)
 }
 
 // NewLocalStorage สร้าง instance ของ LocalStorage และตรวจสอบ/สร้างโฟลเดอร์
-func NewLocalStorage(uploadDir, baseURL string) (*LocalStorage, error) {
+func NewLocalStorage(uploadDir, baseURL string, signingKey []byte) (*LocalStorage, error) {
 	// สร้างโฟลเดอร์ uploadDir ถ้ายังไม่มีอยู่ (0755 คือ permission)
 	if err := os.MkdirAll(uploadDir, 0755); err != nil {
 		return nil, err
 	}
+	if len(signingKey) == 0 {
+		return nil, errors.New("local storage: signing key is required")
+	}
 	return &LocalStorage{
-		UploadDir: uploadDir,
-		BaseURL:   baseURL,
+		UploadDir:  uploadDir,
+		BaseURL:    baseURL,
+		signingKey: signingKey,
 	}, nil
 }
 
@@ -39,32 +59,226 @@ func (s *LocalStorage) UploadFile(ctx context.Context, file *multipart.FileHeade
 	}
 	defer src.Close()
 
-	// Generate unique filename
-	// สร้างชื่อไฟล์ใหม่เพื่อไม่ให้ซ้ำกัน: วันเวลา + UUID + นามสกุลไฟล์เดิม
-	ext := filepath.Ext(file.Filename)
-	filename := fmt.Sprintf("%s_%s%s", time.Now().Format("20060102150405"), uuid.New().String(), ext)
-	filepath := filepath.Join(s.UploadDir, filename)
+	return s.UploadReader(ctx, src, file.Size, file.Filename, file.Header.Get("Content-Type"))
+}
 
-	// สร้างไฟล์ปลายทาง
-	dst, err := os.Create(filepath)
+// UploadReader บันทึกข้อมูลจาก reader ลงเครื่องและคืนค่า URL ที่เข้าถึงไฟล์ได้
+func (s *LocalStorage) UploadReader(ctx context.Context, r io.Reader, size int64, name, contentType string) (string, error) {
+	obj, err := s.PutObject(ctx, r, size, name, contentType)
 	if err != nil {
 		return "", err
 	}
-	defer dst.Close()
+	return obj.URL, nil
+}
 
-	// คัดลอกข้อมูลจากต้นทางไปปลายทาง
-	if _, err = io.Copy(dst, src); err != nil {
-		return "", err
+// PutObject เขียนข้อมูลลงไฟล์ชั่วคราวพร้อมคำนวณ SHA-256 แล้วย้ายไปเป็นไฟล์ชื่อตาม hash
+// ถ้ามีไฟล์เนื้อหาเดียวกันอยู่แล้วจะใช้ไฟล์เดิม
+func (s *LocalStorage) PutObject(ctx context.Context, r io.Reader, size int64, name, contentType string) (*domain.StoredObject, error) {
+	// สร้างไฟล์ชั่วคราวในโฟลเดอร์เดียวกันเพื่อให้ rename ได้ทันที
+	tmp, err := os.CreateTemp(s.UploadDir, ".upload-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name()) // ไม่มีผลถ้าย้ายไฟล์ไปแล้ว
+
+	// คัดลอกข้อมูลจากต้นทางไปปลายทางพร้อมคำนวณ hash
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(tmp, h), r)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sum := h.Sum(nil)
+	filename := contentName(sum)
+	dst := filepath.Join(s.UploadDir, filename)
+	if _, err := os.Stat(dst); errors.Is(err, fs.ErrNotExist) {
+		if err := os.Rename(tmp.Name(), dst); err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, err
+	} else {
+		// ใช้ไฟล์เดิม: ปรับเวลาแก้ไขให้เป็นปัจจุบันเพื่อไม่ให้ถูกเก็บกวาดเป็นไฟล์กำพร้าก่อนบันทึกการอ้างอิง
+		now := time.Now()
+		if err := os.Chtimes(dst, now, now); err != nil {
+			return nil, err
+		}
 	}
 
 	// Store only relative path in DB (do not bind to host/port).
-	return fmt.Sprintf("/uploads/%s", filename), nil
+	return &domain.StoredObject{
+		URL:         localURLPrefix + filename,
+		SHA256:      hex.EncodeToString(sum),
+		Size:        n,
+		ContentType: contentType,
+	}, nil
 }
 
-// DeleteFile ลบไฟล์จากเครื่อง
+// DeleteFile ลบไฟล์จากเครื่อง (URL ที่ไม่ได้อยู่ใน storage นี้จะถูกข้าม)
 func (s *LocalStorage) DeleteFile(ctx context.Context, fileURL string) error {
+	if !s.Contains(fileURL) {
+		return nil
+	}
+	return os.Remove(s.localPath(fileURL))
+}
+
+// Contains ตรวจว่า URL เป็นไฟล์ใน storage นี้ (/uploads/<ชื่อไฟล์> หรือ BaseURL/<ชื่อไฟล์>)
+func (s *LocalStorage) Contains(fileURL string) bool {
+	_, ok := s.ObjectKey(fileURL)
+	return ok
+}
+
+// ObjectKey ดึงชื่อไฟล์จาก URL ทั้งแบบ /uploads/<ชื่อไฟล์> และ BaseURL/<ชื่อไฟล์>
+func (s *LocalStorage) ObjectKey(fileURL string) (string, bool) {
+	name, ok := strings.CutPrefix(fileURL, localURLPrefix)
+	if !ok && s.BaseURL != "" {
+		name, ok = strings.CutPrefix(fileURL, strings.TrimSuffix(s.BaseURL, "/")+"/")
+	}
+	if !ok || name == "" || strings.Contains(name, "/") {
+		return "", false
+	}
+	return name, true
+}
+
+// ListFiles ไล่รายการไฟล์ในโฟลเดอร์ที่เก็บไฟล์
+// ข้ามโฟลเดอร์ย่อย (เช่น ส่วนของ multipart upload) และไฟล์ที่ขึ้นต้นด้วยจุด (ไฟล์ชั่วคราวระหว่างอัปโหลด)
+func (s *LocalStorage) ListFiles(ctx context.Context, fn func(domain.StoredFile) error) error {
+	entries, err := os.ReadDir(s.UploadDir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") {
+			continue
+		}
+		fi, err := entry.Info()
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue // ถูกลบระหว่างไล่รายการ
+			}
+			return err
+		}
+		if !fi.Mode().IsRegular() {
+			continue
+		}
+		if err := fn(domain.StoredFile{
+			Key:     name,
+			URL:     localURLPrefix + name,
+			Size:    fi.Size(),
+			ModTime: fi.ModTime(),
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StatFile ดึงข้อมูลไฟล์บนเครื่อง
+func (s *LocalStorage) StatFile(ctx context.Context, fileURL string) (*domain.FileInfo, error) {
+	fi, err := os.Stat(s.localPath(fileURL))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	if fi.IsDir() {
+		return nil, domain.ErrNotFound
+	}
+	return &domain.FileInfo{
+		Size:        fi.Size(),
+		ContentType: mime.TypeByExtension(filepath.Ext(fi.Name())),
+		ModTime:     fi.ModTime(),
+		ETag:        fmt.Sprintf(`"%x-%x"`, fi.ModTime().UnixNano(), fi.Size()),
+	}, nil
+}
+
+// OpenFile เปิดอ่านไฟล์บนเครื่องตั้งแต่ offset จำนวน length ไบต์
+func (s *LocalStorage) OpenFile(ctx context.Context, fileURL string, offset, length int64) (io.ReadCloser, error) {
+	f, err := os.Open(s.localPath(fileURL))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if length < 0 {
+		return f, nil
+	}
+	return &limitedFile{Reader: io.LimitReader(f, length), Closer: f}, nil
+}
+
+// SignedURL เพิ่ม query expires และ signature (HMAC) ให้ path ของไฟล์ในเครื่อง
+// ตรวจสอบด้วย VerifySignedURL ใน middleware ของ /uploads
+func (s *LocalStorage) SignedURL(ctx context.Context, fileURL string, ttl time.Duration) (string, error) {
+	if !strings.HasPrefix(fileURL, localURLPrefix) {
+		return fileURL, nil
+	}
+	return s.signPath(fileURL, url.Values{}, ttl), nil
+}
+
+// signPath เพิ่ม expires และ signature ที่ครอบคลุม path และ query ทั้งหมด
+func (s *LocalStorage) signPath(path string, q url.Values, ttl time.Duration) string {
+	q.Set("expires", strconv.FormatInt(time.Now().Add(ttl).Unix(), 10))
+	q.Set("signature", s.sign(path, q))
+	return path + "?" + q.Encode()
+}
+
+// VerifySignedURL ตรวจว่า path และ query มีลายเซ็นที่ถูกต้องและยังไม่หมดอายุ
+func (s *LocalStorage) VerifySignedURL(path string, query url.Values) error {
+	expires := query.Get("expires")
+	sig := query.Get("signature")
+	if expires == "" || sig == "" {
+		return domain.ErrUnauthorized
+	}
+	exp, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > exp {
+		return domain.ErrUnauthorized
+	}
+	if !hmac.Equal([]byte(sig), []byte(s.sign(path, query))) {
+		return domain.ErrUnauthorized
+	}
+	return nil
+}
+
+// sign คำนวณลายเซ็นของ path และ query (ไม่รวม signature) เรียงตามชื่อ key
+func (s *LocalStorage) sign(path string, q url.Values) string {
+	canonical := url.Values{}
+	for k, v := range q {
+		if k != "signature" {
+			canonical[k] = v
+		}
+	}
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write([]byte(path + "?" + canonical.Encode()))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// newLocalName สร้างชื่อไฟล์ใหม่เพื่อไม่ให้ซ้ำกัน: วันเวลา + UUID + นามสกุลไฟล์เดิม
+func newLocalName(name string) string {
+	return fmt.Sprintf("%s_%s%s", time.Now().Format("20060102150405"), uuid.New().String(), filepath.Ext(name))
+}
+
+// localPath แปลง URL ที่เก็บไว้ (/uploads/<ชื่อไฟล์>) เป็น path บนเครื่อง
+func (s *LocalStorage) localPath(fileURL string) string {
 	// Extract filename from URL (simplified version)
 	// In a real app, you might need better parsing depending on the URL structure
-	filename := filepath.Base(fileURL)
-	return os.Remove(filepath.Join(s.UploadDir, filename))
+	return filepath.Join(s.UploadDir, filepath.Base(fileURL))
+}
+
+// limitedFile อ่านไฟล์ได้ไม่เกินจำนวนไบต์ที่กำหนดแต่ปิดไฟล์จริงเมื่อ Close
+type limitedFile struct {
+	io.Reader
+	io.Closer
 }
@@ -0,0 +1,137 @@
+package storage // ประกาศ package storage
+
+import (
+	"context"  // นำเข้า context
+	"net/http" // นำเข้า net/http
+	"time"     // นำเข้า time
+
+	"go-music-api/internal/domain" // นำเข้า domain entities
+
+	"github.com/aws/aws-sdk-go-v2/aws"              // นำเข้า aws sdk
+	"github.com/aws/aws-sdk-go-v2/service/s3"       // นำเข้า s3 service
+	"github.com/aws/aws-sdk-go-v2/service/s3/types" // นำเข้า types ของ s3
+)
+
+// CreateUpload จอง object key และเริ่ม multipart upload บน S3 ถ้าต้องการ
+func (s *S3Storage) CreateUpload(ctx context.Context, filename, contentType string, multipart bool) (*domain.UploadTarget, error) {
+	key := s.newS3Key(filename)
+	target := &domain.UploadTarget{FileURL: s.objectURL(key)}
+	if !multipart {
+		return target, nil
+	}
+
+	out, err := s.client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket:      aws.String(s.bucketName),
+		Key:         aws.String(key),
+		ContentType: aws.String(contentType),
+	})
+	if err != nil {
+		return nil, err
+	}
+	target.MultipartID = aws.ToString(out.UploadId)
+	return target, nil
+}
+
+// PresignPut สร้าง presigned PutObject URL ที่บังคับ Content-Type ขนาด และ checksum
+func (s *S3Storage) PresignPut(ctx context.Context, target *domain.UploadTarget, contentType, checksum string, size int64, ttl time.Duration) (*domain.PresignedRequest, error) {
+	key, err := s.uploadKey(target)
+	if err != nil {
+		return nil, err
+	}
+	input := &s3.PutObjectInput{
+		Bucket:        aws.String(s.bucketName),
+		Key:           key,
+		ContentType:   aws.String(contentType),
+		ContentLength: aws.Int64(size),
+	}
+	if checksum != "" {
+		// S3 จะปฏิเสธไฟล์ที่ SHA-256 ไม่ตรงกับค่านี้
+		input.ChecksumSHA256 = aws.String(checksum)
+	}
+	req, err := s3.NewPresignClient(s.client).PresignPutObject(ctx, input, s3.WithPresignExpires(ttl))
+	if err != nil {
+		return nil, err
+	}
+	return &domain.PresignedRequest{Method: req.Method, URL: req.URL, Headers: signedHeaders(req.SignedHeader)}, nil
+}
+
+// PresignPart สร้าง presigned UploadPart URL ของส่วนที่ partNumber
+func (s *S3Storage) PresignPart(ctx context.Context, target *domain.UploadTarget, partNumber int, ttl time.Duration) (*domain.PresignedRequest, error) {
+	key, err := s.uploadKey(target)
+	if err != nil {
+		return nil, err
+	}
+	req, err := s3.NewPresignClient(s.client).PresignUploadPart(ctx, &s3.UploadPartInput{
+		Bucket:     aws.String(s.bucketName),
+		Key:        key,
+		UploadId:   aws.String(target.MultipartID),
+		PartNumber: aws.Int32(int32(partNumber)),
+	}, s3.WithPresignExpires(ttl))
+	if err != nil {
+		return nil, err
+	}
+	return &domain.PresignedRequest{Method: req.Method, URL: req.URL, Headers: signedHeaders(req.SignedHeader)}, nil
+}
+
+// CompleteMultipart รวมส่วนที่อัปโหลดแล้วเป็น object เดียว
+func (s *S3Storage) CompleteMultipart(ctx context.Context, target *domain.UploadTarget, parts []domain.CompletedPart) error {
+	key, err := s.uploadKey(target)
+	if err != nil {
+		return err
+	}
+	completed := make([]types.CompletedPart, len(parts))
+	for i, p := range parts {
+		completed[i] = types.CompletedPart{ETag: aws.String(p.ETag), PartNumber: aws.Int32(int32(p.PartNumber))}
+	}
+	_, err = s.client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(s.bucketName),
+		Key:             key,
+		UploadId:        aws.String(target.MultipartID),
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: completed},
+	})
+	if err != nil {
+		return s3Error(err)
+	}
+	return nil
+}
+
+// AbortUpload ยกเลิก multipart upload และลบ object ที่อาจอัปโหลดไปแล้ว
+func (s *S3Storage) AbortUpload(ctx context.Context, target *domain.UploadTarget) error {
+	key, err := s.uploadKey(target)
+	if err != nil {
+		return err
+	}
+	if target.MultipartID != "" {
+		_, err := s.client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+			Bucket:   aws.String(s.bucketName),
+			Key:      key,
+			UploadId: aws.String(target.MultipartID),
+		})
+		if err != nil && s3Error(err) != domain.ErrNotFound {
+			return err
+		}
+	}
+	_, err = s.client.DeleteObject(ctx, &s3.DeleteObjectInput{Bucket: aws.String(s.bucketName), Key: key})
+	return err
+}
+
+// uploadKey ดึง object key ของปลายทางการอัปโหลด
+func (s *S3Storage) uploadKey(target *domain.UploadTarget) (*string, error) {
+	key, ok := s.keyFromURL(target.FileURL)
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return aws.String(key), nil
+}
+
+// signedHeaders แปลง header ที่ต้องส่งตามลายเซ็นให้ client (Host ถูกกำหนดจาก URL อยู่แล้ว)
+func signedHeaders(h http.Header) map[string]string {
+	headers := map[string]string{}
+	for k, v := range h {
+		if http.CanonicalHeaderKey(k) == "Host" || len(v) == 0 {
+			continue
+		}
+		headers[k] = v[0]
+	}
+	return headers
+}
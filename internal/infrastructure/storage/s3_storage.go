@@ -1,41 +1,110 @@
 package storage // ประกาศ package storage
 
 import (
-	"context"        // นำเข้า context
-	"fmt"            // นำเข้า fmt สำหรับจัดการข้อความ
-	"mime/multipart" // นำเข้า multipart สำหรับจัดการไฟล์
-	"path/filepath"  // นำเข้า filepath
-	"time"           // นำเข้า time
-
-	"github.com/aws/aws-sdk-go-v2/aws"        // นำเข้า aws sdk
-	"github.com/aws/aws-sdk-go-v2/config"     // นำเข้า config
-	"github.com/aws/aws-sdk-go-v2/service/s3" // นำเข้า s3 service
-	"github.com/google/uuid"                  // นำเข้า uuid
+	"context"         // นำเข้า context
+	"crypto/sha256"   // นำเข้า sha256 สำหรับ key ตาม hash ของเนื้อหา
+	"encoding/base64" // นำเข้า base64 สำหรับ checksum ที่ส่งให้ S3
+	"encoding/hex"    // นำเข้า hex
+	"errors"          // นำเข้า errors
+	"fmt"             // นำเข้า fmt สำหรับจัดการข้อความ
+	"io"              // นำเข้า io สำหรับอ่านข้อมูลแบบ stream
+	"mime/multipart"  // นำเข้า multipart สำหรับจัดการไฟล์
+	"net/http"        // นำเข้า net/http สำหรับตรวจ status code
+	"net/url"         // นำเข้า url สำหรับแยก key จาก URL
+	"os"              // นำเข้า os สำหรับพักข้อมูลที่ seek ไม่ได้ลงไฟล์ชั่วคราว
+	"path/filepath"   // นำเข้า filepath
+	"strings"         // นำเข้า strings
+	"time"            // นำเข้า time
+
+	"go-music-api/internal/domain" // นำเข้า domain entities
+
+	"github.com/aws/aws-sdk-go-v2/aws"                        // นำเข้า aws sdk
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http" // นำเข้า transport http สำหรับอ่าน status code ของ error
+	"github.com/aws/aws-sdk-go-v2/config"                     // นำเข้า config
+	"github.com/aws/aws-sdk-go-v2/service/s3"                 // นำเข้า s3 service
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"           // นำเข้า types ของ s3
+	"github.com/google/uuid"                                  // นำเข้า uuid
 )
 
-// S3Storage struct สำหรับจัดการไฟล์บน AWS S3
+// S3Config การตั้งค่าของ S3Storage (รองรับ S3-compatible storage เช่น MinIO)
+type S3Config struct {
+	Bucket        string // ชื่อ Bucket
+	Region        string // Region ของ Bucket
+	Endpoint      string // endpoint ของ S3-compatible storage เช่น http://localhost:9000 (ว่าง = AWS)
+	UsePathStyle  bool   // ใช้ URL แบบ path-style (<endpoint>/<bucket>/<key>) แทน virtual-hosted style
+	KeyPrefix     string // prefix ที่ใส่หน้า object key ทุกตัว เช่น "media" (เติม / ให้เอง)
+	PublicBaseURL string // URL พื้นฐานสำหรับเข้าถึงไฟล์แบบ public เช่น CDN (ว่าง = URL ของ bucket)
+}
+
+// S3Storage struct สำหรับจัดการไฟล์บน AWS S3 หรือ S3-compatible storage
 type S3Storage struct {
 	client     *s3.Client // AWS S3 Client
 	bucketName string     // ชื่อ Bucket
-	region     string     // Region ของ Bucket
+	keyPrefix  string     // prefix ของ object key
+	baseURL    *url.URL   // URL พื้นฐานที่ใช้สร้าง URL ของ object
+	public     bool       // baseURL เป็น public/CDN URL ที่เข้าถึงได้โดยไม่ต้องลงลายเซ็น
+	knownBases []*url.URL // URL พื้นฐานทั้งหมดที่ถือว่าเป็น object ใน bucket นี้ (ใช้ดึง key)
 }
 
 // NewS3Storage สร้าง instance ใหม่ของ S3Storage
-func NewS3Storage(bucketName, region string) (*S3Storage, error) {
+func NewS3Storage(cfg S3Config) (*S3Storage, error) {
+	if cfg.Bucket == "" || cfg.Region == "" {
+		return nil, errors.New("s3 storage: bucket and region are required")
+	}
+
 	// โหลด Default Configuration
-	cfg, err := config.LoadDefaultConfig(context.TODO(), config.WithRegion(region))
+	awsCfg, err := config.LoadDefaultConfig(context.TODO(), config.WithRegion(cfg.Region))
 	if err != nil {
 		return nil, fmt.Errorf("unable to load SDK config, %v", err)
 	}
 
+	var endpoint *url.URL
+	if cfg.Endpoint != "" {
+		if endpoint, err = parseBaseURL(cfg.Endpoint); err != nil {
+			return nil, fmt.Errorf("s3 storage: invalid endpoint: %v", err)
+		}
+	}
+
 	// สร้าง S3 Client
-	client := s3.NewFromConfig(cfg)
+	client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		if endpoint != nil {
+			o.BaseEndpoint = aws.String(endpoint.String())
+		}
+		o.UsePathStyle = cfg.UsePathStyle
+	})
 
-	return &S3Storage{
+	s := &S3Storage{
 		client:     client,
-		bucketName: bucketName,
-		region:     region,
-	}, nil
+		bucketName: cfg.Bucket,
+	}
+	if prefix := strings.Trim(cfg.KeyPrefix, "/"); prefix != "" {
+		s.keyPrefix = prefix + "/"
+	}
+
+	// URL ของ bucket ทั้งสองแบบ (รับ URL ที่บันทึกไว้ก่อนเปลี่ยนรูปแบบได้ด้วย)
+	if endpoint == nil {
+		endpoint = &url.URL{Scheme: "https", Host: fmt.Sprintf("s3.%s.amazonaws.com", cfg.Region)}
+	}
+	virtualHosted := &url.URL{Scheme: endpoint.Scheme, Host: cfg.Bucket + "." + endpoint.Host, Path: endpoint.Path}
+	pathStyle := &url.URL{Scheme: endpoint.Scheme, Host: endpoint.Host, Path: endpoint.Path + "/" + cfg.Bucket}
+	if cfg.UsePathStyle {
+		s.baseURL = pathStyle
+	} else {
+		s.baseURL = virtualHosted
+	}
+	s.knownBases = []*url.URL{virtualHosted, pathStyle}
+
+	if cfg.PublicBaseURL != "" {
+		public, err := parseBaseURL(cfg.PublicBaseURL)
+		if err != nil {
+			return nil, fmt.Errorf("s3 storage: invalid public base URL: %v", err)
+		}
+		s.baseURL = public
+		s.public = true
+		s.knownBases = append([]*url.URL{public}, s.knownBases...)
+	}
+
+	return s, nil
 }
 
 // UploadFile อัปโหลดไฟล์ขึ้น S3 และคืนค่า URL
@@ -47,33 +116,272 @@ func (s *S3Storage) UploadFile(ctx context.Context, file *multipart.FileHeader)
 	}
 	defer src.Close()
 
-	// สร้างชื่อไฟล์ใหม่เพื่อไม่ให้ซ้ำกัน
-	ext := filepath.Ext(file.Filename)
-	newFileName := fmt.Sprintf("%d_%s%s", time.Now().Unix(), uuid.New().String(), ext)
+	return s.UploadReader(ctx, src, file.Size, file.Filename, file.Header.Get("Content-Type"))
+}
 
-	// อัปโหลดไฟล์ไปยัง S3
+// UploadReader อัปโหลดข้อมูลจาก reader ขึ้น S3 และคืนค่า URL
+func (s *S3Storage) UploadReader(ctx context.Context, r io.Reader, size int64, name, contentType string) (string, error) {
+	obj, err := s.PutObject(ctx, r, size, name, contentType)
+	if err != nil {
+		return "", err
+	}
+	return obj.URL, nil
+}
+
+// PutObject อัปโหลดข้อมูลขึ้น S3 ที่ key ตาม SHA-256 ของเนื้อหา
+// reader ที่ seek ได้จะถูกอ่านคำนวณ hash ก่อนแล้วอ่านใหม่ตอนอัปโหลด ส่วน reader อื่นจะพักลงไฟล์ชั่วคราวพร้อมคำนวณ hash
+// ถ้ามี object เนื้อหาเดียวกันอยู่แล้วจะไม่อัปโหลดซ้ำ
+func (s *S3Storage) PutObject(ctx context.Context, r io.Reader, size int64, name, contentType string) (*domain.StoredObject, error) {
+	h := sha256.New()
+	body, ok := r.(io.ReadSeeker)
+	if ok {
+		start, err := body.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, err
+		}
+		if size, err = io.Copy(h, body); err != nil {
+			return nil, err
+		}
+		if _, err := body.Seek(start, io.SeekStart); err != nil {
+			return nil, err
+		}
+	} else {
+		tmp, err := os.CreateTemp("", "s3-upload-*")
+		if err != nil {
+			return nil, err
+		}
+		defer os.Remove(tmp.Name())
+		defer tmp.Close()
+		if size, err = io.Copy(io.MultiWriter(tmp, h), r); err != nil {
+			return nil, err
+		}
+		if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		body = tmp
+	}
+
+	sum := h.Sum(nil)
+	key := s.keyPrefix + contentName(sum)
+	obj := &domain.StoredObject{
+		URL:         s.objectURL(key),
+		SHA256:      hex.EncodeToString(sum),
+		Size:        size,
+		ContentType: contentType,
+	}
+
+	// มี object เนื้อหาเดียวกันอยู่แล้ว ไม่ต้องอัปโหลดซ้ำ
+	_, err := s.client.HeadObject(ctx, &s3.HeadObjectInput{Bucket: aws.String(s.bucketName), Key: aws.String(key)})
+	if err == nil {
+		return obj, nil
+	}
+	if err = s3Error(err); err != domain.ErrNotFound {
+		return nil, err
+	}
+
+	// อัปโหลดไฟล์ไปยัง S3 โดยให้ S3 ตรวจ SHA-256 ของข้อมูลที่ได้รับ
 	_, err = s.client.PutObject(ctx, &s3.PutObjectInput{
-		Bucket:      aws.String(s.bucketName),
-		Key:         aws.String(newFileName),
-		Body:        src,
-		ContentType: aws.String(file.Header.Get("Content-Type")),
+		Bucket:         aws.String(s.bucketName),
+		Key:            aws.String(key),
+		Body:           body,
+		ContentLength:  aws.Int64(size),
+		ContentType:    aws.String(contentType),
+		ChecksumSHA256: aws.String(base64.StdEncoding.EncodeToString(sum)),
 		// ACL:         types.ObjectCannedACLPublicRead, // ถ้าต้องการให้เข้าถึงได้แบบ Public (ต้องตั้งค่า Bucket Policy ด้วย)
 	})
-
 	if err != nil {
-		return "", fmt.Errorf("failed to upload file to S3: %v", err)
+		return nil, fmt.Errorf("failed to upload file to S3: %v", err)
 	}
+	return obj, nil
+}
+
+// DeleteFile ลบ object ออกจาก S3 (URL ที่ไม่ได้อยู่ใน bucket นี้จะถูกข้าม)
+func (s *S3Storage) DeleteFile(ctx context.Context, fileURL string) error {
+	key, ok := s.keyFromURL(fileURL)
+	if !ok {
+		return nil
+	}
+	// S3 ตอบ 204 แม้ไม่มี object อยู่แล้ว จึงลบซ้ำได้โดยไม่ error
+	if _, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucketName),
+		Key:    aws.String(key),
+	}); err != nil {
+		return fmt.Errorf("failed to delete file from S3: %w", s3Error(err))
+	}
+	return nil
+}
 
-	// สร้าง URL ของไฟล์ (แบบ Virtual-hosted style)
-	// รูปแบบ: https://bucket-name.s3.region.amazonaws.com/key
-	fileURL := fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", s.bucketName, s.region, newFileName)
+// Contains ตรวจว่า URL เป็น object ใน bucket นี้
+func (s *S3Storage) Contains(fileURL string) bool {
+	_, ok := s.keyFromURL(fileURL)
+	return ok
+}
 
-	return fileURL, nil
+// ObjectKey ดึง object key จาก URL ทุกรูปแบบที่ชี้ไปยัง bucket นี้
+func (s *S3Storage) ObjectKey(fileURL string) (string, bool) {
+	return s.keyFromURL(fileURL)
 }
 
-// DeleteFile ลบไฟล์ออกจาก S3 (ยังไม่ implement logic การ parse key จาก URL ในตอนนี้)
-func (s *S3Storage) DeleteFile(ctx context.Context, fileURL string) error {
-	// ต้องเขียน logic เพื่อดึง key จาก fileURL ก่อน
-	// ตัวอย่าง: https://my-bucket.s3.us-east-1.amazonaws.com/my-file.jpg -> key: my-file.jpg
+// ListFiles ไล่รายการ object ทั้งหมดใต้ key prefix ทีละหน้า (multipart upload ที่ยังไม่เสร็จจะไม่อยู่ในรายการ)
+func (s *S3Storage) ListFiles(ctx context.Context, fn func(domain.StoredFile) error) error {
+	input := &s3.ListObjectsV2Input{Bucket: aws.String(s.bucketName)}
+	if s.keyPrefix != "" {
+		input.Prefix = aws.String(s.keyPrefix)
+	}
+	pages := s3.NewListObjectsV2Paginator(s.client, input)
+	for pages.HasMorePages() {
+		page, err := pages.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list files in S3: %w", s3Error(err))
+		}
+		for _, obj := range page.Contents {
+			key := aws.ToString(obj.Key)
+			if key == "" || strings.HasSuffix(key, "/") {
+				continue // object ที่ใช้แทนโฟลเดอร์
+			}
+			file := domain.StoredFile{
+				Key:  key,
+				URL:  s.objectURL(key),
+				Size: aws.ToInt64(obj.Size),
+			}
+			if obj.LastModified != nil {
+				file.ModTime = *obj.LastModified
+			}
+			if err := fn(file); err != nil {
+				return err
+			}
+		}
+	}
 	return nil
 }
+
+// StatFile ดึงข้อมูล object บน S3
+func (s *S3Storage) StatFile(ctx context.Context, fileURL string) (*domain.FileInfo, error) {
+	key, ok := s.keyFromURL(fileURL)
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	out, err := s.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket:       aws.String(s.bucketName),
+		Key:          aws.String(key),
+		ChecksumMode: types.ChecksumModeEnabled,
+	})
+	if err != nil {
+		return nil, s3Error(err)
+	}
+	info := &domain.FileInfo{
+		Size:        aws.ToInt64(out.ContentLength),
+		ContentType: aws.ToString(out.ContentType),
+		ETag:        aws.ToString(out.ETag),
+	}
+	if out.LastModified != nil {
+		info.ModTime = *out.LastModified
+	}
+	// checksum ของ multipart upload เป็นแบบ composite (มี -<จำนวนส่วน>) ใช้เทียบกับทั้งไฟล์ไม่ได้
+	if sum := aws.ToString(out.ChecksumSHA256); sum != "" && !strings.Contains(sum, "-") {
+		info.ChecksumSHA256 = sum
+	}
+	return info, nil
+}
+
+// OpenFile อ่าน object บน S3 เฉพาะช่วงที่ต้องการด้วย ranged GetObject
+func (s *S3Storage) OpenFile(ctx context.Context, fileURL string, offset, length int64) (io.ReadCloser, error) {
+	key, ok := s.keyFromURL(fileURL)
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(s.bucketName),
+		Key:    aws.String(key),
+	}
+	switch {
+	case length == 0:
+		return io.NopCloser(strings.NewReader("")), nil
+	case length > 0:
+		input.Range = aws.String(fmt.Sprintf("bytes=%d-%d", offset, offset+length-1))
+	case offset > 0:
+		input.Range = aws.String(fmt.Sprintf("bytes=%d-", offset))
+	}
+	out, err := s.client.GetObject(ctx, input)
+	if err != nil {
+		return nil, s3Error(err)
+	}
+	return out.Body, nil
+}
+
+// SignedURL สร้าง presigned GetObject URL ที่หมดอายุใน ttl
+// ถ้ากำหนด public base URL ไว้ จะคืน URL ของ public/CDN โดยไม่ลงลายเซ็น
+func (s *S3Storage) SignedURL(ctx context.Context, fileURL string, ttl time.Duration) (string, error) {
+	key, ok := s.keyFromURL(fileURL)
+	if !ok {
+		// ไม่ใช่ object ใน bucket นี้ (เช่น URL ภายนอก)
+		return fileURL, nil
+	}
+	if s.public {
+		return s.objectURL(key), nil
+	}
+	req, err := s3.NewPresignClient(s.client).PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucketName),
+		Key:    aws.String(key),
+	}, s3.WithPresignExpires(ttl))
+	if err != nil {
+		return "", err
+	}
+	return req.URL, nil
+}
+
+// newS3Key สร้าง object key ที่ไม่ซ้ำกันโดยคงนามสกุลไฟล์เดิม (ใส่ key prefix ให้ด้วย)
+func (s *S3Storage) newS3Key(name string) string {
+	return s.keyPrefix + fmt.Sprintf("%d_%s%s", time.Now().Unix(), uuid.New().String(), filepath.Ext(name))
+}
+
+// objectURL สร้าง URL ของ object จาก URL พื้นฐานที่ตั้งค่าไว้
+func (s *S3Storage) objectURL(key string) string {
+	u := *s.baseURL
+	u.Path = u.Path + "/" + key
+	return u.String()
+}
+
+// keyFromURL ดึง object key จาก URL ทั้งแบบ virtual-hosted, path-style และ public base URL
+// คืนค่า false ถ้า URL ไม่ได้ชี้ไปยัง object ใน bucket นี้
+func (s *S3Storage) keyFromURL(fileURL string) (string, bool) {
+	u, err := url.Parse(fileURL)
+	if err != nil || u.Host == "" {
+		return "", false
+	}
+	for _, base := range s.knownBases {
+		if !strings.EqualFold(u.Host, base.Host) {
+			continue
+		}
+		key, found := strings.CutPrefix(u.Path, base.Path+"/")
+		if found && key != "" {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// parseBaseURL ตรวจและแปลง URL พื้นฐาน (ต้องมี scheme และ host) โดยตัด / ท้าย path ออก
+func parseBaseURL(raw string) (*url.URL, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("%q must be an absolute URL", raw)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	u.RawPath = ""
+	u.RawQuery = ""
+	u.Fragment = ""
+	return u, nil
+}
+
+// s3Error แปลง error จาก S3 ที่เป็น 404 ให้เป็น domain.ErrNotFound
+func s3Error(err error) error {
+	var re *awshttp.ResponseError
+	if errors.As(err, &re) && re.HTTPStatusCode() == http.StatusNotFound {
+		return domain.ErrNotFound
+	}
+	return err
+}
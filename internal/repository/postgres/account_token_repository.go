@@ -0,0 +1,59 @@
+package postgres // ประกาศ package postgres
+
+import (
+	"context" // นำเข้า context
+	"time"    // นำเข้า time
+
+	"go-music-api/internal/domain" // นำเข้า domain entities
+
+	"gorm.io/gorm" // นำเข้า gorm ORM
+)
+
+// accountTokenRepository struct สำหรับ implement interface AccountTokenRepository
+type accountTokenRepository struct {
+	db *gorm.DB // เก็บ connection ของ database
+}
+
+// NewAccountTokenRepository สร้าง instance ของ AccountTokenRepository
+func NewAccountTokenRepository(db *gorm.DB) domain.AccountTokenRepository {
+	return &accountTokenRepository{db: db}
+}
+
+// Create บันทึก token ใหม่
+func (r *accountTokenRepository) Create(ctx context.Context, token *domain.AccountToken) error {
+	return r.db.WithContext(ctx).Create(token).Error
+}
+
+// Consume ทำเครื่องหมายว่า token ถูกใช้แล้ว (ใช้ได้ครั้งเดียวแม้มี request พร้อมกัน) แล้วคืนข้อมูลของ token
+func (r *accountTokenRepository) Consume(ctx context.Context, tokenHash string, purpose domain.AccountTokenPurpose, now time.Time) (*domain.AccountToken, error) {
+	var token domain.AccountToken
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&domain.AccountToken{}).
+			Where("token_hash = ? AND purpose = ? AND used_at IS NULL AND expires_at > ?", tokenHash, purpose, now).
+			Update("used_at", now)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return domain.ErrNotFound
+		}
+		return tx.First(&token, "token_hash = ?", tokenHash).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// DeleteForUser ลบ token ที่ยังไม่ได้ใช้ของผู้ใช้ตามจุดประสงค์ (ลิงก์เก่าใช้ไม่ได้อีก)
+func (r *accountTokenRepository) DeleteForUser(ctx context.Context, userID uint, purpose domain.AccountTokenPurpose) error {
+	return r.db.WithContext(ctx).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+		Delete(&domain.AccountToken{}).Error
+}
+
+// DeleteExpired ลบ token ที่หมดอายุก่อน before
+func (r *accountTokenRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	res := r.db.WithContext(ctx).Where("expires_at < ?", before).Delete(&domain.AccountToken{})
+	return res.RowsAffected, res.Error
+}
@@ -0,0 +1,92 @@
+package postgres // ประกาศ package postgres
+
+import (
+	"context" // นำเข้า context
+	"errors"  // นำเข้า errors
+
+	"go-music-api/internal/domain" // นำเข้า domain entities
+
+	"gorm.io/gorm"        // นำเข้า gorm ORM
+	"gorm.io/gorm/clause" // นำเข้า clause สำหรับละเว้น associations
+)
+
+// albumRepository struct สำหรับ implement interface AlbumRepository
+type albumRepository struct {
+	db *gorm.DB // เก็บ connection ของ database
+}
+
+// NewAlbumRepository สร้าง instance ของ AlbumRepository
+func NewAlbumRepository(db *gorm.DB) domain.AlbumRepository {
+	return &albumRepository{db: db}
+}
+
+// Create สร้างอัลบั้มใหม่
+func (r *albumRepository) Create(ctx context.Context, album *domain.Album) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Create(album).Error
+}
+
+// GetByID ดึงข้อมูลอัลบั้มพร้อมศิลปินและรายการเพลง
+func (r *albumRepository) GetByID(ctx context.Context, id uint) (*domain.Album, error) {
+	var album domain.Album
+	err := r.db.WithContext(ctx).
+		Preload("Artist").
+		Preload("Tracks", func(tx *gorm.DB) *gorm.DB {
+			return tx.Order("disc_number").Order("track_number").Order("id")
+		}).
+		Preload("Tracks.Artists", func(tx *gorm.DB) *gorm.DB {
+			return tx.Order("position")
+		}).
+		Preload("Tracks.Artists.Artist").
+		First(&album, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &album, nil
+}
+
+// GetAll ดึงรายการอัลบั้มแบบแบ่งหน้า เรียงตามวันที่วางจำหน่ายล่าสุด
+func (r *albumRepository) GetAll(ctx context.Context, query domain.AlbumQuery) ([]domain.Album, int64, error) {
+	tx := r.db.WithContext(ctx).Model(&domain.Album{})
+	if query.Title != "" {
+		tx = tx.Where("title ILIKE ?", "%"+escapeLike(query.Title)+"%")
+	}
+	if query.ArtistID != 0 {
+		tx = tx.Where("artist_id = ?", query.ArtistID)
+	}
+
+	var total int64
+	if err := tx.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	albums := make([]domain.Album, 0, query.PageSize)
+	err := tx.Preload("Artist").
+		Order("release_date DESC NULLS LAST").Order("id").
+		Limit(query.PageSize).
+		Offset((query.Page - 1) * query.PageSize).
+		Find(&albums).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return albums, total, nil
+}
+
+// Update อัปเดตข้อมูลอัลบั้ม (ไม่แตะรายการเพลง)
+func (r *albumRepository) Update(ctx context.Context, album *domain.Album) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(album).Error
+}
+
+// Delete ลบอัลบั้ม (เพลงในอัลบั้มจะถูกตั้ง album_id เป็น NULL)
+func (r *albumRepository) Delete(ctx context.Context, id uint) error {
+	res := r.db.WithContext(ctx).Delete(&domain.Album{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package postgres // ประกาศ package postgres
+
+import (
+	"context" // นำเข้า context
+	"errors"  // นำเข้า errors
+	"time"    // นำเข้า time
+
+	"go-music-api/internal/domain" // นำเข้า domain entities
+
+	"gorm.io/gorm" // นำเข้า gorm ORM
+)
+
+// apiKeyRepository struct สำหรับ implement interface APIKeyRepository
+type apiKeyRepository struct {
+	db *gorm.DB // เก็บ connection ของ database
+}
+
+// NewAPIKeyRepository สร้าง instance ของ APIKeyRepository
+func NewAPIKeyRepository(db *gorm.DB) domain.APIKeyRepository {
+	return &apiKeyRepository{db: db}
+}
+
+// Create บันทึก API key ใหม่
+func (r *apiKeyRepository) Create(ctx context.Context, key *domain.APIKey) error {
+	return r.db.WithContext(ctx).Create(key).Error
+}
+
+// GetByHash ดึง API key ตาม hash
+func (r *apiKeyRepository) GetByHash(ctx context.Context, keyHash string) (*domain.APIKey, error) {
+	var key domain.APIKey
+	if err := r.db.WithContext(ctx).First(&key, "key_hash = ?", keyHash).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &key, nil
+}
+
+// ListByUser ดึง API key ทั้งหมดของผู้ใช้ เรียงจากใหม่ไปเก่า
+func (r *apiKeyRepository) ListByUser(ctx context.Context, userID uint) ([]domain.APIKey, error) {
+	var keys []domain.APIKey
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at DESC").Find(&keys).Error
+	return keys, err
+}
+
+// Delete ลบ API key ของผู้ใช้
+func (r *apiKeyRepository) Delete(ctx context.Context, userID, id uint) error {
+	res := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).Delete(&domain.APIKey{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// Touch บันทึกเวลาใช้งานล่าสุด เฉพาะเมื่อครั้งก่อนเก่ากว่า every (ไม่ต้องเขียนฐานข้อมูลทุก request)
+func (r *apiKeyRepository) Touch(ctx context.Context, id uint, now time.Time, every time.Duration) error {
+	return r.db.WithContext(ctx).Model(&domain.APIKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", id, now.Add(-every)).
+		UpdateColumn("last_used_at", now).Error
+}
@@ -0,0 +1,45 @@
+package postgres // ประกาศ package postgres
+
+import (
+	"context" // นำเข้า context
+
+	"go-music-api/internal/domain" // นำเข้า domain entities
+
+	"gorm.io/gorm" // นำเข้า gorm ORM
+)
+
+// LinkMusicArtists ย้ายข้อมูลศิลปินแบบข้อความเดิมของเพลงที่ยังไม่มีความสัมพันธ์กับ Artist
+// โดยสร้าง (หรือใช้) ศิลปินตามชื่อที่ normalize แล้ว และเชื่อมกับเพลงตามบทบาทที่แยกได้
+func LinkMusicArtists(ctx context.Context, db *gorm.DB) error {
+	artists := NewArtistRepository(db)
+	var batch []domain.Music
+	return db.WithContext(ctx).
+		Where("artist <> ''").
+		Where("NOT EXISTS (SELECT 1 FROM music_artists ma WHERE ma.music_id = musics.id)").
+		FindInBatches(&batch, 200, func(_ *gorm.DB, _ int) error {
+			for _, m := range batch {
+				var credits []domain.MusicArtist
+				seen := map[uint]bool{}
+				for _, c := range domain.ParseArtistCredits(m.Artist) {
+					artist := &domain.Artist{Name: c.Name}
+					artist.CreatedBy = m.CreatedBy
+					artist.UpdatedBy = m.CreatedBy
+					if err := artists.FirstOrCreate(ctx, artist); err != nil {
+						return err
+					}
+					if seen[artist.ID] {
+						continue
+					}
+					seen[artist.ID] = true
+					credits = append(credits, domain.MusicArtist{ArtistID: artist.ID, Role: c.Role})
+				}
+				err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+					return replaceMusicArtists(tx, m.ID, credits)
+				})
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+}
@@ -0,0 +1,102 @@
+package postgres // ประกาศ package postgres
+
+import (
+	"context" // นำเข้า context
+	"errors"  // นำเข้า errors
+
+	"go-music-api/internal/domain" // นำเข้า domain entities
+
+	"gorm.io/gorm" // นำเข้า gorm ORM
+)
+
+// artistRepository struct สำหรับ implement interface ArtistRepository
+type artistRepository struct {
+	db *gorm.DB // เก็บ connection ของ database
+}
+
+// NewArtistRepository สร้าง instance ของ ArtistRepository
+func NewArtistRepository(db *gorm.DB) domain.ArtistRepository {
+	return &artistRepository{db: db}
+}
+
+// Create สร้างศิลปินใหม่ (ชื่อที่ normalize แล้วต้องไม่ซ้ำ)
+func (r *artistRepository) Create(ctx context.Context, artist *domain.Artist) error {
+	artist.NormalizedName = domain.NormalizeArtistName(artist.Name)
+	if err := r.db.WithContext(ctx).Create(artist).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return domain.ErrConflict
+		}
+		return err
+	}
+	return nil
+}
+
+// FirstOrCreate ค้นหาศิลปินด้วยชื่อที่ normalize แล้ว ถ้าไม่พบให้สร้างใหม่
+func (r *artistRepository) FirstOrCreate(ctx context.Context, artist *domain.Artist) error {
+	artist.NormalizedName = domain.NormalizeArtistName(artist.Name)
+	return r.db.WithContext(ctx).
+		Where(domain.Artist{NormalizedName: artist.NormalizedName}).
+		FirstOrCreate(artist).Error
+}
+
+// GetByID ดึงข้อมูลศิลปินตาม ID
+func (r *artistRepository) GetByID(ctx context.Context, id uint) (*domain.Artist, error) {
+	var artist domain.Artist
+	if err := r.db.WithContext(ctx).First(&artist, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &artist, nil
+}
+
+// GetAll ดึงรายการศิลปินแบบแบ่งหน้า เรียงตามชื่อ
+func (r *artistRepository) GetAll(ctx context.Context, query domain.ArtistQuery) ([]domain.Artist, int64, error) {
+	tx := r.db.WithContext(ctx).Model(&domain.Artist{})
+	if query.Name != "" {
+		tx = tx.Where("name ILIKE ?", "%"+escapeLike(query.Name)+"%")
+	}
+
+	var total int64
+	if err := tx.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	artists := make([]domain.Artist, 0, query.PageSize)
+	err := tx.Order("normalized_name").Order("id").
+		Limit(query.PageSize).
+		Offset((query.Page - 1) * query.PageSize).
+		Find(&artists).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return artists, total, nil
+}
+
+// Update อัปเดตข้อมูลศิลปิน
+func (r *artistRepository) Update(ctx context.Context, artist *domain.Artist) error {
+	artist.NormalizedName = domain.NormalizeArtistName(artist.Name)
+	if err := r.db.WithContext(ctx).Save(artist).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return domain.ErrConflict
+		}
+		return err
+	}
+	return nil
+}
+
+// Delete ลบศิลปิน (ไม่อนุญาตถ้ายังมีเพลงอ้างอิงอยู่)
+func (r *artistRepository) Delete(ctx context.Context, id uint) error {
+	res := r.db.WithContext(ctx).Delete(&domain.Artist{}, id)
+	if res.Error != nil {
+		if errors.Is(res.Error, gorm.ErrForeignKeyViolated) {
+			return domain.ErrConflict
+		}
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package postgres // ประกาศ package postgres
+
+import (
+	"context" // นำเข้า context
+	"errors"  // นำเข้า errors
+	"time"    // นำเข้า time
+
+	"go-music-api/internal/domain" // นำเข้า domain entities
+
+	"gorm.io/gorm"        // นำเข้า gorm ORM
+	"gorm.io/gorm/clause" // นำเข้า clause สำหรับ RETURNING
+)
+
+// identityRepository struct สำหรับ implement interface IdentityRepository
+type identityRepository struct {
+	db *gorm.DB // เก็บ connection ของ database
+}
+
+// NewIdentityRepository สร้าง instance ของ IdentityRepository
+func NewIdentityRepository(db *gorm.DB) domain.IdentityRepository {
+	return &identityRepository{db: db}
+}
+
+// Get ดึงบัญชีภายนอกตามผู้ให้บริการและ subject
+func (r *identityRepository) Get(ctx context.Context, provider, subject string) (*domain.UserIdentity, error) {
+	var identity domain.UserIdentity
+	if err := r.db.WithContext(ctx).First(&identity, "provider = ? AND subject = ?", provider, subject).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &identity, nil
+}
+
+// Create ผูกบัญชีภายนอกกับผู้ใช้
+func (r *identityRepository) Create(ctx context.Context, identity *domain.UserIdentity) error {
+	if err := r.db.WithContext(ctx).Create(identity).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return domain.ErrConflict
+		}
+		return err
+	}
+	return nil
+}
+
+// ListByUser ดึงบัญชีภายนอกทั้งหมดของผู้ใช้
+func (r *identityRepository) ListByUser(ctx context.Context, userID uint) ([]domain.UserIdentity, error) {
+	var identities []domain.UserIdentity
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at").Find(&identities).Error
+	return identities, err
+}
+
+// Delete ยกเลิกการผูกบัญชีภายนอกของผู้ใช้
+func (r *identityRepository) Delete(ctx context.Context, userID, id uint) error {
+	res := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).Delete(&domain.UserIdentity{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// TouchLogin บันทึกเวลาเข้าสู่ระบบล่าสุดผ่านบัญชีภายนอก
+func (r *identityRepository) TouchLogin(ctx context.Context, id uint, now time.Time) error {
+	return r.db.WithContext(ctx).Model(&domain.UserIdentity{}).Where("id = ?", id).UpdateColumn("last_login_at", now).Error
+}
+
+// CreateState บันทึก state ของการเข้าสู่ระบบที่เริ่มใหม่
+func (r *identityRepository) CreateState(ctx context.Context, state *domain.OAuthState) error {
+	return r.db.WithContext(ctx).Create(state).Error
+}
+
+// ConsumeState ลบ state และคืนค่าเดิม (ใช้ได้ครั้งเดียวแม้ callback มาพร้อมกัน)
+func (r *identityRepository) ConsumeState(ctx context.Context, stateHash string) (*domain.OAuthState, error) {
+	var states []domain.OAuthState
+	res := r.db.WithContext(ctx).Clauses(clause.Returning{}).Where("state_hash = ?", stateHash).Delete(&states)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if len(states) == 0 {
+		return nil, domain.ErrNotFound
+	}
+	return &states[0], nil
+}
+
+// DeleteExpiredStates ลบ state ที่หมดอายุก่อน before
+func (r *identityRepository) DeleteExpiredStates(ctx context.Context, before time.Time) (int64, error) {
+	res := r.db.WithContext(ctx).Where("expires_at < ?", before).Delete(&domain.OAuthState{})
+	return res.RowsAffected, res.Error
+}
@@ -0,0 +1,62 @@
+package postgres // ประกาศ package postgres
+
+import (
+	"context" // นำเข้า context
+	"errors"  // นำเข้า errors
+	"time"    // นำเข้า time
+
+	"go-music-api/internal/domain" // นำเข้า domain entities
+
+	"gorm.io/gorm"        // นำเข้า gorm ORM
+	"gorm.io/gorm/clause" // นำเข้า clause สำหรับ SELECT ... FOR UPDATE
+)
+
+// mediaDeletionRepository struct สำหรับ implement interface MediaDeletionRepository
+type mediaDeletionRepository struct {
+	db *gorm.DB // เก็บ connection ของ database
+}
+
+// NewMediaDeletionRepository สร้าง instance ของ MediaDeletionRepository
+func NewMediaDeletionRepository(db *gorm.DB) domain.MediaDeletionRepository {
+	return &mediaDeletionRepository{db: db}
+}
+
+// ListDue ดึงไฟล์ที่ถึงเวลาลบ เรียงจากที่รอนานที่สุด
+func (r *mediaDeletionRepository) ListDue(ctx context.Context, now time.Time, limit int) ([]domain.MediaDeletion, error) {
+	var deletions []domain.MediaDeletion
+	err := r.db.WithContext(ctx).
+		Where("not_before <= ?", now).
+		Order("not_before").Order("id").
+		Limit(limit).
+		Find(&deletions).Error
+	return deletions, err
+}
+
+// Claim ล็อกรายการด้วย SELECT ... FOR UPDATE SKIP LOCKED จนจบ transaction ใน ctx
+// รายการที่ถูกนำออกไปแล้วหรือมีตัวลบอื่นจับไว้จะคืน ErrNotFound
+func (r *mediaDeletionRepository) Claim(ctx context.Context, id uint) (*domain.MediaDeletion, error) {
+	var deletion domain.MediaDeletion
+	err := r.db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("id = ?", id).
+		Take(&deletion).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &deletion, nil
+}
+
+// Delete นำไฟล์ออกจากคิว
+func (r *mediaDeletionRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&domain.MediaDeletion{}, id).Error
+}
+
+// Reschedule บันทึกจำนวนครั้ง error และเวลาที่จะลองใหม่
+func (r *mediaDeletionRepository) Reschedule(ctx context.Context, deletion *domain.MediaDeletion) error {
+	return r.db.WithContext(ctx).Model(deletion).
+		Select("not_before", "attempts", "last_error").
+		Updates(deletion).Error
+}
@@ -0,0 +1,190 @@
+package postgres // ประกาศ package postgres
+
+import (
+	"context" // นำเข้า context
+	"errors"  // นำเข้า errors
+	"time"    // นำเข้า time
+
+	"go-music-api/internal/domain" // นำเข้า domain entities
+
+	"gorm.io/gorm"        // นำเข้า gorm ORM
+	"gorm.io/gorm/clause" // นำเข้า clause สำหรับ upsert และ RETURNING
+)
+
+// mediaObjectRepository struct สำหรับ implement interface MediaObjectRepository
+type mediaObjectRepository struct {
+	db *gorm.DB // เก็บ connection ของ database
+}
+
+// NewMediaObjectRepository สร้าง instance ของ MediaObjectRepository
+func NewMediaObjectRepository(db *gorm.DB) domain.MediaObjectRepository {
+	return &mediaObjectRepository{db: db}
+}
+
+// Acquire เพิ่มการอ้างอิงไฟล์ในคำสั่งเดียว (INSERT ... ON CONFLICT) จึงปลอดภัยเมื่ออัปโหลดไฟล์เดียวกันพร้อมกัน
+// ถือล็อกของไฟล์เดียวกับตัวลบไฟล์ในคิว จึงไม่เพิ่มการอ้างอิงระหว่างที่ไฟล์กำลังถูกตรวจและลบ
+// object จะได้ข้อมูลที่บันทึกไว้กลับมา (FileURL อาจเป็นของการอัปโหลดครั้งแรก)
+func (r *mediaObjectRepository) Acquire(ctx context.Context, object *domain.MediaObject) error {
+	object.RefCount = 1
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockMediaURL(tx, object.FileURL); err != nil {
+			return err
+		}
+		return tx.Clauses(
+			clause.OnConflict{
+				Columns: []clause.Column{{Name: "sha256"}},
+				DoUpdates: clause.Assignments(map[string]any{
+					"ref_count":  gorm.Expr("media_objects.ref_count + 1"),
+					"updated_at": gorm.Expr("excluded.updated_at"),
+				}),
+			},
+			clause.Returning{},
+		).Create(object).Error
+	})
+}
+
+// Lock ล็อกไฟล์ตาม URL จนจบ transaction ใน ctx (ล็อกเดียวกับ Acquire)
+func (r *mediaObjectRepository) Lock(ctx context.Context, fileURL string) error {
+	return lockMediaURL(r.db.WithContext(ctx), fileURL)
+}
+
+// Release ลดการอ้างอิงไฟล์ เมื่อไม่เหลือการอ้างอิงจะลบข้อมูลและเข้าคิวลบไฟล์ใน transaction เดียวกัน
+func (r *mediaObjectRepository) Release(ctx context.Context, fileURL string) (bool, error) {
+	var tracked bool
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		tracked, err = releaseMediaObject(tx, fileURL, time.Now())
+		return err
+	})
+	return tracked, err
+}
+
+// GetBySHA256 ดึงไฟล์ที่ยังมีการอ้างอิงตาม SHA-256
+func (r *mediaObjectRepository) GetBySHA256(ctx context.Context, sum string) (*domain.MediaObject, error) {
+	var object domain.MediaObject
+	if err := r.db.WithContext(ctx).Where("sha256 = ? AND ref_count > 0", sum).First(&object).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &object, nil
+}
+
+// Referenced ตรวจว่ายังมีการอ้างอิงไฟล์นี้อยู่หรือไม่
+func (r *mediaObjectRepository) Referenced(ctx context.Context, fileURL string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&domain.MediaObject{}).Where("file_url = ? AND ref_count > 0", fileURL).Count(&count).Error
+	return count > 0, err
+}
+
+// Relocate แก้ URL ไฟล์ในตาราง musics จาก oldURL เป็นไฟล์ใหม่ และปรับตาราง media_objects ใน transaction เดียวกัน
+// ถ้ามีไฟล์เนื้อหาเดียวกันที่ URL อื่นอยู่แล้ว การอ้างอิงของ URL นั้นจะถูกย้ายมาที่ไฟล์ใหม่ด้วย
+func (r *mediaObjectRepository) Relocate(ctx context.Context, oldURL string, object *domain.MediaObject) (string, int64, error) {
+	var refs int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockMediaURL(tx, object.FileURL); err != nil {
+			return err
+		}
+		var existing domain.MediaObject
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("sha256 = ?", object.SHA256).Take(&existing).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		found := err == nil
+
+		if refs, err = replaceMediaURL(tx, oldURL, object.FileURL); err != nil {
+			return err
+		}
+		if !found {
+			if refs == 0 {
+				// ไม่มีเพลงอ้างถึงแล้ว (ถูกแก้ไขระหว่างย้าย) ไม่ต้องนับการอ้างอิง
+				return nil
+			}
+			object.RefCount = int(refs)
+			return tx.Create(object).Error
+		}
+
+		// การอ้างอิงของ URL เดิมในตารางนับไว้แล้ว ย้ายตามไปด้วยแต่ไม่นับซ้ำ
+		if existing.FileURL != object.FileURL && existing.FileURL != oldURL {
+			if _, err := replaceMediaURL(tx, existing.FileURL, object.FileURL); err != nil {
+				return err
+			}
+		}
+		// oldURL ที่ไม่ได้อยู่ในตาราง (ไฟล์ก่อนมีการนับการอ้างอิง) เพิ่มเข้าไปในจำนวนการอ้างอิง
+		if existing.FileURL != oldURL {
+			existing.RefCount += int(refs)
+		}
+		existing.FileURL = object.FileURL
+		if err := tx.Model(&existing).Select("file_url", "ref_count").Updates(&existing).Error; err != nil {
+			return err
+		}
+		*object = existing
+		return nil
+	})
+	return object.FileURL, refs, err
+}
+
+// mediaReferencesSQL ทุกคอลัมน์ที่เก็บ URL ไฟล์ใน storage รวมถึงการอัปโหลดตรงที่ยังไม่ finalize (ที่ finalize แล้วถูกย้ายไปเก็บตาม hash)
+// และไฟล์ใน media_objects ที่ยังมีการอ้างอิง (กันไม่ให้ไฟล์ที่เพิ่ง Acquire ถูกมองเป็นไฟล์กำพร้า)
+const mediaReferencesSQL = `
+SELECT 'musics' AS "table", id::text AS id, 'mp3_url' AS "column", mp3_url AS url FROM musics WHERE mp3_url <> ''
+UNION ALL SELECT 'musics', id::text, 'mp4_url', mp4_url FROM musics WHERE mp4_url <> ''
+UNION ALL SELECT 'musics', id::text, 'image_url', image_url FROM musics WHERE image_url <> ''
+UNION ALL SELECT 'users', id::text, 'image_profile', image_profile FROM users WHERE image_profile <> ''
+UNION ALL SELECT 'albums', id::text, 'image_url', image_url FROM albums WHERE image_url <> ''
+UNION ALL SELECT 'artists', id::text, 'image_url', image_url FROM artists WHERE image_url <> ''
+UNION ALL SELECT 'uploads', id, 'file_url', file_url FROM uploads WHERE file_url <> '' AND status = 'pending'
+UNION ALL SELECT 'media_objects', sha256, 'file_url', file_url FROM media_objects WHERE ref_count > 0`
+
+// ListReferences ดึง URL ไฟล์ทั้งหมดที่ฐานข้อมูลอ้างถึงในคำสั่งเดียว
+func (r *mediaObjectRepository) ListReferences(ctx context.Context) ([]domain.MediaReference, error) {
+	refs := []domain.MediaReference{}
+	err := r.db.WithContext(ctx).Raw(mediaReferencesSQL).Scan(&refs).Error
+	return refs, err
+}
+
+// replaceMediaURL แก้ URL ไฟล์ทุกคอลัมน์ของตาราง musics คืนจำนวนการอ้างอิงที่แก้
+// ไม่เปลี่ยน updated_at เพราะเป็นการย้ายที่เก็บไฟล์ ไม่ใช่การแก้ไขเพลง
+func replaceMediaURL(tx *gorm.DB, oldURL, newURL string) (int64, error) {
+	var refs int64
+	for _, column := range []string{"mp3_url", "mp4_url", "image_url"} {
+		res := tx.Model(&domain.Music{}).Where(column+" = ?", oldURL).UpdateColumn(column, newURL)
+		if res.Error != nil {
+			return 0, res.Error
+		}
+		refs += res.RowsAffected
+	}
+	return refs, nil
+}
+
+// lockMediaURL ถือ advisory lock ของไฟล์ตาม URL จนจบ transaction
+// ชื่อไฟล์เป็น SHA-256 ของเนื้อหา URL หนึ่งจึงตรงกับ hash เดียว ทำให้ Acquire และตัวลบไฟล์ในคิวรอกันและกัน
+func lockMediaURL(tx *gorm.DB, fileURL string) error {
+	return tx.Exec("SELECT pg_advisory_xact_lock(hashtextextended(?, 0))", fileURL).Error
+}
+
+// releaseMediaObject ลดการอ้างอิงไฟล์ภายใน transaction ที่ให้มา (ใช้ร่วมกับการลบบัญชี)
+// คืน false ถ้า URL นี้ไม่ได้อยู่ในตาราง media_objects (เช่น ไฟล์ที่อัปโหลดก่อนมีการนับการอ้างอิง)
+func releaseMediaObject(tx *gorm.DB, fileURL string, now time.Time) (bool, error) {
+	// UPDATE ล็อกแถวไว้จนจบ transaction การ Acquire ไฟล์เดียวกันพร้อมกันจะรอจนลบเสร็จแล้วสร้างแถวใหม่
+	var objects []domain.MediaObject
+	res := tx.Model(&objects).Clauses(clause.Returning{}).
+		Where("file_url = ?", fileURL).
+		UpdateColumns(map[string]any{"ref_count": gorm.Expr("ref_count - 1"), "updated_at": now})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	if res.RowsAffected == 0 || len(objects) == 0 {
+		return false, nil
+	}
+	if objects[0].RefCount > 0 {
+		return true, nil
+	}
+
+	// ไม่มีการอ้างอิงแล้ว ลบข้อมูลและให้ตัวลบไฟล์เบื้องหลังลบไฟล์จริง
+	if err := tx.Where("sha256 = ?", objects[0].SHA256).Delete(&domain.MediaObject{}).Error; err != nil {
+		return true, err
+	}
+	return true, tx.Create(&domain.MediaDeletion{FileURL: fileURL, NotBefore: now}).Error
+}
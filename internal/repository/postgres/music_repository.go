@@ -6,7 +6,8 @@ import (
 
 	"go-music-api/internal/domain" // นำเข้า domain entities
 
-	"gorm.io/gorm" // นำเข้า gorm ORM
+	"gorm.io/gorm"        // นำเข้า gorm ORM
+	"gorm.io/gorm/clause" // นำเข้า clause สำหรับละเว้น associations
 )
 
 // musicRepository struct สำหรับ implement interface MusicRepository
@@ -22,14 +23,48 @@ func NewMusicRepository(db *gorm.DB) domain.MusicRepository {
 // Create บันทึกข้อมูลเพลงใหม่ลงฐานข้อมูล
 func (r *musicRepository) Create(ctx context.Context, music *domain.Music) error {
 	// ใช้ WithContext เพื่อให้ gorm เคารพ timeout หรือ cancelation ของ context
-	return r.db.WithContext(ctx).Create(music).Error
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// บันทึกเฉพาะข้อมูลเพลง ความสัมพันธ์กับศิลปินบันทึกแยกด้านล่าง
+		if err := tx.Omit(clause.Associations).Create(music).Error; err != nil {
+			return err
+		}
+		if err := replaceMusicArtists(tx, music.ID, music.Artists); err != nil {
+			return err
+		}
+		// สร้างข้อมูลสำหรับ full-text search
+		return updateSearchVector(ctx, tx, music)
+	})
+}
+
+// replaceMusicArtists แทนที่รายชื่อศิลปินของเพลงทั้งหมดด้วย credits ใหม่
+func replaceMusicArtists(tx *gorm.DB, musicID uint, credits []domain.MusicArtist) error {
+	if err := tx.Where("music_id = ?", musicID).Delete(&domain.MusicArtist{}).Error; err != nil {
+		return err
+	}
+	if len(credits) == 0 {
+		return nil
+	}
+	rows := make([]domain.MusicArtist, len(credits))
+	for i, c := range credits {
+		rows[i] = domain.MusicArtist{MusicID: musicID, ArtistID: c.ArtistID, Role: c.Role, Position: i}
+	}
+	return tx.Omit(clause.Associations).Create(&rows).Error
+}
+
+// withMusicRelations โหลดอัลบั้มและศิลปินของเพลงมาด้วย
+func withMusicRelations(tx *gorm.DB) *gorm.DB {
+	return tx.Preload("Album").
+		Preload("Artists", func(tx *gorm.DB) *gorm.DB {
+			return tx.Order("position")
+		}).
+		Preload("Artists.Artist")
 }
 
 // GetByID ดึงข้อมูลเพลงจาก ID
 func (r *musicRepository) GetByID(ctx context.Context, id uint) (*domain.Music, error) {
 	var music domain.Music
 	// ค้นหาข้อมูล record แรกที่ตรงกับ ID
-	if err := r.db.WithContext(ctx).First(&music, id).Error; err != nil {
+	if err := withMusicRelations(r.db.WithContext(ctx)).First(&music, id).Error; err != nil {
 		// ถ้าไม่พบข้อมูล ให้คืนค่า error เป็น ErrNotFound
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, domain.ErrNotFound
@@ -41,21 +76,135 @@ func (r *musicRepository) GetByID(ctx context.Context, id uint) (*domain.Music,
 	return &music, nil
 }
 
-// GetAll ดึงข้อมูลเพลงทั้งหมด
-func (r *musicRepository) GetAll(ctx context.Context) ([]domain.Music, error) {
-	var musics []domain.Music
-	// ค้นหาข้อมูลทั้งหมดในตาราง musics
-	if err := r.db.WithContext(ctx).Find(&musics).Error; err != nil {
+// GetAll ดึงข้อมูลเพลงแบบแบ่งหน้า รองรับทั้งเลขหน้า (offset) และ cursor (keyset)
+func (r *musicRepository) GetAll(ctx context.Context, query domain.MusicQuery) (*domain.MusicPage, error) {
+	query.Normalize()
+
+	// ถ้ามี cursor ให้ใช้การเรียงลำดับตามที่ฝังไว้ใน cursor
+	var cursor *pageCursor
+	if query.Cursor != "" {
+		c, err := decodeCursor(query.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		cursor = c
+		query.SortBy = c.SortBy
+		query.SortDesc = c.Desc
+	}
+	if !domain.MusicSortFields[query.SortBy] {
+		return nil, domain.ErrInvalidInput
+	}
+
+	// นับจำนวนทั้งหมดที่ตรงเงื่อนไข (ไม่รวมเงื่อนไขของ cursor)
+	var total int64
+	if err := r.filtered(ctx, query).Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	// backward = ย้อนหน้า ต้องกลับทิศการเรียงแล้วค่อยกลับลำดับผลลัพธ์ทีหลัง
+	backward := cursor != nil && cursor.Backward
+	desc := query.SortDesc != backward
+	dir := "ASC"
+	if desc {
+		dir = "DESC"
+	}
+
+	tx := r.filtered(ctx, query).
+		Order(query.SortBy + " " + dir).
+		Order("id " + dir).
+		Limit(query.PageSize + 1)
+
+	page := &domain.MusicPage{PageSize: query.PageSize, Total: total}
+	if cursor != nil {
+		value, err := parseSortValue(cursor.SortBy, cursor.Value)
+		if err != nil {
+			return nil, err
+		}
+		op := ">"
+		if desc {
+			op = "<"
+		}
+		tx = tx.Where("("+query.SortBy+", id) "+op+" (?, ?)", value, cursor.ID)
+	} else {
+		page.Page = query.Page
+		tx = tx.Offset((query.Page - 1) * query.PageSize)
+	}
+
+	musics := make([]domain.Music, 0, query.PageSize+1)
+	if err := withMusicRelations(tx).Find(&musics).Error; err != nil {
 		return nil, err
 	}
-	// คืนค่า slice ของ music
-	return musics, nil
+
+	// ดึงมาเกิน 1 แถวเพื่อรู้ว่ายังมีข้อมูลต่อในทิศทางนั้นหรือไม่
+	hasMore := len(musics) > query.PageSize
+	if hasMore {
+		musics = musics[:query.PageSize]
+	}
+	if backward {
+		for i, j := 0, len(musics)-1; i < j; i, j = i+1, j-1 {
+			musics[i], musics[j] = musics[j], musics[i]
+		}
+	}
+	page.Items = musics
+
+	if len(musics) > 0 {
+		first, last := &musics[0], &musics[len(musics)-1]
+		hasNext := hasMore || backward
+		hasPrev := (backward && hasMore) || (!backward && cursor != nil) || (cursor == nil && query.Page > 1)
+		if hasNext {
+			page.NextCursor = encodeCursor(pageCursor{SortBy: query.SortBy, Desc: query.SortDesc, Value: musicSortValue(last, query.SortBy), ID: last.ID})
+		}
+		if hasPrev {
+			page.PrevCursor = encodeCursor(pageCursor{SortBy: query.SortBy, Desc: query.SortDesc, Value: musicSortValue(first, query.SortBy), ID: first.ID, Backward: true})
+		}
+	}
+
+	return page, nil
+}
+
+// filtered สร้าง query ของตาราง musics พร้อมเงื่อนไขการกรองจาก MusicQuery
+func (r *musicRepository) filtered(ctx context.Context, query domain.MusicQuery) *gorm.DB {
+	tx := r.db.WithContext(ctx).Model(&domain.Music{})
+	if query.Artist != "" {
+		tx = tx.Where("LOWER(artist) = LOWER(?)", query.Artist)
+	}
+	if query.Title != "" {
+		tx = tx.Where("title ILIKE ?", "%"+escapeLike(query.Title)+"%")
+	}
+	if query.CreatedBy != "" {
+		tx = tx.Where("created_by = ?", query.CreatedBy)
+	}
+	if query.ArtistID != 0 {
+		tx = tx.Where("EXISTS (SELECT 1 FROM music_artists ma WHERE ma.music_id = musics.id AND ma.artist_id = ?)", query.ArtistID)
+	}
+	if query.AlbumID != 0 {
+		tx = tx.Where("album_id = ?", query.AlbumID)
+	}
+	if query.CreatedFrom != nil {
+		tx = tx.Where("created_at >= ?", *query.CreatedFrom)
+	}
+	if query.CreatedTo != nil {
+		tx = tx.Where("created_at < ?", *query.CreatedTo)
+	}
+	return tx
 }
 
 // Update อัปเดตข้อมูลเพลง
 func (r *musicRepository) Update(ctx context.Context, music *domain.Music) error {
-	// บันทึกการเปลี่ยนแปลงข้อมูลทั้งหมดของ object music ลงฐานข้อมูล
-	return r.db.WithContext(ctx).Save(music).Error
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// บันทึกการเปลี่ยนแปลงข้อมูลทั้งหมดของ object music ลงฐานข้อมูล
+		if err := tx.Omit(clause.Associations).Save(music).Error; err != nil {
+			return err
+		}
+		// แทนที่รายชื่อศิลปินเฉพาะเมื่อมีการระบุมา (nil = ไม่เปลี่ยน)
+		if music.Artists != nil {
+			if err := replaceMusicArtists(tx, music.ID, music.Artists); err != nil {
+				return err
+			}
+		}
+		// อัปเดตข้อมูลสำหรับ full-text search ให้ตรงกับข้อมูลใหม่
+		return updateSearchVector(ctx, tx, music)
+	})
 }
 
 // Delete ลบเพลงตาม ID
@@ -63,3 +212,25 @@ func (r *musicRepository) Delete(ctx context.Context, id uint) error {
 	// ลบข้อมูลจากตาราง musics โดยระบุ ID
 	return r.db.WithContext(ctx).Delete(&domain.Music{}, id).Error
 }
+
+// ListByOwner ดึงเพลงทั้งหมดของผู้ใช้ เรียงตามลำดับที่สร้าง
+func (r *musicRepository) ListByOwner(ctx context.Context, ownerID uint) ([]domain.Music, error) {
+	musics := []domain.Music{}
+	err := withMusicRelations(r.db.WithContext(ctx)).
+		Where("owner_id = ?", ownerID).
+		Order("id").
+		Find(&musics).Error
+	return musics, err
+}
+
+// ListMedia ดึงเฉพาะ ID และ URL ไฟล์ของเพลงทีละชุด เรียงตาม ID
+func (r *musicRepository) ListMedia(ctx context.Context, afterID uint, limit int) ([]domain.Music, error) {
+	var musics []domain.Music
+	err := r.db.WithContext(ctx).
+		Select("id", "mp3_url", "mp4_url", "image_url").
+		Where("id > ?", afterID).
+		Order("id").
+		Limit(limit).
+		Find(&musics).Error
+	return musics, err
+}
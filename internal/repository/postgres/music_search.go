@@ -0,0 +1,128 @@
+package postgres // ประกาศ package postgres
+
+import (
+	"context" // นำเข้า context
+	"strconv" // นำเข้า strconv
+	"strings" // นำเข้า strings
+
+	"go-music-api/internal/domain" // นำเข้า domain entities
+	"go-music-api/pkg/textseg"     // นำเข้า textseg สำหรับตัดคำภาษาไทย
+
+	"gorm.io/gorm" // นำเข้า gorm ORM
+)
+
+// ข้อจำกัดของ tsvector ใน Postgres
+const (
+	maxLexemeBytes     = 2046  // ความยาวสูงสุดของ lexeme
+	maxLexemePositions = 255   // จำนวนตำแหน่งสูงสุดต่อ lexeme
+	maxTSPosition      = 16383 // ค่าตำแหน่งสูงสุด
+)
+
+// quoteLexeme ครอบ lexeme ด้วย ' และ escape ตามรูปแบบ input ของ tsvector/tsquery
+func quoteLexeme(w string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `''`).Replace(w) + "'"
+}
+
+// buildSearchVector สร้าง tsvector literal จากชื่อเพลง (A) ศิลปิน (B) และเนื้อเพลง (C)
+// ตัดคำเองใน Go แทน parser ของ Postgres เพราะ parser ไม่รู้จักขอบเขตคำภาษาไทย
+func buildSearchVector(m *domain.Music) string {
+	positions := map[string][]string{}
+	var order []string
+	pos := 0
+
+	add := func(text string, weight string) {
+		for _, w := range textseg.Words(text) {
+			if pos >= maxTSPosition {
+				return
+			}
+			pos++
+			if len(w) > maxLexemeBytes || len(positions[w]) >= maxLexemePositions {
+				continue
+			}
+			if _, ok := positions[w]; !ok {
+				order = append(order, w)
+			}
+			positions[w] = append(positions[w], strconv.Itoa(pos)+weight)
+		}
+	}
+	add(m.Title, "A")
+	add(m.Artist, "B")
+	add(m.Lyrics, "C")
+
+	parts := make([]string, 0, len(order))
+	for _, w := range order {
+		parts = append(parts, quoteLexeme(w)+":"+strings.Join(positions[w], ","))
+	}
+	return strings.Join(parts, " ")
+}
+
+// buildSearchQuery สร้าง tsquery literal ที่ต้องมีทุกคำในข้อความค้นหา
+func buildSearchQuery(q string) string {
+	seen := map[string]bool{}
+	var parts []string
+	for _, w := range textseg.Words(q) {
+		if seen[w] || len(w) > maxLexemeBytes {
+			continue
+		}
+		seen[w] = true
+		parts = append(parts, quoteLexeme(w))
+	}
+	return strings.Join(parts, " & ")
+}
+
+// updateSearchVector คำนวณและบันทึก search_vector ของเพลง
+func updateSearchVector(ctx context.Context, db *gorm.DB, m *domain.Music) error {
+	return db.WithContext(ctx).
+		Model(&domain.Music{}).
+		Where("id = ?", m.ID).
+		UpdateColumn("search_vector", gorm.Expr("?::tsvector", buildSearchVector(m))).Error
+}
+
+// Search ค้นหาเพลงแบบ full-text เรียงตามคะแนนความเกี่ยวข้อง
+func (r *musicRepository) Search(ctx context.Context, query domain.MusicSearchQuery) (*domain.MusicSearchPage, error) {
+	page := &domain.MusicSearchPage{
+		Items:    []domain.MusicSearchResult{},
+		Page:     query.Page,
+		PageSize: query.PageSize,
+	}
+
+	tsquery := buildSearchQuery(query.Q)
+	if tsquery == "" {
+		return page, nil
+	}
+
+	matched := func() *gorm.DB {
+		return r.db.WithContext(ctx).Model(&domain.Music{}).Where("search_vector @@ ?::tsquery", tsquery)
+	}
+
+	if err := matched().Count(&page.Total).Error; err != nil {
+		return nil, err
+	}
+
+	err := matched().
+		Select("musics.*, ts_rank_cd(search_vector, ?::tsquery) AS rank", tsquery).
+		Order("rank DESC").
+		Order("id").
+		Limit(query.PageSize).
+		Offset((query.Page - 1) * query.PageSize).
+		Find(&page.Items).Error
+	if err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// BackfillMusicSearchVectors สร้าง search_vector ให้เพลงที่ยังไม่มี (เช่น ข้อมูลก่อนเปิดใช้การค้นหา)
+func BackfillMusicSearchVectors(ctx context.Context, db *gorm.DB) error {
+	var batch []domain.Music
+	return db.WithContext(ctx).
+		Where("search_vector IS NULL").
+		FindInBatches(&batch, 200, func(tx *gorm.DB, _ int) error {
+			for i := range batch {
+				if err := updateSearchVector(ctx, db, &batch[i]); err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+}
@@ -0,0 +1,83 @@
+package postgres // ประกาศ package postgres
+
+import (
+	"encoding/base64" // นำเข้า base64 สำหรับเข้ารหัส cursor
+	"encoding/json"   // นำเข้า json สำหรับ serialize cursor
+	"strconv"         // นำเข้า strconv
+	"strings"         // นำเข้า strings
+	"time"            // นำเข้า time
+
+	"go-music-api/internal/domain" // นำเข้า domain entities
+)
+
+// pageCursor ข้อมูลภายใน cursor (client มองเห็นเป็น string แบบ opaque)
+type pageCursor struct {
+	SortBy   string `json:"s"`           // คอลัมน์ที่ใช้เรียง
+	Desc     bool   `json:"d,omitempty"` // เรียงจากมากไปน้อยหรือไม่
+	Value    string `json:"v"`           // ค่าของคอลัมน์ที่ใช้เรียงของแถวอ้างอิง
+	ID       uint   `json:"i"`           // ID ของแถวอ้างอิง (ใช้ตัดสินกรณีค่าซ้ำ)
+	Backward bool   `json:"b,omitempty"` // true = ย้อนไปหน้าก่อนหน้า
+}
+
+// encodeCursor แปลง cursor เป็น string แบบ base64 URL-safe
+func encodeCursor(c pageCursor) string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// decodeCursor แปลง string กลับเป็น cursor และตรวจสอบความถูกต้อง
+func decodeCursor(s string) (*pageCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, domain.ErrInvalidInput
+	}
+	var c pageCursor
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return nil, domain.ErrInvalidInput
+	}
+	if !domain.MusicSortFields[c.SortBy] {
+		return nil, domain.ErrInvalidInput
+	}
+	return &c, nil
+}
+
+// musicSortValue ดึงค่าของคอลัมน์ที่ใช้เรียงจาก Music เป็น string
+func musicSortValue(m *domain.Music, column string) string {
+	switch column {
+	case "title":
+		return m.Title
+	case "artist":
+		return m.Artist
+	case "created_at":
+		return m.CreatedAt.UTC().Format(time.RFC3339Nano)
+	case "updated_at":
+		return m.UpdatedAt.UTC().Format(time.RFC3339Nano)
+	default:
+		return strconv.FormatUint(uint64(m.ID), 10)
+	}
+}
+
+// parseSortValue แปลงค่าใน cursor กลับเป็นชนิดข้อมูลที่ตรงกับคอลัมน์
+func parseSortValue(column, value string) (any, error) {
+	switch column {
+	case "created_at", "updated_at":
+		t, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return nil, domain.ErrInvalidInput
+		}
+		return t, nil
+	case "id":
+		id, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return nil, domain.ErrInvalidInput
+		}
+		return uint(id), nil
+	default:
+		return value, nil
+	}
+}
+
+// escapeLike escape อักขระพิเศษของ LIKE เพื่อให้ค้นหาแบบ substring ตรงตัว
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
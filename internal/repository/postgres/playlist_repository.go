@@ -0,0 +1,298 @@
+package postgres // ประกาศ package postgres
+
+import (
+	"context" // นำเข้า context
+	"errors"  // นำเข้า errors
+
+	"go-music-api/internal/domain" // นำเข้า domain entities
+
+	"gorm.io/gorm"        // นำเข้า gorm ORM
+	"gorm.io/gorm/clause" // นำเข้า clause สำหรับ row lock และละเว้น associations
+)
+
+// positionStep ระยะห่างของ Position ระหว่างเพลงที่ต่อกัน เว้นไว้เพื่อให้แทรกตรงกลางได้
+const positionStep int64 = 1 << 16
+
+// playlistRepository struct สำหรับ implement interface PlaylistRepository
+type playlistRepository struct {
+	db *gorm.DB // เก็บ connection ของ database
+}
+
+// NewPlaylistRepository สร้าง instance ของ PlaylistRepository
+func NewPlaylistRepository(db *gorm.DB) domain.PlaylistRepository {
+	return &playlistRepository{db: db}
+}
+
+// Create สร้าง playlist ใหม่
+func (r *playlistRepository) Create(ctx context.Context, playlist *domain.Playlist) error {
+	if err := r.db.WithContext(ctx).Omit(clause.Associations).Create(playlist).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return domain.ErrConflict
+		}
+		return err
+	}
+	return nil
+}
+
+// GetByID ดึง playlist พร้อมเจ้าของและผู้ร่วมแก้ไข (ไม่รวมรายการเพลง)
+func (r *playlistRepository) GetByID(ctx context.Context, id uint) (*domain.Playlist, error) {
+	return r.first(ctx, "id = ?", id)
+}
+
+// GetByShareToken ดึง playlist จาก token แชร์
+func (r *playlistRepository) GetByShareToken(ctx context.Context, token string) (*domain.Playlist, error) {
+	return r.first(ctx, "share_token = ?", token)
+}
+
+// first ดึง playlist แรกที่ตรงเงื่อนไขพร้อมเจ้าของและผู้ร่วมแก้ไข
+func (r *playlistRepository) first(ctx context.Context, query string, args ...any) (*domain.Playlist, error) {
+	var playlist domain.Playlist
+	err := r.db.WithContext(ctx).
+		Preload("Owner").
+		Preload("Collaborators.User").
+		Where(query, args...).
+		First(&playlist).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &playlist, nil
+}
+
+// ListByUser ดึง playlist ที่ผู้ใช้เป็นเจ้าของหรือเป็นผู้ร่วมแก้ไข
+func (r *playlistRepository) ListByUser(ctx context.Context, userID uint, page, pageSize int) ([]domain.Playlist, int64, error) {
+	tx := r.db.WithContext(ctx).Model(&domain.Playlist{}).
+		Where("owner_id = ? OR EXISTS (SELECT 1 FROM playlist_collaborators pc WHERE pc.playlist_id = playlists.id AND pc.user_id = ?)", userID, userID)
+	return r.list(tx, page, pageSize)
+}
+
+// ListPublic ดึง playlist สาธารณะ
+func (r *playlistRepository) ListPublic(ctx context.Context, page, pageSize int) ([]domain.Playlist, int64, error) {
+	tx := r.db.WithContext(ctx).Model(&domain.Playlist{}).Where("visibility = ?", domain.PlaylistPublic)
+	return r.list(tx, page, pageSize)
+}
+
+// list นับและดึง playlist แบบแบ่งหน้า เรียงจากแก้ไขล่าสุด
+func (r *playlistRepository) list(tx *gorm.DB, page, pageSize int) ([]domain.Playlist, int64, error) {
+	var total int64
+	if err := tx.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	playlists := make([]domain.Playlist, 0, pageSize)
+	err := tx.Preload("Owner").
+		Order("updated_at DESC").Order("id").
+		Limit(pageSize).
+		Offset((page - 1) * pageSize).
+		Find(&playlists).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return playlists, total, nil
+}
+
+// Update อัปเดตข้อมูล playlist (ไม่แตะรายการเพลงและผู้ร่วมแก้ไข)
+func (r *playlistRepository) Update(ctx context.Context, playlist *domain.Playlist) error {
+	if err := r.db.WithContext(ctx).Omit(clause.Associations).Save(playlist).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return domain.ErrConflict
+		}
+		return err
+	}
+	return nil
+}
+
+// Delete ลบ playlist (รายการเพลงและผู้ร่วมแก้ไขถูกลบตาม foreign key)
+func (r *playlistRepository) Delete(ctx context.Context, id uint) error {
+	res := r.db.WithContext(ctx).Delete(&domain.Playlist{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// GetEntries ดึงรายการเพลงใน playlist เรียงตามลำดับ
+func (r *playlistRepository) GetEntries(ctx context.Context, playlistID uint) ([]domain.PlaylistEntry, error) {
+	entries := []domain.PlaylistEntry{}
+	err := r.db.WithContext(ctx).
+		Preload("Music").
+		Where("playlist_id = ?", playlistID).
+		Order("position").Order("id").
+		Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// InsertEntry แทรกเพลงที่ลำดับ index (เริ่มที่ 0) ถ้า index เป็น nil หรือเกินจำนวนจะต่อท้าย
+// แก้ไขเพียงแถวเดียว ยกเว้นกรณีช่องว่างระหว่าง Position หมดจึงจัดเรียงใหม่ทั้ง playlist
+func (r *playlistRepository) InsertEntry(ctx context.Context, entry *domain.PlaylistEntry, index *int) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockPlaylist(tx, entry.PlaylistID); err != nil {
+			return err
+		}
+		pos, err := positionAt(tx, entry.PlaylistID, 0, index)
+		if err != nil {
+			return err
+		}
+		entry.Position = pos
+		return tx.Omit(clause.Associations).Create(entry).Error
+	})
+}
+
+// MoveEntry ย้ายเพลงไปที่ลำดับ index โดยแก้ Position ของแถวนั้นเพียงแถวเดียว
+func (r *playlistRepository) MoveEntry(ctx context.Context, playlistID, entryID uint, index int) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockPlaylist(tx, playlistID); err != nil {
+			return err
+		}
+		var count int64
+		if err := tx.Model(&domain.PlaylistEntry{}).Where("id = ? AND playlist_id = ?", entryID, playlistID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return domain.ErrNotFound
+		}
+		pos, err := positionAt(tx, playlistID, entryID, &index)
+		if err != nil {
+			return err
+		}
+		return tx.Model(&domain.PlaylistEntry{}).Where("id = ?", entryID).Update("position", pos).Error
+	})
+}
+
+// RemoveEntry ลบเพลงออกจาก playlist (ลำดับของเพลงอื่นไม่ต้องแก้)
+func (r *playlistRepository) RemoveEntry(ctx context.Context, playlistID, entryID uint) error {
+	res := r.db.WithContext(ctx).Where("id = ? AND playlist_id = ?", entryID, playlistID).Delete(&domain.PlaylistEntry{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// AddCollaborator เพิ่มผู้ร่วมแก้ไข (ถ้ามีอยู่แล้วถือว่าสำเร็จ)
+func (r *playlistRepository) AddCollaborator(ctx context.Context, playlistID, userID uint) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Omit(clause.Associations).
+		Create(&domain.PlaylistCollaborator{PlaylistID: playlistID, UserID: userID}).Error
+}
+
+// RemoveCollaborator ลบผู้ร่วมแก้ไข
+func (r *playlistRepository) RemoveCollaborator(ctx context.Context, playlistID, userID uint) error {
+	res := r.db.WithContext(ctx).
+		Where("playlist_id = ? AND user_id = ?", playlistID, userID).
+		Delete(&domain.PlaylistCollaborator{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// lockPlaylist ล็อกแถว playlist เพื่อไม่ให้การคำนวณ Position ของหลาย request ชนกัน
+func lockPlaylist(tx *gorm.DB, playlistID uint) error {
+	var p domain.Playlist
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&p, playlistID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return domain.ErrNotFound
+	}
+	return err
+}
+
+// positionAt คำนวณ Position สำหรับวางเพลงที่ลำดับ index โดยไม่นับแถว excludeID (แถวที่กำลังย้าย)
+func positionAt(tx *gorm.DB, playlistID, excludeID uint, index *int) (int64, error) {
+	for attempt := 0; attempt < 2; attempt++ {
+		prev, next, err := neighbours(tx, playlistID, excludeID, index)
+		if err != nil {
+			return 0, err
+		}
+		switch {
+		case prev == nil && next == nil:
+			return positionStep, nil
+		case next == nil:
+			return *prev + positionStep, nil
+		case prev == nil:
+			return *next - positionStep, nil
+		case *next-*prev >= 2:
+			return *prev + (*next-*prev)/2, nil
+		}
+		// ช่องว่างหมดแล้ว จัดเรียง Position ใหม่ทั้งหมดแล้วลองอีกครั้ง
+		if err := renumberEntries(tx, playlistID); err != nil {
+			return 0, err
+		}
+	}
+	return 0, errors.New("playlist: unable to allocate entry position")
+}
+
+// neighbours หา Position ของเพลงก่อนหน้าและถัดไปของตำแหน่ง index
+func neighbours(tx *gorm.DB, playlistID, excludeID uint, index *int) (*int64, *int64, error) {
+	base := func() *gorm.DB {
+		return tx.Model(&domain.PlaylistEntry{}).Where("playlist_id = ? AND id <> ?", playlistID, excludeID)
+	}
+
+	if index == nil {
+		var last []int64
+		if err := base().Order("position DESC").Limit(1).Pluck("position", &last).Error; err != nil {
+			return nil, nil, err
+		}
+		if len(last) == 0 {
+			return nil, nil, nil
+		}
+		return &last[0], nil, nil
+	}
+
+	i := *index
+	if i < 0 {
+		i = 0
+	}
+	offset, limit := i-1, 2
+	if i == 0 {
+		offset, limit = 0, 1
+	}
+	var positions []int64
+	if err := base().Order("position").Order("id").Offset(offset).Limit(limit).Pluck("position", &positions).Error; err != nil {
+		return nil, nil, err
+	}
+
+	if i == 0 {
+		if len(positions) == 0 {
+			return nil, nil, nil
+		}
+		return nil, &positions[0], nil
+	}
+	switch len(positions) {
+	case 0:
+		// index เกินจำนวนเพลง ให้ต่อท้าย
+		return neighbours(tx, playlistID, excludeID, nil)
+	case 1:
+		return &positions[0], nil, nil
+	default:
+		return &positions[0], &positions[1], nil
+	}
+}
+
+// renumberEntries จัด Position ของทุกเพลงใน playlist ใหม่ให้ห่างกันเท่า positionStep
+func renumberEntries(tx *gorm.DB, playlistID uint) error {
+	var ids []uint
+	if err := tx.Model(&domain.PlaylistEntry{}).Where("playlist_id = ?", playlistID).Order("position").Order("id").Pluck("id", &ids).Error; err != nil {
+		return err
+	}
+	for i, id := range ids {
+		if err := tx.Model(&domain.PlaylistEntry{}).Where("id = ?", id).Update("position", int64(i+1)*positionStep).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
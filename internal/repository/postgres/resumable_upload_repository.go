@@ -0,0 +1,59 @@
+package postgres // ประกาศ package postgres
+
+import (
+	"context" // นำเข้า context
+	"errors"  // นำเข้า errors
+	"time"    // นำเข้า time
+
+	"go-music-api/internal/domain" // นำเข้า domain entities
+
+	"gorm.io/gorm" // นำเข้า gorm ORM
+)
+
+// resumableUploadRepository struct สำหรับ implement interface ResumableUploadRepository
+type resumableUploadRepository struct {
+	db *gorm.DB // เก็บ connection ของ database
+}
+
+// NewResumableUploadRepository สร้าง instance ของ ResumableUploadRepository
+func NewResumableUploadRepository(db *gorm.DB) domain.ResumableUploadRepository {
+	return &resumableUploadRepository{db: db}
+}
+
+// Create บันทึกการอัปโหลดใหม่
+func (r *resumableUploadRepository) Create(ctx context.Context, upload *domain.ResumableUpload) error {
+	return r.db.WithContext(ctx).Create(upload).Error
+}
+
+// GetByID ดึงข้อมูลการอัปโหลดตาม ID
+func (r *resumableUploadRepository) GetByID(ctx context.Context, id string) (*domain.ResumableUpload, error) {
+	var upload domain.ResumableUpload
+	if err := r.db.WithContext(ctx).First(&upload, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &upload, nil
+}
+
+// Update บันทึก offset และสถานะของการอัปโหลด
+func (r *resumableUploadRepository) Update(ctx context.Context, upload *domain.ResumableUpload) error {
+	return r.db.WithContext(ctx).Save(upload).Error
+}
+
+// Delete ลบข้อมูลการอัปโหลด
+func (r *resumableUploadRepository) Delete(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Delete(&domain.ResumableUpload{}, "id = ?", id).Error
+}
+
+// ListExpired ดึงการอัปโหลดที่ยังไม่ครบและหมดอายุก่อน before
+func (r *resumableUploadRepository) ListExpired(ctx context.Context, before time.Time, limit int) ([]domain.ResumableUpload, error) {
+	var uploads []domain.ResumableUpload
+	err := r.db.WithContext(ctx).
+		Where("status <> ? AND expires_at < ?", domain.UploadCompleted, before).
+		Order("expires_at").
+		Limit(limit).
+		Find(&uploads).Error
+	return uploads, err
+}
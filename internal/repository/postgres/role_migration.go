@@ -0,0 +1,19 @@
+package postgres // ประกาศ package postgres
+
+import (
+	"context" // นำเข้า context
+
+	"go-music-api/internal/domain" // นำเข้า domain entities
+
+	"gorm.io/gorm" // นำเข้า gorm ORM
+)
+
+// PromoteAdmins กำหนดบทบาท admin ให้ผู้ใช้ตามรายการอีเมล (ใช้สร้าง admin คนแรกของระบบ)
+func PromoteAdmins(ctx context.Context, db *gorm.DB, emails []string) error {
+	if len(emails) == 0 {
+		return nil
+	}
+	return db.WithContext(ctx).Model(&domain.User{}).
+		Where("email IN ?", emails).
+		Update("role", domain.RoleAdmin).Error
+}
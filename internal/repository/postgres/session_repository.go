@@ -0,0 +1,114 @@
+package postgres // ประกาศ package postgres
+
+import (
+	"context" // นำเข้า context
+	"errors"  // นำเข้า errors
+	"time"    // นำเข้า time
+
+	"go-music-api/internal/domain" // นำเข้า domain entities
+
+	"gorm.io/gorm" // นำเข้า gorm ORM
+)
+
+// sessionRepository struct สำหรับ implement interface SessionRepository
+type sessionRepository struct {
+	db *gorm.DB // เก็บ connection ของ database
+}
+
+// NewSessionRepository สร้าง instance ของ SessionRepository
+func NewSessionRepository(db *gorm.DB) domain.SessionRepository {
+	return &sessionRepository{db: db}
+}
+
+// Create บันทึก session ใหม่พร้อม refresh token ตัวแรก
+func (r *sessionRepository) Create(ctx context.Context, session *domain.Session, tokenHash string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(session).Error; err != nil {
+			return err
+		}
+		return tx.Create(&domain.SessionToken{TokenHash: tokenHash, SessionID: session.ID}).Error
+	})
+}
+
+// GetByID ดึง session ตาม ID
+func (r *sessionRepository) GetByID(ctx context.Context, id string) (*domain.Session, error) {
+	var session domain.Session
+	if err := r.db.WithContext(ctx).First(&session, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+// GetToken ดึง refresh token ตาม hash พร้อม session ของมัน
+func (r *sessionRepository) GetToken(ctx context.Context, tokenHash string) (*domain.SessionToken, error) {
+	var token domain.SessionToken
+	if err := r.db.WithContext(ctx).Preload("Session").First(&token, "token_hash = ?", tokenHash).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// Rotate ทำเครื่องหมายว่า token เดิมถูกใช้แล้วและบันทึก token ใหม่ใน transaction เดียวกัน
+// ถ้า token เดิมถูกใช้ไปแล้ว (เช่น request พร้อมกันหรือถูกขโมย) จะคืน ErrConflict
+func (r *sessionRepository) Rotate(ctx context.Context, session *domain.Session, oldHash, newHash string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&domain.SessionToken{}).
+			Where("token_hash = ? AND used_at IS NULL", oldHash).
+			Update("used_at", time.Now())
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return domain.ErrConflict
+		}
+		if err := tx.Create(&domain.SessionToken{TokenHash: newHash, SessionID: session.ID}).Error; err != nil {
+			return err
+		}
+		return tx.Model(session).Select("user_agent", "ip", "last_used_at", "expires_at").Updates(session).Error
+	})
+}
+
+// ListActive ดึง session ที่ยังไม่หมดอายุและไม่ถูกยกเลิกของผู้ใช้ เรียงตามการใช้งานล่าสุด
+func (r *sessionRepository) ListActive(ctx context.Context, userID uint, now time.Time) ([]domain.Session, error) {
+	var sessions []domain.Session
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, now).
+		Order("last_used_at DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// Revoke ยกเลิก session (refresh token ทุกตัวใน family ใช้ไม่ได้อีก)
+func (r *sessionRepository) Revoke(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Model(&domain.Session{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeAll ยกเลิกทุก session ของผู้ใช้
+func (r *sessionRepository) RevokeAll(ctx context.Context, userID uint) error {
+	return r.db.WithContext(ctx).Model(&domain.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeOthers ยกเลิกทุก session ของผู้ใช้ยกเว้น keepID
+func (r *sessionRepository) RevokeOthers(ctx context.Context, userID uint, keepID string) error {
+	return r.db.WithContext(ctx).Model(&domain.Session{}).
+		Where("user_id = ? AND id <> ? AND revoked_at IS NULL", userID, keepID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// DeleteExpired ลบ session ที่หมดอายุหรือถูกยกเลิกก่อน before (token ถูกลบตามด้วย cascade)
+func (r *sessionRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	res := r.db.WithContext(ctx).
+		Where("expires_at < ? OR revoked_at < ?", before, before).
+		Delete(&domain.Session{})
+	return res.RowsAffected, res.Error
+}
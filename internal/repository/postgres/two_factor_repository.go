@@ -0,0 +1,138 @@
+package postgres // ประกาศ package postgres
+
+import (
+	"context" // นำเข้า context
+	"errors"  // นำเข้า errors
+	"time"    // นำเข้า time
+
+	"go-music-api/internal/domain" // นำเข้า domain entities
+
+	"gorm.io/gorm"        // นำเข้า gorm ORM
+	"gorm.io/gorm/clause" // นำเข้า clause สำหรับ upsert
+)
+
+// twoFactorRepository struct สำหรับ implement interface TwoFactorRepository
+type twoFactorRepository struct {
+	db *gorm.DB // เก็บ connection ของ database
+}
+
+// NewTwoFactorRepository สร้าง instance ของ TwoFactorRepository
+func NewTwoFactorRepository(db *gorm.DB) domain.TwoFactorRepository {
+	return &twoFactorRepository{db: db}
+}
+
+// Get ดึงการตั้งค่า TOTP ของผู้ใช้
+func (r *twoFactorRepository) Get(ctx context.Context, userID uint) (*domain.TwoFactor, error) {
+	var tf domain.TwoFactor
+	if err := r.db.WithContext(ctx).First(&tf, "user_id = ?", userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &tf, nil
+}
+
+// Save สร้างหรือแทนที่การตั้งค่า TOTP ของผู้ใช้
+func (r *twoFactorRepository) Save(ctx context.Context, tf *domain.TwoFactor) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"secret", "confirmed_at", "last_step", "updated_at"}),
+	}).Create(tf).Error
+}
+
+// Delete ปิด 2FA ของผู้ใช้และลบรหัสกู้คืนทั้งหมด
+func (r *twoFactorRepository) Delete(ctx context.Context, userID uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&domain.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&domain.TwoFactor{}).Error
+	})
+}
+
+// UseStep บันทึกช่วงเวลาของรหัสที่ใช้ เฉพาะเมื่อใหม่กว่าช่วงเวลาล่าสุด (รหัสเดิมใช้ซ้ำไม่ได้แม้ส่งพร้อมกัน)
+func (r *twoFactorRepository) UseStep(ctx context.Context, userID uint, step int64) error {
+	res := r.db.WithContext(ctx).Model(&domain.TwoFactor{}).
+		Where("user_id = ? AND last_step < ?", userID, step).
+		Update("last_step", step)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return domain.ErrConflict
+	}
+	return nil
+}
+
+// ReplaceRecoveryCodes ลบรหัสกู้คืนเดิมทั้งหมดและบันทึกชุดใหม่
+func (r *twoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, userID uint, hashes []string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&domain.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		codes := make([]domain.RecoveryCode, len(hashes))
+		for i, h := range hashes {
+			codes[i] = domain.RecoveryCode{UserID: userID, CodeHash: h}
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+// UseRecoveryCode ทำเครื่องหมายว่ารหัสกู้คืนถูกใช้แล้ว
+func (r *twoFactorRepository) UseRecoveryCode(ctx context.Context, userID uint, hash string) error {
+	res := r.db.WithContext(ctx).Model(&domain.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hash).
+		Update("used_at", time.Now())
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// CountRecoveryCodes นับรหัสกู้คืนที่ยังไม่ได้ใช้
+func (r *twoFactorRepository) CountRecoveryCodes(ctx context.Context, userID uint) (int, error) {
+	var n int64
+	err := r.db.WithContext(ctx).Model(&domain.RecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&n).Error
+	return int(n), err
+}
+
+// CreateChallenge บันทึก challenge ใหม่
+func (r *twoFactorRepository) CreateChallenge(ctx context.Context, challenge *domain.LoginChallenge) error {
+	return r.db.WithContext(ctx).Create(challenge).Error
+}
+
+// GetChallenge ดึง challenge ตาม hash ของ token
+func (r *twoFactorRepository) GetChallenge(ctx context.Context, tokenHash string) (*domain.LoginChallenge, error) {
+	var challenge domain.LoginChallenge
+	if err := r.db.WithContext(ctx).First(&challenge, "token_hash = ?", tokenHash).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &challenge, nil
+}
+
+// AddChallengeAttempt เพิ่มจำนวนครั้งที่ส่งรหัสผิด
+func (r *twoFactorRepository) AddChallengeAttempt(ctx context.Context, tokenHash string) error {
+	return r.db.WithContext(ctx).Model(&domain.LoginChallenge{}).
+		Where("token_hash = ?", tokenHash).
+		UpdateColumn("attempts", gorm.Expr("attempts + 1")).Error
+}
+
+// DeleteChallenge ลบ challenge
+func (r *twoFactorRepository) DeleteChallenge(ctx context.Context, tokenHash string) error {
+	return r.db.WithContext(ctx).Delete(&domain.LoginChallenge{}, "token_hash = ?", tokenHash).Error
+}
+
+// DeleteExpiredChallenges ลบ challenge ที่หมดอายุก่อน before
+func (r *twoFactorRepository) DeleteExpiredChallenges(ctx context.Context, before time.Time) (int64, error) {
+	res := r.db.WithContext(ctx).Where("expires_at < ?", before).Delete(&domain.LoginChallenge{})
+	return res.RowsAffected, res.Error
+}
@@ -0,0 +1,64 @@
+package postgres // ประกาศ package postgres
+
+import (
+	"context" // นำเข้า context สำหรับส่ง transaction ต่อให้ repository
+
+	"go-music-api/internal/domain" // นำเข้า domain entities
+
+	"gorm.io/gorm" // นำเข้า gorm ORM
+)
+
+// txKey key ของ transaction ที่เก็บใน context
+type txKey struct{}
+
+// txManager struct สำหรับ implement interface TxManager
+type txManager struct {
+	db *gorm.DB // connection หลักที่ใช้เปิด transaction
+}
+
+// NewTxManager สร้าง instance ของ TxManager
+func NewTxManager(db *gorm.DB) domain.TxManager {
+	return &txManager{db: db}
+}
+
+// WithinTx รัน fn ใน transaction ถ้า ctx มี transaction อยู่แล้วจะสร้าง savepoint แทน
+// error ใน fn ชั้นในจะ rollback เฉพาะถึง savepoint นั้น ชั้นนอกเลือกได้ว่าจะคืน error ต่อหรือทำงานต่อ
+// transaction ใช้ได้ทีละ goroutine ห้ามเรียก repository จาก ctx เดียวกันพร้อมกัน
+func (m *txManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return m.conn(ctx).WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// Music คืน MusicRepository ที่ผูกกับ transaction ใน ctx
+func (m *txManager) Music(ctx context.Context) domain.MusicRepository {
+	return NewMusicRepository(m.conn(ctx))
+}
+
+// Artist คืน ArtistRepository ที่ผูกกับ transaction ใน ctx
+func (m *txManager) Artist(ctx context.Context) domain.ArtistRepository {
+	return NewArtistRepository(m.conn(ctx))
+}
+
+// User คืน UserRepository ที่ผูกกับ transaction ใน ctx
+func (m *txManager) User(ctx context.Context) domain.UserRepository {
+	return NewUserRepository(m.conn(ctx))
+}
+
+// MediaObject คืน MediaObjectRepository ที่ผูกกับ transaction ใน ctx
+func (m *txManager) MediaObject(ctx context.Context) domain.MediaObjectRepository {
+	return NewMediaObjectRepository(m.conn(ctx))
+}
+
+// MediaDeletion คืน MediaDeletionRepository ที่ผูกกับ transaction ใน ctx
+func (m *txManager) MediaDeletion(ctx context.Context) domain.MediaDeletionRepository {
+	return NewMediaDeletionRepository(m.conn(ctx))
+}
+
+// conn คืน transaction ใน ctx หรือ connection หลักถ้าไม่ได้อยู่ใน transaction
+func (m *txManager) conn(ctx context.Context) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx
+	}
+	return m.db
+}
@@ -0,0 +1,278 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// fakeStore ฐานข้อมูลจำลองที่มีตารางเดียว เก็บแถวเป็นข้อความ
+type fakeStore struct {
+	mu   sync.Mutex
+	rows []string
+}
+
+func (s *fakeStore) committed() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.rows)
+}
+
+// fakeConnector สร้าง connection ที่จำลอง transaction และ savepoint ของ PostgreSQL
+type fakeConnector struct {
+	store *fakeStore
+}
+
+func (c *fakeConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return &fakeConn{store: c.store}, nil
+}
+
+func (c *fakeConnector) Driver() driver.Driver { return fakeDriver{} }
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) { return nil, errors.New("use fakeConnector") }
+
+// fakeConn รองรับเฉพาะ INSERT, SAVEPOINT และ ROLLBACK TO SAVEPOINT
+// แถวที่เขียนใน transaction จะเห็นเฉพาะใน connection นี้จนกว่าจะ commit
+type fakeConn struct {
+	store      *fakeStore
+	inTx       bool
+	rows       []string            // แถวที่ transaction เห็น
+	savepoints map[string][]string // แถวตอนสร้างแต่ละ savepoint
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, fmt.Errorf("prepare is not supported: %q", query)
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *fakeConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if c.inTx {
+		return nil, errors.New("transaction already started")
+	}
+	c.inTx = true
+	c.rows = c.store.committed()
+	c.savepoints = map[string][]string{}
+	return &fakeTx{conn: c}, nil
+}
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	switch {
+	case strings.HasPrefix(query, "INSERT "):
+		value, ok := args[0].Value.(string)
+		if !ok {
+			return nil, fmt.Errorf("unexpected argument %v", args[0].Value)
+		}
+		if !c.inTx {
+			c.store.mu.Lock()
+			c.store.rows = append(c.store.rows, value)
+			c.store.mu.Unlock()
+			return driver.RowsAffected(1), nil
+		}
+		c.rows = append(c.rows, value)
+	case strings.HasPrefix(query, "SAVEPOINT "):
+		c.savepoints[strings.TrimPrefix(query, "SAVEPOINT ")] = slices.Clone(c.rows)
+	case strings.HasPrefix(query, "ROLLBACK TO SAVEPOINT "):
+		rows, ok := c.savepoints[strings.TrimPrefix(query, "ROLLBACK TO SAVEPOINT ")]
+		if !ok {
+			return nil, errors.New("savepoint does not exist")
+		}
+		c.rows = slices.Clone(rows)
+	default:
+		return nil, fmt.Errorf("unexpected query %q", query)
+	}
+	return driver.RowsAffected(1), nil
+}
+
+type fakeTx struct {
+	conn *fakeConn
+}
+
+func (t *fakeTx) Commit() error {
+	t.conn.store.mu.Lock()
+	t.conn.store.rows = t.conn.rows
+	t.conn.store.mu.Unlock()
+	t.conn.inTx = false
+	return nil
+}
+
+func (t *fakeTx) Rollback() error {
+	t.conn.inTx = false
+	return nil
+}
+
+func newTestTxManager(t *testing.T) (*txManager, *fakeStore) {
+	t.Helper()
+	store := &fakeStore{}
+	sqlDB := sql.OpenDB(&fakeConnector{store: store})
+	t.Cleanup(func() { sqlDB.Close() })
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("gorm.Open() error = %v", err)
+	}
+	return &txManager{db: db}, store
+}
+
+// insert เขียนแถวผ่าน connection ที่ repository ได้จาก ctx
+func insert(m *txManager, ctx context.Context, value string) error {
+	return m.conn(ctx).WithContext(ctx).Exec("INSERT INTO items (name) VALUES (?)", value).Error
+}
+
+func TestWithinTx(t *testing.T) {
+	errFail := errors.New("fail")
+
+	tests := []struct {
+		name    string
+		fn      func(m *txManager) func(ctx context.Context) error
+		wantErr error
+		want    []string
+	}{
+		{
+			name: "commits when fn succeeds",
+			fn: func(m *txManager) func(ctx context.Context) error {
+				return func(ctx context.Context) error {
+					if err := insert(m, ctx, "a"); err != nil {
+						return err
+					}
+					return insert(m, ctx, "b")
+				}
+			},
+			want: []string{"a", "b"},
+		},
+		{
+			name: "rolls back when fn fails",
+			fn: func(m *txManager) func(ctx context.Context) error {
+				return func(ctx context.Context) error {
+					if err := insert(m, ctx, "a"); err != nil {
+						return err
+					}
+					return errFail
+				}
+			},
+			wantErr: errFail,
+		},
+		{
+			name: "inner error rolls back only the inner work",
+			fn: func(m *txManager) func(ctx context.Context) error {
+				return func(ctx context.Context) error {
+					if err := insert(m, ctx, "a"); err != nil {
+						return err
+					}
+					err := m.WithinTx(ctx, func(ctx context.Context) error {
+						if err := insert(m, ctx, "b"); err != nil {
+							return err
+						}
+						return errFail
+					})
+					if err != errFail {
+						return fmt.Errorf("inner WithinTx() error = %v, want %v", err, errFail)
+					}
+					return insert(m, ctx, "c")
+				}
+			},
+			want: []string{"a", "c"},
+		},
+		{
+			name: "outer error rolls back everything including committed inner work",
+			fn: func(m *txManager) func(ctx context.Context) error {
+				return func(ctx context.Context) error {
+					if err := insert(m, ctx, "a"); err != nil {
+						return err
+					}
+					if err := m.WithinTx(ctx, func(ctx context.Context) error {
+						return insert(m, ctx, "b")
+					}); err != nil {
+						return err
+					}
+					return errFail
+				}
+			},
+			wantErr: errFail,
+		},
+		{
+			name: "nested savepoints roll back to the right level",
+			fn: func(m *txManager) func(ctx context.Context) error {
+				return func(ctx context.Context) error {
+					return m.WithinTx(ctx, func(ctx context.Context) error {
+						if err := insert(m, ctx, "a"); err != nil {
+							return err
+						}
+						_ = m.WithinTx(ctx, func(ctx context.Context) error {
+							if err := insert(m, ctx, "b"); err != nil {
+								return err
+							}
+							return errFail
+						})
+						return insert(m, ctx, "c")
+					})
+				}
+			},
+			want: []string{"a", "c"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, store := newTestTxManager(t)
+
+			err := m.WithinTx(context.Background(), tt.fn(m))
+			if err != tt.wantErr {
+				t.Fatalf("WithinTx() error = %v, want %v", err, tt.wantErr)
+			}
+			if got := store.committed(); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("committed rows = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithinTxRollsBackOnPanic(t *testing.T) {
+	m, store := newTestTxManager(t)
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("WithinTx() did not re-panic")
+			}
+		}()
+		_ = m.WithinTx(context.Background(), func(ctx context.Context) error {
+			if err := insert(m, ctx, "a"); err != nil {
+				return err
+			}
+			panic("boom")
+		})
+	}()
+
+	if got := store.committed(); len(got) != 0 {
+		t.Fatalf("committed rows = %v, want none", got)
+	}
+}
+
+func TestConnOutsideTx(t *testing.T) {
+	m, store := newTestTxManager(t)
+
+	if err := insert(m, context.Background(), "a"); err != nil {
+		t.Fatalf("insert() error = %v", err)
+	}
+	if got := store.committed(); !reflect.DeepEqual(got, []string{"a"}) {
+		t.Fatalf("committed rows = %v, want [a]", got)
+	}
+}
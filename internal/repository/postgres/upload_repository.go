@@ -0,0 +1,42 @@
+package postgres // ประกาศ package postgres
+
+import (
+	"context" // นำเข้า context
+	"errors"  // นำเข้า errors
+
+	"go-music-api/internal/domain" // นำเข้า domain entities
+
+	"gorm.io/gorm" // นำเข้า gorm ORM
+)
+
+// uploadRepository struct สำหรับ implement interface UploadRepository
+type uploadRepository struct {
+	db *gorm.DB // เก็บ connection ของ database
+}
+
+// NewUploadRepository สร้าง instance ของ UploadRepository
+func NewUploadRepository(db *gorm.DB) domain.UploadRepository {
+	return &uploadRepository{db: db}
+}
+
+// Create บันทึกการอัปโหลดใหม่
+func (r *uploadRepository) Create(ctx context.Context, upload *domain.Upload) error {
+	return r.db.WithContext(ctx).Create(upload).Error
+}
+
+// GetByID ดึงข้อมูลการอัปโหลดตาม ID
+func (r *uploadRepository) GetByID(ctx context.Context, id string) (*domain.Upload, error) {
+	var upload domain.Upload
+	if err := r.db.WithContext(ctx).First(&upload, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &upload, nil
+}
+
+// Update บันทึกสถานะของการอัปโหลด
+func (r *uploadRepository) Update(ctx context.Context, upload *domain.Upload) error {
+	return r.db.WithContext(ctx).Save(upload).Error
+}
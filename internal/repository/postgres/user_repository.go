@@ -3,6 +3,8 @@ package postgres // ประกาศ package postgres
 import (
 	"context" // นำเข้า context
 	"errors"  // นำเข้า errors
+	"fmt"     // นำเข้า fmt
+	"time"    // นำเข้า time
 
 	"go-music-api/internal/domain" // นำเข้า domain entities
 
@@ -36,7 +38,7 @@ func (r *userRepository) Create(ctx context.Context, user *domain.User) error {
 func (r *userRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
 	var user domain.User
 	// ค้นหา record แรกที่ email ตรงกัน
-	if err := r.db.WithContext(ctx).Where("email = ?", email).First(&user).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("email = ? AND deleted_at IS NULL", email).First(&user).Error; err != nil {
 		// ถ้าไม่พบข้อมูล
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, domain.ErrNotFound
@@ -49,8 +51,8 @@ func (r *userRepository) GetByEmail(ctx context.Context, email string) (*domain.
 // GetByID ค้นหาผู้ใช้จาก ID
 func (r *userRepository) GetByID(ctx context.Context, id uint) (*domain.User, error) {
 	var user domain.User
-	// ค้นหา record แรกที่ id ตรงกัน
-	if err := r.db.WithContext(ctx).First(&user, id).Error; err != nil {
+	// ค้นหา record แรกที่ id ตรงกัน (ไม่รวมบัญชีที่ถูกลบ)
+	if err := r.db.WithContext(ctx).Where("deleted_at IS NULL").First(&user, id).Error; err != nil {
 		// ถ้าไม่พบข้อมูล
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, domain.ErrNotFound
@@ -61,7 +63,7 @@ func (r *userRepository) GetByID(ctx context.Context, id uint) (*domain.User, er
 }
 
 func (r *userRepository) UpdateProfile(ctx context.Context, id uint, updates map[string]any) error {
-	res := r.db.WithContext(ctx).Model(&domain.User{}).Where("id = ?", id).Updates(updates)
+	res := r.db.WithContext(ctx).Model(&domain.User{}).Where("id = ? AND deleted_at IS NULL", id).Updates(updates)
 	if res.Error != nil {
 		return res.Error
 	}
@@ -70,3 +72,88 @@ func (r *userRepository) UpdateProfile(ctx context.Context, id uint, updates map
 	}
 	return nil
 }
+
+// SoftDelete ลบบัญชีผู้ใช้ใน transaction เดียว
+// แถวของผู้ใช้ยังอยู่ (ID ยังถูกอ้างถึงได้) แต่ข้อมูลส่วนตัวถูกลบ และอีเมลถูกแทนเพื่อให้สมัครใหม่ด้วยอีเมลเดิมได้
+func (r *userRepository) SoftDelete(ctx context.Context, id uint, now time.Time) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var user domain.User
+		if err := tx.Where("deleted_at IS NULL").First(&user, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return domain.ErrNotFound
+			}
+			return err
+		}
+
+		// เพลงของผู้ใช้: ปล่อยการอ้างอิงไฟล์ (หรือเข้าคิวลบไฟล์ที่ไม่ได้นับการอ้างอิง) แล้วลบเพลง
+		// (ศิลปินที่เชื่อมและรายการใน playlist ถูกลบตาม cascade)
+		var tracks []domain.Music
+		if err := tx.Select("id", "mp3_url", "mp4_url", "image_url").Where("owner_id = ?", id).Find(&tracks).Error; err != nil {
+			return err
+		}
+		var deletions []domain.MediaDeletion
+		for _, m := range tracks {
+			for _, fileURL := range []string{m.MP3URL, m.MP4URL, m.ImageURL} {
+				if fileURL == "" {
+					continue
+				}
+				// ไฟล์ที่ยังมีเพลงอื่นอ้างถึงจะไม่ถูกลบ
+				tracked, err := releaseMediaObject(tx, fileURL, now)
+				if err != nil {
+					return err
+				}
+				if !tracked {
+					deletions = append(deletions, domain.MediaDeletion{FileURL: fileURL, NotBefore: now})
+				}
+			}
+		}
+		// รูปโปรไฟล์ไม่ได้นับการอ้างอิง เข้าคิวลบไว้ก่อนล้างค่า (ตัวลบไฟล์ข้ามไฟล์ที่ยังมีการอ้างอิงและ URL ที่ไม่ได้อยู่ใน storage)
+		if user.ImageProfile != "" {
+			deletions = append(deletions, domain.MediaDeletion{FileURL: user.ImageProfile, NotBefore: now})
+		}
+		if len(deletions) > 0 {
+			if err := tx.Create(&deletions).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("owner_id = ?", id).Delete(&domain.Music{}).Error; err != nil {
+			return err
+		}
+
+		// playlist ของผู้ใช้ (รายการเพลงและผู้ร่วมแก้ไขถูกลบตาม cascade) และการร่วมแก้ไข playlist ของผู้อื่น
+		if err := tx.Where("owner_id = ?", id).Delete(&domain.Playlist{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", id).Delete(&domain.PlaylistCollaborator{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", id).Delete(&domain.AccountToken{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", id).Delete(&domain.APIKey{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", id).Delete(&domain.UserIdentity{}).Error; err != nil {
+			return err
+		}
+
+		// แทนอีเมลของผู้ใช้ใน created_by/updated_by (ไม่แตะ updated_at)
+		for _, model := range []any{&domain.User{}, &domain.Music{}, &domain.Artist{}, &domain.Album{}, &domain.Playlist{}} {
+			for _, column := range []string{"created_by", "updated_by"} {
+				if err := tx.Model(model).Where(column+" = ?", user.Email).UpdateColumn(column, domain.DeletedUserLabel).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		return tx.Model(&user).Updates(map[string]any{
+			"email":             fmt.Sprintf("deleted-%d@deleted.invalid", id),
+			"password":          "",
+			"first_name":        "",
+			"last_name":         "",
+			"image_profile":     "",
+			"email_verified_at": nil,
+			"deleted_at":        now,
+		}).Error
+	})
+}
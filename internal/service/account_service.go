@@ -0,0 +1,232 @@
+package service // ประกาศ package service
+
+import (
+	"context" // นำเข้า context
+	"fmt"     // นำเข้า fmt สำหรับสร้างเนื้อหาอีเมล
+	"log"     // นำเข้า log สำหรับบันทึกอีเมลที่ส่งเบื้องหลังไม่สำเร็จ
+	"net/url" // นำเข้า url สำหรับสร้างลิงก์
+	"time"    // นำเข้า time
+
+	"go-music-api/internal/domain" // นำเข้า domain entities
+	"go-music-api/pkg/utils"       // นำเข้า utils สำหรับสร้าง token
+
+	"golang.org/x/crypto/bcrypt" // นำเข้า bcrypt สำหรับเข้ารหัสรหัสผ่าน
+)
+
+// mailTimeout ระยะเวลาสูงสุดในการส่งอีเมลหนึ่งฉบับ (SMTP อาจช้ากว่าการเรียกฐานข้อมูล)
+const mailTimeout = 30 * time.Second
+
+// accountService struct สำหรับ implement interface AccountService
+type accountService struct {
+	userRepo  domain.UserRepository         // repository สำหรับจัดการข้อมูลผู้ใช้
+	tokenRepo domain.AccountTokenRepository // repository สำหรับ token ที่ส่งทางอีเมล
+	sessions  domain.SessionService         // ใช้ออกจากระบบทุกอุปกรณ์หลังรีเซ็ตรหัสผ่าน
+	mailer    domain.Mailer                 // ใช้ส่งอีเมล
+	resetURL  string                        // หน้าตั้งรหัสผ่านใหม่ (ต่อท้ายด้วย ?token=)
+	verifyURL string                        // ลิงก์ยืนยันอีเมล (ต่อท้ายด้วย ?token=)
+	resetTTL  time.Duration                 // อายุของลิงก์รีเซ็ตรหัสผ่าน
+	verifyTTL time.Duration                 // อายุของลิงก์ยืนยันอีเมล
+	timeout   time.Duration                 // ระยะเวลา timeout สำหรับ context
+}
+
+// NewAccountService สร้าง instance ของ AccountService
+func NewAccountService(userRepo domain.UserRepository, tokenRepo domain.AccountTokenRepository, sessions domain.SessionService, mailer domain.Mailer, resetURL, verifyURL string, resetTTL, verifyTTL, timeout time.Duration) domain.AccountService {
+	return &accountService{
+		userRepo:  userRepo,
+		tokenRepo: tokenRepo,
+		sessions:  sessions,
+		mailer:    mailer,
+		resetURL:  resetURL,
+		verifyURL: verifyURL,
+		resetTTL:  resetTTL,
+		verifyTTL: verifyTTL,
+		timeout:   timeout,
+	}
+}
+
+// withToken ต่อ token เข้ากับลิงก์เป็น query parameter
+func withToken(link, token string) string {
+	u, err := url.Parse(link)
+	if err != nil {
+		return link + "?token=" + url.QueryEscape(token)
+	}
+	q := u.Query()
+	q.Set("token", token)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// ttlText แสดงอายุของลิงก์ในเนื้อหาอีเมล เช่น "1 hour", "48 hours", "30 minutes"
+func ttlText(d time.Duration) string {
+	if d >= time.Hour && d%time.Hour == 0 {
+		if h := int(d / time.Hour); h != 1 {
+			return fmt.Sprintf("%d hours", h)
+		}
+		return "1 hour"
+	}
+	return fmt.Sprintf("%d minutes", int(d.Round(time.Minute)/time.Minute))
+}
+
+// send ส่งอีเมลด้วย timeout ของการส่งอีเมล
+func (s *accountService) send(ctx context.Context, mail domain.Mail) error {
+	ctx, cancel := context.WithTimeout(ctx, mailTimeout)
+	defer cancel()
+	return s.mailer.Send(ctx, mail)
+}
+
+// background รันงานแยกจาก request ด้วย context ที่ไม่ถูกยกเลิกเมื่อ request จบ และบันทึก error ลง log
+func (s *accountService) background(ctx context.Context, name string, fn func(ctx context.Context) error) {
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		if err := fn(ctx); err != nil {
+			log.Printf("%s email: %v", name, err)
+		}
+	}()
+}
+
+// issue สร้าง token ใหม่ของผู้ใช้ (ลิงก์เดิมที่ยังไม่ได้ใช้จะใช้ไม่ได้อีก)
+func (s *accountService) issue(ctx context.Context, userID uint, purpose domain.AccountTokenPurpose, ttl time.Duration) (string, error) {
+	token, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+	if err := s.tokenRepo.DeleteForUser(ctx, userID, purpose); err != nil {
+		return "", err
+	}
+	err = s.tokenRepo.Create(ctx, &domain.AccountToken{
+		TokenHash: utils.HashToken(token),
+		UserID:    userID,
+		Purpose:   purpose,
+		ExpiresAt: time.Now().Add(ttl),
+	})
+	return token, err
+}
+
+// RequestPasswordReset ส่งลิงก์รีเซ็ตรหัสผ่านเบื้องหลัง
+// การค้นหาผู้ใช้ ออก token และส่งอีเมลทำหลังตอบกลับแล้ว คำตอบและเวลาตอบจึงเหมือนกันไม่ว่าอีเมลจะมีในระบบหรือไม่ (กันการเดาอีเมล)
+func (s *accountService) RequestPasswordReset(ctx context.Context, email string) {
+	s.background(ctx, "password reset", func(ctx context.Context) error {
+		return s.sendPasswordReset(ctx, email)
+	})
+}
+
+// sendPasswordReset ออก token และส่งลิงก์รีเซ็ตรหัสผ่าน อีเมลที่ไม่มีในระบบจะไม่ทำอะไร
+func (s *accountService) sendPasswordReset(ctx context.Context, email string) error {
+	dbCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	user, err := s.userRepo.GetByEmail(dbCtx, email)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return nil
+		}
+		return err
+	}
+
+	token, err := s.issue(dbCtx, user.ID, domain.TokenPasswordReset, s.resetTTL)
+	if err != nil {
+		return err
+	}
+	return s.send(ctx, domain.Mail{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Someone asked to reset the password for your account.\n\n"+
+			"Open this link to choose a new password (valid for %s):\n%s\n\n"+
+			"If you did not ask for this, you can ignore this email.", ttlText(s.resetTTL), withToken(s.resetURL, token)),
+	})
+}
+
+// ResetPassword ตั้งรหัสผ่านใหม่ด้วย token และยกเลิกทุก session ของผู้ใช้
+// การรีเซ็ตสำเร็จแสดงว่าผู้ใช้เข้าถึงอีเมลได้ จึงถือว่ายืนยันอีเมลแล้วด้วย
+func (s *accountService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	t, err := s.tokenRepo.Consume(ctx, utils.HashToken(token), domain.TokenPasswordReset, now)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return domain.ErrUnauthorized
+		}
+		return err
+	}
+	user, err := s.userRepo.GetByID(ctx, t.UserID)
+	if err != nil {
+		return err
+	}
+
+	updates := map[string]any{"password": string(hashedPassword)}
+	if user.EmailVerifiedAt == nil {
+		updates["email_verified_at"] = now
+	}
+	if err := s.userRepo.UpdateProfile(ctx, user.ID, updates); err != nil {
+		return err
+	}
+	return s.sessions.LogoutAll(ctx, user.ID)
+}
+
+// SendVerification ส่งลิงก์ยืนยันอีเมลให้ผู้ใช้
+func (s *accountService) SendVerification(ctx context.Context, user *domain.User) error {
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+	dbCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	token, err := s.issue(dbCtx, user.ID, domain.TokenEmailVerification, s.verifyTTL)
+	if err != nil {
+		return err
+	}
+	return s.send(ctx, domain.Mail{
+		To:      user.Email,
+		Subject: "Confirm your email address",
+		Body: fmt.Sprintf("Welcome! Please confirm your email address by opening this link (valid for %s):\n%s\n\n"+
+			"If you did not create an account, you can ignore this email.", ttlText(s.verifyTTL), withToken(s.verifyURL, token)),
+	})
+}
+
+// ResendVerification ส่งลิงก์ยืนยันอีเมลอีกครั้งเบื้องหลังเหมือน RequestPasswordReset (กันการเดาอีเมล)
+func (s *accountService) ResendVerification(ctx context.Context, email string) {
+	s.background(ctx, "verification", func(ctx context.Context) error {
+		dbCtx, cancel := context.WithTimeout(ctx, s.timeout)
+		user, err := s.userRepo.GetByEmail(dbCtx, email)
+		cancel()
+		if err != nil {
+			if err == domain.ErrNotFound {
+				return nil
+			}
+			return err
+		}
+		return s.SendVerification(ctx, user)
+	})
+}
+
+// VerifyEmail ยืนยันอีเมลด้วย token
+func (s *accountService) VerifyEmail(ctx context.Context, token string) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	now := time.Now()
+	t, err := s.tokenRepo.Consume(ctx, utils.HashToken(token), domain.TokenEmailVerification, now)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return domain.ErrUnauthorized
+		}
+		return err
+	}
+	return s.userRepo.UpdateProfile(ctx, t.UserID, map[string]any{"email_verified_at": now})
+}
+
+// CleanupExpired ลบ token ที่หมดอายุ คืนจำนวน token ที่ลบ
+func (s *accountService) CleanupExpired(ctx context.Context) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	n, err := s.tokenRepo.DeleteExpired(ctx, time.Now())
+	return int(n), err
+}
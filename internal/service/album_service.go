@@ -0,0 +1,125 @@
+package service // ประกาศ package service
+
+import (
+	"context" // นำเข้า context
+	"strings" // นำเข้า strings
+	"time"    // นำเข้า time
+
+	"go-music-api/internal/domain" // นำเข้า domain entities
+)
+
+// albumService struct สำหรับ implement interface AlbumService
+type albumService struct {
+	albumRepo  domain.AlbumRepository  // repository สำหรับจัดการข้อมูลอัลบั้ม
+	artistRepo domain.ArtistRepository // repository สำหรับตรวจสอบศิลปินเจ้าของอัลบั้ม
+	timeout    time.Duration           // ระยะเวลา timeout
+}
+
+// NewAlbumService สร้าง instance ของ AlbumService
+func NewAlbumService(albumRepo domain.AlbumRepository, artistRepo domain.ArtistRepository, timeout time.Duration) domain.AlbumService {
+	return &albumService{
+		albumRepo:  albumRepo,
+		artistRepo: artistRepo,
+		timeout:    timeout,
+	}
+}
+
+// Create สร้างอัลบั้มใหม่
+func (s *albumService) Create(ctx context.Context, album *domain.Album) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	if err := s.validate(ctx, album); err != nil {
+		return err
+	}
+	if err := s.albumRepo.Create(ctx, album); err != nil {
+		return err
+	}
+	return s.loadArtist(ctx, album)
+}
+
+// GetByID ดึงข้อมูลอัลบั้มพร้อมรายการเพลง
+func (s *albumService) GetByID(ctx context.Context, id uint) (*domain.Album, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	return s.albumRepo.GetByID(ctx, id)
+}
+
+// GetAll ดึงรายการอัลบั้มแบบแบ่งหน้า
+func (s *albumService) GetAll(ctx context.Context, query domain.AlbumQuery) ([]domain.Album, int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	query.Page, query.PageSize = normalizePage(query.Page, query.PageSize)
+	return s.albumRepo.GetAll(ctx, query)
+}
+
+// Update อัปเดตข้อมูลอัลบั้ม
+func (s *albumService) Update(ctx context.Context, album *domain.Album) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	existing, err := s.albumRepo.GetByID(ctx, album.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.validate(ctx, album); err != nil {
+		return err
+	}
+
+	existing.Title = album.Title
+	existing.ArtistID = album.ArtistID
+	existing.ReleaseDate = album.ReleaseDate
+	existing.ImageURL = album.ImageURL
+	existing.UpdatedBy = album.UpdatedBy
+	existing.Artist = nil
+
+	if err := s.albumRepo.Update(ctx, existing); err != nil {
+		return err
+	}
+	if err := s.loadArtist(ctx, existing); err != nil {
+		return err
+	}
+	*album = *existing
+	return nil
+}
+
+// Delete ลบอัลบั้ม
+func (s *albumService) Delete(ctx context.Context, id uint) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	return s.albumRepo.Delete(ctx, id)
+}
+
+// validate ตรวจสอบชื่ออัลบั้มและศิลปินเจ้าของอัลบั้ม
+func (s *albumService) validate(ctx context.Context, album *domain.Album) error {
+	album.Title = strings.TrimSpace(album.Title)
+	if album.Title == "" {
+		return domain.ErrInvalidInput
+	}
+	if album.ArtistID != nil {
+		if _, err := s.artistRepo.GetByID(ctx, *album.ArtistID); err != nil {
+			if err == domain.ErrNotFound {
+				return domain.ErrInvalidInput
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// loadArtist โหลดข้อมูลศิลปินเจ้าของอัลบั้มสำหรับส่งกลับใน response
+func (s *albumService) loadArtist(ctx context.Context, album *domain.Album) error {
+	if album.ArtistID == nil {
+		album.Artist = nil
+		return nil
+	}
+	artist, err := s.artistRepo.GetByID(ctx, *album.ArtistID)
+	if err != nil {
+		return err
+	}
+	album.Artist = artist
+	return nil
+}
@@ -0,0 +1,121 @@
+package service // ประกาศ package service
+
+import (
+	"context" // นำเข้า context
+	"slices"  // นำเข้า slices
+	"strings" // นำเข้า strings
+	"time"    // นำเข้า time
+
+	"go-music-api/internal/domain" // นำเข้า domain entities
+	"go-music-api/pkg/utils"       // นำเข้า utils สำหรับสร้างและ hash key
+)
+
+// apiKeyTouchInterval บันทึกเวลาใช้งานล่าสุดของ key ไม่เกินครั้งละเท่านี้
+const apiKeyTouchInterval = time.Minute
+
+// apiKeyService struct สำหรับ implement interface APIKeyService
+type apiKeyService struct {
+	keyRepo  domain.APIKeyRepository // repository สำหรับ API key
+	userRepo domain.UserRepository   // repository สำหรับดึงเจ้าของ key
+	timeout  time.Duration           // ระยะเวลา timeout สำหรับ context
+}
+
+// NewAPIKeyService สร้าง instance ของ APIKeyService
+func NewAPIKeyService(keyRepo domain.APIKeyRepository, userRepo domain.UserRepository, timeout time.Duration) domain.APIKeyService {
+	return &apiKeyService{keyRepo: keyRepo, userRepo: userRepo, timeout: timeout}
+}
+
+// Create สร้าง API key ให้ผู้ใช้ scope ต้องเป็นสิทธิ์ที่กำหนดให้ key ได้และบทบาทของผู้ใช้มี
+func (s *apiKeyService) Create(ctx context.Context, actor domain.Actor, req domain.APIKeyRequest) (*domain.APIKey, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	if !actor.Can(domain.PermAPIKeyManage) {
+		return nil, "", domain.ErrForbidden
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || len(req.Scopes) == 0 {
+		return nil, "", domain.ErrInvalidInput
+	}
+	var scopes []domain.Permission
+	for _, scope := range req.Scopes {
+		if !slices.Contains(domain.APIKeyScopes, scope) {
+			return nil, "", domain.ErrInvalidInput
+		}
+		if !actor.Can(scope) {
+			return nil, "", domain.ErrForbidden
+		}
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, "", domain.ErrInvalidInput
+	}
+
+	token, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return nil, "", err
+	}
+	secret := domain.APIKeyPrefix + token
+	key := &domain.APIKey{
+		UserID:    actor.UserID,
+		Name:      req.Name,
+		Prefix:    secret[:len(domain.APIKeyPrefix)+8],
+		KeyHash:   utils.HashToken(secret),
+		Scopes:    scopes,
+		ExpiresAt: req.ExpiresAt,
+	}
+	if err := s.keyRepo.Create(ctx, key); err != nil {
+		return nil, "", err
+	}
+	return key, secret, nil
+}
+
+// List คืนรายการ API key ของผู้ใช้
+func (s *apiKeyService) List(ctx context.Context, userID uint) ([]domain.APIKey, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	return s.keyRepo.ListByUser(ctx, userID)
+}
+
+// Revoke ลบ API key ของผู้ใช้ (มีผลกับ request ถัดไปทันที)
+func (s *apiKeyService) Revoke(ctx context.Context, userID, id uint) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	return s.keyRepo.Delete(ctx, userID, id)
+}
+
+// Authenticate ตรวจ API key และคืนเจ้าของ (บทบาทอ่านจากฐานข้อมูล การเปลี่ยนบทบาทจึงมีผลทันที)
+func (s *apiKeyService) Authenticate(ctx context.Context, secret string) (*domain.APIKey, *domain.User, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	if !strings.HasPrefix(secret, domain.APIKeyPrefix) {
+		return nil, nil, domain.ErrUnauthorized
+	}
+	key, err := s.keyRepo.GetByHash(ctx, utils.HashToken(secret))
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return nil, nil, domain.ErrUnauthorized
+		}
+		return nil, nil, err
+	}
+	now := time.Now()
+	if key.ExpiresAt != nil && !now.Before(*key.ExpiresAt) {
+		return nil, nil, domain.ErrUnauthorized
+	}
+	user, err := s.userRepo.GetByID(ctx, key.UserID)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return nil, nil, domain.ErrUnauthorized
+		}
+		return nil, nil, err
+	}
+	if err := s.keyRepo.Touch(ctx, key.ID, now, apiKeyTouchInterval); err != nil {
+		return nil, nil, err
+	}
+	return key, user, nil
+}
@@ -0,0 +1,99 @@
+package service // ประกาศ package service
+
+import (
+	"context" // นำเข้า context
+	"strings" // นำเข้า strings
+	"time"    // นำเข้า time
+
+	"go-music-api/internal/domain" // นำเข้า domain entities
+)
+
+// artistService struct สำหรับ implement interface ArtistService
+type artistService struct {
+	artistRepo domain.ArtistRepository // repository สำหรับจัดการข้อมูลศิลปิน
+	timeout    time.Duration           // ระยะเวลา timeout
+}
+
+// NewArtistService สร้าง instance ของ ArtistService
+func NewArtistService(artistRepo domain.ArtistRepository, timeout time.Duration) domain.ArtistService {
+	return &artistService{
+		artistRepo: artistRepo,
+		timeout:    timeout,
+	}
+}
+
+// Create สร้างศิลปินใหม่
+func (s *artistService) Create(ctx context.Context, artist *domain.Artist) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	artist.Name = strings.TrimSpace(artist.Name)
+	if artist.Name == "" {
+		return domain.ErrInvalidInput
+	}
+	return s.artistRepo.Create(ctx, artist)
+}
+
+// GetByID ดึงข้อมูลศิลปินตาม ID
+func (s *artistService) GetByID(ctx context.Context, id uint) (*domain.Artist, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	return s.artistRepo.GetByID(ctx, id)
+}
+
+// GetAll ดึงรายการศิลปินแบบแบ่งหน้า
+func (s *artistService) GetAll(ctx context.Context, query domain.ArtistQuery) ([]domain.Artist, int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	query.Page, query.PageSize = normalizePage(query.Page, query.PageSize)
+	return s.artistRepo.GetAll(ctx, query)
+}
+
+// Update อัปเดตข้อมูลศิลปิน
+func (s *artistService) Update(ctx context.Context, artist *domain.Artist) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	existing, err := s.artistRepo.GetByID(ctx, artist.ID)
+	if err != nil {
+		return err
+	}
+
+	existing.Name = strings.TrimSpace(artist.Name)
+	if existing.Name == "" {
+		return domain.ErrInvalidInput
+	}
+	existing.Bio = artist.Bio
+	existing.ImageURL = artist.ImageURL
+	existing.UpdatedBy = artist.UpdatedBy
+
+	if err := s.artistRepo.Update(ctx, existing); err != nil {
+		return err
+	}
+	*artist = *existing
+	return nil
+}
+
+// Delete ลบศิลปิน
+func (s *artistService) Delete(ctx context.Context, id uint) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	return s.artistRepo.Delete(ctx, id)
+}
+
+// normalizePage เติมค่าเริ่มต้นและจำกัดขอบเขตของการแบ่งหน้า
+func normalizePage(page, pageSize int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = domain.DefaultPageSize
+	}
+	if pageSize > domain.MaxPageSize {
+		pageSize = domain.MaxPageSize
+	}
+	return page, pageSize
+}
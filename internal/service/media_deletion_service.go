@@ -0,0 +1,101 @@
+package service // ประกาศ package service
+
+import (
+	"context" // นำเข้า context
+	"errors"  // นำเข้า errors
+	"io/fs"   // นำเข้า fs สำหรับตรวจว่าไฟล์ไม่มีอยู่แล้ว
+	"time"    // นำเข้า time
+
+	"go-music-api/internal/domain" // นำเข้า domain entities
+)
+
+// mediaDeletionBatchSize จำนวนไฟล์ที่ลบในแต่ละรอบ
+const mediaDeletionBatchSize = 100
+
+// mediaDeletionService struct สำหรับ implement interface MediaDeletionService
+type mediaDeletionService struct {
+	deletionRepo domain.MediaDeletionRepository // repository สำหรับคิวลบไฟล์
+	txManager    domain.TxManager               // ใช้จับรายการในคิวและล็อกไฟล์ระหว่างตรวจการอ้างอิงและลบไฟล์
+	storage      domain.StorageService          // storage ที่เก็บไฟล์ (ลบไฟล์จริงโดยไม่ผ่านการนับการอ้างอิง)
+	retryDelay   time.Duration                  // ระยะเวลารอก่อนลองใหม่ (เพิ่มตามจำนวนครั้งที่ล้มเหลว)
+	timeout      time.Duration                  // ระยะเวลา timeout สำหรับ context
+}
+
+// NewMediaDeletionService สร้าง instance ของ MediaDeletionService
+func NewMediaDeletionService(deletionRepo domain.MediaDeletionRepository, txManager domain.TxManager, storage domain.StorageService, retryDelay, timeout time.Duration) domain.MediaDeletionService {
+	return &mediaDeletionService{
+		deletionRepo: deletionRepo,
+		txManager:    txManager,
+		storage:      storage,
+		retryDelay:   retryDelay,
+		timeout:      timeout,
+	}
+}
+
+// ProcessDue ลบไฟล์ที่ถึงเวลา ไฟล์ที่ลบไม่สำเร็จจะถูกเลื่อนไปลองใหม่ภายหลัง
+func (s *mediaDeletionService) ProcessDue(ctx context.Context) (int, error) {
+	dbCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	deletions, err := s.deletionRepo.ListDue(dbCtx, time.Now(), mediaDeletionBatchSize)
+	cancel()
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for i := range deletions {
+		d := &deletions[i]
+		ctx, cancel := context.WithTimeout(ctx, s.timeout)
+		claimed, err := s.remove(ctx, d)
+		if err != nil {
+			d.Attempts++
+			d.LastError = err.Error()
+			d.NotBefore = time.Now().Add(time.Duration(d.Attempts) * s.retryDelay)
+			err = s.deletionRepo.Reschedule(ctx, d)
+			cancel()
+			if err != nil {
+				return removed, err
+			}
+			continue
+		}
+		cancel()
+		// รายการที่ถูกนำออกไปแล้วหรือตัวลบอื่นจับไว้ไม่นับเป็นไฟล์ที่ลบ
+		if claimed {
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+// remove จับรายการในคิวแล้วตรวจการอ้างอิงและลบไฟล์ใน transaction เดียวกัน โดยถือล็อกของไฟล์ (ล็อกเดียวกับ Acquire)
+// ไว้จนนำรายการออกจากคิว การอัปโหลดเนื้อหาเดียวกันพร้อมกันจึงไม่เพิ่มการอ้างอิงระหว่างตรวจกับลบไฟล์
+// คืน false ถ้ารายการถูกนำออกไปแล้วหรือตัวลบอื่นจับไว้
+func (s *mediaDeletionService) remove(ctx context.Context, d *domain.MediaDeletion) (bool, error) {
+	claimed := false
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		deletionRepo := s.txManager.MediaDeletion(ctx)
+		objectRepo := s.txManager.MediaObject(ctx)
+		if _, err := deletionRepo.Claim(ctx, d.ID); err != nil {
+			if err == domain.ErrNotFound {
+				return nil
+			}
+			return err
+		}
+		claimed = true
+		if err := objectRepo.Lock(ctx, d.FileURL); err != nil {
+			return err
+		}
+		// ไฟล์ที่ถูกอัปโหลดซ้ำ (เนื้อหาเดียวกัน) หลังเข้าคิวจะไม่ถูกลบ นำออกจากคิวเฉยๆ
+		referenced, err := objectRepo.Referenced(ctx, d.FileURL)
+		if err != nil {
+			return err
+		}
+		if !referenced {
+			// ไฟล์ที่ไม่มีอยู่แล้วถือว่าลบสำเร็จ
+			if err := s.storage.DeleteFile(ctx, d.FileURL); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+		}
+		return deletionRepo.Delete(ctx, d.ID)
+	})
+	return claimed, err
+}
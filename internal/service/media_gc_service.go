@@ -0,0 +1,150 @@
+package service // ประกาศ package service
+
+import (
+	"context" // นำเข้า context
+	"errors"  // นำเข้า errors
+	"io/fs"   // นำเข้า fs สำหรับตรวจว่าไฟล์ไม่มีอยู่แล้ว
+	"sort"    // นำเข้า sort สำหรับเรียงรายงาน
+	"time"    // นำเข้า time
+
+	"go-music-api/internal/domain" // นำเข้า domain entities
+)
+
+// mediaGCService struct สำหรับ implement interface MediaGCService
+type mediaGCService struct {
+	objectRepo domain.MediaObjectRepository // repository สำหรับดึงการอ้างอิงไฟล์ทั้งหมด
+	storage    domain.BlobStorage           // storage ที่ตรวจ (ลบไฟล์จริงโดยไม่ผ่านการนับการอ้างอิง)
+	timeout    time.Duration                // ระยะเวลา timeout สำหรับการอ่านฐานข้อมูลแต่ละครั้ง
+}
+
+// NewMediaGCService สร้าง instance ของ MediaGCService
+func NewMediaGCService(objectRepo domain.MediaObjectRepository, storage domain.BlobStorage, timeout time.Duration) domain.MediaGCService {
+	return &mediaGCService{objectRepo: objectRepo, storage: storage, timeout: timeout}
+}
+
+// Run ดึงการอ้างอิงจากฐานข้อมูลก่อนแล้วจึงไล่รายการไฟล์ ไฟล์ที่สร้างหลังดึงการอ้างอิงจะอยู่ใน grace period
+// ไฟล์กำพร้าจะถูกตรวจกับฐานข้อมูลอีกครั้งก่อนลบ เผื่อมีแถวที่อ้างถึงถูกเขียนระหว่างไล่รายการไฟล์
+func (s *mediaGCService) Run(ctx context.Context, opts domain.MediaGCOptions) (*domain.MediaGCReport, error) {
+	report := &domain.MediaGCReport{Orphans: []domain.MediaOrphan{}, Missing: []domain.MediaReference{}}
+
+	referenced, err := s.references(ctx, report)
+	if err != nil {
+		return report, err
+	}
+
+	cutoff := time.Now().Add(-opts.GracePeriod)
+	found := make(map[string]bool, len(referenced))
+	var orphans []domain.StoredFile
+	err = s.storage.ListFiles(ctx, func(f domain.StoredFile) error {
+		report.Files++
+		report.Bytes += f.Size
+		if _, ok := referenced[f.Key]; ok {
+			found[f.Key] = true
+			return nil
+		}
+		if f.ModTime.After(cutoff) {
+			report.Recent++
+			return nil
+		}
+		orphans = append(orphans, f)
+		return nil
+	})
+	if err != nil {
+		return report, err
+	}
+
+	// การอ้างอิงที่ไม่มีไฟล์ (ข้ามการอัปโหลดตรงที่ client อาจยังอัปโหลดไม่เสร็จ)
+	for key, refs := range referenced {
+		if found[key] {
+			continue
+		}
+		for _, ref := range refs {
+			if ref.Table != "uploads" {
+				report.Missing = append(report.Missing, ref)
+			}
+		}
+	}
+	sort.Slice(report.Missing, func(i, j int) bool {
+		a, b := report.Missing[i], report.Missing[j]
+		if a.Table != b.Table {
+			return a.Table < b.Table
+		}
+		if a.ID != b.ID {
+			return a.ID < b.ID
+		}
+		return a.Column < b.Column
+	})
+
+	if len(orphans) == 0 {
+		return report, nil
+	}
+	if opts.DeleteOrphans {
+		// ดึงการอ้างอิงใหม่เพื่อไม่ลบไฟล์ที่ถูกอ้างถึงระหว่างไล่รายการ
+		if referenced, err = s.references(ctx, nil); err != nil {
+			return report, err
+		}
+	}
+	for _, f := range orphans {
+		orphan := domain.MediaOrphan{Key: f.Key, URL: f.URL, Size: f.Size, ModTime: f.ModTime}
+		if opts.DeleteOrphans {
+			if _, ok := referenced[f.Key]; ok {
+				continue
+			}
+			if err := s.deleteOrphan(ctx, f.URL); err != nil {
+				orphan.Error = err.Error()
+			} else {
+				orphan.Deleted = true
+				report.Deleted++
+			}
+		}
+		report.Orphans = append(report.Orphans, orphan)
+		report.OrphanBytes += f.Size
+	}
+	return report, nil
+}
+
+// references ดึงการอ้างอิงทั้งหมดและจัดกลุ่มตาม key ของไฟล์ใน storage (URL คนละรูปแบบของไฟล์เดียวกันได้ key เดียวกัน)
+// นับจำนวนการอ้างอิงลงใน report ถ้าให้มา
+func (s *mediaGCService) references(ctx context.Context, report *domain.MediaGCReport) (map[string][]domain.MediaReference, error) {
+	dbCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	refs, err := s.objectRepo.ListReferences(dbCtx)
+	cancel()
+	if err != nil {
+		return nil, err
+	}
+
+	referenced := make(map[string][]domain.MediaReference)
+	for _, ref := range refs {
+		key, ok := s.storage.ObjectKey(ref.URL)
+		if !ok {
+			if report != nil {
+				report.External++
+			}
+			continue
+		}
+		if report != nil {
+			report.References++
+		}
+		referenced[key] = append(referenced[key], ref)
+	}
+	return referenced, nil
+}
+
+// deleteOrphan ลบไฟล์กำพร้าถ้าไม่มีการอ้างอิงในตาราง media_objects (เช่น เพิ่งอัปโหลดเนื้อหาเดียวกันซ้ำ)
+func (s *mediaGCService) deleteOrphan(ctx context.Context, fileURL string) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	referenced, err := s.objectRepo.Referenced(ctx, fileURL)
+	if err != nil {
+		return err
+	}
+	if referenced {
+		return errors.New("file was referenced again")
+	}
+	// ไฟล์ที่ไม่มีอยู่แล้วถือว่าลบสำเร็จ
+	if err := s.storage.DeleteFile(ctx, fileURL); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
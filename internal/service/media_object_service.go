@@ -0,0 +1,34 @@
+package service // ประกาศ package service
+
+import (
+	"context"       // นำเข้า context
+	"crypto/sha256" // นำเข้า sha256 สำหรับขนาดของ hash
+	"encoding/hex"  // นำเข้า hex สำหรับตรวจรูปแบบ SHA-256
+	"strings"       // นำเข้า strings
+	"time"          // นำเข้า time
+
+	"go-music-api/internal/domain" // นำเข้า domain entities
+)
+
+// mediaObjectService struct สำหรับ implement interface MediaObjectService
+type mediaObjectService struct {
+	objectRepo domain.MediaObjectRepository // repository สำหรับข้อมูลไฟล์
+	timeout    time.Duration                // ระยะเวลา timeout สำหรับ context
+}
+
+// NewMediaObjectService สร้าง instance ของ MediaObjectService
+func NewMediaObjectService(objectRepo domain.MediaObjectRepository, timeout time.Duration) domain.MediaObjectService {
+	return &mediaObjectService{objectRepo: objectRepo, timeout: timeout}
+}
+
+// GetBySHA256 ดึงข้อมูลไฟล์ตาม SHA-256 (hex 64 ตัวอักษร ไม่สนตัวพิมพ์เล็กใหญ่)
+func (s *mediaObjectService) GetBySHA256(ctx context.Context, sum string) (*domain.MediaObject, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	sum = strings.ToLower(sum)
+	if b, err := hex.DecodeString(sum); err != nil || len(b) != sha256.Size {
+		return nil, domain.ErrInvalidInput
+	}
+	return s.objectRepo.GetBySHA256(ctx, sum)
+}
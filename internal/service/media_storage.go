@@ -0,0 +1,85 @@
+package service // ประกาศ package service
+
+import (
+	"context"        // นำเข้า context
+	"io"             // นำเข้า io
+	"mime/multipart" // นำเข้า multipart สำหรับจัดการไฟล์
+	"time"           // นำเข้า time
+
+	"go-music-api/internal/domain" // นำเข้า domain entities
+)
+
+// mediaStorage ครอบ BlobStorage เพื่อนับการอ้างอิงไฟล์ในตาราง media_objects
+// ไฟล์เนื้อหาเดียวกันถูกเก็บครั้งเดียว และถูกลบ (ผ่านคิวลบไฟล์) เมื่อการอ้างอิงสุดท้ายหายไป
+type mediaStorage struct {
+	blob       domain.BlobStorage           // storage ที่เก็บไฟล์ตาม hash
+	objectRepo domain.MediaObjectRepository // repository สำหรับนับการอ้างอิง
+}
+
+// NewMediaStorage สร้าง StorageService ที่นับการอ้างอิงไฟล์
+func NewMediaStorage(blob domain.BlobStorage, objectRepo domain.MediaObjectRepository) domain.StorageService {
+	return &mediaStorage{blob: blob, objectRepo: objectRepo}
+}
+
+// UploadFile อัปโหลดไฟล์และเพิ่มการอ้างอิง
+func (s *mediaStorage) UploadFile(ctx context.Context, file *multipart.FileHeader) (string, error) {
+	src, err := file.Open()
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	return s.UploadReader(ctx, src, file.Size, file.Filename, file.Header.Get("Content-Type"))
+}
+
+// UploadReader อัปโหลดข้อมูลจาก reader และเพิ่มการอ้างอิง
+// ถ้าไฟล์นี้มีอยู่แล้วจะคืน URL เดิมที่บันทึกไว้ เพื่อให้การปล่อยการอ้างอิงใช้ URL เดียวกันเสมอ
+func (s *mediaStorage) UploadReader(ctx context.Context, r io.Reader, size int64, filename, contentType string) (string, error) {
+	stored, err := s.blob.PutObject(ctx, r, size, filename, contentType)
+	if err != nil {
+		return "", err
+	}
+	object := &domain.MediaObject{
+		SHA256:      stored.SHA256,
+		FileURL:     stored.URL,
+		Size:        stored.Size,
+		ContentType: stored.ContentType,
+	}
+	if err := s.objectRepo.Acquire(ctx, object); err != nil {
+		return "", err
+	}
+	return object.FileURL, nil
+}
+
+// StatFile ดึงข้อมูลไฟล์
+func (s *mediaStorage) StatFile(ctx context.Context, fileURL string) (*domain.FileInfo, error) {
+	return s.blob.StatFile(ctx, fileURL)
+}
+
+// OpenFile เปิดอ่านไฟล์บางช่วง
+func (s *mediaStorage) OpenFile(ctx context.Context, fileURL string, offset, length int64) (io.ReadCloser, error) {
+	return s.blob.OpenFile(ctx, fileURL, offset, length)
+}
+
+// SignedURL สร้าง URL แบบมีลายเซ็น
+func (s *mediaStorage) SignedURL(ctx context.Context, fileURL string, ttl time.Duration) (string, error) {
+	return s.blob.SignedURL(ctx, fileURL, ttl)
+}
+
+// DeleteFile ปล่อยการอ้างอิงไฟล์ ไฟล์จะถูกลบโดยคิวลบไฟล์เมื่อไม่เหลือการอ้างอิง
+// ไฟล์ที่ไม่ได้นับการอ้างอิง (อัปโหลดก่อนมีตาราง media_objects หรืออัปโหลดตรง) จะถูกลบทันทีเหมือนเดิม
+func (s *mediaStorage) DeleteFile(ctx context.Context, fileURL string) error {
+	tracked, err := s.objectRepo.Release(ctx, fileURL)
+	if err != nil {
+		return err
+	}
+	if !tracked {
+		return s.blob.DeleteFile(ctx, fileURL)
+	}
+	return nil
+}
+
+// ListFiles ไล่รายการไฟล์ทั้งหมดใน storage
+func (s *mediaStorage) ListFiles(ctx context.Context, fn func(domain.StoredFile) error) error {
+	return s.blob.ListFiles(ctx, fn)
+}
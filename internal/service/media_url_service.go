@@ -0,0 +1,55 @@
+package service // ประกาศ package service
+
+import (
+	"context" // นำเข้า context
+	"time"    // นำเข้า time
+
+	"go-music-api/internal/domain" // นำเข้า domain entities
+)
+
+// defaultMediaURLTTL อายุของ URL เมื่อไม่ได้กำหนดไว้สำหรับประเภทนั้น
+const defaultMediaURLTTL = 15 * time.Minute
+
+// mediaURLService struct สำหรับ implement interface MediaURLService
+type mediaURLService struct {
+	storage domain.StorageService              // service สำหรับจัดการไฟล์
+	ttls    map[domain.MediaKind]time.Duration // อายุของ URL แยกตามประเภทไฟล์
+}
+
+// NewMediaURLService สร้าง instance ของ MediaURLService
+func NewMediaURLService(storage domain.StorageService, ttls map[domain.MediaKind]time.Duration) domain.MediaURLService {
+	return &mediaURLService{
+		storage: storage,
+		ttls:    ttls,
+	}
+}
+
+// SignURL สร้าง URL ที่ลงลายเซ็นตามอายุของประเภทไฟล์
+func (s *mediaURLService) SignURL(ctx context.Context, fileURL string, kind domain.MediaKind) string {
+	if fileURL == "" {
+		return ""
+	}
+	ttl := s.ttls[kind]
+	if ttl <= 0 {
+		ttl = defaultMediaURLTTL
+	}
+	signed, err := s.storage.SignedURL(ctx, fileURL, ttl)
+	if err != nil {
+		// ไม่คืน URL เดิมเพราะจะเข้าถึงไม่ได้อยู่แล้ว
+		return ""
+	}
+	return signed
+}
+
+// SignMusic แทนที่ URL ของไฟล์ในเพลงด้วย URL ที่ลงลายเซ็น
+func (s *mediaURLService) SignMusic(ctx context.Context, music *domain.Music) {
+	if music == nil {
+		return
+	}
+	music.MP3URL = s.SignURL(ctx, music.MP3URL, domain.MediaAudio)
+	music.MP4URL = s.SignURL(ctx, music.MP4URL, domain.MediaVideo)
+	music.ImageURL = s.SignURL(ctx, music.ImageURL, domain.MediaImage)
+	if music.Album != nil {
+		music.Album.ImageURL = s.SignURL(ctx, music.Album.ImageURL, domain.MediaImage)
+	}
+}
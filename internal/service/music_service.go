@@ -1,69 +1,325 @@
 package service // ประกาศ package service
 
 import (
+	"bytes"          // นำเข้า bytes สำหรับอัปโหลดรูปหน้าปกจาก tag
 	"context"        // นำเข้า context
+	"io"             // นำเข้า io
+	"log"            // นำเข้า log สำหรับบันทึกไฟล์ที่ปล่อยไม่สำเร็จ
 	"mime/multipart" // นำเข้า multipart สำหรับจัดการไฟล์
+	"strings"        // นำเข้า strings
 	"time"           // นำเข้า time
 
 	"go-music-api/internal/domain" // นำเข้า domain entities
+	"go-music-api/pkg/mp3meta"     // นำเข้า mp3meta สำหรับอ่าน ID3 tag และข้อมูลเสียง
+	"go-music-api/pkg/textseg"     // นำเข้า textseg สำหรับตัดคำเพื่อไฮไลต์
+
+	"golang.org/x/sync/errgroup" // นำเข้า errgroup สำหรับอัปโหลดไฟล์พร้อมกัน
 )
 
+// maxSearchSnippets จำนวนบรรทัดเนื้อเพลงที่ไฮไลต์สูงสุดต่อผลลัพธ์
+const maxSearchSnippets = 3
+
 // musicService struct สำหรับ implement interface MusicService
 type musicService struct {
-	musicRepo domain.MusicRepository // repository สำหรับจัดการข้อมูลเพลง
-	storage   domain.StorageService  // service สำหรับจัดการไฟล์
-	timeout   time.Duration          // ระยะเวลา timeout สำหรับ context
+	musicRepo  domain.MusicRepository  // repository สำหรับจัดการข้อมูลเพลง
+	artistRepo domain.ArtistRepository // repository สำหรับจัดการข้อมูลศิลปิน
+	albumRepo  domain.AlbumRepository  // repository สำหรับตรวจสอบอัลบั้ม
+	txManager  domain.TxManager        // ใช้บันทึกเพลงหลายขั้นตอนใน transaction เดียวกัน
+	storage    domain.StorageService   // service สำหรับจัดการไฟล์
+	timeout    time.Duration           // ระยะเวลา timeout สำหรับ context
 }
 
 // NewMusicService สร้าง instance ของ MusicService
-func NewMusicService(musicRepo domain.MusicRepository, storage domain.StorageService, timeout time.Duration) domain.MusicService {
+func NewMusicService(musicRepo domain.MusicRepository, artistRepo domain.ArtistRepository, albumRepo domain.AlbumRepository, txManager domain.TxManager, storage domain.StorageService, timeout time.Duration) domain.MusicService {
 	return &musicService{
-		musicRepo: musicRepo,
-		storage:   storage,
-		timeout:   timeout,
+		musicRepo:  musicRepo,
+		artistRepo: artistRepo,
+		albumRepo:  albumRepo,
+		txManager:  txManager,
+		storage:    storage,
+		timeout:    timeout,
+	}
+}
+
+// resolveAlbum ตรวจสอบว่าอัลบั้มที่อ้างถึงมีอยู่จริง
+func (s *musicService) resolveAlbum(ctx context.Context, music *domain.Music) error {
+	if music.AlbumID != nil {
+		if _, err := s.albumRepo.GetByID(ctx, *music.AlbumID); err != nil {
+			if err == domain.ErrNotFound {
+				return domain.ErrInvalidInput
+			}
+			return err
+		}
+	}
+	if music.DiscNumber < 1 {
+		music.DiscNumber = 1
+	}
+	return nil
+}
+
+// linkArtists แยกชื่อศิลปินจากข้อความ Artist แล้วสร้าง/เชื่อมกับ Artist ให้อัตโนมัติ
+// เรียกด้วย ArtistRepository ของ transaction ที่บันทึกเพลง ถ้าบันทึกเพลงไม่สำเร็จศิลปินที่สร้างใหม่จะถูก rollback ด้วย
+func linkArtists(ctx context.Context, artistRepo domain.ArtistRepository, music *domain.Music) error {
+	music.Artists = []domain.MusicArtist{}
+	for _, c := range domain.ParseArtistCredits(music.Artist) {
+		artist := &domain.Artist{Name: c.Name}
+		artist.CreatedBy = music.UpdatedBy
+		artist.UpdatedBy = music.UpdatedBy
+		if err := artistRepo.FirstOrCreate(ctx, artist); err != nil {
+			return err
+		}
+		music.Artists = append(music.Artists, domain.MusicArtist{ArtistID: artist.ID, Role: c.Role})
+	}
+	return nil
+}
+
+// resolveArtists ตรวจสอบศิลปินที่ระบุมา และสร้างข้อความ credit ถ้ายังไม่มี
+// ถ้าไม่ได้ระบุศิลปินมา ศิลปินจะถูกเชื่อมจากข้อความ Artist ด้วย linkArtists ตอนบันทึกเพลง
+func (s *musicService) resolveArtists(ctx context.Context, music *domain.Music) error {
+	if len(music.Artists) == 0 {
+		return nil
+	}
+
+	var primary, featured []string
+	seen := map[domain.MusicArtist]bool{}
+	credits := make([]domain.MusicArtist, 0, len(music.Artists))
+	for _, c := range music.Artists {
+		if c.Role == "" {
+			c.Role = domain.ArtistRolePrimary
+		}
+		if !c.Role.Valid() {
+			return domain.ErrInvalidInput
+		}
+		artist, err := s.artistRepo.GetByID(ctx, c.ArtistID)
+		if err != nil {
+			if err == domain.ErrNotFound {
+				return domain.ErrInvalidInput
+			}
+			return err
+		}
+		key := domain.MusicArtist{ArtistID: c.ArtistID, Role: c.Role}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		credits = append(credits, key)
+		switch c.Role {
+		case domain.ArtistRolePrimary:
+			primary = append(primary, artist.Name)
+		case domain.ArtistRoleFeatured:
+			featured = append(featured, artist.Name)
+		}
+	}
+	music.Artists = credits
+	if strings.TrimSpace(music.Artist) == "" {
+		music.Artist = strings.Join(primary, ", ")
+		if len(featured) > 0 {
+			music.Artist += " feat. " + strings.Join(featured, ", ")
+		}
+	}
+	if strings.TrimSpace(music.Artist) == "" {
+		return domain.ErrInvalidInput
+	}
+	return nil
+}
+
+// readMP3Metadata อ่าน ID3 tag และข้อมูลเสียงจากไฟล์ MP3 ที่อัปโหลด
+// ถ้าไฟล์อ่านไม่ได้เลยจะคืน nil (ไม่ถือเป็น error เพราะเป็นข้อมูลเสริม)
+func readMP3Metadata(file *multipart.FileHeader) *mp3meta.Metadata {
+	src, err := file.Open()
+	if err != nil {
+		return nil
+	}
+	defer src.Close()
+
+	// แม้ไม่พบ MPEG frame ก็ยังใช้ค่าจาก tag ที่อ่านได้
+	meta, _ := mp3meta.Parse(src, file.Size)
+	return meta
+}
+
+// canModifyMusic ตรวจว่าผู้ใช้แก้ไข/ลบเพลงนี้ได้ (admin ได้ทุกเพลง editor ได้เฉพาะเพลงที่ตัวเองสร้าง)
+func canModifyMusic(actor domain.Actor, music *domain.Music) bool {
+	if actor.Can(domain.PermMusicManage) {
+		return true
+	}
+	return ownsMusic(actor, music)
+}
+
+// ownsMusic ตรวจว่าผู้ใช้แก้ไขเพลงนี้ได้ในฐานะเจ้าของ (ไม่ต้องใช้สิทธิ์จัดการเพลงทั้งหมด)
+func ownsMusic(actor domain.Actor, music *domain.Music) bool {
+	return actor.Can(domain.PermMusicWrite) && music.OwnerID != 0 && music.OwnerID == actor.UserID
+}
+
+// applyMP3Metadata บันทึกข้อมูลเสียงลงเพลง และเติม tag ลงช่องที่ยังว่าง
+func applyMP3Metadata(music *domain.Music, meta *mp3meta.Metadata) {
+	music.Duration = meta.Duration.Seconds()
+	music.Bitrate = meta.Bitrate
+	music.SampleRate = meta.SampleRate
+	music.Channels = meta.Channels
+
+	if strings.TrimSpace(music.Title) == "" {
+		music.Title = meta.Title
+	}
+	if strings.TrimSpace(music.Artist) == "" && len(music.Artists) == 0 {
+		music.Artist = meta.Artist
+	}
+	if music.Year == 0 {
+		music.Year = meta.Year
+	}
+	if music.Genre == "" {
+		music.Genre = meta.Genre
+	}
+	if music.TrackNumber == 0 {
+		music.TrackNumber = meta.TrackNumber
+	}
+}
+
+// uploadCover อัปโหลดรูปหน้าปกที่ฝังอยู่ใน tag และคืนค่า URL
+func uploadCover(ctx context.Context, storage domain.StorageService, pic *mp3meta.Picture) (string, error) {
+	ext := ".jpg"
+	switch pic.MIMEType {
+	case "image/png":
+		ext = ".png"
+	case "image/gif":
+		ext = ".gif"
+	case "image/webp":
+		ext = ".webp"
+	}
+	return storage.UploadReader(ctx, bytes.NewReader(pic.Data), int64(len(pic.Data)), "cover"+ext, pic.MIMEType)
+}
+
+// mediaUpload ไฟล์หนึ่งไฟล์ที่ต้องอัปโหลดพร้อมฟิลด์ของเพลงที่จะเก็บ URL
+type mediaUpload struct {
+	field  *string                                   // ฟิลด์ของเพลงที่เก็บ URL (เขียนเมื่ออัปโหลดสำเร็จทุกไฟล์แล้ว)
+	upload func(ctx context.Context) (string, error) // อัปโหลดไฟล์และคืนค่า URL
+}
+
+// fileUpload สร้าง mediaUpload สำหรับไฟล์จาก form
+func (s *musicService) fileUpload(field *string, file *multipart.FileHeader) mediaUpload {
+	return mediaUpload{field: field, upload: func(ctx context.Context) (string, error) {
+		return s.storage.UploadFile(ctx, file)
+	}}
+}
+
+// coverUpload สร้าง mediaUpload สำหรับรูปหน้าปกที่ฝังอยู่ใน tag
+func (s *musicService) coverUpload(field *string, pic *mp3meta.Picture) mediaUpload {
+	return mediaUpload{field: field, upload: func(ctx context.Context) (string, error) {
+		return uploadCover(ctx, s.storage, pic)
+	}}
+}
+
+// uploadMedia อัปโหลดไฟล์ทั้งหมดพร้อมกันแล้วเขียน URL ลงฟิลด์ของเพลง คืน URL ที่อัปโหลด (ใช้ชดเชยเมื่อบันทึกเพลงไม่สำเร็จ)
+// ถ้ามีไฟล์ใดล้มเหลว ไฟล์ที่เหลือจะถูกยกเลิก และไฟล์ที่อัปโหลดสำเร็จแล้วจะถูกปล่อยก่อนคืน error
+func (s *musicService) uploadMedia(ctx context.Context, uploads []mediaUpload) ([]string, error) {
+	urls := make([]string, len(uploads))
+	g, gctx := errgroup.WithContext(ctx)
+	for i, u := range uploads {
+		g.Go(func() error {
+			url, err := u.upload(gctx)
+			urls[i] = url
+			return err
+		})
+	}
+	err := g.Wait()
+
+	stored := make([]string, 0, len(urls))
+	for _, url := range urls {
+		if url != "" {
+			stored = append(stored, url)
+		}
+	}
+	if err != nil {
+		releaseMedia(ctx, s.storage, s.timeout, stored...)
+		return nil, err
+	}
+	for i, u := range uploads {
+		*u.field = urls[i]
+	}
+	return stored, nil
+}
+
+// releaseMedia ปล่อยไฟล์ที่ไม่ใช้แล้ว (ไฟล์ที่อัปโหลดแต่บันทึกเพลงไม่สำเร็จ หรือไฟล์เดิมที่ถูกแทนที่)
+// ใช้ context ที่ไม่ถูกยกเลิกตาม request เพราะมักถูกเรียกหลัง timeout ส่วนไฟล์ที่ปล่อยไม่สำเร็จจะถูกเก็บกวาดโดย media-gc
+func releaseMedia(ctx context.Context, storage domain.StorageService, timeout time.Duration, urls ...string) {
+	if len(urls) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+	defer cancel()
+	for _, url := range urls {
+		if err := storage.DeleteFile(ctx, url); err != nil {
+			log.Printf("release media file %s: %v", url, err)
+		}
 	}
 }
 
 // Create สร้างเพลงใหม่พร้อมอัปโหลดไฟล์
+// ไฟล์ทั้งหมดอัปโหลดพร้อมกันก่อนบันทึกเพลงใน transaction ถ้าขั้นตอนใดล้มเหลวไฟล์ที่อัปโหลดแล้วจะถูกปล่อย
 func (s *musicService) Create(ctx context.Context, music *domain.Music, mp3File, mp4File, imageFile *multipart.FileHeader) error {
 	// สร้าง context ใหม่ที่มี timeout เพื่อป้องกันการทำงานนานเกินไป
 	ctx, cancel := context.WithTimeout(ctx, s.timeout)
 	defer cancel() // ยกเลิก context เมื่อฟังก์ชันทำงานเสร็จ
 
-	// ตรวจสอบว่ามีการอัปโหลดไฟล์ MP3 หรือไม่
+	// อ่านข้อมูลจากไฟล์ MP3 ก่อน เพื่อใช้ชื่อเพลง/ศิลปินจาก tag เมื่อ form ไม่ได้ระบุมา
+	var meta *mp3meta.Metadata
 	if mp3File != nil {
-		// อัปโหลดไฟล์ MP3
-		url, err := s.storage.UploadFile(ctx, mp3File)
-		if err != nil {
-			return err
+		if meta = readMP3Metadata(mp3File); meta != nil {
+			applyMP3Metadata(music, meta)
 		}
-		// บันทึก URL ของไฟล์ MP3 ลงใน object music
-		music.MP3URL = url
+	}
+	if strings.TrimSpace(music.Title) == "" || (strings.TrimSpace(music.Artist) == "" && len(music.Artists) == 0) {
+		return domain.ErrInvalidInput
 	}
 
-	// ตรวจสอบว่ามีการอัปโหลดไฟล์ MP4 หรือไม่
-	if mp4File != nil {
-		// อัปโหลดไฟล์ MP4
-		url, err := s.storage.UploadFile(ctx, mp4File)
-		if err != nil {
-			// ถ้าอัปโหลด MP4 ล้มเหลว อาจจะต้องลบ MP3 ที่อัปโหลดไปแล้ว (แต่ในที่นี้ละเว้นไว้เพื่อความเรียบง่าย)
-			return err
-		}
-		// บันทึก URL ของไฟล์ MP4 ลงใน object music
-		music.MP4URL = url
+	// ตรวจสอบอัลบั้มและศิลปินที่ระบุมาก่อนอัปโหลด เพื่อไม่ต้องอัปโหลดไฟล์เมื่อข้อมูลไม่ถูกต้อง
+	if err := s.resolveAlbum(ctx, music); err != nil {
+		return err
+	}
+	if err := s.resolveArtists(ctx, music); err != nil {
+		return err
 	}
 
-	// ตรวจสอบว่ามีการอัปโหลดรูปหน้าปกหรือไม่
+	var uploads []mediaUpload
+	if mp3File != nil {
+		uploads = append(uploads, s.fileUpload(&music.MP3URL, mp3File))
+	}
+	if mp4File != nil {
+		uploads = append(uploads, s.fileUpload(&music.MP4URL, mp4File))
+	}
 	if imageFile != nil {
-		url, err := s.storage.UploadFile(ctx, imageFile)
+		uploads = append(uploads, s.fileUpload(&music.ImageURL, imageFile))
+	} else if meta != nil && meta.Picture != nil {
+		// ไม่ได้อัปโหลดรูปมา ใช้รูปหน้าปกที่ฝังอยู่ในไฟล์ MP3 แทน
+		uploads = append(uploads, s.coverUpload(&music.ImageURL, meta.Picture))
+	}
+	stored, err := s.uploadMedia(ctx, uploads)
+	if err != nil {
+		return err
+	}
+
+	// เชื่อมศิลปินจากข้อความ Artist บันทึกข้อมูลเพลงลงฐานข้อมูล แล้วโหลดกลับมาพร้อมอัลบั้มและศิลปินใน transaction เดียวกัน
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if len(music.Artists) == 0 {
+			if err := linkArtists(ctx, s.txManager.Artist(ctx), music); err != nil {
+				return err
+			}
+		}
+		musicRepo := s.txManager.Music(ctx)
+		if err := musicRepo.Create(ctx, music); err != nil {
+			return err
+		}
+		created, err := musicRepo.GetByID(ctx, music.ID)
 		if err != nil {
 			return err
 		}
-		music.ImageURL = url
+		*music = *created
+		return nil
+	})
+	if err != nil {
+		releaseMedia(ctx, s.storage, s.timeout, stored...)
+		return err
 	}
-
-	// บันทึกข้อมูลเพลงลงฐานข้อมูล
-	return s.musicRepo.Create(ctx, music)
+	return nil
 }
 
 // GetByID ดึงข้อมูลเพลงตาม ID
@@ -75,17 +331,18 @@ func (s *musicService) GetByID(ctx context.Context, id uint) (*domain.Music, err
 	return s.musicRepo.GetByID(ctx, id)
 }
 
-// GetAll ดึงข้อมูลเพลงทั้งหมด
-func (s *musicService) GetAll(ctx context.Context) ([]domain.Music, error) {
+// GetAll ดึงข้อมูลเพลงแบบแบ่งหน้าตามเงื่อนไขที่กำหนด
+func (s *musicService) GetAll(ctx context.Context, query domain.MusicQuery) (*domain.MusicPage, error) {
 	// สร้าง context ที่มี timeout
 	ctx, cancel := context.WithTimeout(ctx, s.timeout)
 	defer cancel()
-	// เรียก repository เพื่อดึงข้อมูลทั้งหมด
-	return s.musicRepo.GetAll(ctx)
+	// เติมค่าเริ่มต้นของการแบ่งหน้าก่อนส่งต่อให้ repository
+	query.Normalize()
+	return s.musicRepo.GetAll(ctx, query)
 }
 
 // Update อัปเดตข้อมูลเพลง
-func (s *musicService) Update(ctx context.Context, music *domain.Music, mp3File, mp4File, imageFile *multipart.FileHeader) error {
+func (s *musicService) Update(ctx context.Context, actor domain.Actor, music *domain.Music, mp3File, mp4File, imageFile *multipart.FileHeader) error {
 	// สร้าง context ที่มี timeout
 	ctx, cancel := context.WithTimeout(ctx, s.timeout)
 	defer cancel()
@@ -95,53 +352,100 @@ func (s *musicService) Update(ctx context.Context, music *domain.Music, mp3File,
 	if err != nil {
 		return err
 	}
+	if !canModifyMusic(actor, existingMusic) {
+		return domain.ErrForbidden
+	}
 
 	// อัปเดตเฉพาะข้อมูลที่แก้ไขได้
+	artistChanged := existingMusic.Artist != music.Artist
 	existingMusic.Title = music.Title
 	existingMusic.Artist = music.Artist
 	existingMusic.Lyrics = music.Lyrics
 	existingMusic.UpdatedBy = music.UpdatedBy
+	existingMusic.AlbumID = music.AlbumID
+	existingMusic.Album = nil
+	existingMusic.TrackNumber = music.TrackNumber
+	existingMusic.DiscNumber = music.DiscNumber
+
+	if err := s.resolveAlbum(ctx, existingMusic); err != nil {
+		return err
+	}
+
+	// เปลี่ยนรายชื่อศิลปินเมื่อระบุมาใหม่ หรือเมื่อข้อความ Artist เปลี่ยน (nil = ไม่เปลี่ยน)
+	existingMusic.Artists = nil
+	relink := false
+	if music.Artists != nil || artistChanged {
+		existingMusic.Artists = music.Artists
+		if err := s.resolveArtists(ctx, existingMusic); err != nil {
+			return err
+		}
+		// ไม่ได้ระบุศิลปินมา เชื่อมจากข้อความ Artist ใหม่ใน transaction ที่บันทึกเพลง
+		relink = len(existingMusic.Artists) == 0
+	}
 	// existingMusic.UpdatedAt จะถูกจัดการโดย GORM หรือเราจะ set เองก็ได้ แต่ GORM จัดการให้
 
+	// อัปโหลดไฟล์ใหม่ทั้งหมดก่อน ไฟล์เดิมจะถูกปล่อยหลังบันทึกเพลงสำเร็จแล้วเท่านั้น
+	var uploads []mediaUpload
+	var replaced []string
+	var meta *mp3meta.Metadata
 	if mp3File != nil {
-		url, err := s.storage.UploadFile(ctx, mp3File)
-		if err != nil {
-			return err
+		// ไฟล์เสียงเปลี่ยน อ่านข้อมูลเสียงใหม่ (ชื่อเพลงและศิลปินไม่ถูกแทนที่เพราะไม่ว่าง)
+		if meta = readMP3Metadata(mp3File); meta != nil {
+			applyMP3Metadata(existingMusic, meta)
 		}
+		uploads = append(uploads, s.fileUpload(&existingMusic.MP3URL, mp3File))
 		if existingMusic.MP3URL != "" {
-			_ = s.storage.DeleteFile(ctx, existingMusic.MP3URL)
+			replaced = append(replaced, existingMusic.MP3URL)
 		}
-		existingMusic.MP3URL = url
 	}
-
 	if mp4File != nil {
-		url, err := s.storage.UploadFile(ctx, mp4File)
-		if err != nil {
-			return err
-		}
+		uploads = append(uploads, s.fileUpload(&existingMusic.MP4URL, mp4File))
 		if existingMusic.MP4URL != "" {
-			_ = s.storage.DeleteFile(ctx, existingMusic.MP4URL)
+			replaced = append(replaced, existingMusic.MP4URL)
 		}
-		existingMusic.MP4URL = url
 	}
-
 	if imageFile != nil {
-		url, err := s.storage.UploadFile(ctx, imageFile)
-		if err != nil {
-			return err
-		}
+		uploads = append(uploads, s.fileUpload(&existingMusic.ImageURL, imageFile))
 		if existingMusic.ImageURL != "" {
-			_ = s.storage.DeleteFile(ctx, existingMusic.ImageURL)
+			replaced = append(replaced, existingMusic.ImageURL)
 		}
-		existingMusic.ImageURL = url
+	} else if existingMusic.ImageURL == "" && meta != nil && meta.Picture != nil {
+		uploads = append(uploads, s.coverUpload(&existingMusic.ImageURL, meta.Picture))
+	}
+	stored, err := s.uploadMedia(ctx, uploads)
+	if err != nil {
+		return err
 	}
 
-	// บันทึกข้อมูลที่อัปเดตแล้วลงฐานข้อมูล
-	return s.musicRepo.Update(ctx, existingMusic)
+	// บันทึกข้อมูลที่อัปเดตแล้วโหลดกลับมาใน transaction เดียวกัน เหมือนตอนสร้างเพลง
+	// ถ้าไม่สำเร็จให้ปล่อยไฟล์ใหม่และคงไฟล์เดิมไว้ ไฟล์เดิมถูกปล่อยหลัง commit แล้วเท่านั้น
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if relink {
+			if err := linkArtists(ctx, s.txManager.Artist(ctx), existingMusic); err != nil {
+				return err
+			}
+		}
+		musicRepo := s.txManager.Music(ctx)
+		if err := musicRepo.Update(ctx, existingMusic); err != nil {
+			return err
+		}
+		updated, err := musicRepo.GetByID(ctx, existingMusic.ID)
+		if err != nil {
+			return err
+		}
+		*music = *updated
+		return nil
+	})
+	if err != nil {
+		releaseMedia(ctx, s.storage, s.timeout, stored...)
+		return err
+	}
+	releaseMedia(ctx, s.storage, s.timeout, replaced...)
+	return nil
 }
 
 // Delete ลบเพลงตาม ID
-func (s *musicService) Delete(ctx context.Context, id uint) error {
+func (s *musicService) Delete(ctx context.Context, actor domain.Actor, id uint) error {
 	// สร้าง context ที่มี timeout
 	ctx, cancel := context.WithTimeout(ctx, s.timeout)
 	defer cancel()
@@ -151,20 +455,106 @@ func (s *musicService) Delete(ctx context.Context, id uint) error {
 	if err != nil {
 		return err
 	}
+	if !canModifyMusic(actor, music) {
+		return domain.ErrForbidden
+	}
+	// การลบเพลงของผู้อื่นด้วยสิทธิ์จัดการเพลงต้องเข้าสู่ระบบด้วย 2FA เหมือนการลบศิลปิน/อัลบั้ม
+	if !ownsMusic(actor, music) && !actor.MFA {
+		return domain.ErrMFARequired
+	}
+
+	// ลบข้อมูลเพลงจากฐานข้อมูลก่อน แล้วจึงปล่อยไฟล์ (ถ้าลบไม่สำเร็จไฟล์ของเพลงยังใช้ได้)
+	if err := s.musicRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+	var files []string
+	for _, url := range []string{music.MP3URL, music.MP4URL, music.ImageURL} {
+		if url != "" {
+			files = append(files, url)
+		}
+	}
+	releaseMedia(ctx, s.storage, s.timeout, files...)
+	return nil
+}
+
+// StatMedia ดึงข้อมูลไฟล์สื่อ (mp3/mp4) ของเพลงสำหรับ stream
+func (s *musicService) StatMedia(ctx context.Context, id uint, format domain.MediaFormat) (*domain.MediaFile, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	music, err := s.musicRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var fileURL string
+	switch format {
+	case domain.MediaMP3:
+		fileURL = music.MP3URL
+	case domain.MediaMP4:
+		fileURL = music.MP4URL
+	default:
+		return nil, domain.ErrInvalidInput
+	}
+	if fileURL == "" {
+		return nil, domain.ErrNotFound
+	}
+
+	info, err := s.storage.StatFile(ctx, fileURL)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.MediaFile{URL: fileURL, Format: format, FileInfo: *info}, nil
+}
+
+// OpenMedia เปิดอ่านไฟล์สื่อบางช่วง
+// ไม่ใส่ timeout ของ service เพราะการส่งข้อมูลอาจนานกว่านั้น ใช้ context ของ request แทน
+func (s *musicService) OpenMedia(ctx context.Context, media *domain.MediaFile, offset, length int64) (io.ReadCloser, error) {
+	return s.storage.OpenFile(ctx, media.URL, offset, length)
+}
+
+// Search ค้นหาเพลงแบบ full-text และแนบบรรทัดเนื้อเพลงที่ตรงกับคำค้น
+func (s *musicService) Search(ctx context.Context, query domain.MusicSearchQuery) (*domain.MusicSearchPage, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	if query.Page < 1 {
+		query.Page = 1
+	}
+	if query.PageSize < 1 || query.PageSize > domain.MaxPageSize {
+		query.PageSize = domain.DefaultPageSize
+	}
 
-	// ลบไฟล์ MP3 จาก storage ถ้ามี
-	if music.MP3URL != "" {
-		_ = s.storage.DeleteFile(ctx, music.MP3URL)
+	page, err := s.musicRepo.Search(ctx, query)
+	if err != nil {
+		return nil, err
 	}
-	// ลบไฟล์ MP4 จาก storage ถ้ามี
-	if music.MP4URL != "" {
-		_ = s.storage.DeleteFile(ctx, music.MP4URL)
+
+	// ตัดคำของคำค้นด้วยวิธีเดียวกับตอนสร้าง index เพื่อให้ไฮไลต์ตรงกัน
+	terms := map[string]bool{}
+	for _, w := range textseg.Words(query.Q) {
+		terms[w] = true
 	}
-	// ลบรูปหน้าปกจาก storage ถ้ามี
-	if music.ImageURL != "" {
-		_ = s.storage.DeleteFile(ctx, music.ImageURL)
+	for i := range page.Items {
+		page.Items[i].Snippets = lyricSnippets(page.Items[i].Lyrics, terms)
 	}
+	return page, nil
+}
 
-	// ลบข้อมูลเพลงจากฐานข้อมูล
-	return s.musicRepo.Delete(ctx, id)
+// lyricSnippets คืนบรรทัดเนื้อเพลงที่มีคำค้น โดยครอบคำที่ตรงด้วย <mark>
+func lyricSnippets(lyrics string, terms map[string]bool) []string {
+	var snippets []string
+	for _, line := range strings.Split(lyrics, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if marked, ok := textseg.Highlight(line, terms, "<mark>", "</mark>"); ok {
+			snippets = append(snippets, marked)
+			if len(snippets) == maxSearchSnippets {
+				break
+			}
+		}
+	}
+	return snippets
 }
@@ -0,0 +1,219 @@
+package service // ประกาศ package service
+
+import (
+	"context"         // นำเข้า context
+	"crypto/sha256"   // นำเข้า sha256 สำหรับ PKCE
+	"encoding/base64" // นำเข้า base64 สำหรับ PKCE
+	"log"             // นำเข้า log สำหรับบันทึกสาเหตุที่ผู้ให้บริการปฏิเสธ
+	"sort"            // นำเข้า sort
+	"time"            // นำเข้า time
+
+	"go-music-api/internal/domain" // นำเข้า domain entities
+	"go-music-api/pkg/utils"       // นำเข้า utils สำหรับสร้างและ hash token
+)
+
+// oauthStateTTL อายุของ state ระหว่างที่ผู้ใช้เข้าสู่ระบบกับผู้ให้บริการ
+const oauthStateTTL = 10 * time.Minute
+
+// oidcService struct สำหรับ implement interface OIDCService
+type oidcService struct {
+	providers    map[string]domain.IdentityProvider // ผู้ให้บริการตามชื่อ
+	identityRepo domain.IdentityRepository          // repository สำหรับบัญชีภายนอกและ state
+	userRepo     domain.UserRepository              // repository สำหรับจัดการข้อมูลผู้ใช้
+	twoFactor    domain.TwoFactorService            // ผู้ใช้ที่เปิด 2FA ต้องยืนยันรหัสต่อ
+	sessions     domain.SessionService              // ใช้ออก token
+	timeout      time.Duration                      // ระยะเวลา timeout สำหรับ context
+}
+
+// NewOIDCService สร้าง instance ของ OIDCService
+func NewOIDCService(providers []domain.IdentityProvider, identityRepo domain.IdentityRepository, userRepo domain.UserRepository, twoFactor domain.TwoFactorService, sessions domain.SessionService, timeout time.Duration) domain.OIDCService {
+	byName := make(map[string]domain.IdentityProvider, len(providers))
+	for _, p := range providers {
+		byName[p.Name()] = p
+	}
+	return &oidcService{
+		providers:    byName,
+		identityRepo: identityRepo,
+		userRepo:     userRepo,
+		twoFactor:    twoFactor,
+		sessions:     sessions,
+		timeout:      timeout,
+	}
+}
+
+// pkceChallenge คำนวณ code challenge แบบ S256 จาก code verifier (RFC 7636)
+func pkceChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// Providers คืนชื่อผู้ให้บริการที่เปิดใช้ เรียงตามตัวอักษร
+func (s *oidcService) Providers() []string {
+	names := make([]string, 0, len(s.providers))
+	for name := range s.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Begin สร้าง state, nonce และ PKCE verifier แล้วคืน URL ของผู้ให้บริการ
+func (s *oidcService) Begin(ctx context.Context, provider string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	p, ok := s.providers[provider]
+	if !ok {
+		return "", domain.ErrNotFound
+	}
+	state, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+	nonce, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+	verifier, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+
+	authURL, err := p.AuthCodeURL(ctx, state, nonce, pkceChallenge(verifier))
+	if err != nil {
+		return "", err
+	}
+	if err := s.identityRepo.CreateState(ctx, &domain.OAuthState{
+		StateHash:    utils.HashToken(state),
+		Provider:     provider,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    time.Now().Add(oauthStateTTL),
+	}); err != nil {
+		return "", err
+	}
+	return authURL, nil
+}
+
+// Complete ตรวจ state แลก code กับผู้ให้บริการ แล้วหาผู้ใช้จากบัญชีที่ผูกไว้
+// ถ้ายังไม่ผูก จะผูกกับผู้ใช้ที่มีอีเมลเดียวกัน (เฉพาะอีเมลที่ผู้ให้บริการยืนยันแล้ว) หรือสร้างผู้ใช้ใหม่
+func (s *oidcService) Complete(ctx context.Context, provider, state, code string, client domain.ClientInfo) (*domain.LoginResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	p, ok := s.providers[provider]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	saved, err := s.identityRepo.ConsumeState(ctx, utils.HashToken(state))
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return nil, domain.ErrUnauthorized
+		}
+		return nil, err
+	}
+	if saved.Provider != provider || time.Now().After(saved.ExpiresAt) {
+		return nil, domain.ErrUnauthorized
+	}
+
+	ext, err := p.Authenticate(ctx, code, saved.CodeVerifier, saved.Nonce)
+	if err != nil {
+		log.Printf("OIDC login with %s failed: %v", provider, err)
+		return nil, domain.ErrUnauthorized
+	}
+
+	user, err := s.resolveUser(ctx, ext)
+	if err != nil {
+		return nil, err
+	}
+	return beginLogin(ctx, s.twoFactor, s.sessions, user, client)
+}
+
+// resolveUser หาหรือสร้างผู้ใช้ของบัญชีภายนอก
+func (s *oidcService) resolveUser(ctx context.Context, ext *domain.ExternalIdentity) (*domain.User, error) {
+	now := time.Now()
+
+	identity, err := s.identityRepo.Get(ctx, ext.Provider, ext.Subject)
+	if err == nil {
+		user, err := s.userRepo.GetByID(ctx, identity.UserID)
+		if err != nil {
+			if err == domain.ErrNotFound {
+				// บัญชีถูกลบไปแล้ว
+				return nil, domain.ErrUnauthorized
+			}
+			return nil, err
+		}
+		if err := s.identityRepo.TouchLogin(ctx, identity.ID, now); err != nil {
+			return nil, err
+		}
+		return user, nil
+	}
+	if err != domain.ErrNotFound {
+		return nil, err
+	}
+
+	// ผูกหรือสร้างผู้ใช้ด้วยอีเมลได้เฉพาะเมื่อผู้ให้บริการยืนยันอีเมลแล้ว ไม่อย่างนั้นอาจถูกใช้ยึดบัญชีของผู้อื่น
+	if !ext.EmailVerified {
+		return nil, domain.ErrUnverified
+	}
+
+	user, err := s.userRepo.GetByEmail(ctx, ext.Email)
+	switch {
+	case err == domain.ErrNotFound:
+		// ผู้ใช้ใหม่ไม่มีรหัสผ่าน (ตั้งได้หลังเข้าสู่ระบบไม่นานหรือผ่านลิงก์รีเซ็ตรหัสผ่าน ระหว่างนั้นยืนยันรายการสำคัญด้วยการเข้าสู่ระบบล่าสุด)
+		user = &domain.User{
+			Email:           ext.Email,
+			FirstName:       ext.FirstName,
+			LastName:        ext.LastName,
+			ImageProfile:    ext.Picture,
+			EmailVerifiedAt: &now,
+		}
+		user.CreatedBy = ext.Provider
+		user.UpdatedBy = ext.Provider
+		if err := s.userRepo.Create(ctx, user); err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	case user.EmailVerifiedAt == nil:
+		// บัญชีที่ยังไม่ยืนยันอีเมลอาจถูกผู้อื่นสมัครไว้ก่อนด้วยอีเมลของเจ้าของจริง (ตั้งรหัสผ่าน 2FA หรือ API key ไว้แล้ว)
+		// จึงไม่ผูกให้อัตโนมัติ เจ้าของต้องยืนยันอีเมลหรือรีเซ็ตรหัสผ่านก่อน
+		return nil, domain.ErrConflict
+	}
+
+	if err := s.identityRepo.Create(ctx, &domain.UserIdentity{
+		UserID:      user.ID,
+		Provider:    ext.Provider,
+		Subject:     ext.Subject,
+		Email:       ext.Email,
+		LastLoginAt: &now,
+	}); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// ListIdentities คืนบัญชีภายนอกที่ผูกกับผู้ใช้
+func (s *oidcService) ListIdentities(ctx context.Context, userID uint) ([]domain.UserIdentity, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	return s.identityRepo.ListByUser(ctx, userID)
+}
+
+// Unlink ยกเลิกการผูกบัญชีภายนอก
+func (s *oidcService) Unlink(ctx context.Context, userID, id uint) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	return s.identityRepo.Delete(ctx, userID, id)
+}
+
+// CleanupExpired ลบ state ของการเข้าสู่ระบบที่ไม่เสร็จและหมดอายุแล้ว
+func (s *oidcService) CleanupExpired(ctx context.Context) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	n, err := s.identityRepo.DeleteExpiredStates(ctx, time.Now())
+	return int(n), err
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-music-api/internal/domain"
+)
+
+// fakeIdentityRepo IdentityRepository ในหน่วยความจำ
+type fakeIdentityRepo struct {
+	identities []domain.UserIdentity
+	touched    []uint
+}
+
+func (r *fakeIdentityRepo) Get(ctx context.Context, provider, subject string) (*domain.UserIdentity, error) {
+	for i := range r.identities {
+		if r.identities[i].Provider == provider && r.identities[i].Subject == subject {
+			return &r.identities[i], nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (r *fakeIdentityRepo) Create(ctx context.Context, identity *domain.UserIdentity) error {
+	identity.ID = uint(len(r.identities) + 1)
+	r.identities = append(r.identities, *identity)
+	return nil
+}
+
+func (r *fakeIdentityRepo) ListByUser(ctx context.Context, userID uint) ([]domain.UserIdentity, error) {
+	var out []domain.UserIdentity
+	for _, i := range r.identities {
+		if i.UserID == userID {
+			out = append(out, i)
+		}
+	}
+	return out, nil
+}
+
+func (r *fakeIdentityRepo) Delete(ctx context.Context, userID, id uint) error {
+	return domain.ErrNotFound
+}
+
+func (r *fakeIdentityRepo) TouchLogin(ctx context.Context, id uint, now time.Time) error {
+	r.touched = append(r.touched, id)
+	return nil
+}
+
+func (r *fakeIdentityRepo) CreateState(ctx context.Context, state *domain.OAuthState) error {
+	return nil
+}
+
+func (r *fakeIdentityRepo) ConsumeState(ctx context.Context, stateHash string) (*domain.OAuthState, error) {
+	return nil, domain.ErrNotFound
+}
+
+func (r *fakeIdentityRepo) DeleteExpiredStates(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+// emailUser ผู้ใช้ที่มีรหัสผ่าน (verifiedAt เป็น nil = ยังไม่ยืนยันอีเมล)
+func emailUser(id uint, email string, verifiedAt *time.Time) *domain.User {
+	u := testUser(id, email)
+	u.EmailVerifiedAt = verifiedAt
+	return u
+}
+
+func TestOIDCResolveUser(t *testing.T) {
+	verifiedAt := time.Now().Add(-24 * time.Hour)
+
+	tests := []struct {
+		name       string
+		users      []*domain.User
+		identities []domain.UserIdentity
+		ext        domain.ExternalIdentity
+		wantErr    error
+		wantUserID uint // 0 = ผู้ใช้ที่สร้างใหม่
+		wantLinked bool // ผูกบัญชีภายนอกใหม่กับผู้ใช้
+	}{
+		{
+			name:       "links a verified email to the existing verified user",
+			users:      []*domain.User{emailUser(7, "alice@example.com", &verifiedAt)},
+			ext:        domain.ExternalIdentity{Provider: "google", Subject: "g-1", Email: "alice@example.com", EmailVerified: true},
+			wantUserID: 7,
+			wantLinked: true,
+		},
+		{
+			name:    "does not link an unverified provider email",
+			users:   []*domain.User{emailUser(7, "alice@example.com", &verifiedAt)},
+			ext:     domain.ExternalIdentity{Provider: "google", Subject: "g-1", Email: "alice@example.com"},
+			wantErr: domain.ErrUnverified,
+		},
+		{
+			name:    "does not link to an account whose email is not verified",
+			users:   []*domain.User{emailUser(7, "alice@example.com", nil)},
+			ext:     domain.ExternalIdentity{Provider: "google", Subject: "g-1", Email: "alice@example.com", EmailVerified: true},
+			wantErr: domain.ErrConflict,
+		},
+		{
+			name:       "creates a user without a password for a new verified email",
+			ext:        domain.ExternalIdentity{Provider: "google", Subject: "g-1", Email: "bob@example.com", EmailVerified: true, FirstName: "Bob"},
+			wantLinked: true,
+		},
+		{
+			name:       "uses the linked account even when the email changed",
+			users:      []*domain.User{emailUser(7, "alice@example.com", nil)},
+			identities: []domain.UserIdentity{{ID: 1, UserID: 7, Provider: "google", Subject: "g-1"}},
+			ext:        domain.ExternalIdentity{Provider: "google", Subject: "g-1", Email: "other@example.com"},
+			wantUserID: 7,
+		},
+		{
+			name:       "rejects an identity whose user was deleted",
+			identities: []domain.UserIdentity{{ID: 1, UserID: 7, Provider: "google", Subject: "g-1"}},
+			ext:        domain.ExternalIdentity{Provider: "google", Subject: "g-1", Email: "alice@example.com", EmailVerified: true},
+			wantErr:    domain.ErrUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := newFakeUserRepo(tt.users...)
+			identities := &fakeIdentityRepo{identities: tt.identities}
+			s := &oidcService{identityRepo: identities, userRepo: users, timeout: time.Second}
+			before := len(identities.identities)
+
+			user, err := s.resolveUser(context.Background(), &tt.ext)
+			if err != tt.wantErr {
+				t.Fatalf("resolveUser() error = %v, want %v", err, tt.wantErr)
+			}
+			linked := len(identities.identities) > before
+			if linked != tt.wantLinked {
+				t.Fatalf("linked = %v, want %v", linked, tt.wantLinked)
+			}
+			if err != nil {
+				return
+			}
+
+			if tt.wantUserID != 0 && user.ID != tt.wantUserID {
+				t.Fatalf("user ID = %d, want %d", user.ID, tt.wantUserID)
+			}
+			if tt.wantUserID == 0 {
+				if user.Password != "" || user.EmailVerifiedAt == nil || user.CreatedBy != tt.ext.Provider {
+					t.Fatalf("created user = %+v", user)
+				}
+			}
+			if linked {
+				got := identities.identities[len(identities.identities)-1]
+				if got.UserID != user.ID || got.Provider != tt.ext.Provider || got.Subject != tt.ext.Subject {
+					t.Fatalf("linked identity = %+v", got)
+				}
+			} else if len(identities.touched) != 1 {
+				t.Fatalf("TouchLogin calls = %v, want one", identities.touched)
+			}
+		})
+	}
+}
@@ -0,0 +1,335 @@
+package service // ประกาศ package service
+
+import (
+	"context"      // นำเข้า context
+	"crypto/rand"  // นำเข้า rand สำหรับสร้าง token แชร์
+	"encoding/hex" // นำเข้า hex สำหรับแปลง token เป็นข้อความ
+	"strings"      // นำเข้า strings
+	"time"         // นำเข้า time
+
+	"go-music-api/internal/domain" // นำเข้า domain entities
+)
+
+// playlistService struct สำหรับ implement interface PlaylistService
+type playlistService struct {
+	playlistRepo domain.PlaylistRepository // repository สำหรับจัดการ playlist
+	musicRepo    domain.MusicRepository    // ใช้ตรวจสอบว่าเพลงมีอยู่จริง
+	userRepo     domain.UserRepository     // ใช้ค้นหาผู้ร่วมแก้ไขจากอีเมล
+	timeout      time.Duration             // ระยะเวลา timeout
+}
+
+// NewPlaylistService สร้าง instance ของ PlaylistService
+func NewPlaylistService(playlistRepo domain.PlaylistRepository, musicRepo domain.MusicRepository, userRepo domain.UserRepository, timeout time.Duration) domain.PlaylistService {
+	return &playlistService{
+		playlistRepo: playlistRepo,
+		musicRepo:    musicRepo,
+		userRepo:     userRepo,
+		timeout:      timeout,
+	}
+}
+
+// newShareToken สร้าง token แบบสุ่มสำหรับลิงก์แชร์
+func newShareToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// canEdit เจ้าของหรือผู้ร่วมแก้ไขสามารถแก้รายการเพลงได้
+func canEdit(p *domain.Playlist, userID uint) bool {
+	if p.OwnerID == userID {
+		return true
+	}
+	for _, c := range p.Collaborators {
+		if c.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// canView ตรวจสอบสิทธิ์การดู playlist ผ่าน ID ตาม visibility
+// (playlist แบบ unlisted ที่ไม่ได้เป็นเจ้าของ ต้องเข้าผ่านลิงก์แชร์เท่านั้น)
+func canView(p *domain.Playlist, userID uint) bool {
+	return p.Visibility == domain.PlaylistPublic || canEdit(p, userID)
+}
+
+// redact ซ่อนข้อมูลที่ผู้ดูทั่วไปไม่ควรเห็น
+func redact(p *domain.Playlist, userID uint) {
+	if !canEdit(p, userID) {
+		p.ShareToken = ""
+	}
+	if p.Owner != nil {
+		p.Owner = &domain.User{BaseModel: domain.BaseModel{ID: p.Owner.ID}, FirstName: p.Owner.FirstName, LastName: p.Owner.LastName, ImageProfile: p.Owner.ImageProfile}
+	}
+	for i := range p.Collaborators {
+		if u := p.Collaborators[i].User; u != nil {
+			p.Collaborators[i].User = &domain.User{BaseModel: domain.BaseModel{ID: u.ID}, FirstName: u.FirstName, LastName: u.LastName, ImageProfile: u.ImageProfile}
+		}
+	}
+}
+
+// editable ดึง playlist และตรวจสอบว่าผู้ใช้แก้ไขได้
+func (s *playlistService) editable(ctx context.Context, id, viewerID uint) (*domain.Playlist, error) {
+	p, err := s.playlistRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !canEdit(p, viewerID) {
+		if !canView(p, viewerID) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, domain.ErrForbidden
+	}
+	return p, nil
+}
+
+// owned ดึง playlist และตรวจสอบว่าผู้ใช้เป็นเจ้าของ
+func (s *playlistService) owned(ctx context.Context, id, viewerID uint) (*domain.Playlist, error) {
+	p, err := s.editable(ctx, id, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	if p.OwnerID != viewerID {
+		return nil, domain.ErrForbidden
+	}
+	return p, nil
+}
+
+// Create สร้าง playlist ใหม่ ค่าเริ่มต้นเป็น private
+func (s *playlistService) Create(ctx context.Context, playlist *domain.Playlist) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	playlist.Name = strings.TrimSpace(playlist.Name)
+	if playlist.Name == "" {
+		return domain.ErrInvalidInput
+	}
+	if playlist.Visibility == "" {
+		playlist.Visibility = domain.PlaylistPrivate
+	}
+	if !playlist.Visibility.Valid() {
+		return domain.ErrInvalidInput
+	}
+	token, err := newShareToken()
+	if err != nil {
+		return err
+	}
+	playlist.ShareToken = token
+	return s.playlistRepo.Create(ctx, playlist)
+}
+
+// Get ดึง playlist พร้อมรายการเพลงตามสิทธิ์ของผู้ดู
+func (s *playlistService) Get(ctx context.Context, id, viewerID uint) (*domain.Playlist, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	p, err := s.playlistRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !canView(p, viewerID) {
+		// ไม่บอกว่ามี playlist นี้อยู่
+		return nil, domain.ErrNotFound
+	}
+	return s.withEntries(ctx, p, viewerID)
+}
+
+// GetShared ดึง playlist ผ่าน token แชร์ (ใช้ได้กับ unlisted และ public)
+func (s *playlistService) GetShared(ctx context.Context, token string) (*domain.Playlist, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	p, err := s.playlistRepo.GetByShareToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if p.Visibility == domain.PlaylistPrivate {
+		return nil, domain.ErrNotFound
+	}
+	return s.withEntries(ctx, p, 0)
+}
+
+// withEntries โหลดรายการเพลงและซ่อนข้อมูลที่ไม่ควรเปิดเผย
+func (s *playlistService) withEntries(ctx context.Context, p *domain.Playlist, viewerID uint) (*domain.Playlist, error) {
+	entries, err := s.playlistRepo.GetEntries(ctx, p.ID)
+	if err != nil {
+		return nil, err
+	}
+	p.Entries = entries
+	redact(p, viewerID)
+	return p, nil
+}
+
+// ListMine ดึง playlist ที่ผู้ใช้เป็นเจ้าของหรือร่วมแก้ไข
+func (s *playlistService) ListMine(ctx context.Context, viewerID uint, page, pageSize int) ([]domain.Playlist, int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	page, pageSize = normalizePage(page, pageSize)
+	playlists, total, err := s.playlistRepo.ListByUser(ctx, viewerID, page, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range playlists {
+		redact(&playlists[i], viewerID)
+	}
+	return playlists, total, nil
+}
+
+// ListPublic ดึง playlist สาธารณะ
+func (s *playlistService) ListPublic(ctx context.Context, page, pageSize int) ([]domain.Playlist, int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	page, pageSize = normalizePage(page, pageSize)
+	playlists, total, err := s.playlistRepo.ListPublic(ctx, page, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range playlists {
+		redact(&playlists[i], 0)
+	}
+	return playlists, total, nil
+}
+
+// Update แก้ไขชื่อ คำอธิบาย และ visibility (เจ้าของเท่านั้น)
+func (s *playlistService) Update(ctx context.Context, playlist *domain.Playlist, viewerID uint) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	existing, err := s.owned(ctx, playlist.ID, viewerID)
+	if err != nil {
+		return err
+	}
+
+	existing.Name = strings.TrimSpace(playlist.Name)
+	if existing.Name == "" || !playlist.Visibility.Valid() {
+		return domain.ErrInvalidInput
+	}
+	existing.Description = playlist.Description
+	existing.Visibility = playlist.Visibility
+	existing.UpdatedBy = playlist.UpdatedBy
+
+	if err := s.playlistRepo.Update(ctx, existing); err != nil {
+		return err
+	}
+	redact(existing, viewerID)
+	*playlist = *existing
+	return nil
+}
+
+// Delete ลบ playlist (เจ้าของเท่านั้น)
+func (s *playlistService) Delete(ctx context.Context, id, viewerID uint) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	if _, err := s.owned(ctx, id, viewerID); err != nil {
+		return err
+	}
+	return s.playlistRepo.Delete(ctx, id)
+}
+
+// RegenerateShareToken ออก token แชร์ใหม่ (เจ้าของเท่านั้น)
+func (s *playlistService) RegenerateShareToken(ctx context.Context, id, viewerID uint) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	p, err := s.owned(ctx, id, viewerID)
+	if err != nil {
+		return "", err
+	}
+	token, err := newShareToken()
+	if err != nil {
+		return "", err
+	}
+	p.ShareToken = token
+	if err := s.playlistRepo.Update(ctx, p); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// AddEntry เพิ่มเพลงลง playlist ที่ลำดับ index (nil = ต่อท้าย)
+func (s *playlistService) AddEntry(ctx context.Context, playlistID, musicID, viewerID uint, index *int) (*domain.PlaylistEntry, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	if _, err := s.editable(ctx, playlistID, viewerID); err != nil {
+		return nil, err
+	}
+	music, err := s.musicRepo.GetByID(ctx, musicID)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return nil, domain.ErrInvalidInput
+		}
+		return nil, err
+	}
+
+	entry := &domain.PlaylistEntry{PlaylistID: playlistID, MusicID: musicID, AddedBy: viewerID}
+	if err := s.playlistRepo.InsertEntry(ctx, entry, index); err != nil {
+		return nil, err
+	}
+	entry.Music = music
+	return entry, nil
+}
+
+// MoveEntry ย้ายเพลงไปที่ลำดับ index
+func (s *playlistService) MoveEntry(ctx context.Context, playlistID, entryID, viewerID uint, index int) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	if _, err := s.editable(ctx, playlistID, viewerID); err != nil {
+		return err
+	}
+	return s.playlistRepo.MoveEntry(ctx, playlistID, entryID, index)
+}
+
+// RemoveEntry ลบเพลงออกจาก playlist
+func (s *playlistService) RemoveEntry(ctx context.Context, playlistID, entryID, viewerID uint) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	if _, err := s.editable(ctx, playlistID, viewerID); err != nil {
+		return err
+	}
+	return s.playlistRepo.RemoveEntry(ctx, playlistID, entryID)
+}
+
+// AddCollaborator เพิ่มผู้ร่วมแก้ไขด้วยอีเมล (เจ้าของเท่านั้น)
+func (s *playlistService) AddCollaborator(ctx context.Context, playlistID, viewerID uint, email string) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	if _, err := s.owned(ctx, playlistID, viewerID); err != nil {
+		return err
+	}
+	user, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return domain.ErrInvalidInput
+		}
+		return err
+	}
+	if user.ID == viewerID {
+		return domain.ErrInvalidInput
+	}
+	return s.playlistRepo.AddCollaborator(ctx, playlistID, user.ID)
+}
+
+// RemoveCollaborator ลบผู้ร่วมแก้ไข เจ้าของลบใครก็ได้ ผู้ร่วมแก้ไขลบได้เฉพาะตัวเอง
+func (s *playlistService) RemoveCollaborator(ctx context.Context, playlistID, viewerID, userID uint) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	p, err := s.editable(ctx, playlistID, viewerID)
+	if err != nil {
+		return err
+	}
+	if p.OwnerID != viewerID && userID != viewerID {
+		return domain.ErrForbidden
+	}
+	return s.playlistRepo.RemoveCollaborator(ctx, playlistID, userID)
+}
@@ -0,0 +1,298 @@
+package service // ประกาศ package service
+
+import (
+	"bytes"   // นำเข้า bytes สำหรับเทียบ checksum
+	"context" // นำเข้า context
+	"io"      // นำเข้า io
+	"log"     // นำเข้า log สำหรับบันทึกการผูกไฟล์กับเพลงที่ไม่สำเร็จ
+	"strings" // นำเข้า strings
+	"sync"    // นำเข้า sync สำหรับกันการเขียน chunk พร้อมกัน
+	"time"    // นำเข้า time
+
+	"go-music-api/internal/domain" // นำเข้า domain entities
+
+	"github.com/google/uuid" // นำเข้า uuid สำหรับสร้าง ID ของการอัปโหลด
+)
+
+// cleanupBatchSize จำนวนการอัปโหลดที่หมดอายุที่ลบในแต่ละรอบ
+const cleanupBatchSize = 100
+
+// resumableUploadService struct สำหรับ implement interface ResumableUploadService
+type resumableUploadService struct {
+	uploadRepo domain.ResumableUploadRepository // repository สำหรับจัดการข้อมูลการอัปโหลด
+	musicRepo  domain.MusicRepository           // repository สำหรับผูกไฟล์กับเพลง
+	storage    domain.StorageService            // storage ปลายทางเมื่ออัปโหลดครบ
+	chunks     domain.ChunkStore                // ที่พักข้อมูลระหว่างอัปโหลด
+	maxSize    int64                            // ขนาดไฟล์สูงสุดที่อนุญาต
+	ttl        time.Duration                    // อายุของการอัปโหลดนับจาก chunk ล่าสุด
+	timeout    time.Duration                    // ระยะเวลา timeout สำหรับ context
+
+	mu   sync.Mutex          // ป้องกัน busy
+	busy map[string]struct{} // การอัปโหลดที่กำลังเขียนอยู่
+}
+
+// NewResumableUploadService สร้าง instance ของ ResumableUploadService
+func NewResumableUploadService(uploadRepo domain.ResumableUploadRepository, musicRepo domain.MusicRepository, storage domain.StorageService, chunks domain.ChunkStore, maxSize int64, ttl, timeout time.Duration) domain.ResumableUploadService {
+	return &resumableUploadService{
+		uploadRepo: uploadRepo,
+		musicRepo:  musicRepo,
+		storage:    storage,
+		chunks:     chunks,
+		maxSize:    maxSize,
+		ttl:        ttl,
+		timeout:    timeout,
+		busy:       make(map[string]struct{}),
+	}
+}
+
+// MaxSize ขนาดไฟล์สูงสุดที่รับได้
+func (s *resumableUploadService) MaxSize() int64 {
+	return s.maxSize
+}
+
+// lock จองการอัปโหลดไว้ให้ request เดียวเขียน คืน false ถ้ามี request อื่นใช้อยู่
+func (s *resumableUploadService) lock(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.busy[id]; ok {
+		return false
+	}
+	s.busy[id] = struct{}{}
+	return true
+}
+
+// unlock ปล่อยการอัปโหลดที่จองไว้
+func (s *resumableUploadService) unlock(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.busy, id)
+}
+
+// Create ตรวจสอบข้อมูลและสร้างที่พักข้อมูลสำหรับการอัปโหลดใหม่
+func (s *resumableUploadService) Create(ctx context.Context, req domain.ResumableUploadRequest, actor domain.Actor) (*domain.ResumableUpload, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	if req.Length > s.maxSize {
+		return nil, domain.ErrTooLarge
+	}
+	if !req.Kind.Valid() || strings.TrimSpace(req.Filename) == "" || req.Length <= 0 || req.MusicID == 0 {
+		return nil, domain.ErrInvalidInput
+	}
+	if !allowedContentType(req.Kind, req.ContentType) {
+		return nil, domain.ErrInvalidInput
+	}
+	music, err := s.musicRepo.GetByID(ctx, req.MusicID)
+	if err != nil {
+		return nil, err
+	}
+	if !canModifyMusic(actor, music) {
+		return nil, domain.ErrForbidden
+	}
+
+	upload := &domain.ResumableUpload{
+		ID:          uuid.New().String(),
+		OwnerID:     actor.UserID,
+		MusicID:     req.MusicID,
+		Kind:        req.Kind,
+		Filename:    req.Filename,
+		ContentType: req.ContentType,
+		Length:      req.Length,
+		Metadata:    req.Metadata,
+		Status:      domain.UploadPending,
+		ExpiresAt:   time.Now().Add(s.ttl),
+	}
+	if err := s.chunks.Create(ctx, upload.ID); err != nil {
+		return nil, err
+	}
+	if err := s.uploadRepo.Create(ctx, upload); err != nil {
+		_ = s.chunks.Remove(ctx, upload.ID)
+		return nil, err
+	}
+	return upload, nil
+}
+
+// get ดึงการอัปโหลดของผู้ใช้ การอัปโหลดที่หมดอายุถือว่าไม่พบ
+func (s *resumableUploadService) get(ctx context.Context, id string, ownerID uint) (*domain.ResumableUpload, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	upload, err := s.uploadRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if upload.OwnerID != ownerID {
+		return nil, domain.ErrForbidden
+	}
+	if upload.Status != domain.UploadCompleted && time.Now().After(upload.ExpiresAt) {
+		return nil, domain.ErrNotFound
+	}
+	return upload, nil
+}
+
+// Get ดูสถานะและ offset ปัจจุบันของการอัปโหลด
+func (s *resumableUploadService) Get(ctx context.Context, id string, ownerID uint) (*domain.ResumableUpload, error) {
+	return s.get(ctx, id, ownerID)
+}
+
+// Append เขียน chunk ต่อจาก offset ปัจจุบัน เมื่อได้รับครบจะส่งไฟล์เข้า storage และผูกกับเพลง
+// ถ้าแจ้ง checksum มาแล้วไม่ตรง chunk นั้นจะถูกทิ้งทั้งหมด
+func (s *resumableUploadService) Append(ctx context.Context, id string, ownerID uint, offset int64, r io.Reader, checksum *domain.ChunkChecksum) (*domain.ResumableUpload, error) {
+	if !s.lock(id) {
+		return nil, domain.ErrConflict
+	}
+	defer s.unlock(id)
+
+	upload, err := s.get(ctx, id, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	if upload.Offset != offset {
+		return nil, domain.ErrConflict
+	}
+	switch upload.Status {
+	case domain.UploadCompleted:
+		// ได้รับครบแล้ว (client ส่งซ้ำหลังไม่ได้รับ response)
+		return upload, nil
+	case domain.UploadFailed:
+		return nil, domain.ErrConflict
+	}
+
+	if checksum != nil {
+		r = io.TeeReader(r, checksum.Hash)
+	}
+	// อ่านเกินที่เหลือหนึ่งไบต์เพื่อตรวจว่า body ยาวเกิน Upload-Length หรือไม่
+	remaining := upload.Length - upload.Offset
+	n, err := s.chunks.Append(ctx, id, offset, r, remaining+1)
+	if n > remaining {
+		_ = s.chunks.Truncate(ctx, id, offset)
+		return nil, domain.ErrInvalidInput
+	}
+	if checksum != nil && (err != nil || !bytes.Equal(checksum.Hash.Sum(nil), checksum.Sum)) {
+		_ = s.chunks.Truncate(ctx, id, offset)
+		if err != nil {
+			return nil, err
+		}
+		return nil, domain.ErrChecksum
+	}
+	if n == 0 && err != nil {
+		return nil, err
+	}
+
+	// เก็บส่วนที่ได้รับไว้แม้การเชื่อมต่อจะขาดกลางทาง เพื่อให้ client ส่งต่อจาก offset ใหม่ได้
+	upload.Offset += n
+	upload.ExpiresAt = time.Now().Add(s.ttl)
+	dbCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), s.timeout)
+	defer cancel()
+	if uerr := s.uploadRepo.Update(dbCtx, upload); uerr != nil {
+		return nil, uerr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if upload.Offset == upload.Length {
+		if err := s.complete(ctx, upload); err != nil {
+			return nil, err
+		}
+	}
+	return upload, nil
+}
+
+// complete ส่งไฟล์ที่ประกอบครบแล้วเข้า storage ผูกกับเพลง และลบข้อมูลที่พักไว้
+// ถ้าส่งเข้า storage ไม่สำเร็จ ข้อมูลยังอยู่ client ส่ง PATCH ว่างที่ offset สุดท้ายเพื่อลองใหม่ได้
+func (s *resumableUploadService) complete(ctx context.Context, upload *domain.ResumableUpload) error {
+	f, err := s.chunks.Open(ctx, upload.ID)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	fileURL, err := s.storage.UploadReader(ctx, f, upload.Length, upload.Filename, upload.ContentType)
+	if err != nil {
+		return err
+	}
+
+	dbCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	music, err := s.musicRepo.GetByID(dbCtx, upload.MusicID)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			// เพลงถูกลบไประหว่างอัปโหลด
+			releaseMedia(ctx, s.storage, s.timeout, fileURL)
+			upload.Status = domain.UploadFailed
+			_ = s.uploadRepo.Update(dbCtx, upload)
+		}
+		return err
+	}
+	if err := attachMedia(ctx, s.musicRepo, s.storage, s.timeout, music, upload.Kind, fileURL, f, upload.Length); err != nil {
+		// ปล่อยการอ้างอิงที่เพิ่งเพิ่ม ข้อมูลที่พักไว้ยังอยู่ให้ client ลองใหม่ได้
+		log.Printf("attach tus upload %s to music %d: %v", upload.ID, upload.MusicID, err)
+		releaseMedia(ctx, s.storage, s.timeout, fileURL)
+		return err
+	}
+
+	upload.FileURL = fileURL
+	upload.Status = domain.UploadCompleted
+	if err := s.uploadRepo.Update(dbCtx, upload); err != nil {
+		return err
+	}
+	return s.chunks.Remove(ctx, upload.ID)
+}
+
+// Terminate ยกเลิกการอัปโหลดและลบข้อมูลที่พักไว้
+func (s *resumableUploadService) Terminate(ctx context.Context, id string, ownerID uint) error {
+	if !s.lock(id) {
+		return domain.ErrConflict
+	}
+	defer s.unlock(id)
+
+	if _, err := s.get(ctx, id, ownerID); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	if err := s.chunks.Remove(ctx, id); err != nil {
+		return err
+	}
+	return s.uploadRepo.Delete(ctx, id)
+}
+
+// CleanupExpired ลบข้อมูลที่พักไว้ของการอัปโหลดที่หมดอายุ คืนจำนวนการอัปโหลดที่ลบ
+func (s *resumableUploadService) CleanupExpired(ctx context.Context) (int, error) {
+	removed := 0
+	for {
+		dbCtx, cancel := context.WithTimeout(ctx, s.timeout)
+		uploads, err := s.uploadRepo.ListExpired(dbCtx, time.Now(), cleanupBatchSize)
+		cancel()
+		if err != nil {
+			return removed, err
+		}
+
+		progress := false
+		for _, u := range uploads {
+			// ข้ามการอัปโหลดที่กำลังรับ chunk อยู่
+			if !s.lock(u.ID) {
+				continue
+			}
+			err := s.chunks.Remove(ctx, u.ID)
+			if err == nil {
+				dbCtx, cancel := context.WithTimeout(ctx, s.timeout)
+				err = s.uploadRepo.Delete(dbCtx, u.ID)
+				cancel()
+			}
+			s.unlock(u.ID)
+			if err != nil {
+				return removed, err
+			}
+			removed++
+			progress = true
+		}
+		if len(uploads) < cleanupBatchSize || !progress {
+			return removed, nil
+		}
+	}
+}
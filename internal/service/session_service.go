@@ -0,0 +1,209 @@
+package service // ประกาศ package service
+
+import (
+	"context" // นำเข้า context
+	"time"    // นำเข้า time
+
+	"go-music-api/internal/domain" // นำเข้า domain entities
+	"go-music-api/pkg/utils"       // นำเข้า utils สำหรับสร้าง token
+
+	"github.com/google/uuid" // นำเข้า uuid สำหรับสร้าง ID ของ session
+)
+
+// sessionService struct สำหรับ implement interface SessionService
+type sessionService struct {
+	sessionRepo domain.SessionRepository // repository สำหรับจัดการ session
+	userRepo    domain.UserRepository    // repository สำหรับอ่านบทบาทล่าสุดของผู้ใช้
+	refreshTTL  time.Duration            // อายุของ session นับจากการใช้งานล่าสุด
+	timeout     time.Duration            // ระยะเวลา timeout สำหรับ context
+}
+
+// NewSessionService สร้าง instance ของ SessionService
+func NewSessionService(sessionRepo domain.SessionRepository, userRepo domain.UserRepository, refreshTTL, timeout time.Duration) domain.SessionService {
+	return &sessionService{
+		sessionRepo: sessionRepo,
+		userRepo:    userRepo,
+		refreshTTL:  refreshTTL,
+		timeout:     timeout,
+	}
+}
+
+// Issue เริ่ม session ใหม่และออก token คู่แรก
+func (s *sessionService) Issue(ctx context.Context, user *domain.User, client domain.ClientInfo, mfa bool) (*domain.TokenPair, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	refreshToken, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	session := &domain.Session{
+		ID:         uuid.New().String(),
+		UserID:     user.ID,
+		UserAgent:  client.UserAgent,
+		IP:         client.IP,
+		LastUsedAt: now,
+		ExpiresAt:  now.Add(s.refreshTTL),
+		MFA:        mfa,
+	}
+	if err := s.sessionRepo.Create(ctx, session, utils.HashToken(refreshToken)); err != nil {
+		return nil, err
+	}
+
+	accessToken, err := utils.GenerateAccessToken(user.ID, user.Email, string(user.Role), session.ID, session.MFA)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.TokenPair{AccessToken: accessToken, RefreshToken: refreshToken}, nil
+}
+
+// Refresh หมุน refresh token: token เดิมใช้ไม่ได้อีกและได้ token คู่ใหม่
+// ถ้า token ที่ถูกหมุนไปแล้วถูกนำมาใช้ซ้ำ ถือว่าถูกขโมยและยกเลิกทั้ง session
+func (s *sessionService) Refresh(ctx context.Context, refreshToken string, client domain.ClientInfo) (*domain.TokenPair, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	oldHash := utils.HashToken(refreshToken)
+	token, err := s.sessionRepo.GetToken(ctx, oldHash)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return nil, domain.ErrUnauthorized
+		}
+		return nil, err
+	}
+	session := token.Session
+	now := time.Now()
+	if session == nil || session.RevokedAt != nil || now.After(session.ExpiresAt) {
+		return nil, domain.ErrUnauthorized
+	}
+	if token.UsedAt != nil {
+		if err := s.sessionRepo.Revoke(ctx, session.ID); err != nil {
+			return nil, err
+		}
+		return nil, domain.ErrUnauthorized
+	}
+
+	// อ่านบทบาทล่าสุดของผู้ใช้ เพื่อให้การเปลี่ยนบทบาทมีผลเมื่อขอ token ใหม่
+	user, err := s.userRepo.GetByID(ctx, session.UserID)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return nil, domain.ErrUnauthorized
+		}
+		return nil, err
+	}
+
+	newToken, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	session.UserAgent = client.UserAgent
+	session.IP = client.IP
+	session.LastUsedAt = now
+	session.ExpiresAt = now.Add(s.refreshTTL)
+	if err := s.sessionRepo.Rotate(ctx, session, oldHash, utils.HashToken(newToken)); err != nil {
+		if err == domain.ErrConflict {
+			// token ถูกใช้ไปพร้อมกันโดย request อื่น
+			if rerr := s.sessionRepo.Revoke(ctx, session.ID); rerr != nil {
+				return nil, rerr
+			}
+			return nil, domain.ErrUnauthorized
+		}
+		return nil, err
+	}
+
+	accessToken, err := utils.GenerateAccessToken(user.ID, user.Email, string(user.Role), session.ID, session.MFA)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.TokenPair{AccessToken: accessToken, RefreshToken: newToken}, nil
+}
+
+// Logout ยกเลิก session ของ refresh token (token ที่ไม่รู้จักถือว่าออกจากระบบแล้ว)
+func (s *sessionService) Logout(ctx context.Context, refreshToken string) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	token, err := s.sessionRepo.GetToken(ctx, utils.HashToken(refreshToken))
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return nil
+		}
+		return err
+	}
+	return s.sessionRepo.Revoke(ctx, token.SessionID)
+}
+
+// LogoutAll ยกเลิกทุก session ของผู้ใช้
+func (s *sessionService) LogoutAll(ctx context.Context, userID uint) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	return s.sessionRepo.RevokeAll(ctx, userID)
+}
+
+// LogoutOthers ยกเลิกทุก session ของผู้ใช้ยกเว้น session ปัจจุบัน (ไม่ระบุ session ปัจจุบัน = ยกเลิกทั้งหมด)
+func (s *sessionService) LogoutOthers(ctx context.Context, userID uint, keepSessionID string) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	if keepSessionID == "" {
+		return s.sessionRepo.RevokeAll(ctx, userID)
+	}
+	return s.sessionRepo.RevokeOthers(ctx, userID, keepSessionID)
+}
+
+// List ดึงรายการ session ที่ยังใช้งานได้ของผู้ใช้
+func (s *sessionService) List(ctx context.Context, userID uint) ([]domain.Session, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	return s.sessionRepo.ListActive(ctx, userID, time.Now())
+}
+
+// RecentLogin ตรวจว่า session ของผู้ใช้ยังใช้งานได้และเริ่มจากการเข้าสู่ระบบภายในช่วง within ที่ผ่านมา
+// ใช้ยืนยันตัวตนแทนรหัสผ่านของบัญชีที่เข้าสู่ระบบผ่าน OpenID Connect เท่านั้น (การหมุน refresh token ไม่นับเป็นการเข้าสู่ระบบใหม่)
+func (s *sessionService) RecentLogin(ctx context.Context, userID uint, sessionID string, within time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	if sessionID == "" {
+		return domain.ErrReauthRequired
+	}
+	session, err := s.sessionRepo.GetByID(ctx, sessionID)
+	if err == domain.ErrNotFound {
+		return domain.ErrReauthRequired
+	}
+	if err != nil {
+		return err
+	}
+	if session.UserID != userID || session.RevokedAt != nil || time.Since(session.CreatedAt) > within {
+		return domain.ErrReauthRequired
+	}
+	return nil
+}
+
+// Revoke ยกเลิก session หนึ่งของผู้ใช้ (session ของผู้อื่นถือว่าไม่พบ)
+func (s *sessionService) Revoke(ctx context.Context, userID uint, sessionID string) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	session, err := s.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	if session.UserID != userID {
+		return domain.ErrNotFound
+	}
+	return s.sessionRepo.Revoke(ctx, session.ID)
+}
+
+// CleanupExpired ลบ session ที่หมดอายุหรือถูกยกเลิกเกินหนึ่งวัน คืนจำนวน session ที่ลบ
+func (s *sessionService) CleanupExpired(ctx context.Context) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	// เก็บ session ที่เพิ่งถูกยกเลิกไว้ระยะหนึ่ง เพื่อให้ยังตรวจจับการใช้ token ซ้ำได้
+	n, err := s.sessionRepo.DeleteExpired(ctx, time.Now().Add(-24*time.Hour))
+	return int(n), err
+}
@@ -0,0 +1,374 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-music-api/internal/domain"
+	"go-music-api/pkg/utils"
+)
+
+// fakeUserRepo UserRepository ในหน่วยความจำ
+type fakeUserRepo struct {
+	users  map[uint]*domain.User
+	nextID uint
+}
+
+func newFakeUserRepo(users ...*domain.User) *fakeUserRepo {
+	r := &fakeUserRepo{users: make(map[uint]*domain.User), nextID: 100}
+	for _, u := range users {
+		r.users[u.ID] = u
+	}
+	return r
+}
+
+func (r *fakeUserRepo) Create(ctx context.Context, user *domain.User) error {
+	r.nextID++
+	user.ID = r.nextID
+	r.users[user.ID] = user
+	return nil
+}
+
+func (r *fakeUserRepo) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+	for _, u := range r.users {
+		if u.Email == email {
+			return u, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (r *fakeUserRepo) GetByID(ctx context.Context, id uint) (*domain.User, error) {
+	if u, ok := r.users[id]; ok {
+		return u, nil
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (r *fakeUserRepo) UpdateProfile(ctx context.Context, id uint, updates map[string]any) error {
+	return nil
+}
+
+func (r *fakeUserRepo) SoftDelete(ctx context.Context, id uint, now time.Time) error {
+	delete(r.users, id)
+	return nil
+}
+
+// testUser ผู้ใช้ที่มีรหัสผ่าน
+func testUser(id uint, email string) *domain.User {
+	u := &domain.User{Email: email, Password: "hash"}
+	u.ID = id
+	return u
+}
+
+// fakeSessionRepo SessionRepository ในหน่วยความจำ (Rotate ทำงานแบบเดียวกับฐานข้อมูล: token ที่ใช้แล้วคืน ErrConflict)
+type fakeSessionRepo struct {
+	sessions map[string]*domain.Session
+	tokens   map[string]*domain.SessionToken
+	conflict bool // จำลอง request อื่นที่หมุน token ไปก่อนระหว่าง GetToken กับ Rotate
+}
+
+func newFakeSessionRepo() *fakeSessionRepo {
+	return &fakeSessionRepo{sessions: make(map[string]*domain.Session), tokens: make(map[string]*domain.SessionToken)}
+}
+
+func (r *fakeSessionRepo) Create(ctx context.Context, session *domain.Session, tokenHash string) error {
+	if session.CreatedAt.IsZero() {
+		session.CreatedAt = time.Now()
+	}
+	s := *session
+	r.sessions[s.ID] = &s
+	r.tokens[tokenHash] = &domain.SessionToken{TokenHash: tokenHash, SessionID: s.ID, CreatedAt: time.Now()}
+	return nil
+}
+
+func (r *fakeSessionRepo) GetByID(ctx context.Context, id string) (*domain.Session, error) {
+	s, ok := r.sessions[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	c := *s
+	return &c, nil
+}
+
+func (r *fakeSessionRepo) GetToken(ctx context.Context, tokenHash string) (*domain.SessionToken, error) {
+	t, ok := r.tokens[tokenHash]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	c := *t
+	c.Session, _ = r.GetByID(ctx, t.SessionID)
+	return &c, nil
+}
+
+func (r *fakeSessionRepo) Rotate(ctx context.Context, session *domain.Session, oldHash, newHash string) error {
+	old := r.tokens[oldHash]
+	if r.conflict || old.UsedAt != nil {
+		return domain.ErrConflict
+	}
+	now := time.Now()
+	old.UsedAt = &now
+	r.tokens[newHash] = &domain.SessionToken{TokenHash: newHash, SessionID: session.ID, CreatedAt: now}
+	s := *session
+	r.sessions[s.ID] = &s
+	return nil
+}
+
+func (r *fakeSessionRepo) ListActive(ctx context.Context, userID uint, now time.Time) ([]domain.Session, error) {
+	var out []domain.Session
+	for _, s := range r.sessions {
+		if s.UserID == userID && s.RevokedAt == nil && now.Before(s.ExpiresAt) {
+			out = append(out, *s)
+		}
+	}
+	return out, nil
+}
+
+func (r *fakeSessionRepo) Revoke(ctx context.Context, id string) error {
+	if s, ok := r.sessions[id]; ok && s.RevokedAt == nil {
+		now := time.Now()
+		s.RevokedAt = &now
+	}
+	return nil
+}
+
+func (r *fakeSessionRepo) RevokeAll(ctx context.Context, userID uint) error {
+	return r.RevokeOthers(ctx, userID, "")
+}
+
+func (r *fakeSessionRepo) RevokeOthers(ctx context.Context, userID uint, keepID string) error {
+	for id, s := range r.sessions {
+		if s.UserID == userID && id != keepID {
+			r.Revoke(ctx, id)
+		}
+	}
+	return nil
+}
+
+func (r *fakeSessionRepo) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+// newTestSessionService สร้าง sessionService กับผู้ใช้ ID 7 และ key สำหรับเซ็น access token
+func newTestSessionService(t *testing.T) (*sessionService, *fakeSessionRepo, *fakeUserRepo) {
+	t.Helper()
+	key, err := utils.NewHMACKey([]byte("session-test-secret"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys, err := utils.NewKeyManager(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	utils.SetKeyManager(keys)
+
+	sessions := newFakeSessionRepo()
+	users := newFakeUserRepo(testUser(7, "alice@example.com"))
+	return &sessionService{sessionRepo: sessions, userRepo: users, refreshTTL: time.Hour, timeout: time.Second}, sessions, users
+}
+
+func TestSessionRefresh(t *testing.T) {
+	client := domain.ClientInfo{UserAgent: "test", IP: "127.0.0.1"}
+
+	tests := []struct {
+		name string
+		// setup เตรียมสถานะและคืน refresh token ที่จะนำมาใช้
+		setup       func(t *testing.T, s *sessionService, repo *fakeSessionRepo, users *fakeUserRepo, first string) string
+		wantErr     error
+		wantRevoked bool // session (ทั้ง family) ต้องถูกยกเลิก
+	}{
+		{
+			name: "first use rotates the token",
+			setup: func(t *testing.T, s *sessionService, repo *fakeSessionRepo, users *fakeUserRepo, first string) string {
+				return first
+			},
+		},
+		{
+			name: "token from a rotation can be used",
+			setup: func(t *testing.T, s *sessionService, repo *fakeSessionRepo, users *fakeUserRepo, first string) string {
+				pair, err := s.Refresh(context.Background(), first, client)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return pair.RefreshToken
+			},
+		},
+		{
+			name: "reused token revokes the family",
+			setup: func(t *testing.T, s *sessionService, repo *fakeSessionRepo, users *fakeUserRepo, first string) string {
+				if _, err := s.Refresh(context.Background(), first, client); err != nil {
+					t.Fatal(err)
+				}
+				return first
+			},
+			wantErr:     domain.ErrUnauthorized,
+			wantRevoked: true,
+		},
+		{
+			name: "token used concurrently revokes the family",
+			setup: func(t *testing.T, s *sessionService, repo *fakeSessionRepo, users *fakeUserRepo, first string) string {
+				repo.conflict = true
+				return first
+			},
+			wantErr:     domain.ErrUnauthorized,
+			wantRevoked: true,
+		},
+		{
+			name: "unknown token",
+			setup: func(t *testing.T, s *sessionService, repo *fakeSessionRepo, users *fakeUserRepo, first string) string {
+				return "not-a-token"
+			},
+			wantErr: domain.ErrUnauthorized,
+		},
+		{
+			name: "revoked session",
+			setup: func(t *testing.T, s *sessionService, repo *fakeSessionRepo, users *fakeUserRepo, first string) string {
+				s.LogoutAll(context.Background(), 7)
+				return first
+			},
+			wantErr:     domain.ErrUnauthorized,
+			wantRevoked: true,
+		},
+		{
+			name: "expired session",
+			setup: func(t *testing.T, s *sessionService, repo *fakeSessionRepo, users *fakeUserRepo, first string) string {
+				for _, session := range repo.sessions {
+					session.ExpiresAt = time.Now().Add(-time.Minute)
+				}
+				return first
+			},
+			wantErr: domain.ErrUnauthorized,
+		},
+		{
+			name: "deleted user",
+			setup: func(t *testing.T, s *sessionService, repo *fakeSessionRepo, users *fakeUserRepo, first string) string {
+				users.SoftDelete(context.Background(), 7, time.Now())
+				return first
+			},
+			wantErr: domain.ErrUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, repo, users := newTestSessionService(t)
+			user, _ := users.GetByID(context.Background(), 7)
+			pair, err := s.Issue(context.Background(), user, client, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			token := tt.setup(t, s, repo, users, pair.RefreshToken)
+
+			got, err := s.Refresh(context.Background(), token, client)
+			if err != tt.wantErr {
+				t.Fatalf("Refresh() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil {
+				claims, err := utils.ValidateToken(got.AccessToken)
+				if err != nil {
+					t.Fatalf("access token: %v", err)
+				}
+				if claims.UserID != 7 || !claims.MFA || repo.sessions[claims.SessionID] == nil {
+					t.Fatalf("access token claims = %+v", claims)
+				}
+				if got.RefreshToken == token {
+					t.Fatal("refresh token was not rotated")
+				}
+				if _, err := s.Refresh(context.Background(), got.RefreshToken, client); err != nil {
+					t.Fatalf("Refresh() with the new token: %v", err)
+				}
+			}
+
+			for _, session := range repo.sessions {
+				if revoked := session.RevokedAt != nil; revoked != tt.wantRevoked {
+					t.Fatalf("session revoked = %v, want %v", revoked, tt.wantRevoked)
+				}
+			}
+		})
+	}
+}
+
+// ผู้ขโมย token หมุนไปก่อน เมื่อเจ้าของใช้ token เดิมซ้ำ token ที่ผู้ขโมยได้ไปต้องใช้ไม่ได้ด้วย
+func TestSessionRefreshReuseRevokesStolenToken(t *testing.T) {
+	ctx := context.Background()
+	s, _, users := newTestSessionService(t)
+	user, _ := users.GetByID(ctx, 7)
+	pair, err := s.Issue(ctx, user, domain.ClientInfo{}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stolen, err := s.Refresh(ctx, pair.RefreshToken, domain.ClientInfo{IP: "203.0.113.9"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Refresh(ctx, pair.RefreshToken, domain.ClientInfo{}); err != domain.ErrUnauthorized {
+		t.Fatalf("reuse error = %v, want %v", err, domain.ErrUnauthorized)
+	}
+	if _, err := s.Refresh(ctx, stolen.RefreshToken, domain.ClientInfo{}); err != domain.ErrUnauthorized {
+		t.Fatalf("stolen token error = %v, want %v", err, domain.ErrUnauthorized)
+	}
+}
+
+func TestSessionRecentLogin(t *testing.T) {
+	tests := []struct {
+		name      string
+		sessionID func(session *domain.Session) string
+		modify    func(session *domain.Session)
+		userID    uint
+		wantErr   error
+	}{
+		{name: "fresh login", userID: 7},
+		{
+			name:    "login older than the window",
+			userID:  7,
+			modify:  func(session *domain.Session) { session.CreatedAt = time.Now().Add(-time.Hour) },
+			wantErr: domain.ErrReauthRequired,
+		},
+		{
+			name:    "revoked session",
+			userID:  7,
+			modify:  func(session *domain.Session) { now := time.Now(); session.RevokedAt = &now },
+			wantErr: domain.ErrReauthRequired,
+		},
+		{name: "session of another user", userID: 8, wantErr: domain.ErrReauthRequired},
+		{
+			name:      "no session ID",
+			userID:    7,
+			sessionID: func(*domain.Session) string { return "" },
+			wantErr:   domain.ErrReauthRequired,
+		},
+		{
+			name:      "unknown session",
+			userID:    7,
+			sessionID: func(*domain.Session) string { return "missing" },
+			wantErr:   domain.ErrReauthRequired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, repo, users := newTestSessionService(t)
+			user, _ := users.GetByID(context.Background(), 7)
+			if _, err := s.Issue(context.Background(), user, domain.ClientInfo{}, false); err != nil {
+				t.Fatal(err)
+			}
+			var session *domain.Session
+			for _, v := range repo.sessions {
+				session = v
+			}
+			if tt.modify != nil {
+				tt.modify(session)
+			}
+			id := session.ID
+			if tt.sessionID != nil {
+				id = tt.sessionID(session)
+			}
+
+			if err := s.RecentLogin(context.Background(), tt.userID, id, 10*time.Minute); err != tt.wantErr {
+				t.Fatalf("RecentLogin() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
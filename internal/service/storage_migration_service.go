@@ -0,0 +1,183 @@
+package service // ประกาศ package service
+
+import (
+	"context"         // นำเข้า context
+	"encoding/base64" // นำเข้า base64 สำหรับเทียบ checksum ของ storage ต้นทาง
+	"encoding/hex"    // นำเข้า hex
+	"errors"          // นำเข้า errors
+	"fmt"             // นำเข้า fmt สำหรับข้อความ error
+	"path"            // นำเข้า path สำหรับแยกชื่อไฟล์จาก URL
+	"strings"         // นำเข้า strings
+	"time"            // นำเข้า time
+
+	"go-music-api/internal/domain" // นำเข้า domain entities
+)
+
+// defaultMigrationBatchSize จำนวนเพลงที่อ่านต่อรอบถ้าไม่ได้กำหนด
+const defaultMigrationBatchSize = 100
+
+// storageMigrationService struct สำหรับ implement interface StorageMigrationService
+type storageMigrationService struct {
+	musicRepo  domain.MusicRepository       // repository สำหรับอ่าน URL ไฟล์ของเพลง
+	objectRepo domain.MediaObjectRepository // repository สำหรับแก้ URL และจำนวนการอ้างอิง
+	source     domain.BlobStorage           // storage ต้นทาง
+	dest       domain.BlobStorage           // storage ปลายทาง
+	timeout    time.Duration                // ระยะเวลา timeout สำหรับงานฐานข้อมูล
+}
+
+// NewStorageMigrationService สร้าง instance ของ StorageMigrationService
+func NewStorageMigrationService(musicRepo domain.MusicRepository, objectRepo domain.MediaObjectRepository, source, dest domain.BlobStorage, timeout time.Duration) domain.StorageMigrationService {
+	return &storageMigrationService{
+		musicRepo:  musicRepo,
+		objectRepo: objectRepo,
+		source:     source,
+		dest:       dest,
+		timeout:    timeout,
+	}
+}
+
+// Run อ่านเพลงทีละชุดตาม ID แล้วย้ายไฟล์ที่ยังอยู่ใน storage ต้นทาง
+// URL ในฐานข้อมูลถูกแก้ทันทีหลังคัดลอกและตรวจไฟล์แต่ละไฟล์ จึงหยุดกลางทางแล้วรันใหม่ได้
+// (ไฟล์ที่อยู่ในปลายทางแล้วจะถูกข้าม) ไฟล์ในต้นทางไม่ถูกลบ
+func (s *storageMigrationService) Run(ctx context.Context, opts domain.StorageMigrationOptions) (*domain.StorageMigrationReport, error) {
+	if opts.BatchSize < 1 {
+		opts.BatchSize = defaultMigrationBatchSize
+	}
+	report := &domain.StorageMigrationReport{
+		LastID:  opts.AfterID,
+		Missing: []domain.StorageMigrationIssue{},
+		Failed:  []domain.StorageMigrationIssue{},
+	}
+	// URL ที่ตรวจแล้ว (ไฟล์เดียวกันอาจถูกอ้างถึงจากหลายเพลง และชุดที่อ่านไว้ยังเป็น URL เดิม)
+	seen := map[string]bool{}
+
+	for {
+		dbCtx, cancel := context.WithTimeout(ctx, s.timeout)
+		tracks, err := s.musicRepo.ListMedia(dbCtx, report.LastID, opts.BatchSize)
+		cancel()
+		if err != nil {
+			return report, err
+		}
+		if len(tracks) == 0 {
+			return report, nil
+		}
+
+		for _, m := range tracks {
+			fields := []struct{ name, url string }{
+				{"mp3_url", m.MP3URL},
+				{"mp4_url", m.MP4URL},
+				{"image_url", m.ImageURL},
+			}
+			for _, f := range fields {
+				if f.url == "" || seen[f.url] {
+					continue
+				}
+				seen[f.url] = true
+				s.migrateFile(ctx, opts.DryRun, report, domain.StorageMigrationIssue{MusicID: m.ID, Field: f.name, URL: f.url})
+			}
+			if err := ctx.Err(); err != nil {
+				// หยุดก่อนเพลงนี้เสร็จ รอบถัดไปเริ่มจากเพลงนี้
+				return report, err
+			}
+			report.Tracks++
+			report.LastID = m.ID
+		}
+	}
+}
+
+// migrateFile ย้ายไฟล์หนึ่งไฟล์และบันทึกผลลง report
+func (s *storageMigrationService) migrateFile(ctx context.Context, dryRun bool, report *domain.StorageMigrationReport, file domain.StorageMigrationIssue) {
+	if s.dest.Contains(file.URL) {
+		report.Migrated++
+		return
+	}
+	if !s.source.Contains(file.URL) {
+		report.External++
+		return
+	}
+
+	info, err := s.source.StatFile(ctx, file.URL)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			report.Missing = append(report.Missing, file)
+		} else {
+			file.Error = err.Error()
+			report.Failed = append(report.Failed, file)
+		}
+		return
+	}
+	if dryRun {
+		report.Copied++
+		report.Bytes += info.Size
+		return
+	}
+
+	object, err := s.copyFile(ctx, file.URL, info)
+	if err == nil {
+		dbCtx, cancel := context.WithTimeout(ctx, s.timeout)
+		// ไฟล์เนื้อหาเดียวกันถูกย้ายไว้แล้ว (เช่น นามสกุลต่างกัน) ใช้ไฟล์นั้นแทน
+		if existing, gerr := s.objectRepo.GetBySHA256(dbCtx, object.SHA256); gerr == nil && s.dest.Contains(existing.FileURL) {
+			object.FileURL = existing.FileURL
+		}
+		_, _, err = s.objectRepo.Relocate(dbCtx, file.URL, object)
+		cancel()
+	}
+	if err != nil {
+		// ไฟล์ที่คัดลอกไปแล้วแต่แก้ฐานข้อมูลไม่สำเร็จจะถูกใช้ซ้ำในรอบถัดไป (key ตาม hash)
+		file.Error = err.Error()
+		report.Failed = append(report.Failed, file)
+		return
+	}
+	report.Copied++
+	report.Bytes += object.Size
+}
+
+// copyFile คัดลอกไฟล์ไปยังปลายทางแล้วตรวจขนาดและ SHA-256 กับต้นทาง และอ่านไฟล์ปลายทางกลับมาตรวจอีกครั้ง
+func (s *storageMigrationService) copyFile(ctx context.Context, fileURL string, info *domain.FileInfo) (*domain.MediaObject, error) {
+	body, err := s.source.OpenFile(ctx, fileURL, 0, -1)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	name := path.Base(fileURL)
+	stored, err := s.dest.PutObject(ctx, body, info.Size, name, info.ContentType)
+	if err != nil {
+		return nil, err
+	}
+	if stored.Size != info.Size {
+		return nil, fmt.Errorf("size mismatch: source %d bytes, copied %d bytes", info.Size, stored.Size)
+	}
+	sum, err := hex.DecodeString(stored.SHA256)
+	if err != nil {
+		return nil, err
+	}
+	// checksum ที่ storage ต้นทางเก็บไว้ (S3) หรือ hash ในชื่อไฟล์แบบ content-addressed
+	if info.ChecksumSHA256 != "" && info.ChecksumSHA256 != base64.StdEncoding.EncodeToString(sum) {
+		return nil, errors.New("checksum mismatch with source")
+	}
+	if hash := strings.TrimSuffix(name, path.Ext(name)); len(hash) == hex.EncodedLen(len(sum)) && isHex(hash) && !strings.EqualFold(hash, stored.SHA256) {
+		return nil, errors.New("source content does not match its content hash")
+	}
+
+	copied, err := storageSHA256(ctx, s.dest, stored.URL)
+	if err != nil {
+		return nil, err
+	}
+	if hex.EncodeToString(copied) != stored.SHA256 {
+		return nil, errors.New("checksum mismatch after copy")
+	}
+
+	return &domain.MediaObject{
+		SHA256:      stored.SHA256,
+		FileURL:     stored.URL,
+		Size:        stored.Size,
+		ContentType: stored.ContentType,
+	}, nil
+}
+
+// isHex ตรวจว่าเป็นตัวอักษร hex ทั้งหมด
+func isHex(s string) bool {
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
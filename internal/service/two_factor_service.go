@@ -0,0 +1,339 @@
+package service // ประกาศ package service
+
+import (
+	"context"         // นำเข้า context
+	"crypto/rand"     // นำเข้า rand สำหรับสุ่มรหัสกู้คืน
+	"encoding/base32" // นำเข้า base32 สำหรับแปลงรหัสกู้คืนเป็นตัวอักษร
+	"strings"         // นำเข้า strings
+	"time"            // นำเข้า time
+
+	"go-music-api/internal/domain" // นำเข้า domain entities
+	"go-music-api/pkg/totp"        // นำเข้า totp สำหรับสร้างและตรวจรหัส
+	"go-music-api/pkg/utils"       // นำเข้า utils สำหรับสร้างและ hash token
+)
+
+const (
+	recoveryCodeCount    = 10 // จำนวนรหัสกู้คืนต่อชุด
+	totpSkew             = 1  // ยอมให้นาฬิกาของอุปกรณ์คลาดเคลื่อนได้ ±1 ช่วงเวลา (30 วินาที)
+	maxChallengeAttempts = 5  // จำนวนครั้งที่ส่งรหัสผิดได้ก่อน challenge ถูกยกเลิก
+)
+
+// recoveryEncoding ตัวอักษรของรหัสกู้คืน (base32 ตัวเล็ก ไม่มี padding)
+var recoveryEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// twoFactorService struct สำหรับ implement interface TwoFactorService
+type twoFactorService struct {
+	tfRepo       domain.TwoFactorRepository // repository สำหรับข้อมูล 2FA
+	userRepo     domain.UserRepository      // repository สำหรับจัดการข้อมูลผู้ใช้
+	sessions     domain.SessionService      // ใช้ออก token หลังยืนยันรหัสสำเร็จ
+	issuer       string                     // ชื่อที่แสดงในแอปยืนยันตัวตน
+	challengeTTL time.Duration              // อายุของ challenge token
+	timeout      time.Duration              // ระยะเวลา timeout สำหรับ context
+	now          func() time.Time           // เวลาปัจจุบัน (แทนได้ในการทดสอบ)
+}
+
+// NewTwoFactorService สร้าง instance ของ TwoFactorService
+func NewTwoFactorService(tfRepo domain.TwoFactorRepository, userRepo domain.UserRepository, sessions domain.SessionService, issuer string, challengeTTL, timeout time.Duration) domain.TwoFactorService {
+	return &twoFactorService{
+		tfRepo:       tfRepo,
+		userRepo:     userRepo,
+		sessions:     sessions,
+		issuer:       issuer,
+		challengeTTL: challengeTTL,
+		timeout:      timeout,
+		now:          time.Now,
+	}
+}
+
+// normalizeRecoveryCode ตัดช่องว่างและขีด และแปลงเป็นตัวเล็ก (ผู้ใช้พิมพ์ได้ทั้งแบบมีและไม่มีขีด)
+func normalizeRecoveryCode(code string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToLower(code))
+}
+
+// isOTP บอกว่ารหัสเป็นตัวเลข TOTP หรือไม่ (ไม่ใช่ = รหัสกู้คืน)
+func isOTP(code string) bool {
+	code = strings.TrimSpace(code)
+	if len(code) != totp.Digits {
+		return false
+	}
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// newRecoveryCodes สุ่มรหัสกู้คืนชุดใหม่ คืนรหัสที่แสดงให้ผู้ใช้ (รูปแบบ xxxxx-xxxxx) และ hash ที่เก็บในฐานข้อมูล
+func newRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		b := make([]byte, 7)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+		raw := recoveryEncoding.EncodeToString(b)[:10]
+		codes[i] = raw[:5] + "-" + raw[5:]
+		hashes[i] = utils.HashToken(raw)
+	}
+	return codes, hashes, nil
+}
+
+// enabled ดึงการตั้งค่า TOTP ที่ยืนยันแล้ว (ErrNotFound ถ้ายังไม่เปิดใช้)
+func (s *twoFactorService) enabled(ctx context.Context, userID uint) (*domain.TwoFactor, error) {
+	tf, err := s.tfRepo.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if tf.ConfirmedAt == nil {
+		return nil, domain.ErrNotFound
+	}
+	return tf, nil
+}
+
+// verifyTOTP ตรวจรหัส TOTP และบันทึกช่วงเวลาที่ใช้ (รหัสเดิมใช้ซ้ำไม่ได้)
+func (s *twoFactorService) verifyTOTP(ctx context.Context, tf *domain.TwoFactor, code string) (int64, error) {
+	step, ok := totp.Validate(tf.Secret, code, s.now(), totpSkew)
+	if !ok || step <= tf.LastStep {
+		return 0, domain.ErrInvalidCreds
+	}
+	if err := s.tfRepo.UseStep(ctx, tf.UserID, step); err != nil {
+		if err == domain.ErrConflict {
+			return 0, domain.ErrInvalidCreds
+		}
+		return 0, err
+	}
+	return step, nil
+}
+
+// verifyCode ตรวจรหัส TOTP หรือรหัสกู้คืน (รหัสกู้คืนแต่ละตัวใช้ได้ครั้งเดียว)
+func (s *twoFactorService) verifyCode(ctx context.Context, tf *domain.TwoFactor, code string) error {
+	if isOTP(code) {
+		_, err := s.verifyTOTP(ctx, tf, code)
+		return err
+	}
+	normalized := normalizeRecoveryCode(code)
+	if normalized == "" {
+		return domain.ErrInvalidCreds
+	}
+	if err := s.tfRepo.UseRecoveryCode(ctx, tf.UserID, utils.HashToken(normalized)); err != nil {
+		if err == domain.ErrNotFound {
+			return domain.ErrInvalidCreds
+		}
+		return err
+	}
+	return nil
+}
+
+// Status คืนสถานะ 2FA ของผู้ใช้
+func (s *twoFactorService) Status(ctx context.Context, userID uint) (*domain.TwoFactorStatus, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	tf, err := s.enabled(ctx, userID)
+	if err == domain.ErrNotFound {
+		return &domain.TwoFactorStatus{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	left, err := s.tfRepo.CountRecoveryCodes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.TwoFactorStatus{Enabled: true, ConfirmedAt: tf.ConfirmedAt, RecoveryCodesLeft: left}, nil
+}
+
+// Setup สร้าง secret ใหม่ที่ยังไม่เปิดใช้จนกว่าจะยืนยันรหัสแรก (เรียกซ้ำได้ถ้ายังไม่ยืนยัน)
+func (s *twoFactorService) Setup(ctx context.Context, user *domain.User) (*domain.TwoFactorSetup, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	if _, err := s.enabled(ctx, user.ID); err == nil {
+		return nil, domain.ErrConflict
+	} else if err != domain.ErrNotFound {
+		return nil, err
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.tfRepo.Save(ctx, &domain.TwoFactor{UserID: user.ID, Secret: secret}); err != nil {
+		return nil, err
+	}
+	return &domain.TwoFactorSetup{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(s.issuer, user.Email, secret),
+	}, nil
+}
+
+// Confirm ยืนยันรหัสแรกจากแอปเพื่อเปิดใช้ 2FA และคืนรหัสกู้คืน (แสดงได้ครั้งเดียว)
+func (s *twoFactorService) Confirm(ctx context.Context, userID uint, code string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	tf, err := s.tfRepo.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if tf.ConfirmedAt != nil {
+		return nil, domain.ErrConflict
+	}
+	step, err := s.verifyTOTP(ctx, tf, code)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.tfRepo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+	now := s.now()
+	tf.ConfirmedAt = &now
+	tf.LastStep = step
+	if err := s.tfRepo.Save(ctx, tf); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// RegenerateRecoveryCodes ออกรหัสกู้คืนชุดใหม่แทนชุดเดิม (ต้องยืนยันด้วยรหัส TOTP)
+func (s *twoFactorService) RegenerateRecoveryCodes(ctx context.Context, userID uint, code string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	tf, err := s.enabled(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.verifyTOTP(ctx, tf, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.tfRepo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Disable ปิด 2FA เมื่อรหัสผ่านและรหัส TOTP หรือรหัสกู้คืนถูกต้อง
+func (s *twoFactorService) Disable(ctx context.Context, userID uint, sessionID, password, code string) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := confirmIdentity(ctx, s.sessions, user, sessionID, password); err != nil {
+		return err
+	}
+	tf, err := s.enabled(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.verifyCode(ctx, tf, code); err != nil {
+		return err
+	}
+	return s.tfRepo.Delete(ctx, userID)
+}
+
+// Enabled บอกว่าผู้ใช้เปิดใช้ 2FA อยู่หรือไม่
+func (s *twoFactorService) Enabled(ctx context.Context, userID uint) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	_, err := s.enabled(ctx, userID)
+	if err == domain.ErrNotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// BeginChallenge ออก challenge token อายุสั้นหลังรหัสผ่านถูกต้อง
+func (s *twoFactorService) BeginChallenge(ctx context.Context, userID uint) (string, time.Time, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	token, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	expiresAt := s.now().Add(s.challengeTTL)
+	if err := s.tfRepo.CreateChallenge(ctx, &domain.LoginChallenge{
+		TokenHash: utils.HashToken(token),
+		UserID:    userID,
+		ExpiresAt: expiresAt,
+	}); err != nil {
+		return "", time.Time{}, err
+	}
+	return token, expiresAt, nil
+}
+
+// CompleteChallenge ตรวจรหัส TOTP หรือรหัสกู้คืนของ challenge แล้วเริ่ม session ที่ผ่าน 2FA
+// ส่งรหัสผิดเกิน maxChallengeAttempts ครั้ง challenge จะถูกยกเลิกและต้องเข้าสู่ระบบใหม่
+func (s *twoFactorService) CompleteChallenge(ctx context.Context, token, code string, client domain.ClientInfo) (*domain.TokenPair, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	hash := utils.HashToken(token)
+	challenge, err := s.tfRepo.GetChallenge(ctx, hash)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return nil, domain.ErrInvalidCreds
+		}
+		return nil, err
+	}
+	if s.now().After(challenge.ExpiresAt) || challenge.Attempts >= maxChallengeAttempts {
+		_ = s.tfRepo.DeleteChallenge(ctx, hash)
+		return nil, domain.ErrInvalidCreds
+	}
+
+	tf, err := s.enabled(ctx, challenge.UserID)
+	if err != nil {
+		// 2FA ถูกปิดระหว่างนั้น ให้เข้าสู่ระบบใหม่
+		_ = s.tfRepo.DeleteChallenge(ctx, hash)
+		return nil, domain.ErrInvalidCreds
+	}
+	if err := s.verifyCode(ctx, tf, code); err != nil {
+		if err == domain.ErrInvalidCreds {
+			if aerr := s.tfRepo.AddChallengeAttempt(ctx, hash); aerr != nil {
+				return nil, aerr
+			}
+		}
+		return nil, err
+	}
+
+	// ลบ challenge ก่อนออก token เพื่อให้ใช้ได้ครั้งเดียว
+	if err := s.tfRepo.DeleteChallenge(ctx, hash); err != nil {
+		return nil, err
+	}
+	user, err := s.userRepo.GetByID(ctx, challenge.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return s.sessions.Issue(ctx, user, client, true)
+}
+
+// CleanupExpired ลบ challenge ที่หมดอายุ
+func (s *twoFactorService) CleanupExpired(ctx context.Context) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	n, err := s.tfRepo.DeleteExpiredChallenges(ctx, s.now())
+	return int(n), err
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-music-api/internal/domain"
+	"go-music-api/pkg/totp"
+)
+
+// fakeTwoFactorRepo TwoFactorRepository ที่จำลองเฉพาะ UseStep (เมธอดอื่นไม่ถูกเรียกในการทดสอบนี้)
+type fakeTwoFactorRepo struct {
+	domain.TwoFactorRepository
+	lastStep int64
+}
+
+// UseStep บันทึกช่วงเวลาเฉพาะเมื่อใหม่กว่าช่วงเวลาล่าสุด เหมือนเงื่อนไข last_step < ? ในฐานข้อมูล
+func (r *fakeTwoFactorRepo) UseStep(ctx context.Context, userID uint, step int64) error {
+	if step <= r.lastStep {
+		return domain.ErrConflict
+	}
+	r.lastStep = step
+	return nil
+}
+
+func TestVerifyTOTP(t *testing.T) {
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret() error = %v", err)
+	}
+	now := time.Unix(1234567890, 0)
+	code := func(offset int64) string {
+		c, err := totp.Code(secret, totp.Step(now)+offset)
+		if err != nil {
+			t.Fatalf("Code() error = %v", err)
+		}
+		return c
+	}
+
+	tests := []struct {
+		name    string
+		codes   []string // รหัสที่ส่งตามลำดับ ตัวสุดท้ายคือรหัสที่ตรวจผล
+		wantErr error
+	}{
+		{name: "accepts the current code", codes: []string{code(0)}},
+		{name: "accepts the previous step within the skew window", codes: []string{code(-1)}},
+		{name: "accepts the next step within the skew window", codes: []string{code(1)}},
+		{name: "rejects a code outside the skew window", codes: []string{code(-2)}, wantErr: domain.ErrInvalidCreds},
+		{name: "rejects a replayed code", codes: []string{code(0), code(0)}, wantErr: domain.ErrInvalidCreds},
+		{name: "rejects an older code after a newer one", codes: []string{code(1), code(0)}, wantErr: domain.ErrInvalidCreds},
+		{name: "accepts a newer code after an older one", codes: []string{code(-1), code(1)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeTwoFactorRepo{}
+			s := &twoFactorService{tfRepo: repo, timeout: time.Second, now: func() time.Time { return now }}
+			tf := &domain.TwoFactor{UserID: 1, Secret: secret}
+
+			var err error
+			for _, c := range tt.codes {
+				err = s.verifyCode(context.Background(), tf, c)
+			}
+			if err != tt.wantErr {
+				t.Fatalf("verifyCode() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifyTOTPRejectsStepAtLastStep(t *testing.T) {
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret() error = %v", err)
+	}
+	now := time.Unix(1234567890, 0)
+	step := totp.Step(now)
+	c, err := totp.Code(secret, step)
+	if err != nil {
+		t.Fatalf("Code() error = %v", err)
+	}
+
+	// รหัสของช่วงเวลาที่บันทึกไว้ใน last_step แล้วถูกปฏิเสธก่อนถึงฐานข้อมูล
+	s := &twoFactorService{tfRepo: &fakeTwoFactorRepo{}, timeout: time.Second, now: func() time.Time { return now }}
+	tf := &domain.TwoFactor{UserID: 1, Secret: secret, LastStep: step}
+	if _, err := s.verifyTOTP(context.Background(), tf, c); err != domain.ErrInvalidCreds {
+		t.Fatalf("verifyTOTP() error = %v, want %v", err, domain.ErrInvalidCreds)
+	}
+}
@@ -0,0 +1,342 @@
+package service // ประกาศ package service
+
+import (
+	"context"         // นำเข้า context
+	"crypto/sha256"   // นำเข้า sha256 สำหรับตรวจ checksum
+	"encoding/base64" // นำเข้า base64
+	"io"              // นำเข้า io
+	"mime"            // นำเข้า mime สำหรับอ่าน Content-Type
+	"strings"         // นำเข้า strings
+	"time"            // นำเข้า time
+
+	"go-music-api/internal/domain" // นำเข้า domain entities
+	"go-music-api/pkg/mp3meta"     // นำเข้า mp3meta สำหรับอ่าน ID3 tag จากไฟล์ที่อัปโหลด
+
+	"github.com/google/uuid" // นำเข้า uuid สำหรับสร้าง ID ของการอัปโหลด
+)
+
+// ไฟล์ที่ใหญ่กว่า multipartThreshold จะอัปโหลดแบบแบ่งส่วน ส่วนละ uploadPartSize (S3 กำหนดไม่เกิน 10000 ส่วน)
+const (
+	multipartThreshold int64 = 64 << 20
+	uploadPartSize     int64 = 16 << 20
+	maxUploadParts     int64 = 10000
+)
+
+// allowedUploadTypes Content-Type ที่อนุญาตสำหรับแต่ละชนิดไฟล์
+var allowedUploadTypes = map[domain.UploadKind][]string{
+	domain.UploadMP3:   {"audio/mpeg", "audio/mp3"},
+	domain.UploadMP4:   {"video/mp4"},
+	domain.UploadImage: {"image/jpeg", "image/png", "image/webp", "image/gif"},
+}
+
+// uploadService struct สำหรับ implement interface UploadService
+type uploadService struct {
+	uploadRepo domain.UploadRepository    // repository สำหรับจัดการข้อมูลการอัปโหลด
+	musicRepo  domain.MusicRepository     // repository สำหรับผูกไฟล์กับเพลง
+	storage    domain.StorageService      // service สำหรับอ่าน/ลบไฟล์
+	direct     domain.DirectUploadStorage // storage ที่รับไฟล์จาก client โดยตรง
+	maxSize    int64                      // ขนาดไฟล์สูงสุดที่อนุญาต
+	urlTTL     time.Duration              // อายุของ URL สำหรับอัปโหลด
+	timeout    time.Duration              // ระยะเวลา timeout สำหรับ context
+}
+
+// NewUploadService สร้าง instance ของ UploadService
+func NewUploadService(uploadRepo domain.UploadRepository, musicRepo domain.MusicRepository, storage domain.StorageService, direct domain.DirectUploadStorage, maxSize int64, urlTTL, timeout time.Duration) domain.UploadService {
+	return &uploadService{
+		uploadRepo: uploadRepo,
+		musicRepo:  musicRepo,
+		storage:    storage,
+		direct:     direct,
+		maxSize:    maxSize,
+		urlTTL:     urlTTL,
+		timeout:    timeout,
+	}
+}
+
+// allowedContentType ตรวจว่า Content-Type ตรงกับชนิดไฟล์
+func allowedContentType(kind domain.UploadKind, contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, t := range allowedUploadTypes[kind] {
+		if mediaType == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Create ตรวจสอบคำขอ จองปลายทางใน storage และคืน URL ที่ลงลายเซ็นสำหรับอัปโหลด
+func (s *uploadService) Create(ctx context.Context, req domain.UploadRequest, ownerID uint) (*domain.UploadTicket, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	if !req.Kind.Valid() || strings.TrimSpace(req.Filename) == "" || req.Size <= 0 || req.Size > s.maxSize {
+		return nil, domain.ErrInvalidInput
+	}
+	if !allowedContentType(req.Kind, req.ContentType) {
+		return nil, domain.ErrInvalidInput
+	}
+	if req.ChecksumSHA256 != "" {
+		sum, err := base64.StdEncoding.DecodeString(req.ChecksumSHA256)
+		if err != nil || len(sum) != sha256.Size {
+			return nil, domain.ErrInvalidInput
+		}
+	}
+
+	multipart := req.Size > multipartThreshold
+	target, err := s.direct.CreateUpload(ctx, req.Filename, req.ContentType, multipart)
+	if err != nil {
+		return nil, err
+	}
+
+	upload := &domain.Upload{
+		ID:             uuid.New().String(),
+		OwnerID:        ownerID,
+		Kind:           req.Kind,
+		Filename:       req.Filename,
+		ContentType:    req.ContentType,
+		Size:           req.Size,
+		ChecksumSHA256: req.ChecksumSHA256,
+		FileURL:        target.FileURL,
+		MultipartID:    target.MultipartID,
+		Status:         domain.UploadPending,
+		ExpiresAt:      time.Now().Add(s.urlTTL),
+	}
+
+	ticket := &domain.UploadTicket{Upload: upload}
+	if !multipart {
+		ticket.Request, err = s.direct.PresignPut(ctx, target, req.ContentType, req.ChecksumSHA256, req.Size, s.urlTTL)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		upload.PartSize = uploadPartSize
+		if (req.Size+uploadPartSize-1)/uploadPartSize > maxUploadParts {
+			upload.PartSize = (req.Size + maxUploadParts - 1) / maxUploadParts
+		}
+		count := int((req.Size + upload.PartSize - 1) / upload.PartSize)
+		ticket.Parts = make([]domain.PresignedPart, count)
+		for i := range ticket.Parts {
+			r, err := s.direct.PresignPart(ctx, target, i+1, s.urlTTL)
+			if err != nil {
+				_ = s.direct.AbortUpload(ctx, target)
+				return nil, err
+			}
+			ticket.Parts[i] = domain.PresignedPart{PartNumber: i + 1, PresignedRequest: *r}
+		}
+	}
+
+	if err := s.uploadRepo.Create(ctx, upload); err != nil {
+		_ = s.direct.AbortUpload(ctx, target)
+		return nil, err
+	}
+	return ticket, nil
+}
+
+// Finalize รวมส่วนที่อัปโหลด (ถ้ามี) ตรวจขนาด ชนิดไฟล์ และ checksum แล้วย้ายไฟล์ไปเก็บตาม SHA-256 และผูกกับเพลง
+// การตรวจ checksum อาจต้องอ่านทั้งไฟล์ จึงใช้ timeout ของ service เฉพาะการทำงานกับฐานข้อมูล
+func (s *uploadService) Finalize(ctx context.Context, id string, actor domain.Actor, musicID uint, parts []domain.CompletedPart) (*domain.Music, error) {
+	dbCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	upload, err := s.uploadRepo.GetByID(dbCtx, id)
+	if err != nil {
+		return nil, err
+	}
+	if upload.OwnerID != actor.UserID {
+		return nil, domain.ErrForbidden
+	}
+	if upload.Status != domain.UploadPending {
+		return nil, domain.ErrConflict
+	}
+	if time.Now().After(upload.ExpiresAt) {
+		// URL สำหรับอัปโหลดหมดอายุแล้ว ต้องเริ่มการอัปโหลดใหม่
+		return nil, domain.ErrConflict
+	}
+	music, err := s.musicRepo.GetByID(dbCtx, musicID)
+	if err != nil {
+		return nil, err
+	}
+	if !canModifyMusic(actor, music) {
+		return nil, domain.ErrForbidden
+	}
+
+	target := &domain.UploadTarget{FileURL: upload.FileURL, MultipartID: upload.MultipartID}
+	if err := s.verify(ctx, upload, target, parts); err != nil {
+		if err == domain.ErrInvalidInput {
+			// ไฟล์ไม่ผ่านการตรวจสอบ ลบทิ้งและปิดการอัปโหลดนี้
+			_ = s.direct.AbortUpload(ctx, target)
+			upload.Status = domain.UploadFailed
+			_ = s.uploadRepo.Update(dbCtx, upload)
+		}
+		return nil, err
+	}
+
+	// ไฟล์ที่อัปโหลดตรงอยู่ที่ชื่อสุ่ม คัดลอกไปเก็บตาม SHA-256 และนับการอ้างอิงเหมือนไฟล์ที่อัปโหลดผ่าน API
+	fileURL, err := s.store(ctx, upload)
+	if err != nil {
+		return nil, err
+	}
+	src := &storageReaderAt{ctx: ctx, storage: s.storage, fileURL: fileURL}
+	if err := attachMedia(ctx, s.musicRepo, s.storage, s.timeout, music, upload.Kind, fileURL, src, upload.Size); err != nil {
+		// ไฟล์ชื่อสุ่มยังอยู่ finalize ซ้ำได้
+		releaseMedia(ctx, s.storage, s.timeout, fileURL)
+		return nil, err
+	}
+
+	dbCtx, cancel = context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	directURL := upload.FileURL
+	upload.FileURL = fileURL
+	upload.Status = domain.UploadCompleted
+	upload.MusicID = &music.ID
+	if err := s.uploadRepo.Update(dbCtx, upload); err != nil {
+		return nil, err
+	}
+	// ไฟล์ชื่อสุ่มไม่ได้นับการอ้างอิง จึงถูกลบทันที (ลบไม่สำเร็จจะถูกเก็บกวาดโดย media-gc)
+	releaseMedia(ctx, s.storage, s.timeout, directURL)
+	return s.musicRepo.GetByID(dbCtx, music.ID)
+}
+
+// store คัดลอกไฟล์ที่ตรวจแล้วไปเก็บที่ key ตาม SHA-256 ของเนื้อหาและเพิ่มการอ้างอิง คืน URL ของไฟล์ที่เก็บ
+// ถ้ามีไฟล์เนื้อหาเดียวกันอยู่แล้วจะได้ URL เดิม
+func (s *uploadService) store(ctx context.Context, upload *domain.Upload) (string, error) {
+	body, err := s.storage.OpenFile(ctx, upload.FileURL, 0, -1)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+
+	return s.storage.UploadReader(ctx, body, upload.Size, upload.Filename, upload.ContentType)
+}
+
+// attachMedia ผูกไฟล์ที่อัปโหลดเสร็จแล้วกับฟิลด์ของเพลงตามชนิดไฟล์ และลบไฟล์เดิม
+// ไฟล์ mp3 จะถูกอ่าน ID3 tag จาก src เพื่อเติมข้อมูลเพลงและภาพปก
+func attachMedia(ctx context.Context, musicRepo domain.MusicRepository, storage domain.StorageService, timeout time.Duration, music *domain.Music, kind domain.UploadKind, fileURL string, src io.ReaderAt, size int64) error {
+	var oldURL string
+	switch kind {
+	case domain.UploadMP3:
+		oldURL, music.MP3URL = music.MP3URL, fileURL
+		if meta, _ := mp3meta.Parse(src, size); meta != nil {
+			applyMP3Metadata(music, meta)
+			if music.ImageURL == "" && meta.Picture != nil {
+				if url, err := uploadCover(ctx, storage, meta.Picture); err == nil {
+					music.ImageURL = url
+				}
+			}
+		}
+	case domain.UploadMP4:
+		oldURL, music.MP4URL = music.MP4URL, fileURL
+	case domain.UploadImage:
+		oldURL, music.ImageURL = music.ImageURL, fileURL
+	}
+
+	dbCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// ไม่แตะอัลบั้มและรายชื่อศิลปินของเพลง
+	music.Album = nil
+	music.Artists = nil
+	if err := musicRepo.Update(dbCtx, music); err != nil {
+		return err
+	}
+	// ปล่อยไฟล์เดิมเสมอแม้จะเป็น URL เดียวกัน (เนื้อหาเดิมอัปโหลดซ้ำ) เพราะการอัปโหลดใหม่ได้เพิ่มการอ้างอิงไว้แล้ว
+	if oldURL != "" {
+		releaseMedia(ctx, storage, timeout, oldURL)
+	}
+	return nil
+}
+
+// verify รวมส่วนของ multipart upload แล้วตรวจว่าไฟล์ใน storage ตรงกับที่แจ้งไว้
+// คืน ErrInvalidInput ถ้าไฟล์ไม่ถูกต้อง
+func (s *uploadService) verify(ctx context.Context, upload *domain.Upload, target *domain.UploadTarget, parts []domain.CompletedPart) error {
+	if upload.MultipartID != "" {
+		if len(parts) == 0 {
+			return domain.ErrInvalidInput
+		}
+		if err := s.direct.CompleteMultipart(ctx, target, parts); err != nil {
+			if err == domain.ErrNotFound {
+				return domain.ErrInvalidInput
+			}
+			return err
+		}
+	}
+
+	info, err := s.storage.StatFile(ctx, upload.FileURL)
+	if err != nil {
+		if err == domain.ErrNotFound {
+			return domain.ErrInvalidInput
+		}
+		return err
+	}
+	if info.Size != upload.Size {
+		return domain.ErrInvalidInput
+	}
+	// storage บางแบบเดาชนิดไฟล์จากนามสกุล ตรวจเฉพาะเมื่อรู้ชนิดไฟล์
+	if info.ContentType != "" && info.ContentType != "application/octet-stream" && !allowedContentType(upload.Kind, info.ContentType) {
+		return domain.ErrInvalidInput
+	}
+
+	if upload.ChecksumSHA256 == "" {
+		return nil
+	}
+	sum := info.ChecksumSHA256
+	if sum == "" {
+		if sum, err = s.checksum(ctx, upload.FileURL); err != nil {
+			return err
+		}
+	}
+	if sum != upload.ChecksumSHA256 {
+		return domain.ErrInvalidInput
+	}
+	return nil
+}
+
+// checksum คำนวณ SHA-256 (base64) ของไฟล์ใน storage
+func (s *uploadService) checksum(ctx context.Context, fileURL string) (string, error) {
+	sum, err := storageSHA256(ctx, s.storage, fileURL)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(sum), nil
+}
+
+// storageSHA256 อ่านไฟล์ใน storage ทั้งไฟล์เพื่อคำนวณ SHA-256
+func storageSHA256(ctx context.Context, storage domain.StorageService, fileURL string) ([]byte, error) {
+	body, err := storage.OpenFile(ctx, fileURL, 0, -1)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, body); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// storageReaderAt อ่านไฟล์ใน storage แบบสุ่มตำแหน่งด้วย OpenFile (ใช้อ่าน ID3 tag โดยไม่ต้องโหลดทั้งไฟล์)
+type storageReaderAt struct {
+	ctx     context.Context
+	storage domain.StorageService
+	fileURL string
+}
+
+// ReadAt อ่านข้อมูล len(p) ไบต์จากตำแหน่ง off
+func (r *storageReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	body, err := r.storage.OpenFile(r.ctx, r.fileURL, off, int64(len(p)))
+	if err != nil {
+		return 0, err
+	}
+	defer body.Close()
+
+	n, err := io.ReadFull(body, p)
+	if err == io.ErrUnexpectedEOF {
+		err = io.EOF
+	}
+	return n, err
+}
@@ -2,25 +2,38 @@ package service // ประกาศ package service
 
 import (
 	"context" // นำเข้า context
+	"strings" // นำเข้า strings
 	"time"    // นำเข้า time
 
 	"go-music-api/internal/domain" // นำเข้า domain entities
-	"go-music-api/pkg/utils"       // นำเข้า utils สำหรับช่วยจัดการ JWT
 
 	"golang.org/x/crypto/bcrypt" // นำเข้า bcrypt สำหรับเข้ารหัสรหัสผ่าน
 )
 
 // userService struct สำหรับ implement interface UserService
 type userService struct {
-	userRepo domain.UserRepository // repository สำหรับจัดการข้อมูลผู้ใช้
-	timeout  time.Duration         // ระยะเวลา timeout
+	userRepo     domain.UserRepository     // repository สำหรับจัดการข้อมูลผู้ใช้
+	musicRepo    domain.MusicRepository    // repository สำหรับเพลงของผู้ใช้ (export)
+	playlistRepo domain.PlaylistRepository // repository สำหรับ playlist ของผู้ใช้ (export)
+	sessions     domain.SessionService     // service สำหรับออก token และจัดการ session
+	twoFactor    domain.TwoFactorService   // service สำหรับขั้นที่สองของการเข้าสู่ระบบ
+	lockout      domain.LoginLockout       // ล็อกอีเมลที่เข้าสู่ระบบผิดซ้ำ
+	// requireVerified ไม่ให้บัญชีที่ยังไม่ยืนยันอีเมลเข้าสู่ระบบ
+	requireVerified bool
+	timeout         time.Duration // ระยะเวลา timeout
 }
 
 // NewUserService สร้าง instance ของ UserService
-func NewUserService(userRepo domain.UserRepository, timeout time.Duration) domain.UserService {
+func NewUserService(userRepo domain.UserRepository, musicRepo domain.MusicRepository, playlistRepo domain.PlaylistRepository, sessions domain.SessionService, twoFactor domain.TwoFactorService, lockout domain.LoginLockout, requireVerified bool, timeout time.Duration) domain.UserService {
 	return &userService{
-		userRepo: userRepo,
-		timeout:  timeout,
+		userRepo:        userRepo,
+		musicRepo:       musicRepo,
+		playlistRepo:    playlistRepo,
+		sessions:        sessions,
+		twoFactor:       twoFactor,
+		lockout:         lockout,
+		requireVerified: requireVerified,
+		timeout:         timeout,
 	}
 }
 
@@ -42,51 +55,75 @@ func (s *userService) Register(ctx context.Context, user *domain.User) error {
 	return s.userRepo.Create(ctx, user)
 }
 
-// Login ตรวจสอบข้อมูลการเข้าสู่ระบบและสร้าง Token
-func (s *userService) Login(ctx context.Context, email, password string) (string, string, error) {
+// Login ตรวจสอบข้อมูลการเข้าสู่ระบบและเริ่ม session ใหม่
+// ถ้าผู้ใช้เปิด 2FA จะคืน challenge token แทน และออก token หลังยืนยันรหัสผ่าน TwoFactorService.CompleteChallenge
+func (s *userService) Login(ctx context.Context, email, password string, client domain.ClientInfo) (*domain.LoginResult, error) {
 	// สร้าง context ที่มี timeout
 	ctx, cancel := context.WithTimeout(ctx, s.timeout)
 	defer cancel()
 
+	// ตรวจการล็อกก่อน bcrypt เพื่อไม่ให้การเดารหัสผ่านซ้ำ ๆ กิน CPU
+	// นับตามอีเมลแม้ไม่มีบัญชีนั้น เพื่อไม่ให้ใช้การล็อกเดาได้ว่าอีเมลใดมีในระบบ
+	lockKey := strings.ToLower(strings.TrimSpace(email))
+	if wait, err := s.lockout.Locked(ctx, lockKey); err != nil {
+		return nil, err
+	} else if wait > 0 {
+		return nil, &domain.LockedError{RetryAfter: wait}
+	}
+
 	// ค้นหาผู้ใช้จากอีเมล
 	user, err := s.userRepo.GetByEmail(ctx, email)
 	if err != nil {
 		// ถ้าหาไม่เจอ ให้คืนค่า error ว่า credentials ไม่ถูกต้อง
-		return "", "", domain.ErrInvalidCreds
+		return nil, s.loginFailed(ctx, lockKey)
 	}
 
 	// ตรวจสอบรหัสผ่านว่าตรงกับที่เข้ารหัสไว้หรือไม่
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
 		// ถ้ารหัสผ่านไม่ตรง ให้คืนค่า error ว่า credentials ไม่ถูกต้อง
-		return "", "", domain.ErrInvalidCreds
+		return nil, s.loginFailed(ctx, lockKey)
+	}
+	if err := s.lockout.Reset(ctx, lockKey); err != nil {
+		return nil, err
 	}
 
-	// สร้าง JWT token pair (Access Token และ Refresh Token)
-	accessToken, refreshToken, err := utils.GenerateTokenPair(user.ID, user.Email)
-	if err != nil {
-		return "", "", err
+	// ตรวจหลังรหัสผ่านถูกต้อง เพื่อไม่ให้ใช้เดาได้ว่าอีเมลใดมีในระบบ
+	if s.requireVerified && user.EmailVerifiedAt == nil {
+		return nil, domain.ErrUnverified
 	}
 
-	// คืนค่า token ทั้งคู่
-	return accessToken, refreshToken, nil
+	return beginLogin(ctx, s.twoFactor, s.sessions, user, client)
 }
 
-// RefreshToken ตรวจสอบ Refresh Token และสร้าง Access Token ใหม่
-func (s *userService) RefreshToken(ctx context.Context, refreshToken string) (string, error) {
-	// ตรวจสอบความถูกต้องของ Refresh Token
-	claims, err := utils.ValidateToken(refreshToken)
+// beginLogin เริ่ม session ให้ผู้ใช้ที่ยืนยันตัวตนขั้นแรกแล้ว (รหัสผ่านหรือผู้ให้บริการภายนอก)
+// ถ้าผู้ใช้เปิด 2FA จะออก challenge แทน
+func beginLogin(ctx context.Context, twoFactor domain.TwoFactorService, sessions domain.SessionService, user *domain.User, client domain.ClientInfo) (*domain.LoginResult, error) {
+	enabled, err := twoFactor.Enabled(ctx, user.ID)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	if enabled {
+		token, expiresAt, err := twoFactor.BeginChallenge(ctx, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		return &domain.LoginResult{ChallengeToken: token, ChallengeExpiresAt: expiresAt}, nil
 	}
 
-	// สร้าง Token Pair ใหม่ (จริงๆ เราต้องการแค่ Access Token ใหม่ แต่ใช้ฟังก์ชันเดิมเพื่อความสะดวก)
-	// หมายเหตุ: ในระบบจริงอาจจะมีการตรวจสอบเพิ่มเติม เช่น Blacklist หรือเช็คว่า user ยัง active อยู่ไหม
-	accessToken, _, err := utils.GenerateTokenPair(claims.UserID, claims.Email)
+	// สร้าง session ใหม่พร้อม Access Token และ Refresh Token
+	tokens, err := sessions.Issue(ctx, user, client, false)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
+	return &domain.LoginResult{Tokens: tokens}, nil
+}
 
-	return accessToken, nil
+// loginFailed บันทึกการเข้าสู่ระบบผิดและคืน ErrInvalidCreds
+func (s *userService) loginFailed(ctx context.Context, lockKey string) error {
+	if err := s.lockout.Fail(ctx, lockKey); err != nil {
+		return err
+	}
+	return domain.ErrInvalidCreds
 }
 
 func (s *userService) GetByID(ctx context.Context, id uint) (*domain.User, error) {
@@ -106,3 +143,130 @@ func (s *userService) UpdateProfile(ctx context.Context, id uint, updates map[st
 
 	return s.userRepo.GetByID(ctx, id)
 }
+
+// AssignRole กำหนดบทบาทให้ผู้ใช้ (ต้องมีสิทธิ์จัดการผู้ใช้ และ admin ลดบทบาทตัวเองไม่ได้)
+func (s *userService) AssignRole(ctx context.Context, actor domain.Actor, id uint, role domain.Role) (*domain.User, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	if !actor.Can(domain.PermUserManage) {
+		return nil, domain.ErrForbidden
+	}
+	if !role.Valid() {
+		return nil, domain.ErrInvalidInput
+	}
+	// กันไม่ให้ระบบไม่เหลือ admin จากการลดบทบาทตัวเองโดยไม่ตั้งใจ
+	if id == actor.UserID && role != domain.RoleAdmin {
+		return nil, domain.ErrInvalidInput
+	}
+
+	updates := map[string]any{"role": role}
+	if actor.Email != "" {
+		updates["updated_by"] = actor.Email
+	}
+	if err := s.userRepo.UpdateProfile(ctx, id, updates); err != nil {
+		return nil, err
+	}
+	return s.userRepo.GetByID(ctx, id)
+}
+
+// recentLoginWindow ช่วงเวลาหลังเข้าสู่ระบบที่บัญชีไม่มีรหัสผ่านยืนยันการทำรายการสำคัญได้
+const recentLoginWindow = 10 * time.Minute
+
+// confirmIdentity ยืนยันตัวตนก่อนทำรายการสำคัญ: ตรวจรหัสผ่านปัจจุบัน
+// หรือถ้าบัญชีไม่มีรหัสผ่าน (สร้างผ่าน OpenID Connect) ต้องเป็น session ที่เพิ่งเข้าสู่ระบบผ่านผู้ให้บริการ
+func confirmIdentity(ctx context.Context, sessions domain.SessionService, user *domain.User, sessionID, password string) error {
+	if user.Password == "" {
+		return sessions.RecentLogin(ctx, user.ID, sessionID, recentLoginWindow)
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)) != nil {
+		return domain.ErrInvalidCreds
+	}
+	return nil
+}
+
+// checkPassword ยืนยันตัวตนของผู้ใช้ด้วยรหัสผ่านปัจจุบัน (หรือการเข้าสู่ระบบล่าสุดถ้าไม่มีรหัสผ่าน)
+func (s *userService) checkPassword(ctx context.Context, id uint, sessionID, password string) (*domain.User, error) {
+	user, err := s.userRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := confirmIdentity(ctx, s.sessions, user, sessionID, password); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// ChangePassword เปลี่ยนรหัสผ่านเมื่อรหัสผ่านปัจจุบันถูกต้อง และยกเลิกทุก session ยกเว้น session ที่ใช้เปลี่ยน
+// บัญชีที่ยังไม่มีรหัสผ่านตั้งรหัสผ่านแรกได้ภายในช่วงเวลาหลังเข้าสู่ระบบ (ไม่ต้องส่งรหัสผ่านปัจจุบัน)
+func (s *userService) ChangePassword(ctx context.Context, id uint, sessionID, current, password string) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	user, err := s.checkPassword(ctx, id, sessionID, current)
+	if err != nil {
+		return err
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	if err := s.userRepo.UpdateProfile(ctx, id, map[string]any{"password": string(hashedPassword), "updated_by": user.Email}); err != nil {
+		return err
+	}
+	return s.sessions.LogoutOthers(ctx, id, sessionID)
+}
+
+// Export รวบรวมข้อมูลโปรไฟล์ เพลงที่อัปโหลด และ playlist ทั้งหมดของผู้ใช้
+func (s *userService) Export(ctx context.Context, id uint) (*domain.UserExport, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	user, err := s.userRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	tracks, err := s.musicRepo.ListByOwner(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	playlists := []domain.Playlist{}
+	for page := 1; ; page++ {
+		items, total, err := s.playlistRepo.ListByUser(ctx, id, page, domain.MaxPageSize)
+		if err != nil {
+			return nil, err
+		}
+		for i := range items {
+			if items[i].Entries, err = s.playlistRepo.GetEntries(ctx, items[i].ID); err != nil {
+				return nil, err
+			}
+		}
+		playlists = append(playlists, items...)
+		if len(items) == 0 || int64(len(playlists)) >= total {
+			break
+		}
+	}
+
+	return &domain.UserExport{
+		ExportedAt: time.Now(),
+		Profile:    user,
+		Tracks:     tracks,
+		Playlists:  playlists,
+	}, nil
+}
+
+// DeleteAccount ลบบัญชีของผู้ใช้เมื่อยืนยันรหัสผ่านถูกต้อง และยกเลิกทุก session
+// ไฟล์เพลงของผู้ใช้จะถูกลบจาก storage ภายหลังโดยงานลบไฟล์
+func (s *userService) DeleteAccount(ctx context.Context, id uint, sessionID, password string) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	if _, err := s.checkPassword(ctx, id, sessionID, password); err != nil {
+		return err
+	}
+	if err := s.userRepo.SoftDelete(ctx, id, time.Now()); err != nil {
+		return err
+	}
+	return s.sessions.LogoutAll(ctx, id)
+}
@@ -0,0 +1,343 @@
+package mp3meta // ประกาศ package mp3meta
+
+import (
+	"bytes"           // นำเข้า bytes
+	"encoding/binary" // นำเข้า binary สำหรับอ่านตัวเลขแบบ big-endian
+	"io"              // นำเข้า io
+	"strconv"         // นำเข้า strconv
+	"strings"         // นำเข้า strings
+	"unicode/utf16"   // นำเข้า utf16 สำหรับถอดรหัสข้อความ UTF-16
+)
+
+// syncsafe แปลงตัวเลข 28 บิตที่เก็บแบบ 7 บิตต่อไบต์
+func syncsafe(b []byte) int {
+	return int(b[0]&0x7f)<<21 | int(b[1]&0x7f)<<14 | int(b[2]&0x7f)<<7 | int(b[3]&0x7f)
+}
+
+// removeUnsync ถอด unsynchronisation (0xFF 0x00 -> 0xFF)
+func removeUnsync(b []byte) []byte {
+	return bytes.ReplaceAll(b, []byte{0xff, 0x00}, []byte{0xff})
+}
+
+// readID3v2 อ่าน ID3v2 tag ที่ต้นไฟล์ (ถ้ามี) และคืนตำแหน่งเริ่มต้นของข้อมูลเสียง
+func readID3v2(r io.ReaderAt, size int64, meta *Metadata) (int64, error) {
+	header := make([]byte, 10)
+	if _, err := r.ReadAt(header, 0); err != nil {
+		if err == io.EOF {
+			return 0, ErrNotMP3
+		}
+		return 0, err
+	}
+	if string(header[:3]) != "ID3" {
+		return 0, nil
+	}
+
+	major := header[3]
+	flags := header[5]
+	tagSize := syncsafe(header[6:10])
+	audioStart := int64(10 + tagSize)
+	if flags&0x10 != 0 {
+		audioStart += 10 // มี footer
+	}
+	if major < 2 || major > 4 || tagSize > maxTagSize || int64(tagSize)+10 > size {
+		// tag ผิดรูปแบบ ข้ามไปอ่านเฉพาะข้อมูลเสียง
+		return audioStart, nil
+	}
+
+	tag := make([]byte, tagSize)
+	if _, err := r.ReadAt(tag, 10); err != nil && err != io.EOF {
+		return 0, err
+	}
+	if flags&0x80 != 0 && major < 4 {
+		tag = removeUnsync(tag)
+	}
+
+	// ข้าม extended header
+	if flags&0x40 != 0 && len(tag) >= 4 {
+		var ext int
+		if major == 4 {
+			ext = syncsafe(tag[:4])
+		} else {
+			ext = int(binary.BigEndian.Uint32(tag[:4])) + 4
+		}
+		if ext > len(tag) {
+			return audioStart, nil
+		}
+		tag = tag[ext:]
+	}
+
+	parseFrames(tag, major, meta)
+	return audioStart, nil
+}
+
+// parseFrames อ่าน frame ทั้งหมดใน tag
+func parseFrames(tag []byte, major byte, meta *Metadata) {
+	idLen, headerLen := 4, 10
+	if major == 2 {
+		idLen, headerLen = 3, 6
+	}
+
+	for len(tag) >= headerLen {
+		id := string(tag[:idLen])
+		if id[0] == 0 {
+			break // padding
+		}
+
+		var frameSize int
+		var formatFlags byte
+		switch major {
+		case 2:
+			frameSize = int(tag[3])<<16 | int(tag[4])<<8 | int(tag[5])
+		case 3:
+			frameSize = int(binary.BigEndian.Uint32(tag[4:8]))
+			formatFlags = tag[9]
+		default:
+			frameSize = syncsafe(tag[4:8])
+			formatFlags = tag[9]
+		}
+		if frameSize <= 0 || headerLen+frameSize > len(tag) {
+			break
+		}
+		data := tag[headerLen : headerLen+frameSize]
+		tag = tag[headerLen+frameSize:]
+
+		// ข้าม frame ที่บีบอัดหรือเข้ารหัส
+		if major == 3 && formatFlags&0xc0 != 0 {
+			continue
+		}
+		if major == 4 {
+			if formatFlags&0x0c != 0 {
+				continue
+			}
+			if formatFlags&0x01 != 0 { // data length indicator
+				if len(data) < 4 {
+					continue
+				}
+				data = data[4:]
+			}
+			if formatFlags&0x02 != 0 {
+				data = removeUnsync(data)
+			}
+		}
+
+		applyFrame(id, data, meta)
+	}
+}
+
+// applyFrame นำข้อมูลจาก frame ที่รู้จักไปใส่ใน metadata
+func applyFrame(id string, data []byte, meta *Metadata) {
+	switch id {
+	case "TIT2", "TT2":
+		meta.Title = textFrame(data)
+	case "TPE1", "TP1":
+		meta.Artist = textFrame(data)
+	case "TALB", "TAL":
+		meta.Album = textFrame(data)
+	case "TYER", "TYE", "TDRC", "TDOR":
+		if meta.Year == 0 {
+			meta.Year = parseYear(textFrame(data))
+		}
+	case "TCON", "TCO":
+		meta.Genre = parseGenre(textFrame(data))
+	case "TRCK", "TRK":
+		meta.TrackNumber = parseTrack(textFrame(data))
+	case "APIC":
+		if pic := apicFrame(data); pic != nil && (meta.Picture == nil || (pic.Type == 3 && meta.Picture.Type != 3)) {
+			meta.Picture = pic
+		}
+	case "PIC":
+		if pic := picFrame(data); pic != nil && (meta.Picture == nil || (pic.Type == 3 && meta.Picture.Type != 3)) {
+			meta.Picture = pic
+		}
+	}
+}
+
+// textFrame ถอดรหัส text frame (ไบต์แรกคือ encoding)
+func textFrame(data []byte) string {
+	if len(data) < 1 {
+		return ""
+	}
+	s := decodeText(data[0], data[1:])
+	// ID3v2.4 ใช้ null คั่นหลายค่า ใช้เฉพาะค่าแรก
+	if i := strings.IndexByte(s, 0); i >= 0 {
+		s = s[:i]
+	}
+	return strings.TrimSpace(s)
+}
+
+// decodeText ถอดรหัสข้อความตาม encoding ของ ID3
+func decodeText(enc byte, b []byte) string {
+	switch enc {
+	case 1, 2: // UTF-16 (มี BOM) / UTF-16BE
+		bigEndian := enc == 2
+		if len(b) >= 2 {
+			if b[0] == 0xff && b[1] == 0xfe {
+				bigEndian, b = false, b[2:]
+			} else if b[0] == 0xfe && b[1] == 0xff {
+				bigEndian, b = true, b[2:]
+			}
+		}
+		u := make([]uint16, 0, len(b)/2)
+		for i := 0; i+1 < len(b); i += 2 {
+			if bigEndian {
+				u = append(u, uint16(b[i])<<8|uint16(b[i+1]))
+			} else {
+				u = append(u, uint16(b[i+1])<<8|uint16(b[i]))
+			}
+		}
+		return string(utf16.Decode(u))
+	case 3: // UTF-8
+		return string(b)
+	default: // ISO-8859-1
+		runes := make([]rune, len(b))
+		for i, c := range b {
+			runes[i] = rune(c)
+		}
+		return string(runes)
+	}
+}
+
+// splitTerminated แยกข้อความที่จบด้วย null ตาม encoding และคืนส่วนที่เหลือ
+func splitTerminated(enc byte, b []byte) (string, []byte) {
+	if enc == 1 || enc == 2 {
+		for i := 0; i+1 < len(b); i += 2 {
+			if b[i] == 0 && b[i+1] == 0 {
+				return decodeText(enc, b[:i]), b[i+2:]
+			}
+		}
+		return decodeText(enc, b), nil
+	}
+	if i := bytes.IndexByte(b, 0); i >= 0 {
+		return decodeText(enc, b[:i]), b[i+1:]
+	}
+	return decodeText(enc, b), nil
+}
+
+// apicFrame อ่านรูปภาพจาก APIC frame (ID3v2.3/2.4)
+func apicFrame(data []byte) *Picture {
+	if len(data) < 4 {
+		return nil
+	}
+	enc := data[0]
+	mime, rest := splitTerminated(0, data[1:])
+	if len(rest) < 1 {
+		return nil
+	}
+	picType := rest[0]
+	_, img := splitTerminated(enc, rest[1:])
+	if len(img) == 0 {
+		return nil
+	}
+	return &Picture{MIMEType: normalizeMIME(mime, img), Type: picType, Data: img}
+}
+
+// picFrame อ่านรูปภาพจาก PIC frame (ID3v2.2)
+func picFrame(data []byte) *Picture {
+	if len(data) < 6 {
+		return nil
+	}
+	enc := data[0]
+	format := strings.ToUpper(string(data[1:4]))
+	picType := data[4]
+	_, img := splitTerminated(enc, data[5:])
+	if len(img) == 0 {
+		return nil
+	}
+	mime := "image/jpeg"
+	if format == "PNG" {
+		mime = "image/png"
+	}
+	return &Picture{MIMEType: normalizeMIME(mime, img), Type: picType, Data: img}
+}
+
+// normalizeMIME ใช้ MIME ที่ระบุ ถ้าไม่มีหรือไม่ครบให้เดาจาก magic bytes
+func normalizeMIME(mime string, img []byte) string {
+	mime = strings.ToLower(strings.TrimSpace(mime))
+	switch mime {
+	case "image/jpg", "jpg", "jpeg":
+		return "image/jpeg"
+	case "png":
+		return "image/png"
+	}
+	if strings.HasPrefix(mime, "image/") {
+		return mime
+	}
+	if bytes.HasPrefix(img, []byte{0x89, 'P', 'N', 'G'}) {
+		return "image/png"
+	}
+	return "image/jpeg"
+}
+
+// parseYear อ่านปีจากข้อความ เช่น "2019" หรือ "2019-05-01"
+func parseYear(s string) int {
+	if len(s) < 4 {
+		return 0
+	}
+	y, err := strconv.Atoi(s[:4])
+	if err != nil {
+		return 0
+	}
+	return y
+}
+
+// parseTrack อ่านลำดับเพลง เช่น "3" หรือ "3/12"
+func parseTrack(s string) int {
+	if i := strings.IndexByte(s, '/'); i >= 0 {
+		s = s[:i]
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
+
+// parseGenre แปลงรูปแบบ "(17)", "17" หรือ "(17)Rock" เป็นชื่อแนวเพลง
+func parseGenre(s string) string {
+	if strings.HasPrefix(s, "(") {
+		if end := strings.IndexByte(s, ')'); end > 0 {
+			if rest := strings.TrimSpace(s[end+1:]); rest != "" {
+				return rest
+			}
+			s = s[1:end]
+		}
+	}
+	if n, err := strconv.Atoi(s); err == nil {
+		return genreName(n)
+	}
+	return s
+}
+
+// readID3v1 อ่าน ID3v1 tag ที่ท้ายไฟล์ (128 ไบต์สุดท้าย)
+func readID3v1(r io.ReaderAt, size int64) (*Metadata, bool) {
+	if size < 128 {
+		return nil, false
+	}
+	b := make([]byte, 128)
+	if _, err := r.ReadAt(b, size-128); err != nil && err != io.EOF {
+		return nil, false
+	}
+	if string(b[:3]) != "TAG" {
+		return nil, false
+	}
+
+	field := func(f []byte) string {
+		if i := bytes.IndexByte(f, 0); i >= 0 {
+			f = f[:i]
+		}
+		return strings.TrimSpace(decodeText(0, f))
+	}
+
+	meta := &Metadata{
+		Title:  field(b[3:33]),
+		Artist: field(b[33:63]),
+		Album:  field(b[63:93]),
+		Year:   parseYear(field(b[93:97])),
+		Genre:  genreName(int(b[127])),
+	}
+	// ID3v1.1: ไบต์ที่ 29 ของ comment เป็นลำดับเพลงเมื่อไบต์ที่ 28 เป็น 0
+	if b[125] == 0 && b[126] != 0 {
+		meta.TrackNumber = int(b[126])
+	}
+	return meta, true
+}
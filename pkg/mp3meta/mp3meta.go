@@ -0,0 +1,81 @@
+// Package mp3meta อ่าน ID3v1/ID3v2 tag และข้อมูลเสียงจาก MPEG frame header ของไฟล์ MP3 (pure Go)
+package mp3meta // ประกาศ package mp3meta
+
+import (
+	"errors" // นำเข้า errors
+	"io"     // นำเข้า io
+	"time"   // นำเข้า time
+)
+
+// ErrNotMP3 ไม่พบ MPEG audio frame ในไฟล์
+var ErrNotMP3 = errors.New("mp3meta: no MPEG audio frame found")
+
+// maxTagSize ขนาด ID3v2 tag สูงสุดที่ยอมอ่าน (กันไฟล์ที่ตั้งขนาด tag ผิดปกติ)
+const maxTagSize = 16 << 20
+
+// Picture รูปภาพที่ฝังอยู่ใน tag (APIC/PIC)
+type Picture struct {
+	MIMEType string // เช่น image/jpeg
+	Type     byte   // ชนิดรูปตามสเปค ID3 (3 = หน้าปก)
+	Data     []byte // ข้อมูลรูป
+}
+
+// Metadata ข้อมูลที่อ่านได้จากไฟล์ MP3
+type Metadata struct {
+	Title       string
+	Artist      string
+	Album       string
+	Year        int
+	Genre       string
+	TrackNumber int
+	Picture     *Picture // รูปหน้าปก (nil ถ้าไม่มี)
+
+	Duration   time.Duration // ความยาวเพลง
+	Bitrate    int           // kbps (ค่าเฉลี่ยสำหรับไฟล์ VBR)
+	SampleRate int           // Hz
+	Channels   int           // 1 = mono, 2 = stereo
+}
+
+// Parse อ่าน metadata จากไฟล์ MP3 ขนาด size ไบต์
+// ค่าจาก ID3v2 มีลำดับความสำคัญสูงกว่า ID3v1
+func Parse(r io.ReaderAt, size int64) (*Metadata, error) {
+	meta := &Metadata{}
+
+	audioStart, err := readID3v2(r, size, meta)
+	if err != nil {
+		return nil, err
+	}
+
+	audioEnd := size
+	if v1, ok := readID3v1(r, size); ok {
+		audioEnd -= 128
+		meta.fillFrom(v1)
+	}
+
+	if err := readMPEG(r, audioStart, audioEnd, meta); err != nil {
+		return meta, err
+	}
+	return meta, nil
+}
+
+// fillFrom เติมค่าที่ยังว่างจาก metadata อื่น
+func (m *Metadata) fillFrom(o *Metadata) {
+	if m.Title == "" {
+		m.Title = o.Title
+	}
+	if m.Artist == "" {
+		m.Artist = o.Artist
+	}
+	if m.Album == "" {
+		m.Album = o.Album
+	}
+	if m.Year == 0 {
+		m.Year = o.Year
+	}
+	if m.Genre == "" {
+		m.Genre = o.Genre
+	}
+	if m.TrackNumber == 0 {
+		m.TrackNumber = o.TrackNumber
+	}
+}
@@ -0,0 +1,334 @@
+package mp3meta
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+	"time"
+	"unicode/utf16"
+)
+
+// syncsafeBytes เข้ารหัสตัวเลขแบบ 7 บิตต่อไบต์
+func syncsafeBytes(n int) []byte {
+	return []byte{byte(n >> 21 & 0x7f), byte(n >> 14 & 0x7f), byte(n >> 7 & 0x7f), byte(n & 0x7f)}
+}
+
+// frame สร้าง frame ของ ID3v2.2/2.3/2.4 (flags คือไบต์ format flags ของ 2.3/2.4)
+func frame(major byte, id string, flags byte, data []byte) []byte {
+	var b []byte
+	switch major {
+	case 2:
+		n := len(data)
+		b = append([]byte(id), byte(n>>16), byte(n>>8), byte(n))
+	case 3:
+		b = binary.BigEndian.AppendUint32([]byte(id), uint32(len(data)))
+		b = append(b, 0, flags)
+	default:
+		b = append([]byte(id), syncsafeBytes(len(data))...)
+		b = append(b, 0, flags)
+	}
+	return append(b, data...)
+}
+
+// text ข้อมูลของ text frame แบบ ISO-8859-1
+func text(s string) []byte {
+	return append([]byte{0}, s...)
+}
+
+// utf16Text ข้อมูลของ text frame แบบ UTF-16 little-endian ที่มี BOM
+func utf16Text(s string) []byte {
+	b := []byte{1, 0xff, 0xfe}
+	for _, u := range utf16.Encode([]rune(s)) {
+		b = append(b, byte(u), byte(u>>8))
+	}
+	return b
+}
+
+// id3v2 สร้าง ID3v2 tag จาก frame ที่ให้ (ต่อท้ายด้วย padding)
+func id3v2(major, flags byte, frames ...[]byte) []byte {
+	body := bytes.Join(frames, nil)
+	body = append(body, make([]byte, 16)...)
+	header := append([]byte{'I', 'D', '3', major, 0, flags}, syncsafeBytes(len(body))...)
+	return append(header, body...)
+}
+
+// id3v1 สร้าง ID3v1.1 tag 128 ไบต์
+func id3v1(title, artist, album, year string, track, genre byte) []byte {
+	b := make([]byte, 128)
+	copy(b, "TAG")
+	copy(b[3:33], title)
+	copy(b[33:63], artist)
+	copy(b[63:93], album)
+	copy(b[93:97], year)
+	b[126] = track
+	b[127] = genre
+	return b
+}
+
+// cbrFrames MPEG1 Layer III 128 kbps 44.1 kHz stereo จำนวน n frame (417 ไบต์ต่อ frame)
+func cbrFrames(n int) []byte {
+	f := make([]byte, 417)
+	copy(f, []byte{0xff, 0xfb, 0x90, 0x00})
+	return bytes.Repeat(f, n)
+}
+
+func TestParseFrames(t *testing.T) {
+	tests := []struct {
+		name   string
+		major  byte
+		frames [][]byte
+		want   Metadata
+	}{
+		{
+			name:  "ID3v2.3 text frames",
+			major: 3,
+			frames: [][]byte{
+				frame(3, "TIT2", 0, text("Song")),
+				frame(3, "TPE1", 0, text("Band")),
+				frame(3, "TALB", 0, text("Record")),
+				frame(3, "TYER", 0, text("2019")),
+				frame(3, "TCON", 0, text("(17)")),
+				frame(3, "TRCK", 0, text("3/12")),
+			},
+			want: Metadata{Title: "Song", Artist: "Band", Album: "Record", Year: 2019, Genre: "Rock", TrackNumber: 3},
+		},
+		{
+			name:  "ID3v2.4 UTF-8 with multiple values and recording date",
+			major: 4,
+			frames: [][]byte{
+				frame(4, "TIT2", 0, append([]byte{3}, "เพลงไทย\x00Second"...)),
+				frame(4, "TDRC", 0, text("2021-05-01")),
+				frame(4, "TCON", 0, text("(13)Thai Pop")),
+			},
+			want: Metadata{Title: "เพลงไทย", Year: 2021, Genre: "Thai Pop"},
+		},
+		{
+			name:   "UTF-16 with BOM",
+			major:  3,
+			frames: [][]byte{frame(3, "TPE1", 0, utf16Text("Café"))},
+			want:   Metadata{Artist: "Café"},
+		},
+		{
+			name:   "ID3v2.2 three-letter frames",
+			major:  2,
+			frames: [][]byte{frame(2, "TT2", 0, text("Old")), frame(2, "TP1", 0, text("Tag")), frame(2, "TRK", 0, text("7"))},
+			want:   Metadata{Title: "Old", Artist: "Tag", TrackNumber: 7},
+		},
+		{
+			name:   "ID3v2.3 compressed frame is skipped",
+			major:  3,
+			frames: [][]byte{frame(3, "TIT2", 0x80, text("Zipped")), frame(3, "TPE1", 0, text("Band"))},
+			want:   Metadata{Artist: "Band"},
+		},
+		{
+			name:   "ID3v2.4 data length indicator",
+			major:  4,
+			frames: [][]byte{frame(4, "TIT2", 0x01, append(syncsafeBytes(5), text("Song")...))},
+			want:   Metadata{Title: "Song"},
+		},
+		{
+			name:   "ID3v2.4 frame unsynchronisation",
+			major:  4,
+			frames: [][]byte{frame(4, "TIT2", 0x02, []byte{0, 'A', 0xff, 0x00, 'B'})},
+			want:   Metadata{Title: "AÿB"},
+		},
+		{
+			name:   "first recorded year wins",
+			major:  4,
+			frames: [][]byte{frame(4, "TDRC", 0, text("2001")), frame(4, "TDOR", 0, text("1999"))},
+			want:   Metadata{Year: 2001},
+		},
+		{
+			name:   "frame larger than the tag stops parsing",
+			major:  3,
+			frames: [][]byte{frame(3, "TIT2", 0, text("Song")), {'T', 'P', 'E', '1', 0, 0, 1, 0, 0, 0, 0, 'X'}},
+			want:   Metadata{Title: "Song"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var meta Metadata
+			tag := bytes.Join(tt.frames, nil)
+			tag = append(tag, make([]byte, 10)...) // padding
+			parseFrames(tag, tt.major, &meta)
+			if meta != tt.want {
+				t.Fatalf("parseFrames() = %+v, want %+v", meta, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePicture(t *testing.T) {
+	jpeg := []byte{0xff, 0xd8, 0xff, 0xe0}
+	png := []byte{0x89, 'P', 'N', 'G'}
+	apic := func(mime string, picType byte, img []byte) []byte {
+		b := append([]byte{0}, mime...)
+		b = append(b, 0, picType)
+		b = append(b, "desc\x00"...)
+		return append(b, img...)
+	}
+
+	tests := []struct {
+		name     string
+		major    byte
+		frames   [][]byte
+		wantMIME string
+		wantType byte
+		wantData []byte
+	}{
+		{
+			name:     "APIC front cover",
+			major:    3,
+			frames:   [][]byte{frame(3, "APIC", 0, apic("image/jpeg", 3, jpeg))},
+			wantMIME: "image/jpeg",
+			wantType: 3,
+			wantData: jpeg,
+		},
+		{
+			name:     "front cover replaces an earlier picture",
+			major:    3,
+			frames:   [][]byte{frame(3, "APIC", 0, apic("image/png", 4, png)), frame(3, "APIC", 0, apic("jpg", 3, jpeg))},
+			wantMIME: "image/jpeg",
+			wantType: 3,
+			wantData: jpeg,
+		},
+		{
+			name:     "MIME guessed from magic bytes",
+			major:    4,
+			frames:   [][]byte{frame(4, "APIC", 0, apic("", 0, png))},
+			wantMIME: "image/png",
+			wantType: 0,
+			wantData: png,
+		},
+		{
+			name:     "ID3v2.2 PIC",
+			major:    2,
+			frames:   [][]byte{frame(2, "PIC", 0, append([]byte{0, 'P', 'N', 'G', 3, 0}, png...))},
+			wantMIME: "image/png",
+			wantType: 3,
+			wantData: png,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var meta Metadata
+			parseFrames(bytes.Join(tt.frames, nil), tt.major, &meta)
+			pic := meta.Picture
+			if pic == nil {
+				t.Fatal("Picture = nil")
+			}
+			if pic.MIMEType != tt.wantMIME || pic.Type != tt.wantType || !bytes.Equal(pic.Data, tt.wantData) {
+				t.Fatalf("Picture = %q type %d %x, want %q type %d %x", pic.MIMEType, pic.Type, pic.Data, tt.wantMIME, tt.wantType, tt.wantData)
+			}
+		})
+	}
+}
+
+func TestParseGenre(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"(17)", "Rock"},
+		{"17", "Rock"},
+		{"(17)Indie Rock", "Indie Rock"},
+		{"Jazz", "Jazz"},
+		{"(999)", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := parseGenre(tt.in); got != tt.want {
+			t.Errorf("parseGenre(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseTrackAndYear(t *testing.T) {
+	tracks := map[string]int{"3": 3, "3/12": 3, " 4 ": 4, "": 0, "-1": 0, "A": 0}
+	for in, want := range tracks {
+		if got := parseTrack(in); got != want {
+			t.Errorf("parseTrack(%q) = %d, want %d", in, got, want)
+		}
+	}
+	years := map[string]int{"2019": 2019, "2019-05-01": 2019, "19": 0, "abcd": 0}
+	for in, want := range years {
+		if got := parseYear(in); got != want {
+			t.Errorf("parseYear(%q) = %d, want %d", in, got, want)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	// Xing header ที่บอกว่ามี 1000 frame (ต่อจาก side information 32 ไบต์ของ MPEG1 stereo)
+	xing := cbrFrames(3)
+	copy(xing[36:], "Xing")
+	binary.BigEndian.PutUint32(xing[40:], 0x01)
+	binary.BigEndian.PutUint32(xing[44:], 1000)
+	xingSeconds := float64(1000) * 1152 / 44100
+
+	tests := []struct {
+		name    string
+		file    []byte
+		want    Metadata
+		wantErr error
+	}{
+		{
+			name: "ID3v2 wins over ID3v1 and CBR duration",
+			file: bytes.Join([][]byte{
+				id3v2(3, 0, frame(3, "TIT2", 0, text("V2 Title"))),
+				cbrFrames(100),
+				id3v1("V1 Title", "V1 Artist", "V1 Album", "1999", 5, 8),
+			}, nil),
+			want: Metadata{
+				Title: "V2 Title", Artist: "V1 Artist", Album: "V1 Album", Year: 1999, Genre: "Jazz", TrackNumber: 5,
+				Bitrate: 128, SampleRate: 44100, Channels: 2, Duration: time.Duration(417*100*8) * time.Second / 128000,
+			},
+		},
+		{
+			name: "Xing frame count gives VBR duration",
+			file: xing,
+			want: Metadata{
+				Bitrate: int(float64(len(xing)) * 8 / xingSeconds / 1000), SampleRate: 44100, Channels: 2,
+				Duration: time.Duration(xingSeconds * float64(time.Second)),
+			},
+		},
+		{
+			name: "tag-wide unsynchronisation in ID3v2.3",
+			file: append(id3v2(3, 0x80, frame(3, "TIT2", 0, []byte{0, 'A', 0xff, 0x00, 'B'})), cbrFrames(2)...),
+			want: Metadata{
+				Title: "AÿB", Bitrate: 128, SampleRate: 44100, Channels: 2, Duration: time.Duration(417*2*8) * time.Second / 128000,
+			},
+		},
+		{
+			name:    "no audio frames",
+			file:    append(id3v2(3, 0, frame(3, "TIT2", 0, text("Only Tag"))), make([]byte, 512)...),
+			want:    Metadata{Title: "Only Tag"},
+			wantErr: ErrNotMP3,
+		},
+		{
+			name:    "empty file",
+			file:    nil,
+			wantErr: ErrNotMP3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			meta, err := Parse(bytes.NewReader(tt.file), int64(len(tt.file)))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Parse() error = %v, want %v", err, tt.wantErr)
+			}
+			if meta == nil {
+				if tt.want != (Metadata{}) {
+					t.Fatalf("Parse() = nil, want %+v", tt.want)
+				}
+				return
+			}
+			if *meta != tt.want {
+				t.Fatalf("Parse() = %+v, want %+v", *meta, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package mp3meta // ประกาศ package mp3meta
+
+import (
+	"encoding/binary" // นำเข้า binary สำหรับอ่านตัวเลขแบบ big-endian
+	"io"              // นำเข้า io
+	"time"            // นำเข้า time
+)
+
+// scanLimit จำนวนไบต์สูงสุดที่ค้นหา frame sync หลังจากจุดเริ่มข้อมูลเสียง
+const scanLimit = 64 << 10
+
+// ตาราง bitrate (kbps) แยกตาม [MPEG1][layer] และ [MPEG2/2.5][layer] (index 0 = free, 15 = ไม่ถูกต้อง)
+var bitrates = [2][3][16]int{
+	{ // MPEG1
+		{0, 32, 64, 96, 128, 160, 192, 224, 256, 288, 320, 352, 384, 416, 448, 0}, // Layer I
+		{0, 32, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 384, 0},    // Layer II
+		{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 0},     // Layer III
+	},
+	{ // MPEG2 / MPEG2.5
+		{0, 32, 48, 56, 64, 80, 96, 112, 128, 144, 160, 176, 192, 224, 256, 0}, // Layer I
+		{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0},      // Layer II
+		{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0},      // Layer III
+	},
+}
+
+// ตาราง sample rate (Hz) แยกตาม version: MPEG1, MPEG2, MPEG2.5
+var sampleRates = [3][3]int{
+	{44100, 48000, 32000},
+	{22050, 24000, 16000},
+	{11025, 12000, 8000},
+}
+
+// frameHeader ข้อมูลจาก MPEG audio frame header
+type frameHeader struct {
+	version    int // 0 = MPEG1, 1 = MPEG2, 2 = MPEG2.5
+	layer      int // 0 = Layer I, 1 = Layer II, 2 = Layer III
+	bitrate    int // kbps
+	sampleRate int // Hz
+	padding    int
+	channels   int
+}
+
+// parseHeader ถอด frame header 4 ไบต์ คืน false ถ้าไม่ใช่ header ที่ถูกต้อง
+func parseHeader(b []byte) (frameHeader, bool) {
+	var h frameHeader
+	if b[0] != 0xff || b[1]&0xe0 != 0xe0 {
+		return h, false
+	}
+	switch (b[1] >> 3) & 0x03 {
+	case 3:
+		h.version = 0
+	case 2:
+		h.version = 1
+	case 0:
+		h.version = 2
+	default:
+		return h, false
+	}
+	layerBits := (b[1] >> 1) & 0x03
+	if layerBits == 0 {
+		return h, false
+	}
+	h.layer = 3 - int(layerBits)
+
+	bitrateIdx := b[2] >> 4
+	srIdx := (b[2] >> 2) & 0x03
+	if bitrateIdx == 0 || bitrateIdx == 15 || srIdx == 3 {
+		return h, false
+	}
+	table := 0
+	if h.version != 0 {
+		table = 1
+	}
+	h.bitrate = bitrates[table][h.layer][bitrateIdx]
+	h.sampleRate = sampleRates[h.version][srIdx]
+	h.padding = int((b[2] >> 1) & 0x01)
+	h.channels = 2
+	if b[3]>>6 == 3 {
+		h.channels = 1
+	}
+	return h, true
+}
+
+// samplesPerFrame จำนวน sample ต่อ frame
+func (h frameHeader) samplesPerFrame() int {
+	switch {
+	case h.layer == 0:
+		return 384
+	case h.layer == 2 && h.version != 0:
+		return 576
+	default:
+		return 1152
+	}
+}
+
+// frameLength ความยาวของ frame เป็นไบต์ (รวม header)
+func (h frameHeader) frameLength() int {
+	if h.layer == 0 {
+		return (12*h.bitrate*1000/h.sampleRate + h.padding) * 4
+	}
+	return h.samplesPerFrame()/8*h.bitrate*1000/h.sampleRate + h.padding
+}
+
+// sideInfoSize ขนาด side information ของ Layer III (ตำแหน่งของ Xing header อยู่ถัดไป)
+func (h frameHeader) sideInfoSize() int {
+	if h.version == 0 {
+		if h.channels == 1 {
+			return 17
+		}
+		return 32
+	}
+	if h.channels == 1 {
+		return 9
+	}
+	return 17
+}
+
+// readMPEG หา frame แรกของข้อมูลเสียงแล้วคำนวณความยาว bitrate sample rate และจำนวนช่องเสียง
+func readMPEG(r io.ReaderAt, start, end int64, meta *Metadata) error {
+	if start >= end {
+		return ErrNotMP3
+	}
+	n := end - start
+	if n > scanLimit {
+		n = scanLimit
+	}
+	buf := make([]byte, n)
+	read, err := r.ReadAt(buf, start)
+	if err != nil && err != io.EOF {
+		return err
+	}
+	buf = buf[:read]
+
+	for i := 0; i+4 <= len(buf); i++ {
+		h, ok := parseHeader(buf[i:])
+		if !ok {
+			continue
+		}
+		// ยืนยันด้วย header ของ frame ถัดไป (ถ้าอยู่ใน buffer) เพื่อกัน sync ปลอม
+		if next := i + h.frameLength(); next+4 <= len(buf) {
+			if _, ok := parseHeader(buf[next:]); !ok {
+				continue
+			}
+		}
+
+		meta.SampleRate = h.sampleRate
+		meta.Channels = h.channels
+		audioBytes := end - start - int64(i)
+
+		if frames := vbrFrames(buf[i:], h); frames > 0 {
+			seconds := float64(frames) * float64(h.samplesPerFrame()) / float64(h.sampleRate)
+			meta.Duration = time.Duration(seconds * float64(time.Second))
+			if seconds > 0 {
+				meta.Bitrate = int(float64(audioBytes) * 8 / seconds / 1000)
+			}
+			return nil
+		}
+
+		meta.Bitrate = h.bitrate
+		meta.Duration = time.Duration(audioBytes * 8 * int64(time.Second) / int64(h.bitrate*1000))
+		return nil
+	}
+	return ErrNotMP3
+}
+
+// vbrFrames อ่านจำนวน frame จาก Xing/Info หรือ VBRI header ในเฟรมแรก (คืน 0 ถ้าไม่มี)
+func vbrFrames(frame []byte, h frameHeader) int {
+	if off := 4 + h.sideInfoSize(); off+12 <= len(frame) {
+		tag := string(frame[off : off+4])
+		if tag == "Xing" || tag == "Info" {
+			flags := binary.BigEndian.Uint32(frame[off+4 : off+8])
+			if flags&0x01 != 0 {
+				return int(binary.BigEndian.Uint32(frame[off+8 : off+12]))
+			}
+			return 0
+		}
+	}
+	if off := 4 + 32; off+18 <= len(frame) && string(frame[off:off+4]) == "VBRI" {
+		return int(binary.BigEndian.Uint32(frame[off+14 : off+18]))
+	}
+	return 0
+}
@@ -0,0 +1,258 @@
+// Package textseg ตัดคำข้อความสำหรับการค้นหา รองรับภาษาไทยที่ไม่มีช่องว่างระหว่างคำ
+package textseg // ประกาศ package textseg
+
+import (
+	"bufio"        // นำเข้า bufio สำหรับอ่านไฟล์พจนานุกรมทีละบรรทัด
+	_ "embed"      // นำเข้า embed สำหรับฝังพจนานุกรมเริ่มต้น
+	"io"           // นำเข้า io
+	"strings"      // นำเข้า strings
+	"sync"         // นำเข้า sync สำหรับป้องกันการเขียนพจนานุกรมพร้อมกัน
+	"unicode"      // นำเข้า unicode สำหรับจำแนกชนิดตัวอักษร
+	"unicode/utf8" // นำเข้า utf8 สำหรับอ่าน rune ทีละตัว
+)
+
+//go:embed dict_th.txt
+var defaultDict string
+
+// Token คำที่ตัดได้พร้อมตำแหน่ง byte ในข้อความต้นฉบับ
+type Token struct {
+	Text  string // คำ (ตัวพิมพ์เล็กสำหรับภาษาอังกฤษ)
+	Start int    // ตำแหน่งเริ่มต้น (byte offset)
+	End   int    // ตำแหน่งสิ้นสุด (ไม่รวม)
+}
+
+// trieNode โหนดของ trie ที่ใช้ค้นหาคำในพจนานุกรม
+type trieNode struct {
+	children map[rune]*trieNode
+	word     bool
+}
+
+var (
+	mu   sync.RWMutex
+	root = &trieNode{children: map[rune]*trieNode{}}
+)
+
+func init() {
+	_ = LoadDictionary(strings.NewReader(defaultDict))
+}
+
+// AddWords เพิ่มคำลงในพจนานุกรมภาษาไทย
+func AddWords(words ...string) {
+	mu.Lock()
+	defer mu.Unlock()
+	for _, w := range words {
+		w = strings.TrimSpace(w)
+		if w == "" {
+			continue
+		}
+		n := root
+		for _, r := range w {
+			child, ok := n.children[r]
+			if !ok {
+				child = &trieNode{children: map[rune]*trieNode{}}
+				n.children[r] = child
+			}
+			n = child
+		}
+		n.word = true
+	}
+}
+
+// LoadDictionary อ่านคำจาก reader (หนึ่งคำต่อบรรทัด ข้ามบรรทัดที่ขึ้นต้นด้วย #)
+func LoadDictionary(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	var words []string
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		words = append(words, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	AddWords(words...)
+	return nil
+}
+
+// isThai ตรวจสอบว่าเป็นตัวอักษรไทย (ไม่รวมเลขไทย)
+func isThai(r rune) bool {
+	return r >= 0x0E01 && r <= 0x0E4E
+}
+
+// isWordRune ตัวอักษรหรือตัวเลขที่ไม่ใช่ภาษาไทย
+func isWordRune(r rune) bool {
+	return !isThai(r) && (unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r))
+}
+
+// Tokenize ตัดข้อความเป็นคำ ภาษาไทยตัดด้วยพจนานุกรม ภาษาอื่นตัดตามช่องว่างและเครื่องหมาย
+func Tokenize(s string) []Token {
+	var tokens []Token
+	i := 0
+	for i < len(s) {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case isThai(r):
+			end := i
+			for end < len(s) {
+				r2, sz := utf8.DecodeRuneInString(s[end:])
+				if !isThai(r2) {
+					break
+				}
+				end += sz
+			}
+			tokens = append(tokens, segmentThai(s, i, end)...)
+			i = end
+		case isWordRune(r):
+			end := i
+			for end < len(s) {
+				r2, sz := utf8.DecodeRuneInString(s[end:])
+				if !isWordRune(r2) {
+					break
+				}
+				end += sz
+			}
+			tokens = append(tokens, Token{Text: strings.ToLower(s[i:end]), Start: i, End: end})
+			i = end
+		default:
+			i += size
+		}
+	}
+	return tokens
+}
+
+// Words คืนเฉพาะข้อความของคำที่ตัดได้
+func Words(s string) []string {
+	tokens := Tokenize(s)
+	words := make([]string, len(tokens))
+	for i, t := range tokens {
+		words[i] = t.Text
+	}
+	return words
+}
+
+// Highlight ครอบคำที่อยู่ใน terms ด้วย pre/post และบอกว่ามีคำที่ตรงหรือไม่
+func Highlight(s string, terms map[string]bool, pre, post string) (string, bool) {
+	var b strings.Builder
+	matched := false
+	last := 0
+	for _, t := range Tokenize(s) {
+		if !terms[t.Text] {
+			continue
+		}
+		matched = true
+		b.WriteString(s[last:t.Start])
+		b.WriteString(pre)
+		b.WriteString(s[t.Start:t.End])
+		b.WriteString(post)
+		last = t.End
+	}
+	if !matched {
+		return s, false
+	}
+	b.WriteString(s[last:])
+	return b.String(), true
+}
+
+// segmentThai ตัดคำภาษาไทยช่วง s[start:end] แบบ maximal matching
+// โดยเลือกผลที่มีตัวอักษรที่ไม่รู้จักน้อยที่สุด และจำนวนคำน้อยที่สุดเป็นลำดับรอง
+func segmentThai(s string, start, end int) []Token {
+	runes := []rune(s[start:end])
+	offsets := make([]int, len(runes)+1)
+	pos := start
+	for i, r := range runes {
+		offsets[i] = pos
+		pos += utf8.RuneLen(r)
+	}
+	offsets[len(runes)] = end
+
+	type state struct {
+		unknown, words int
+		prev           int
+		known          bool
+		reached        bool
+	}
+	n := len(runes)
+	best := make([]state, n+1)
+	best[0].reached = true
+
+	better := func(a state, b state) bool {
+		if !b.reached {
+			return true
+		}
+		if a.unknown != b.unknown {
+			return a.unknown < b.unknown
+		}
+		return a.words < b.words
+	}
+
+	mu.RLock()
+	for i := 0; i < n; i++ {
+		if !best[i].reached {
+			continue
+		}
+		// คำที่มีในพจนานุกรมซึ่งเริ่มที่ตำแหน่ง i
+		node := root
+		for j := i; j < n; j++ {
+			node = node.children[runes[j]]
+			if node == nil {
+				break
+			}
+			if node.word {
+				cand := state{unknown: best[i].unknown, words: best[i].words + 1, prev: i, known: true, reached: true}
+				if better(cand, best[j+1]) {
+					best[j+1] = cand
+				}
+			}
+		}
+		// ถ้าไม่รู้จัก ให้ข้ามไปหนึ่งกลุ่มตัวอักษร (พยัญชนะพร้อมสระ/วรรณยุกต์ที่ติดกัน)
+		j := clusterEnd(runes, i)
+		cand := state{unknown: best[i].unknown + (j - i), words: best[i].words + 1, prev: i, reached: true}
+		if better(cand, best[j]) {
+			best[j] = cand
+		}
+	}
+	mu.RUnlock()
+
+	// ย้อนรอยเส้นทางที่ดีที่สุด แล้วรวมกลุ่มที่ไม่รู้จักที่อยู่ติดกันเป็นคำเดียว
+	type span struct {
+		from, to int
+		known    bool
+	}
+	var spans []span
+	for i := n; i > 0; i = best[i].prev {
+		spans = append(spans, span{from: best[i].prev, to: i, known: best[i].known})
+	}
+	var tokens []Token
+	for k := len(spans) - 1; k >= 0; k-- {
+		sp := spans[k]
+		if !sp.known && len(tokens) > 0 && k+1 < len(spans) && !spans[k+1].known {
+			last := &tokens[len(tokens)-1]
+			last.End = offsets[sp.to]
+			last.Text = s[last.Start:last.End]
+			continue
+		}
+		tokens = append(tokens, Token{Text: s[offsets[sp.from]:offsets[sp.to]], Start: offsets[sp.from], End: offsets[sp.to]})
+	}
+	return tokens
+}
+
+// clusterEnd หาตำแหน่งสิ้นสุดของกลุ่มตัวอักษรไทยที่ต้องอยู่ด้วยกันเสมอ
+func clusterEnd(runes []rune, i int) int {
+	j := i
+	// สระหน้า (เ แ โ ใ ไ) ต้องอยู่กับพยัญชนะตัวถัดไป
+	if runes[j] >= 0x0E40 && runes[j] <= 0x0E44 && j+1 < len(runes) {
+		j++
+	}
+	j++
+	for j < len(runes) && isTrailing(runes[j]) {
+		j++
+	}
+	return j
+}
+
+// isTrailing สระบน/ล่าง วรรณยุกต์ และสระหลังที่ต้องติดกับพยัญชนะก่อนหน้า
+func isTrailing(r rune) bool {
+	return unicode.Is(unicode.Mn, r) || r == 0x0E30 || r == 0x0E32 || r == 0x0E33 || r == 0x0E45
+}
@@ -0,0 +1,92 @@
+// Package totp สร้างและตรวจสอบรหัสผ่านใช้ครั้งเดียวตามเวลา (TOTP, RFC 6238) สำหรับแอปยืนยันตัวตน
+package totp // ประกาศ package totp
+
+import (
+	"crypto/hmac"     // นำเข้า hmac
+	"crypto/rand"     // นำเข้า rand สำหรับสุ่ม secret
+	"crypto/sha1"     // นำเข้า sha1 (อัลกอริทึมที่แอปยืนยันตัวตนรองรับทุกตัว)
+	"crypto/subtle"   // นำเข้า subtle สำหรับเทียบรหัสแบบเวลาคงที่
+	"encoding/base32" // นำเข้า base32 สำหรับ secret
+	"encoding/binary" // นำเข้า binary สำหรับแปลง counter
+	"fmt"             // นำเข้า fmt
+	"net/url"         // นำเข้า url สำหรับสร้าง otpauth URI
+	"strings"         // นำเข้า strings
+	"time"            // นำเข้า time
+)
+
+// ค่าคงที่ของ TOTP ที่ใช้ (ค่า default ของแอปยืนยันตัวตนทั่วไป)
+const (
+	Period = 30 // วินาทีต่อหนึ่งช่วงเวลา
+	Digits = 6  // จำนวนหลักของรหัส
+)
+
+// encoding base32 แบบไม่มี padding ตามรูปแบบของ otpauth URI
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateSecret สุ่ม secret ขนาด 160 บิต (ตามที่ RFC 4226 แนะนำ) ในรูปแบบ base32
+func GenerateSecret() (string, error) {
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return encoding.EncodeToString(b), nil
+}
+
+// Step คืนหมายเลขช่วงเวลาของ t
+func Step(t time.Time) int64 {
+	return t.Unix() / Period
+}
+
+// Code คำนวณรหัสของช่วงเวลา step (HOTP ตาม RFC 4226)
+func Code(secret string, step int64) (string, error) {
+	key, err := encoding.DecodeString(strings.ToUpper(strings.TrimRight(secret, "=")))
+	if err != nil {
+		return "", err
+	}
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	mod := uint32(1)
+	for range Digits {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", Digits, value%mod), nil
+}
+
+// Validate ตรวจรหัสโดยยอมให้นาฬิกาคลาดเคลื่อนได้ skew ช่วงเวลา คืนช่วงเวลาที่รหัสตรง
+// ผู้เรียกควรเก็บช่วงเวลาล่าสุดที่ใช้ไปแล้ว และไม่รับช่วงเวลาที่ไม่มากกว่านั้น (กันการใช้รหัสซ้ำ)
+func Validate(secret, code string, t time.Time, skew int) (int64, bool) {
+	code = strings.TrimSpace(code)
+	if len(code) != Digits {
+		return 0, false
+	}
+	now := Step(t)
+	for i := -skew; i <= skew; i++ {
+		step := now + int64(i)
+		expected, err := Code(secret, step)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// ProvisioningURI สร้าง otpauth URI สำหรับเพิ่มบัญชีในแอปยืนยันตัวตน (ใช้เป็นข้อมูลของ QR code)
+func ProvisioningURI(issuer, account, secret string) string {
+	q := url.Values{}
+	q.Set("secret", secret)
+	q.Set("issuer", issuer)
+	q.Set("algorithm", "SHA1")
+	q.Set("digits", fmt.Sprint(Digits))
+	q.Set("period", fmt.Sprint(Period))
+	label := url.PathEscape(issuer + ":" + account)
+	return "otpauth://totp/" + label + "?" + q.Encode()
+}
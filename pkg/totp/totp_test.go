@@ -0,0 +1,79 @@
+package totp
+
+import (
+	"testing"
+	"time"
+)
+
+// rfcSecret key ของ RFC 6238 Appendix B สำหรับ SHA-1 ("12345678901234567890") ในรูปแบบ base32
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCodeRFC6238(t *testing.T) {
+	// ค่าจาก RFC 6238 Appendix B (SHA-1, 8 หลัก) รหัส 6 หลักคือ 6 หลักท้ายของค่าเดียวกัน
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{unix: 59, want: "287082"},          // 94287082
+		{unix: 1111111109, want: "081804"},  // 07081804
+		{unix: 1111111111, want: "050471"},  // 14050471
+		{unix: 1234567890, want: "005924"},  // 89005924
+		{unix: 2000000000, want: "279037"},  // 69279037
+		{unix: 20000000000, want: "353130"}, // 65353130
+	}
+
+	for _, tt := range tests {
+		got, err := Code(rfcSecret, Step(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("Code(T=%d) error = %v", tt.unix, err)
+		}
+		if got != tt.want {
+			t.Errorf("Code(T=%d) = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestValidateSkew(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	step := Step(now)
+
+	tests := []struct {
+		name     string
+		offset   int64 // ช่วงเวลาของรหัสเทียบกับเวลาปัจจุบัน
+		skew     int
+		wantOK   bool
+		wantStep int64
+	}{
+		{name: "current step", offset: 0, skew: 1, wantOK: true, wantStep: step},
+		{name: "previous step within skew", offset: -1, skew: 1, wantOK: true, wantStep: step - 1},
+		{name: "next step within skew", offset: 1, skew: 1, wantOK: true, wantStep: step + 1},
+		{name: "two steps behind", offset: -2, skew: 1},
+		{name: "two steps ahead", offset: 2, skew: 1},
+		{name: "previous step without skew", offset: -1, skew: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, err := Code(rfcSecret, step+tt.offset)
+			if err != nil {
+				t.Fatalf("Code() error = %v", err)
+			}
+			got, ok := Validate(rfcSecret, code, now, tt.skew)
+			if ok != tt.wantOK || got != tt.wantStep {
+				t.Fatalf("Validate() = %d, %v, want %d, %v", got, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestValidateRejectsMalformedCodes(t *testing.T) {
+	now := time.Unix(59, 0)
+	for _, code := range []string{"", "28708", "2870820", "94287082"} {
+		if _, ok := Validate(rfcSecret, code, now, 1); ok {
+			t.Errorf("Validate(%q) = true, want false", code)
+		}
+	}
+	if _, ok := Validate(rfcSecret, " 287082 ", now, 0); !ok {
+		t.Error("Validate() with surrounding spaces = false, want true")
+	}
+}
@@ -7,62 +7,58 @@ import (
 	"github.com/golang-jwt/jwt/v5" // นำเข้า jwt library
 )
 
-// secretKey สำหรับเซ็นลายเซ็น token (ควรเก็บใน .env ในการใช้งานจริง)
-var secretKey = []byte("your-secret-key") // In real app, load from ENV
+// keyManager ชุด key สำหรับเซ็นและตรวจสอบ token (กำหนดตอนเริ่มระบบด้วย SetKeyManager)
+var keyManager *KeyManager
+
+// SetKeyManager กำหนดชุด key ที่ใช้เซ็นและตรวจสอบ token
+func SetKeyManager(m *KeyManager) {
+	keyManager = m
+}
+
+// errNoKeys เกิดเมื่อยังไม่ได้กำหนดชุด key
+var errNoKeys = errors.New("jwt signing keys are not configured")
 
 // Claims struct สำหรับเก็บข้อมูลใน Payload ของ JWT
 type Claims struct {
 	UserID uint   `json:"user_id"` // เก็บ ID ของผู้ใช้
 	Email  string `json:"email"`   // เก็บ Email ของผู้ใช้
+	Role   string `json:"role"`    // เก็บบทบาทของผู้ใช้ (ใช้ตรวจสิทธิ์)
+	// SessionID ของ session ที่ออก token นี้ (ใช้แสดง session ปัจจุบัน)
+	SessionID string `json:"sid,omitempty"`
+	// MFA session นี้ยืนยันตัวตนด้วย 2FA แล้ว
+	MFA bool `json:"mfa,omitempty"`
 	jwt.RegisteredClaims
 }
 
-// GenerateTokenPair สร้าง Access Token และ Refresh Token
-func GenerateTokenPair(userID uint, email string) (string, string, error) {
-	// สร้าง Access Token (อายุสั้น เช่น 15 นาที)
-	accessTokenClaims := &Claims{
-		UserID: userID,
-		Email:  email,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(15 * time.Minute)), // หมดอายุใน 15 นาที
-			IssuedAt:  jwt.NewNumericDate(time.Now()),                       // เวลาที่ออก token
-		},
-	}
-	accessToken := jwt.NewWithClaims(jwt.SigningMethodHS256, accessTokenClaims)
-	accessTokenString, err := accessToken.SignedString(secretKey)
-	if err != nil {
-		return "", "", err
-	}
+// AccessTokenTTL อายุของ Access Token
+const AccessTokenTTL = 15 * time.Minute
 
-	// สร้าง Refresh Token (อายุนาน เช่น 7 วัน)
-	refreshTokenClaims := &Claims{
-		UserID: userID,
-		Email:  email,
+// GenerateAccessToken สร้าง Access Token (อายุสั้น) ที่ผูกกับ session ของผู้ใช้
+func GenerateAccessToken(userID uint, email, role, sessionID string, mfa bool) (string, error) {
+	claims := &Claims{
+		UserID:    userID,
+		Email:     email,
+		Role:      role,
+		SessionID: sessionID,
+		MFA:       mfa,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(7 * 24 * time.Hour)), // หมดอายุใน 7 วัน
-			IssuedAt:  jwt.NewNumericDate(time.Now()),                         // เวลาที่ออก token
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(AccessTokenTTL)), // หมดอายุใน 15 นาที
+			IssuedAt:  jwt.NewNumericDate(time.Now()),                     // เวลาที่ออก token
 		},
 	}
-	refreshToken := jwt.NewWithClaims(jwt.SigningMethodHS256, refreshTokenClaims)
-	refreshTokenString, err := refreshToken.SignedString(secretKey)
-	if err != nil {
-		return "", "", err
+	if keyManager == nil {
+		return "", errNoKeys
 	}
-
-	return accessTokenString, refreshTokenString, nil
+	return keyManager.Sign(claims)
 }
 
 // ValidateToken ตรวจสอบความถูกต้องของ JWT token
 func ValidateToken(tokenString string) (*Claims, error) {
-	// Parse และตรวจสอบ token
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		// ตรวจสอบว่า signing method ถูกต้องหรือไม่ (ต้องเป็น HMAC)
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("unexpected signing method")
-		}
-		// คืนค่า secret key สำหรับการตรวจสอบลายเซ็น
-		return secretKey, nil
-	})
+	if keyManager == nil {
+		return nil, errNoKeys
+	}
+	// Parse และตรวจสอบ token ด้วย key ตาม kid (รวม key ที่เลิกใช้แล้ว)
+	token, err := keyManager.Parse(tokenString, &Claims{})
 
 	if err != nil {
 		return nil, err
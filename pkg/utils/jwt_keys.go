@@ -0,0 +1,250 @@
+package utils // ประกาศ package utils
+
+import (
+	"crypto/ed25519"  // นำเข้า ed25519 สำหรับ EdDSA
+	"crypto/rsa"      // นำเข้า rsa สำหรับ RS256
+	"crypto/sha256"   // นำเข้า sha256 สำหรับคำนวณ kid
+	"crypto/x509"     // นำเข้า x509 สำหรับแปลง PEM เป็น key
+	"encoding/base64" // นำเข้า base64 สำหรับ JWK
+	"encoding/hex"    // นำเข้า hex
+	"encoding/json"   // นำเข้า json สำหรับ JWK thumbprint
+	"encoding/pem"    // นำเข้า pem สำหรับอ่านไฟล์ key
+	"errors"          // นำเข้า errors
+	"fmt"             // นำเข้า fmt
+	"math/big"        // นำเข้า big สำหรับ exponent ของ RSA
+	"os"              // นำเข้า os สำหรับอ่านไฟล์ key
+
+	"github.com/golang-jwt/jwt/v5" // นำเข้า jwt library
+)
+
+// อัลกอริทึมที่รองรับ
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+// SigningKey key หนึ่งตัวสำหรับเซ็นหรือตรวจสอบ JWT
+type SigningKey struct {
+	KID       string // key ID ที่ใส่ใน header "kid"
+	Algorithm string // HS256, RS256 หรือ EdDSA
+	private   any    // key สำหรับเซ็น (nil ถ้าเป็น public key อย่างเดียว)
+	public    any    // key สำหรับตรวจสอบ
+}
+
+// CanSign บอกว่า key นี้ใช้เซ็น token ได้หรือไม่
+func (k *SigningKey) CanSign() bool {
+	return k.private != nil
+}
+
+// method คืน signing method ของ key
+func (k *SigningKey) method() jwt.SigningMethod {
+	switch k.Algorithm {
+	case AlgRS256:
+		return jwt.SigningMethodRS256
+	case AlgEdDSA:
+		return jwt.SigningMethodEdDSA
+	default:
+		return jwt.SigningMethodHS256
+	}
+}
+
+// NewHMACKey สร้าง key แบบ HS256 จาก secret ถ้าไม่ระบุ kid จะคำนวณจาก hash ของ secret
+func NewHMACKey(secret []byte, kid string) (*SigningKey, error) {
+	if len(secret) == 0 {
+		return nil, errors.New("empty HMAC secret")
+	}
+	if kid == "" {
+		sum := sha256.Sum256(append([]byte("jwt-kid:"), secret...))
+		kid = "hs-" + hex.EncodeToString(sum[:8])
+	}
+	return &SigningKey{KID: kid, Algorithm: AlgHS256, private: secret, public: secret}, nil
+}
+
+// ParsePEMKey อ่าน key RSA หรือ Ed25519 จาก PEM (private key ใช้เซ็นได้, public key ใช้ตรวจสอบได้อย่างเดียว)
+// ถ้าไม่ระบุ kid จะใช้ JWK thumbprint (RFC 7638) ของ public key
+func ParsePEMKey(data []byte, kid string) (*SigningKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	var parsed any
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		parsed, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key := &SigningKey{KID: kid}
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.Algorithm, key.private, key.public = AlgRS256, k, &k.PublicKey
+	case *rsa.PublicKey:
+		key.Algorithm, key.public = AlgRS256, k
+	case ed25519.PrivateKey:
+		key.Algorithm, key.private, key.public = AlgEdDSA, k, k.Public()
+	case ed25519.PublicKey:
+		key.Algorithm, key.public = AlgEdDSA, k
+	default:
+		return nil, fmt.Errorf("unsupported key type %T (use RSA or Ed25519)", parsed)
+	}
+	if rk, ok := key.public.(*rsa.PublicKey); ok && rk.N.BitLen() < 2048 {
+		return nil, errors.New("RSA keys must be at least 2048 bits")
+	}
+	if key.KID == "" {
+		key.KID = key.JWK().thumbprint()
+	}
+	return key, nil
+}
+
+// LoadPEMKeyFile อ่าน key จากไฟล์ PEM
+func LoadPEMKeyFile(path, kid string) (*SigningKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := ParsePEMKey(data, kid)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return key, nil
+}
+
+// JWK public key ในรูปแบบ JSON Web Key (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	Crv string `json:"crv,omitempty"` // OKP
+	X   string `json:"x,omitempty"`   // OKP
+	N   string `json:"n,omitempty"`   // RSA
+	E   string `json:"e,omitempty"`   // RSA
+}
+
+// JWKSet ชุดของ public key สำหรับ /.well-known/jwks.json
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWK คืน public key ในรูปแบบ JWK (key แบบ HMAC เปิดเผยไม่ได้ จะคืน JWK ว่าง)
+func (k *SigningKey) JWK() JWK {
+	b64 := base64.RawURLEncoding.EncodeToString
+	switch pub := k.public.(type) {
+	case *rsa.PublicKey:
+		return JWK{Kty: "RSA", Kid: k.KID, Use: "sig", Alg: AlgRS256,
+			N: b64(pub.N.Bytes()), E: b64(big.NewInt(int64(pub.E)).Bytes())}
+	case ed25519.PublicKey:
+		return JWK{Kty: "OKP", Kid: k.KID, Use: "sig", Alg: AlgEdDSA, Crv: "Ed25519", X: b64(pub)}
+	}
+	return JWK{}
+}
+
+// thumbprint คำนวณ JWK thumbprint ตาม RFC 7638 (ใช้ member ที่จำเป็นเรียงตามตัวอักษร)
+func (j JWK) thumbprint() string {
+	var members any
+	if j.Kty == "RSA" {
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{j.E, j.Kty, j.N}
+	} else {
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{j.Crv, j.Kty, j.X}
+	}
+	data, _ := json.Marshal(members)
+	sum := sha256.Sum256(data)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// KeyManager เก็บ key ที่ใช้เซ็น token ปัจจุบัน และ key ที่เลิกใช้แล้วแต่ยังรับตรวจสอบ (ใช้หมุน key โดยผู้ใช้ไม่หลุดจากระบบ)
+type KeyManager struct {
+	active *SigningKey            // key ที่ใช้เซ็น token ใหม่
+	keys   map[string]*SigningKey // key ทั้งหมดที่รับตรวจสอบ ตาม kid
+	order  []*SigningKey          // key ทั้งหมดตามลำดับที่กำหนด
+}
+
+// NewKeyManager สร้าง KeyManager โดย active ต้องเป็น key ที่เซ็นได้ ส่วน retired ใช้ตรวจสอบอย่างเดียว
+func NewKeyManager(active *SigningKey, retired ...*SigningKey) (*KeyManager, error) {
+	if active == nil || !active.CanSign() {
+		return nil, errors.New("active JWT key must be a secret or a private key")
+	}
+	m := &KeyManager{active: active, keys: make(map[string]*SigningKey)}
+	for _, k := range append([]*SigningKey{active}, retired...) {
+		if _, dup := m.keys[k.KID]; dup {
+			return nil, fmt.Errorf("duplicate JWT key id %q", k.KID)
+		}
+		m.keys[k.KID] = k
+		m.order = append(m.order, k)
+	}
+	return m, nil
+}
+
+// Active คืน key ที่ใช้เซ็น token ใหม่
+func (m *KeyManager) Active() *SigningKey {
+	return m.active
+}
+
+// Sign เซ็น claims ด้วย key ปัจจุบันและใส่ kid ใน header
+func (m *KeyManager) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(m.active.method(), claims)
+	token.Header["kid"] = m.active.KID
+	return token.SignedString(m.active.private)
+}
+
+// keyfunc เลือก key สำหรับตรวจสอบ token ตาม kid และต้องตรงกับอัลกอริทึมของ key (กัน algorithm confusion)
+// token ที่ไม่มี kid (ออกก่อนมีการจัดการ key) จะลองกับทุก key ที่อัลกอริทึมตรงกัน
+func (m *KeyManager) keyfunc(token *jwt.Token) (any, error) {
+	alg := token.Method.Alg()
+	if kid, ok := token.Header["kid"].(string); ok {
+		key, found := m.keys[kid]
+		if !found || key.Algorithm != alg {
+			return nil, errors.New("unknown signing key")
+		}
+		return key.public, nil
+	}
+
+	var set jwt.VerificationKeySet
+	for _, k := range m.order {
+		if k.Algorithm == alg {
+			set.Keys = append(set.Keys, k.public)
+		}
+	}
+	if len(set.Keys) == 0 {
+		return nil, errors.New("unexpected signing method")
+	}
+	return set, nil
+}
+
+// Parse ตรวจสอบลายเซ็นและอ่าน claims ของ token
+func (m *KeyManager) Parse(tokenString string, claims jwt.Claims) (*jwt.Token, error) {
+	return jwt.ParseWithClaims(tokenString, claims, m.keyfunc,
+		jwt.WithValidMethods([]string{AlgHS256, AlgRS256, AlgEdDSA}))
+}
+
+// JWKS คืน public key ทั้งหมด (ทั้งที่ใช้อยู่และที่เลิกใช้แล้ว) key แบบ HMAC จะไม่ถูกเปิดเผย
+func (m *KeyManager) JWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+	for _, k := range m.order {
+		if k.Algorithm != AlgHS256 {
+			set.Keys = append(set.Keys, k.JWK())
+		}
+	}
+	return set
+}
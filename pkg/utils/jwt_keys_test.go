@@ -0,0 +1,222 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// pemKey แปลง key เป็น PEM แบบ PKCS#8 หรือ PKIX แล้วอ่านกลับด้วย ParsePEMKey
+func pemKey(t *testing.T, key any, kid string) *SigningKey {
+	t.Helper()
+	var block *pem.Block
+	switch k := key.(type) {
+	case *rsa.PublicKey, ed25519.PublicKey:
+		der, err := x509.MarshalPKIXPublicKey(k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		block = &pem.Block{Type: "PUBLIC KEY", Bytes: der}
+	default:
+		der, err := x509.MarshalPKCS8PrivateKey(k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		block = &pem.Block{Type: "PRIVATE KEY", Bytes: der}
+	}
+	sk, err := ParsePEMKey(pem.EncodeToMemory(block), kid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sk
+}
+
+// testClaims claims ที่ยังไม่หมดอายุ
+func testClaims() *Claims {
+	return &Claims{UserID: 7, RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute))}}
+}
+
+// signWith เซ็น token ด้วย method และ key ที่กำหนด (kid ว่าง = ไม่ใส่ kid)
+func signWith(t *testing.T, method jwt.SigningMethod, key any, kid string) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, testClaims())
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	raw, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+func TestKeyManagerParse(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hmacSecret := []byte("current-secret")
+	oldSecret := []byte("retired-secret")
+
+	active := pemKey(t, rsaKey, "rsa-1")
+	hmac, _ := NewHMACKey(hmacSecret, "hs-1")
+	retired, _ := NewHMACKey(oldSecret, "hs-old")
+	ed := pemKey(t, edKey.Public(), "ed-1") // public key อย่างเดียว ใช้ตรวจสอบ token ที่ออกจากที่อื่น
+	m, err := NewKeyManager(active, hmac, retired, ed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// RSA public key ในรูป PEM ที่เปิดเผยผ่าน JWKS (ผู้โจมตีอาจใช้เป็น HMAC secret)
+	pubDER, _ := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	pubPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER})
+
+	tests := []struct {
+		name    string
+		token   func() string
+		wantErr string // ว่าง = ต้องผ่าน
+	}{
+		{name: "active RSA key", token: func() string { s, _ := m.Sign(testClaims()); return s }},
+		{name: "other HMAC key by kid", token: func() string { return signWith(t, jwt.SigningMethodHS256, hmacSecret, "hs-1") }},
+		{name: "retired key by kid", token: func() string { return signWith(t, jwt.SigningMethodHS256, oldSecret, "hs-old") }},
+		{name: "EdDSA verify-only key", token: func() string { return signWith(t, jwt.SigningMethodEdDSA, edKey, "ed-1") }},
+		{name: "token without kid matches by algorithm", token: func() string { return signWith(t, jwt.SigningMethodHS256, oldSecret, "") }},
+		{
+			name:    "unknown kid",
+			token:   func() string { return signWith(t, jwt.SigningMethodHS256, hmacSecret, "hs-missing") },
+			wantErr: "unknown signing key",
+		},
+		{
+			name:    "HS256 signed with the RSA public key",
+			token:   func() string { return signWith(t, jwt.SigningMethodHS256, pubPEM, "rsa-1") },
+			wantErr: "unknown signing key",
+		},
+		{
+			name:    "HS256 signed with the RSA public key without kid",
+			token:   func() string { return signWith(t, jwt.SigningMethodHS256, pubPEM, "") },
+			wantErr: "signature is invalid",
+		},
+		{
+			name:    "RS256 under an HMAC kid",
+			token:   func() string { return signWith(t, jwt.SigningMethodRS256, rsaKey, "hs-1") },
+			wantErr: "unknown signing key",
+		},
+		{
+			name:    "wrong secret under a known kid",
+			token:   func() string { return signWith(t, jwt.SigningMethodHS256, []byte("guess"), "hs-1") },
+			wantErr: "signature is invalid",
+		},
+		{
+			name:    "alg none",
+			token:   func() string { return signWith(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, "rsa-1") },
+			wantErr: "signing method none is invalid",
+		},
+		{
+			name:    "algorithm outside the allowed list",
+			token:   func() string { return signWith(t, jwt.SigningMethodHS512, hmacSecret, "hs-1") },
+			wantErr: "signing method HS512 is invalid",
+		},
+		{
+			name: "expired",
+			token: func() string {
+				c := testClaims()
+				c.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+				s, _ := m.Sign(c)
+				return s
+			},
+			wantErr: "token is expired",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := &Claims{}
+			_, err := m.Parse(tt.token(), claims)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Parse() error = %v", err)
+				}
+				if claims.UserID != 7 {
+					t.Fatalf("UserID = %d, want 7", claims.UserID)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Parse() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewKeyManager(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hmac, _ := NewHMACKey([]byte("secret"), "k1")
+	dup, _ := NewHMACKey([]byte("other"), "k1")
+	public := pemKey(t, &rsaKey.PublicKey, "")
+
+	tests := []struct {
+		name    string
+		active  *SigningKey
+		retired []*SigningKey
+		wantErr bool
+	}{
+		{name: "HMAC key", active: hmac},
+		{name: "public key cannot sign", active: public, wantErr: true},
+		{name: "missing active key", wantErr: true},
+		{name: "duplicate kid", active: hmac, retired: []*SigningKey{dup}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewKeyManager(tt.active, tt.retired...); (err != nil) != tt.wantErr {
+				t.Fatalf("NewKeyManager() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestJWKSHidesHMACKeys(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hmac, _ := NewHMACKey([]byte("secret"), "")
+	active := pemKey(t, rsaKey, "")
+	m, err := NewKeyManager(hmac, active)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	set := m.JWKS()
+	if len(set.Keys) != 1 || set.Keys[0].Kid != active.KID || set.Keys[0].Kty != "RSA" || set.Keys[0].Alg != AlgRS256 {
+		t.Fatalf("JWKS() = %+v", set)
+	}
+	// kid เริ่มต้นคือ JWK thumbprint ของ public key
+	if public := pemKey(t, &rsaKey.PublicKey, ""); public.KID != active.KID {
+		t.Fatalf("public key kid = %q, want %q", public.KID, active.KID)
+	}
+}
+
+func TestParsePEMKeyRejectsSmallRSA(t *testing.T) {
+	small, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, _ := x509.MarshalPKCS8PrivateKey(small)
+	if _, err := ParsePEMKey(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), ""); err == nil {
+		t.Fatal("ParsePEMKey() accepted a 1024-bit RSA key")
+	}
+}
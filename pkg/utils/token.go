@@ -0,0 +1,23 @@
+package utils // ประกาศ package utils
+
+import (
+	"crypto/rand"     // นำเข้า rand สำหรับสุ่ม token
+	"crypto/sha256"   // นำเข้า sha256 สำหรับ hash token ก่อนเก็บ
+	"encoding/base64" // นำเข้า base64
+	"encoding/hex"    // นำเข้า hex
+)
+
+// GenerateOpaqueToken สุ่ม token แบบทึบ (ไม่มีข้อมูลภายใน) ขนาด 256 บิต
+func GenerateOpaqueToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashToken คืน SHA-256 (hex) ของ token สำหรับเก็บในฐานข้อมูลแทน token จริง
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}